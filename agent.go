@@ -0,0 +1,255 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrMaxIterations is returned by Agent.Run when the model still calls
+	// tools after the last allowed step.
+	ErrMaxIterations = errors.New("agent: maximum iterations reached")
+	// ErrBudgetExceeded is returned by Agent.Run when the run has gone over
+	// its cost or token budget before the model answered.
+	ErrBudgetExceeded = errors.New("agent: budget exceeded")
+)
+
+// defaultAgentMaxIterations bounds the steps of an Agent without MaxIterations.
+const defaultAgentMaxIterations = 10
+
+// Agent answers an input by calling a model in a loop, running the tools it
+// calls and sending back their results, until the model answers without
+// calling tools:
+//
+//	agent := &openrouter.Agent{
+//		Client:       client,
+//		Model:        "anthropic/claude-sonnet-4",
+//		SystemPrompt: "You are a travel assistant.",
+//		Tools:        registry,
+//		Budget:       openrouter.AgentBudget{MaxCost: 0.10},
+//	}
+//	run, err := agent.Run(ctx, "What should I pack for Paris this week?")
+//
+// An Agent is safe for concurrent use if its hooks and tools are.
+type Agent struct {
+	// Client sends the chat completions.
+	Client ClientInterface
+	// Model answers the input.
+	Model string
+	// Router, if set, chooses the model of every step instead of Model.
+	Router AgentRouter
+	// SystemPrompt, if set, starts the messages of every run.
+	SystemPrompt string
+	// Tools offered to the model. Nil runs without tools.
+	Tools *ToolRegistry
+	// Request is the template of the requests of every step, e.g. to set the
+	// temperature or provider routing. Its Model, Messages and Tools are
+	// replaced.
+	Request ChatCompletionRequest
+	// MaxIterations is the maximum number of model calls of a run. Defaults
+	// to 10.
+	MaxIterations int
+	// Budget limits the usage of a run.
+	Budget AgentBudget
+	// BeforeStep, if set, is called with the request of every step before it
+	// is sent, and may change it. An error ends the run.
+	BeforeStep func(ctx context.Context, step int, request *ChatCompletionRequest) error
+	// AfterStep, if set, is called with every step once its tools have run.
+	// An error ends the run.
+	AfterStep func(ctx context.Context, step AgentStep) error
+}
+
+// AgentBudget limits the usage of an agent run, as reported by the API. Zero
+// values are unlimited.
+type AgentBudget struct {
+	// MaxCost is the maximum cost in credits.
+	MaxCost float64
+	// MaxTokens is the maximum number of prompt and completion tokens.
+	MaxTokens int
+}
+
+// AgentStep is one model call of an agent run and the tool calls it made.
+type AgentStep struct {
+	Index int
+	// Model answered the step, as chosen by the Router for Reason.
+	Model    string
+	Reason   string
+	Response ChatCompletionResponse
+	Message  ChatCompletionMessage
+	Tools    []AgentToolCall
+	Latency  time.Duration
+}
+
+// AgentToolCall is a tool call made by the model and its outcome. Failed
+// calls send their error to the model as the result, so it can recover.
+type AgentToolCall struct {
+	Call     ToolCall
+	Result   string
+	Err      error
+	Duration time.Duration
+}
+
+// AgentRun is the trace of an agent run.
+type AgentRun struct {
+	// Answer is the text of the final message of the model.
+	Answer string
+	// Messages is the whole conversation, including the tool results.
+	Messages []ChatCompletionMessage
+	Steps    []AgentStep
+	// Usage sums the usage of the steps, nil when the API reported none.
+	Usage *Usage
+}
+
+// Run answers input. The returned run holds the steps made so far, also when
+// the run fails.
+func (a *Agent) Run(ctx context.Context, input string) (AgentRun, error) {
+	var run AgentRun
+	if a.SystemPrompt != "" {
+		run.Messages = append(run.Messages, SystemMessage(a.SystemPrompt))
+	}
+	run.Messages = append(run.Messages, UserMessage(input))
+
+	maxIterations := a.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultAgentMaxIterations
+	}
+	var draft *AgentStep
+	for index := range maxIterations {
+		route, err := a.route(ctx, index, run, draft)
+		if err != nil {
+			return run, err
+		}
+		if draft != nil && route.Model == draft.Model {
+			return run.finish(*draft), nil
+		}
+
+		step, err := a.step(ctx, index, route, run.Messages)
+		if err != nil {
+			return run, err
+		}
+		run.Steps = append(run.Steps, step)
+		run.Usage = addUsage(run.Usage, step.Response.Usage)
+		if a.AfterStep != nil {
+			if err := a.AfterStep(ctx, step); err != nil {
+				return run, err
+			}
+		}
+
+		switch {
+		case len(step.Tools) > 0:
+			draft = nil
+			run.Messages = append(run.Messages, step.Message)
+			for _, tool := range step.Tools {
+				run.Messages = append(run.Messages, ToolMessage(tool.Call.ID, tool.Result))
+			}
+		case a.Router == nil || draft != nil:
+			return run.finish(step), nil
+		default:
+			// The answer is a draft until the router lets it stand or has
+			// another model write it.
+			draft = &step
+		}
+		if err := a.Budget.check(run.Usage); err != nil {
+			return run, err
+		}
+	}
+	if draft != nil {
+		return run.finish(*draft), nil
+	}
+	return run, fmt.Errorf("%w: %d", ErrMaxIterations, maxIterations)
+}
+
+// finish ends run with the answer of step.
+func (run AgentRun) finish(step AgentStep) AgentRun {
+	run.Messages = append(run.Messages, step.Message)
+	run.Answer = messageText(step.Message)
+	return run
+}
+
+// route returns the model of the step index, which is Model unless a Router
+// is set.
+func (a *Agent) route(ctx context.Context, index int, run AgentRun, draft *AgentStep) (AgentRoute, error) {
+	if a.Router == nil {
+		return AgentRoute{Model: a.Model}, nil
+	}
+	route, err := a.Router.Route(ctx, AgentRouteState{
+		Step:     index,
+		Messages: run.Messages,
+		Steps:    run.Steps,
+		Draft:    draft,
+	})
+	if err != nil {
+		return AgentRoute{}, fmt.Errorf("agent step %d: route: %w", index, err)
+	}
+	if route.Model == "" {
+		route.Model = a.Model
+	}
+	return route, nil
+}
+
+// step calls the model of route with messages and runs the tools it calls.
+func (a *Agent) step(ctx context.Context, index int, route AgentRoute, messages []ChatCompletionMessage) (AgentStep, error) {
+	request := a.Request
+	request.Model = route.Model
+	request.Messages = append([]ChatCompletionMessage(nil), messages...)
+	if a.Tools != nil {
+		request.Tools = a.Tools.Tools()
+	}
+	if a.BeforeStep != nil {
+		if err := a.BeforeStep(ctx, index, &request); err != nil {
+			return AgentStep{}, err
+		}
+	}
+
+	startedAt := time.Now()
+	response, err := a.Client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return AgentStep{}, fmt.Errorf("agent step %d: %w", index, err)
+	}
+	if len(response.Choices) == 0 {
+		return AgentStep{}, fmt.Errorf("agent step %d: no choices in the response", index)
+	}
+	step := AgentStep{
+		Index:    index,
+		Model:    request.Model,
+		Reason:   route.Reason,
+		Response: response,
+		Message:  AssistantMessageFromChoice(response.Choices[0]),
+		Latency:  time.Since(startedAt),
+	}
+
+	for _, call := range step.Message.ToolCalls {
+		step.Tools = append(step.Tools, a.callTool(ctx, call))
+	}
+	return step, nil
+}
+
+func (a *Agent) callTool(ctx context.Context, call ToolCall) AgentToolCall {
+	startedAt := time.Now()
+	result := AgentToolCall{Call: call}
+	if a.Tools == nil {
+		result.Err = fmt.Errorf("%w: %q", ErrUnknownTool, call.Function.Name)
+	} else {
+		result.Result, result.Err = a.Tools.Call(ctx, call)
+	}
+	if result.Err != nil {
+		result.Result = "error: " + result.Err.Error()
+	}
+	result.Duration = time.Since(startedAt)
+	return result
+}
+
+func (b AgentBudget) check(usage *Usage) error {
+	if usage == nil {
+		return nil
+	}
+	if b.MaxCost > 0 && usage.Cost > b.MaxCost {
+		return fmt.Errorf("%w: cost %g of %g", ErrBudgetExceeded, usage.Cost, b.MaxCost)
+	}
+	if b.MaxTokens > 0 && usage.TotalTokens > b.MaxTokens {
+		return fmt.Errorf("%w: %d tokens of %d", ErrBudgetExceeded, usage.TotalTokens, b.MaxTokens)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package openrouter
+
+import "context"
+
+// AgentRouter chooses the model of every step of an agent run, e.g. a cheap
+// model to select tools and a strong one to write the answer. Its decisions
+// are recorded in AgentStep.Model and AgentStep.Reason.
+type AgentRouter interface {
+	Route(ctx context.Context, state AgentRouteState) (AgentRoute, error)
+}
+
+// AgentRouterFunc adapts a function to AgentRouter.
+type AgentRouterFunc func(ctx context.Context, state AgentRouteState) (AgentRoute, error)
+
+func (f AgentRouterFunc) Route(ctx context.Context, state AgentRouteState) (AgentRoute, error) {
+	return f(ctx, state)
+}
+
+// AgentRouteState is the state of an agent run before a step.
+type AgentRouteState struct {
+	// Step is the index of the step to route.
+	Step int
+	// Messages are the messages the step will send.
+	Messages []ChatCompletionMessage
+	// Steps are the steps made so far.
+	Steps []AgentStep
+	// Draft, if not nil, is a step that answered without calling tools. Its
+	// answer stands when the step is routed to the model of the draft;
+	// routing it to another model has that model answer instead, from the
+	// same messages.
+	Draft *AgentStep
+}
+
+// AgentRoute is the decision of an AgentRouter.
+type AgentRoute struct {
+	// Model answers the step. Empty uses Agent.Model.
+	Model string
+	// Reason explains the decision in the run trace.
+	Reason string
+}
+
+// PhaseRouter routes the steps selecting and calling tools to ToolModel, and
+// has SynthesisModel write the final answer from their results.
+type PhaseRouter struct {
+	ToolModel      string
+	SynthesisModel string
+}
+
+func (r PhaseRouter) Route(_ context.Context, state AgentRouteState) (AgentRoute, error) {
+	if state.Draft != nil {
+		return AgentRoute{Model: r.SynthesisModel, Reason: "synthesis"}, nil
+	}
+	return AgentRoute{Model: r.ToolModel, Reason: "tool selection"}, nil
+}
@@ -0,0 +1,178 @@
+package openrouter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+func weatherTools(t *testing.T) *openrouter.ToolRegistry {
+	t.Helper()
+
+	registry := openrouter.NewToolRegistry()
+	require.NoError(t, registry.Register(openrouter.FunctionDefinition{
+		Name:       "get_weather",
+		Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+	}, func(_ context.Context, arguments json.RawMessage) (string, error) {
+		var args struct{ City string }
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", err
+		}
+		if args.City == "" {
+			return "", errors.New("city is required")
+		}
+		return "18°C and sunny in " + args.City, nil
+	}))
+	return registry
+}
+
+func TestAgentRun(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	fake.ChatCompletions.Return(
+		openroutertest.NewChatResponse().WithToolCall("get_weather", map[string]any{}).WithUsage(50, 10, 0.001).Build(),
+		openroutertest.NewChatResponse().WithToolCall("get_weather", map[string]any{"city": "Paris"}).WithUsage(70, 10, 0.001).Build(),
+		openroutertest.NewChatResponse().WithContent("Pack light: 18°C and sunny.").WithUsage(90, 8, 0.002).Build(),
+	)
+
+	var hooked []int
+	agent := &openrouter.Agent{
+		Client:       fake,
+		Model:        "anthropic/claude-sonnet-4",
+		SystemPrompt: "You are a travel assistant.",
+		Tools:        weatherTools(t),
+		Request:      openrouter.ChatCompletionRequest{Temperature: 0.2},
+		AfterStep: func(_ context.Context, step openrouter.AgentStep) error {
+			hooked = append(hooked, step.Index)
+			return nil
+		},
+	}
+	run, err := agent.Run(context.Background(), "What should I pack for Paris?")
+	require.NoError(t, err)
+	require.Equal(t, "Pack light: 18°C and sunny.", run.Answer)
+	require.Equal(t, []int{0, 1, 2}, hooked)
+
+	require.Len(t, run.Steps, 3)
+	require.EqualError(t, run.Steps[0].Tools[0].Err, "city is required")
+	require.Equal(t, "error: city is required", run.Steps[0].Tools[0].Result)
+	require.Equal(t, "18°C and sunny in Paris", run.Steps[1].Tools[0].Result)
+	require.Empty(t, run.Steps[2].Tools)
+	require.Equal(t, 210, run.Usage.PromptTokens)
+	require.InDelta(t, 0.004, run.Usage.Cost, 1e-9)
+
+	requests := fake.ChatCompletions.Requests()
+	require.Len(t, requests, 3)
+	require.Equal(t, "anthropic/claude-sonnet-4", requests[0].Model)
+	require.Equal(t, float32(0.2), requests[0].Temperature)
+	require.Len(t, requests[0].Tools, 1)
+	require.Len(t, requests[2].Messages, 6, "system, user, and a call and its result per tool step")
+	require.Equal(t, openrouter.ChatMessageRoleTool, requests[2].Messages[5].Role)
+	require.Equal(t, "call_1", requests[2].Messages[5].ToolCallID)
+	require.Equal(t, run.Messages[:6], requests[2].Messages)
+}
+
+func TestAgentLimits(t *testing.T) {
+	t.Parallel()
+
+	looping := func() *openroutertest.FakeClient {
+		fake := openroutertest.NewFakeClient()
+		for range 3 {
+			fake.ChatCompletions.Return(openroutertest.NewChatResponse().
+				WithToolCall("get_weather", map[string]any{"city": "Paris"}).
+				WithUsage(100, 10, 0.01).
+				Build())
+		}
+		return fake
+	}
+
+	agent := &openrouter.Agent{Client: looping(), Tools: weatherTools(t), MaxIterations: 2}
+	run, err := agent.Run(context.Background(), "Weather?")
+	require.ErrorIs(t, err, openrouter.ErrMaxIterations)
+	require.Len(t, run.Steps, 2)
+
+	agent = &openrouter.Agent{Client: looping(), Tools: weatherTools(t), Budget: openrouter.AgentBudget{MaxTokens: 200}}
+	run, err = agent.Run(context.Background(), "Weather?")
+	require.ErrorIs(t, err, openrouter.ErrBudgetExceeded)
+	require.Len(t, run.Steps, 2)
+
+	stop := errors.New("stop")
+	agent = &openrouter.Agent{
+		Client: looping(),
+		Tools:  weatherTools(t),
+		BeforeStep: func(_ context.Context, step int, _ *openrouter.ChatCompletionRequest) error {
+			if step == 1 {
+				return stop
+			}
+			return nil
+		},
+	}
+	run, err = agent.Run(context.Background(), "Weather?")
+	require.ErrorIs(t, err, stop)
+	require.Len(t, run.Steps, 1)
+}
+
+func TestAgentRouting(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	fake.ChatCompletions.Return(
+		openroutertest.NewChatResponse().WithToolCall("get_weather", map[string]any{"city": "Paris"}).Build(),
+		openroutertest.NewChatResponse().WithContent("sunny").Build(),
+		openroutertest.NewChatResponse().WithContent("Expect 18°C and sunshine in Paris.").Build(),
+	)
+
+	agent := &openrouter.Agent{
+		Client: fake,
+		Tools:  weatherTools(t),
+		Router: openrouter.PhaseRouter{ToolModel: "openai/gpt-4o-mini", SynthesisModel: "anthropic/claude-opus-4"},
+	}
+	run, err := agent.Run(context.Background(), "Weather in Paris?")
+	require.NoError(t, err)
+	require.Equal(t, "Expect 18°C and sunshine in Paris.", run.Answer)
+
+	require.Len(t, run.Steps, 3)
+	for i, want := range []struct{ model, reason string }{
+		{"openai/gpt-4o-mini", "tool selection"},
+		{"openai/gpt-4o-mini", "tool selection"},
+		{"anthropic/claude-opus-4", "synthesis"},
+	} {
+		require.Equal(t, want.model, run.Steps[i].Model, i)
+		require.Equal(t, want.reason, run.Steps[i].Reason, i)
+	}
+
+	requests := fake.ChatCompletions.Requests()
+	require.Equal(t, "anthropic/claude-opus-4", requests[2].Model)
+	require.Equal(t, requests[1].Messages, requests[2].Messages, "the draft is not sent")
+	require.Len(t, run.Messages, 4)
+	require.Equal(t, "Expect 18°C and sunshine in Paris.", run.Messages[3].Content.Text)
+}
+
+func TestAgentRoutingKeepsDraft(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	fake.ChatCompletions.Return(openroutertest.NewChatResponse().WithContent("Hello!").Build())
+
+	var states []openrouter.AgentRouteState
+	agent := &openrouter.Agent{
+		Client: fake,
+		Model:  "openai/gpt-4o-mini",
+		Router: openrouter.AgentRouterFunc(func(_ context.Context, state openrouter.AgentRouteState) (openrouter.AgentRoute, error) {
+			states = append(states, state)
+			return openrouter.AgentRoute{}, nil
+		}),
+	}
+	run, err := agent.Run(context.Background(), "Hi")
+	require.NoError(t, err)
+	require.Equal(t, "Hello!", run.Answer)
+	require.Len(t, run.Steps, 1)
+	require.Len(t, states, 2)
+	require.Nil(t, states[0].Draft)
+	require.Equal(t, "Hello!", states[1].Draft.Message.Content.Text)
+}
@@ -0,0 +1,71 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// APIKeyRotation is the outcome of RotateAPIKey.
+type APIKeyRotation struct {
+	// Key is the replacement key and Secret its API key, which OpenRouter
+	// returns only once.
+	Key    APIKey
+	Secret string
+	// OldHash is the hash of the rotated key, which stops working at
+	// RetiresAt.
+	OldHash   string
+	RetiresAt time.Time
+}
+
+// RotateAPIKey replaces the key with the given hash, which requires a
+// provisioning key. It creates a key with the name and limits of the old one,
+// then has OpenRouter retire the old key after grace, by disabling it at once
+// when grace is not positive and by moving its expiry forward otherwise. The
+// old key keeps an earlier expiry it already had. Since the expiry is enforced
+// by OpenRouter, the old key is retired even if the process exits during the
+// grace period.
+//
+// Deploy Secret before RetiresAt. When retiring the old key fails, the
+// rotation is returned along with the error, so the secret of the replacement
+// is not lost and the old key can be retired by hand.
+func (c *Client) RotateAPIKey(ctx context.Context, hash string, grace time.Duration) (APIKeyRotation, error) {
+	old, err := c.GetAPIKey(ctx, hash)
+	if err != nil {
+		return APIKeyRotation{}, fmt.Errorf("get API key %s: %w", hash, err)
+	}
+
+	includeByok := old.Data.IncludeByokInLimit
+	created, err := c.CreateAPIKey(ctx, APIKeyCreateRequest{
+		Name:               old.Data.Name,
+		Limit:              old.Data.Limit,
+		LimitReset:         old.Data.LimitReset,
+		IncludeByokInLimit: &includeByok,
+	})
+	if err != nil {
+		return APIKeyRotation{}, fmt.Errorf("create replacement of API key %s: %w", hash, err)
+	}
+
+	rotation := APIKeyRotation{
+		Key:       created.Data,
+		Secret:    created.Key,
+		OldHash:   hash,
+		RetiresAt: time.Now().Add(max(grace, 0)),
+	}
+	var update APIKeyUpdateRequest
+	switch expiresAt := old.Data.ExpiresAt; {
+	case grace <= 0:
+		disabled := true
+		update.Disabled = &disabled
+	case expiresAt != nil && expiresAt.Before(rotation.RetiresAt):
+		rotation.RetiresAt = *expiresAt
+		return rotation, nil
+	default:
+		update.ExpiresAt = &rotation.RetiresAt
+	}
+	// Retiring must not be skipped because ctx ends right after creation.
+	if _, err := c.UpdateAPIKey(context.WithoutCancel(ctx), hash, update); err != nil {
+		return rotation, fmt.Errorf("retire API key %s: %w", hash, err)
+	}
+	return rotation, nil
+}
@@ -0,0 +1,105 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rotationServer answers the key requests of RotateAPIKey and records the
+// create and update bodies.
+type rotationServer struct {
+	mu      sync.Mutex
+	old     string
+	failing bool
+	created APIKeyCreateRequest
+	updated *APIKeyUpdateRequest
+}
+
+func (s *rotationServer) handle(req *http.Request) *http.Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch req.Method {
+	case http.MethodGet:
+		return jsonResponse(http.StatusOK, s.old)
+	case http.MethodPost:
+		if err := json.NewDecoder(req.Body).Decode(&s.created); err != nil {
+			return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"bad body"}}`)
+		}
+		return jsonResponse(http.StatusCreated, `{"data":{"hash":"new-hash","name":"billing"},"key":"sk-or-v1-new"}`)
+	case http.MethodPatch:
+		if s.failing {
+			return jsonResponse(http.StatusInternalServerError, `{"error":{"code":500,"message":"boom"}}`)
+		}
+		s.updated = &APIKeyUpdateRequest{}
+		_ = json.NewDecoder(req.Body).Decode(s.updated)
+		return jsonResponse(http.StatusOK, `{"data":{"hash":"old-hash"}}`)
+	}
+	return jsonResponse(http.StatusMethodNotAllowed, `{"error":{"code":405,"message":"method"}}`)
+}
+
+func TestRotateAPIKeyRetiresTheOldKeyAfterGrace(t *testing.T) {
+	t.Parallel()
+
+	server := &rotationServer{old: `{"data":{"hash":"old-hash","name":"billing","limit":25,"limit_reset":"monthly",` +
+		`"include_byok_in_limit":true}}`}
+	client := newHandlerClient(server.handle)
+
+	rotation, err := client.RotateAPIKey(context.Background(), "old-hash", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "new-hash", rotation.Key.Hash)
+	require.Equal(t, "sk-or-v1-new", rotation.Secret)
+	require.Equal(t, "old-hash", rotation.OldHash)
+	require.WithinDuration(t, time.Now().Add(time.Hour), rotation.RetiresAt, time.Minute)
+
+	includeByok := true
+	require.Equal(t, APIKeyCreateRequest{
+		Name:               "billing",
+		Limit:              25,
+		LimitReset:         KeyLimitResetMonthly,
+		IncludeByokInLimit: &includeByok,
+	}, server.created)
+	require.Nil(t, server.updated.Disabled)
+	require.WithinDuration(t, rotation.RetiresAt, *server.updated.ExpiresAt, time.Second)
+}
+
+func TestRotateAPIKeyWithoutGraceDisablesTheOldKey(t *testing.T) {
+	t.Parallel()
+
+	server := &rotationServer{old: `{"data":{"hash":"old-hash","name":"billing"}}`}
+	client := newHandlerClient(server.handle)
+
+	_, err := client.RotateAPIKey(context.Background(), "old-hash", 0)
+	require.NoError(t, err)
+	require.True(t, *server.updated.Disabled)
+	require.Nil(t, server.updated.ExpiresAt)
+}
+
+func TestRotateAPIKeyKeepsAnEarlierExpiry(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	server := &rotationServer{old: `{"data":{"hash":"old-hash","expires_at":"` + expiresAt.Format(time.RFC3339) + `"}}`}
+	client := newHandlerClient(server.handle)
+
+	rotation, err := client.RotateAPIKey(context.Background(), "old-hash", time.Hour)
+	require.NoError(t, err)
+	require.True(t, expiresAt.Equal(rotation.RetiresAt))
+	require.Nil(t, server.updated)
+}
+
+func TestRotateAPIKeyReturnsTheSecretWhenRetiringFails(t *testing.T) {
+	t.Parallel()
+
+	server := &rotationServer{old: `{"data":{"hash":"old-hash"}}`, failing: true}
+	client := newHandlerClient(server.handle)
+
+	rotation, err := client.RotateAPIKey(context.Background(), "old-hash", time.Hour)
+	require.ErrorContains(t, err, "retire API key old-hash")
+	require.Equal(t, "sk-or-v1-new", rotation.Secret)
+}
@@ -0,0 +1,123 @@
+package openrouter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// APIKeyStatus is the limit and expiry of a key as last polled by WatchAPIKey.
+type APIKeyStatus struct {
+	Label string
+	// Limit is the credit limit of the key, 0 when it has none.
+	Limit          float64
+	LimitRemaining float64
+	LimitReset     KeyLimitReset
+	// ExpiresAt is nil for keys that do not expire.
+	ExpiresAt *time.Time
+}
+
+// APIKeyWatchHandlers holds the thresholds and callbacks of WatchAPIKey. Every
+// callback is optional and is called from the polling goroutine.
+//
+// OnLowLimit and OnExpiring are called when their condition starts to hold,
+// not on every poll, and again only after it stopped holding in between, e.g.
+// because the limit was raised or reset.
+type APIKeyWatchHandlers struct {
+	// LimitRemainingBelow is the remaining credit under which OnLowLimit is
+	// called. Keys without a limit are never low.
+	LimitRemainingBelow float64
+	OnLowLimit          func(status APIKeyStatus)
+	// ExpiresWithin is how long before the key expires OnExpiring is called.
+	ExpiresWithin time.Duration
+	OnExpiring    func(status APIKeyStatus)
+	// OnError is called with every polling error. Polling continues at the
+	// next interval.
+	OnError func(err error)
+}
+
+// APIKeyWatcher polls a key for WatchAPIKey.
+type APIKeyWatcher struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchAPIKey polls the key with the given hash every interval with
+// GetAPIKey, which requires a provisioning key, or the client's own key with
+// GetCurrentAPIKey when hash is empty, and calls handlers as its remaining
+// limit and expiry cross their thresholds. A zero or negative interval is
+// replaced by DefaultPollInterval. It stops when ctx is done or Stop is called.
+func (c *Client) WatchAPIKey(
+	ctx context.Context,
+	hash string,
+	interval time.Duration,
+	handlers APIKeyWatchHandlers,
+) *APIKeyWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &APIKeyWatcher{cancel: cancel, done: make(chan struct{})}
+	interval = pollInterval(interval)
+
+	go func() {
+		defer close(w.done)
+
+		var low, expiring bool
+		poll(ctx, interval, func() {
+			status, err := c.apiKeyStatus(ctx, hash)
+			if err != nil {
+				if ctx.Err() == nil && handlers.OnError != nil {
+					handlers.OnError(err)
+				}
+				return
+			}
+
+			wasLow, wasExpiring := low, expiring
+			low = status.Limit > 0 && status.LimitRemaining < handlers.LimitRemainingBelow
+			expiring = status.ExpiresAt != nil && time.Until(*status.ExpiresAt) < handlers.ExpiresWithin
+			if low && !wasLow && handlers.OnLowLimit != nil {
+				handlers.OnLowLimit(status)
+			}
+			if expiring && !wasExpiring && handlers.OnExpiring != nil {
+				handlers.OnExpiring(status)
+			}
+		})
+	}()
+	return w
+}
+
+// Stop stops polling and waits for a callback in progress to return. It is
+// safe to call any number of times, but not from a callback.
+func (w *APIKeyWatcher) Stop() {
+	w.stopOnce.Do(w.cancel)
+	<-w.done
+}
+
+func (c *Client) apiKeyStatus(ctx context.Context, hash string) (APIKeyStatus, error) {
+	if hash == "" {
+		res, err := c.GetCurrentAPIKey(ctx)
+		if err != nil {
+			return APIKeyStatus{}, err
+		}
+		key := res.Data
+		return APIKeyStatus{
+			Label:          key.Label,
+			Limit:          key.Limit,
+			LimitRemaining: key.LimitRemaining,
+			LimitReset:     key.LimitReset,
+			ExpiresAt:      key.ExpiresAt,
+		}, nil
+	}
+
+	res, err := c.GetAPIKey(ctx, hash)
+	if err != nil {
+		return APIKeyStatus{}, err
+	}
+	key := res.Data
+	return APIKeyStatus{
+		Label:          key.Label,
+		Limit:          key.Limit,
+		LimitRemaining: key.LimitRemaining,
+		LimitReset:     key.LimitReset,
+		ExpiresAt:      key.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchAPIKeyFiresOnThresholdCrossings(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	remaining := []string{"5", "1", "0.5", "", "9", "1"}
+	var polls atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		n := int(polls.Add(1)) - 1
+		if n >= len(remaining) {
+			n = len(remaining) - 1
+		}
+		if remaining[n] == "" {
+			return jsonResponse(http.StatusInternalServerError, `{"error":{"code":500,"message":"boom"}}`)
+		}
+		return jsonResponse(http.StatusOK, fmt.Sprintf(
+			`{"data":{"label":"sk-or-v1-abc","limit":10,"limit_remaining":%s,"expires_at":%q}}`, remaining[n], expiresAt))
+	})
+
+	var (
+		mu       sync.Mutex
+		low      []float64
+		expiring int
+		errs     int
+	)
+	watcher := client.WatchAPIKey(context.Background(), "", time.Millisecond, APIKeyWatchHandlers{
+		LimitRemainingBelow: 2,
+		OnLowLimit: func(status APIKeyStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			low = append(low, status.LimitRemaining)
+		},
+		ExpiresWithin: 2 * time.Hour,
+		OnExpiring: func(status APIKeyStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, "sk-or-v1-abc", status.Label)
+			expiring++
+		},
+		OnError: func(error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs++
+		},
+	})
+	require.Eventually(t, func() bool { return polls.Load() > int32(len(remaining)+2) }, time.Second, time.Millisecond)
+	watcher.Stop()
+	watcher.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []float64{1, 1}, low)
+	require.Equal(t, 1, expiring)
+	require.Equal(t, 1, errs)
+}
+
+func TestWatchAPIKeyPollsManagedKey(t *testing.T) {
+	t.Parallel()
+
+	paths := make(chan string, 1)
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		select {
+		case paths <- req.URL.Path:
+		default:
+		}
+		return jsonResponse(http.StatusOK, `{"data":{"hash":"abc","limit":0,"limit_remaining":0}}`)
+	})
+
+	watcher := client.WatchAPIKey(context.Background(), "abc", time.Hour, APIKeyWatchHandlers{
+		LimitRemainingBelow: 1,
+		OnLowLimit: func(APIKeyStatus) {
+			t.Error("a key without a limit is never low")
+		},
+	})
+	require.Equal(t, "/api/v1/keys/abc", <-paths)
+	watcher.Stop()
+}
@@ -0,0 +1,95 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIKeyCreateResult is the outcome of one key of CreateAPIKeys.
+type APIKeyCreateResult struct {
+	Request  APIKeyCreateRequest
+	Response APIKeyCreateResponse
+	Err      error
+}
+
+// APIKeyDeleteResult is the outcome of one key of DeleteAPIKeys.
+type APIKeyDeleteResult struct {
+	Hash string
+	Err  error
+}
+
+// CreateAPIKeys creates a key for every request, running a bounded number of
+// requests at once. Results are in the order of requests and hold the error of
+// every key that could not be created; the returned error joins them.
+//
+// Creation is only retried on rate limiting and provider unavailability, which
+// reject the request before a key is created, so a retry never duplicates a key.
+func (c *Client) CreateAPIKeys(
+	ctx context.Context,
+	requests []APIKeyCreateRequest,
+	opts ...BulkOption,
+) ([]APIKeyCreateResult, error) {
+	options := newBulkOptions(opts)
+	retryable := func(err error) bool {
+		return IsRateLimited(err) || IsErrorCode(err, http.StatusServiceUnavailable)
+	}
+
+	results := make([]APIKeyCreateResult, len(requests))
+	runBounded(ctx, len(requests), options.concurrency, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Request = requests[i]
+		result.Err = retry(ctx, options.attempts, options.backoff, retryable, func(int) error {
+			if err := options.wait(ctx); err != nil {
+				return err
+			}
+			var err error
+			result.Response, err = c.CreateAPIKey(ctx, requests[i])
+			return err
+		})
+		if result.Err != nil {
+			result.Err = fmt.Errorf("create API key %d (%s): %w", i, requests[i].Name, result.Err)
+		}
+	})
+
+	errs := make([]error, 0, len(results))
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return results, errors.Join(errs...)
+}
+
+// DeleteAPIKeys deletes the keys with the given hashes, running a bounded
+// number of requests at once and retrying transient failures. Results are in
+// the order of hashes and hold the error of every key that could not be
+// deleted; the returned error joins them. A key found missing when a delete is
+// retried counts as deleted by the earlier attempt.
+func (c *Client) DeleteAPIKeys(ctx context.Context, hashes []string, opts ...BulkOption) ([]APIKeyDeleteResult, error) {
+	options := newBulkOptions(opts)
+
+	results := make([]APIKeyDeleteResult, len(hashes))
+	runBounded(ctx, len(hashes), options.concurrency, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Hash = hashes[i]
+		result.Err = retry(ctx, options.attempts, options.backoff, isTransient, func(attempt int) error {
+			if err := options.wait(ctx); err != nil {
+				return err
+			}
+			_, err := c.DeleteAPIKey(ctx, hashes[i])
+			if attempt > 1 && IsErrorCode(err, http.StatusNotFound) {
+				return nil
+			}
+			return err
+		})
+		if result.Err != nil {
+			result.Err = fmt.Errorf("delete API key %s: %w", hashes[i], result.Err)
+		}
+	})
+
+	errs := make([]error, 0, len(results))
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// handlerHTTPClient answers requests with a function, safely for concurrent use.
+type handlerHTTPClient func(req *http.Request) *http.Response
+
+func (h handlerHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return h(req), nil
+}
+
+func newHandlerClient(handler handlerHTTPClient) *Client {
+	cfg := DefaultConfig("test-token")
+	cfg.HTTPClient = handler
+	return NewClientWithConfig(*cfg)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	require.Equal(t, 100*time.Millisecond, backoff(1))
+	require.Equal(t, 200*time.Millisecond, backoff(2))
+	require.Equal(t, 800*time.Millisecond, backoff(4))
+	require.Equal(t, time.Second, backoff(5))
+	require.Equal(t, time.Second, backoff(100))
+}
+
+func TestCreateAPIKeysRetriesRateLimits(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+		running  atomic.Int32
+		peak     atomic.Int32
+	)
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		var body APIKeyCreateRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"bad body"}}`)
+		}
+		mu.Lock()
+		attempts[body.Name]++
+		attempt := attempts[body.Name]
+		mu.Unlock()
+
+		switch {
+		case body.Name == "rate-limited" && attempt == 1:
+			return jsonResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"slow down"}}`)
+		case body.Name == "server-error":
+			return jsonResponse(http.StatusInternalServerError, `{"error":{"code":500,"message":"boom"}}`)
+		}
+		return jsonResponse(http.StatusOK, `{"data":{"hash":"hash-`+body.Name+`","name":"`+body.Name+`"},"key":"sk-`+body.Name+`"}`)
+	})
+
+	requests := []APIKeyCreateRequest{{Name: "a"}, {Name: "rate-limited"}, {Name: "server-error"}, {Name: "b"}, {Name: "c"}}
+	results, err := client.CreateAPIKeys(context.Background(), requests,
+		BulkConcurrency(2), BulkRetries(3, ExponentialBackoff(time.Millisecond, time.Millisecond)))
+
+	require.Error(t, err)
+	require.True(t, IsErrorCode(err, http.StatusInternalServerError))
+	require.Len(t, results, len(requests))
+	for i, result := range results {
+		require.Equal(t, requests[i], result.Request)
+	}
+	require.NoError(t, results[1].Err)
+	require.Equal(t, "sk-rate-limited", results[1].Response.Key)
+	require.ErrorContains(t, results[2].Err, "create API key 2 (server-error)")
+	require.Equal(t, "hash-c", results[4].Response.Data.Hash)
+
+	require.Equal(t, 2, attempts["rate-limited"])
+	require.Equal(t, 1, attempts["server-error"], "creation must not be retried on ambiguous failures")
+	require.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestDeleteAPIKeysRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		hash := path.Base(req.URL.Path)
+		mu.Lock()
+		attempts[hash]++
+		attempt := attempts[hash]
+		mu.Unlock()
+
+		switch {
+		case hash == "flaky" && attempt == 1:
+			return jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"bad gateway"}}`)
+		case hash == "timed-out" && attempt == 1:
+			return jsonResponse(StatusEdgeNetworkTimeout, `{"error":{"code":524,"message":"timeout"}}`)
+		case hash == "timed-out" || hash == "missing":
+			return jsonResponse(http.StatusNotFound, `{"error":{"code":404,"message":"not found"}}`)
+		}
+		return jsonResponse(http.StatusOK, `{"deleted":true}`)
+	})
+
+	hashes := []string{"ok", "flaky", "timed-out", "missing"}
+	results, err := client.DeleteAPIKeys(context.Background(), hashes,
+		BulkRetries(3, ExponentialBackoff(time.Millisecond, time.Millisecond)))
+
+	require.Error(t, err)
+	require.Len(t, results, len(hashes))
+	require.NoError(t, results[0].Err)
+	require.NoError(t, results[1].Err)
+	require.NoError(t, results[2].Err, "a 404 after a retried delete means the first attempt succeeded")
+	require.True(t, IsErrorCode(results[3].Err, http.StatusNotFound))
+	require.Equal(t, "missing", results[3].Hash)
+	require.Equal(t, 1, attempts["missing"])
+}
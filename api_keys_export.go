@@ -0,0 +1,165 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ExportFormat is the output format of ExportAPIKeyUsage.
+type ExportFormat string
+
+const (
+	// ExportFormatCSV writes a header row and a row per key.
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSONLines writes a JSON encoded APIKeyUsageRow per line.
+	ExportFormatJSONLines ExportFormat = "jsonl"
+)
+
+// APIKeyUsageRow is a row of ExportAPIKeyUsage. Amounts are in USD, and every
+// field is written even when zero.
+type APIKeyUsageRow struct {
+	Hash           string        `json:"hash"`
+	Name           string        `json:"name"`
+	Label          string        `json:"label"`
+	Disabled       bool          `json:"disabled"`
+	Limit          float64       `json:"limit"`
+	LimitRemaining float64       `json:"limit_remaining"`
+	LimitReset     KeyLimitReset `json:"limit_reset"`
+	Usage          float64       `json:"usage"`
+	UsageDaily     float64       `json:"usage_daily"`
+	UsageWeekly    float64       `json:"usage_weekly"`
+	UsageMonthly   float64       `json:"usage_monthly"`
+	ByokUsage      float64       `json:"byok_usage"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ExpiresAt      *time.Time    `json:"expires_at"`
+}
+
+var apiKeyUsageColumns = []string{
+	"hash", "name", "label", "disabled", "limit", "limit_remaining", "limit_reset",
+	"usage", "usage_daily", "usage_weekly", "usage_monthly", "byok_usage", "created_at", "expires_at",
+}
+
+func newAPIKeyUsageRow(key APIKey) APIKeyUsageRow {
+	return APIKeyUsageRow{
+		Hash:           key.Hash,
+		Name:           key.Name,
+		Label:          key.Label,
+		Disabled:       key.Disabled,
+		Limit:          key.Limit,
+		LimitRemaining: key.LimitRemaining,
+		LimitReset:     key.LimitReset,
+		Usage:          key.Usage,
+		UsageDaily:     key.UsageDaily,
+		UsageWeekly:    key.UsageWeekly,
+		UsageMonthly:   key.UsageMonthly,
+		ByokUsage:      key.ByokUsage,
+		CreatedAt:      key.CreatedAt,
+		ExpiresAt:      key.ExpiresAt,
+	}
+}
+
+// csv returns the row in the order of apiKeyUsageColumns. Times are RFC 3339,
+// and a key without expiry has an empty expires_at.
+func (r APIKeyUsageRow) csv() []string {
+	amount := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	var expiresAt string
+	if r.ExpiresAt != nil {
+		expiresAt = r.ExpiresAt.Format(time.RFC3339)
+	}
+	return []string{
+		r.Hash, r.Name, r.Label, strconv.FormatBool(r.Disabled),
+		amount(r.Limit), amount(r.LimitRemaining), string(r.LimitReset),
+		amount(r.Usage), amount(r.UsageDaily), amount(r.UsageWeekly), amount(r.UsageMonthly), amount(r.ByokUsage),
+		r.CreatedAt.Format(time.RFC3339), expiresAt,
+	}
+}
+
+// ListAllAPIKeys lists every API key of the account, disabled ones included,
+// following the pagination of the API. Pages are fetched one at a time; only
+// the BulkRetries and BulkRateLimit options apply.
+func (c *Client) ListAllAPIKeys(ctx context.Context, opts ...BulkOption) ([]APIKey, error) {
+	options := newBulkOptions(opts)
+
+	var keys []APIKey
+	seen := make(map[string]bool)
+	for {
+		var page APIKeysListResponse
+		err := retry(ctx, options.attempts, options.backoff, isTransient, func(int) error {
+			if err := options.wait(ctx); err != nil {
+				return err
+			}
+			var err error
+			page, err = c.listAPIKeysPage(ctx, len(keys))
+			return err
+		})
+		if err != nil {
+			return keys, fmt.Errorf("list API keys from offset %d: %w", len(keys), err)
+		}
+		// A page of keys already listed means the offset was ignored.
+		if len(page.Data) == 0 || seen[page.Data[0].Hash] {
+			return keys, nil
+		}
+		for _, key := range page.Data {
+			seen[key.Hash] = true
+		}
+		keys = append(keys, page.Data...)
+	}
+}
+
+func (c *Client) listAPIKeysPage(ctx context.Context, offset int) (APIKeysListResponse, error) {
+	var res APIKeysListResponse
+
+	query := url.Values{}
+	query.Set("include_disabled", "true")
+	query.Set("offset", strconv.Itoa(offset))
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(apiKeysSuffix, withQuery(query)),
+	)
+	if err != nil {
+		return res, err
+	}
+
+	err = c.sendRequest(req, &res)
+	return res, err
+}
+
+// ExportAPIKeyUsage writes the usage, limit and expiry of every API key of the
+// account to w in format, for finance reporting. It requires a provisioning
+// key. Keys are listed with ListAllAPIKeys and opts, and are written only once
+// all were listed, so a failed export writes nothing.
+func (c *Client) ExportAPIKeyUsage(ctx context.Context, w io.Writer, format ExportFormat, opts ...BulkOption) error {
+	if format != ExportFormatCSV && format != ExportFormatJSONLines {
+		return fmt.Errorf("unknown export format %q", format)
+	}
+	keys, err := c.ListAllAPIKeys(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	if format == ExportFormatJSONLines {
+		encoder := json.NewEncoder(w)
+		for _, key := range keys {
+			if err := encoder.Encode(newAPIKeyUsageRow(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(apiKeyUsageColumns)
+	for _, key := range keys {
+		writer.Write(newAPIKeyUsageRow(key).csv())
+	}
+	writer.Flush()
+	return writer.Error()
+}
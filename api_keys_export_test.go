@@ -0,0 +1,86 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pagedKeysClient serves keys in pages of two, by the offset of the request.
+func pagedKeysClient(t *testing.T, keys ...string) *Client {
+	t.Helper()
+	return newHandlerClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "/api/v1/keys", req.URL.Path)
+		require.Equal(t, "true", req.URL.Query().Get("include_disabled"))
+		var offset int
+		fmt.Sscan(req.URL.Query().Get("offset"), &offset)
+		end := min(offset+2, len(keys))
+		if offset > end {
+			offset = end
+		}
+		return jsonResponse(http.StatusOK, `{"data":[`+strings.Join(keys[offset:end], ",")+`]}`)
+	})
+}
+
+func TestListAllAPIKeysFollowsPages(t *testing.T) {
+	t.Parallel()
+
+	client := pagedKeysClient(t, `{"hash":"a"}`, `{"hash":"b"}`, `{"hash":"c","disabled":true}`)
+
+	keys, err := client.ListAllAPIKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	require.Equal(t, "c", keys[2].Hash)
+	require.True(t, keys[2].Disabled)
+}
+
+func TestListAllAPIKeysStopsWhenTheOffsetIsIgnored(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusOK, `{"data":[{"hash":"a"},{"hash":"b"}]}`)
+	})
+
+	keys, err := client.ListAllAPIKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+}
+
+func TestExportAPIKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	client := pagedKeysClient(t,
+		`{"hash":"a","name":"billing","label":"sk-or-v1-a...","limit":25,"limit_remaining":20.5,`+
+			`"limit_reset":"monthly","usage":4.5,"usage_monthly":4.5,"created_at":"2025-01-02T03:04:05Z",`+
+			`"expires_at":"2026-01-01T00:00:00Z"}`,
+		`{"hash":"b","name":"search","disabled":true,"usage":0.25,"created_at":"2025-02-01T00:00:00Z"}`,
+	)
+
+	var csv bytes.Buffer
+	require.NoError(t, client.ExportAPIKeyUsage(context.Background(), &csv, ExportFormatCSV))
+	require.Equal(t, "hash,name,label,disabled,limit,limit_remaining,limit_reset,usage,usage_daily,usage_weekly,"+
+		"usage_monthly,byok_usage,created_at,expires_at\n"+
+		"a,billing,sk-or-v1-a...,false,25,20.5,monthly,4.5,0,0,4.5,0,2025-01-02T03:04:05Z,2026-01-01T00:00:00Z\n"+
+		"b,search,,true,0,0,,0.25,0,0,0,0,2025-02-01T00:00:00Z,\n", csv.String())
+
+	var lines bytes.Buffer
+	require.NoError(t, client.ExportAPIKeyUsage(context.Background(), &lines, ExportFormatJSONLines))
+	var rows []APIKeyUsageRow
+	decoder := json.NewDecoder(&lines)
+	for decoder.More() {
+		var row APIKeyUsageRow
+		require.NoError(t, decoder.Decode(&row))
+		rows = append(rows, row)
+	}
+	require.Len(t, rows, 2)
+	require.Equal(t, 20.5, rows[0].LimitRemaining)
+	require.Nil(t, rows[1].ExpiresAt)
+
+	require.ErrorContains(t, client.ExportAPIKeyUsage(context.Background(), &lines, "xml"), "unknown export format")
+}
@@ -1,15 +1,19 @@
 package openrouter
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
 // UserMessageWithAudioFromFile creates a user message with the given prompt text and audio file.
-// It reads the audio file (mp3 or wav) and creates a message with the embedded audio data.
+// It reads the audio file (mp3, wav, flac, ogg, opus, m4a, aac, aiff or webm) and creates a message
+// with the embedded audio data. The format is taken from the file extension, or detected from the
+// content when the extension is missing or unknown.
 func UserMessageWithAudioFromFile(promptText, filePath string) (ChatCompletionMessage, error) {
 	fileData, err := os.ReadFile(filePath)
 	if err != nil {
@@ -17,13 +21,11 @@ func UserMessageWithAudioFromFile(promptText, filePath string) (ChatCompletionMe
 	}
 
 	ext := filepath.Ext(filePath)
-	var format AudioFormat
-	switch strings.ToLower(ext) {
-	case ".mp3":
-		format = AudioFormatMp3
-	case ".wav":
-		format = AudioFormatWav
-	default:
+	format, ok := audioFormatsByExtension[strings.ToLower(ext)]
+	if !ok {
+		format, ok = DetectAudioFormat(fileData)
+	}
+	if !ok {
 		return ChatCompletionMessage{}, fmt.Errorf("unsupported audio format: %s", ext)
 	}
 
@@ -32,6 +34,89 @@ func UserMessageWithAudioFromFile(promptText, filePath string) (ChatCompletionMe
 	return msg, nil
 }
 
+// audioFormatsByExtension maps audio file extensions to their format.
+var audioFormatsByExtension = map[string]AudioFormat{
+	".mp3":  AudioFormatMp3,
+	".wav":  AudioFormatWav,
+	".flac": AudioFormatFlac,
+	".ogg":  AudioFormatOgg,
+	".oga":  AudioFormatOgg,
+	".opus": AudioFormatOpus,
+	".m4a":  AudioFormatM4a,
+	".mp4":  AudioFormatM4a,
+	".aac":  AudioFormatAac,
+	".aif":  AudioFormatAiff,
+	".aiff": AudioFormatAiff,
+	".webm": AudioFormatWebm,
+}
+
+// DetectAudioFormat detects the format of audio from the signature at its start.
+// Ogg files are reported as opus when their first stream is Opus. Raw PCM has no
+// signature and is never detected.
+func DetectAudioFormat(audio []byte) (AudioFormat, bool) {
+	switch {
+	case len(audio) >= 12 && string(audio[:4]) == "RIFF" && string(audio[8:12]) == "WAVE":
+		return AudioFormatWav, true
+	case len(audio) >= 12 && string(audio[:4]) == "FORM" && (string(audio[8:12]) == "AIFF" || string(audio[8:12]) == "AIFC"):
+		return AudioFormatAiff, true
+	case bytes.HasPrefix(audio, []byte("fLaC")):
+		return AudioFormatFlac, true
+	case bytes.HasPrefix(audio, []byte("OggS")):
+		// The first page holds the identification header of the first stream.
+		if bytes.Contains(audio[:min(len(audio), 64)], []byte("OpusHead")) {
+			return AudioFormatOpus, true
+		}
+		return AudioFormatOgg, true
+	case len(audio) >= 8 && string(audio[4:8]) == "ftyp":
+		return AudioFormatM4a, true
+	case bytes.HasPrefix(audio, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		return AudioFormatWebm, true
+	case bytes.HasPrefix(audio, []byte("ID3")):
+		return AudioFormatMp3, true
+	case len(audio) >= 2 && audio[0] == 0xff && audio[1]&0xf6 == 0xf0:
+		// ADTS frames are MPEG frames whose layer bits are zero.
+		return AudioFormatAac, true
+	case len(audio) >= 2 && audio[0] == 0xff && audio[1]&0xe0 == 0xe0:
+		return AudioFormatMp3, true
+	default:
+		return "", false
+	}
+}
+
+// UserMessageWithAudioFromReader creates a user message with the given prompt text and audio read
+// from r, e.g. a microphone capture or an HTTP upload. It returns a *MediaTooLargeError when the
+// audio exceeds maxBytes, which is not limited when zero or negative.
+func UserMessageWithAudioFromReader(
+	promptText string,
+	r io.Reader,
+	format AudioFormat,
+	maxBytes int64,
+) (ChatCompletionMessage, error) {
+	audio, err := readMedia(r, maxBytes)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+
+	return UserMessageWithAudio(promptText, audio, format), nil
+}
+
+// readMedia reads r to the end, failing with a *MediaTooLargeError as soon as
+// more than maxBytes are read when maxBytes is positive.
+func readMedia(r io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		return io.ReadAll(r)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, &MediaTooLargeError{Limit: maxBytes}
+	}
+	return data, nil
+}
+
 // UserMessageWithAudio creates a user message with the given prompt text and audio content.
 // Creates a message with the embedded audio data.
 func UserMessageWithAudio(promptText string, audio []byte, format AudioFormat) ChatCompletionMessage {
@@ -0,0 +1,89 @@
+package openrouter_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAudioFormat(t *testing.T) {
+	t.Parallel()
+
+	signatures := map[openrouter.AudioFormat][]byte{
+		openrouter.AudioFormatWav:  []byte("RIFF\x24\x00\x00\x00WAVEfmt "),
+		openrouter.AudioFormatAiff: []byte("FORM\x00\x00\x00\x24AIFFCOMM"),
+		openrouter.AudioFormatFlac: []byte("fLaC\x00\x00\x00\x22"),
+		openrouter.AudioFormatOgg:  []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x01vorbis"),
+		openrouter.AudioFormatOpus: []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x01OpusHead"),
+		openrouter.AudioFormatM4a:  []byte("\x00\x00\x00\x20ftypM4A "),
+		openrouter.AudioFormatWebm: {0x1a, 0x45, 0xdf, 0xa3, 0x9f, 0x42, 0x86},
+		openrouter.AudioFormatAac:  {0xff, 0xf1, 0x50, 0x80},
+	}
+	for want, audio := range signatures {
+		got, ok := openrouter.DetectAudioFormat(audio)
+		require.True(t, ok, want)
+		require.Equal(t, want, got)
+	}
+
+	for _, mp3 := range [][]byte{[]byte("ID3\x04\x00"), {0xff, 0xfb, 0x90, 0x64}} {
+		got, ok := openrouter.DetectAudioFormat(mp3)
+		require.True(t, ok)
+		require.Equal(t, openrouter.AudioFormatMp3, got)
+	}
+
+	_, ok := openrouter.DetectAudioFormat([]byte("plain text"))
+	require.False(t, ok)
+	_, ok = openrouter.DetectAudioFormat(nil)
+	require.False(t, ok)
+}
+
+func TestUserMessageWithAudioFromFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	msg, err := openrouter.UserMessageWithAudioFromFile("Transcribe this.", write("note.OPUS", []byte("anything")))
+	require.NoError(t, err)
+	require.Equal(t, openrouter.AudioFormatOpus, msg.Content.Multi[1].InputAudio.Format, "the extension wins")
+
+	msg, err = openrouter.UserMessageWithAudioFromFile("Transcribe this.", write("upload", []byte("fLaC\x00\x00\x00\x22")))
+	require.NoError(t, err)
+	require.Equal(t, openrouter.AudioFormatFlac, msg.Content.Multi[1].InputAudio.Format, "the content is sniffed")
+
+	_, err = openrouter.UserMessageWithAudioFromFile("Transcribe this.", write("notes.txt", []byte("plain text")))
+	require.EqualError(t, err, "unsupported audio format: .txt")
+}
+
+func TestUserMessageWithAudioFromReader(t *testing.T) {
+	t.Parallel()
+
+	audio := []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	msg, err := openrouter.UserMessageWithAudioFromReader("Transcribe this.", bytes.NewReader(audio),
+		openrouter.AudioFormatWav, int64(len(audio)))
+	require.NoError(t, err)
+	require.Equal(t, openrouter.AudioFormatWav, msg.Content.Multi[1].InputAudio.Format)
+	require.Equal(t, base64.StdEncoding.EncodeToString(audio), msg.Content.Multi[1].InputAudio.Data)
+
+	_, err = openrouter.UserMessageWithAudioFromReader("Transcribe this.", strings.NewReader(strings.Repeat("x", 100)),
+		openrouter.AudioFormatWav, 99)
+	var tooLarge *openrouter.MediaTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	require.Equal(t, int64(99), tooLarge.Limit)
+	require.EqualError(t, err, "media exceeds the size limit of 99 bytes")
+
+	_, err = openrouter.UserMessageWithAudioFromReader("Transcribe this.", strings.NewReader(strings.Repeat("x", 100)),
+		openrouter.AudioFormatWav, 0)
+	require.NoError(t, err, "zero does not limit the size")
+}
@@ -0,0 +1,99 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AuditRecord is an outbound request of the client, as archived by an
+// Auditor.
+type AuditRecord struct {
+	// Time is when the request was sent.
+	Time   time.Time
+	Method string
+	URL    string
+	// Caller is the caller set with ContextWithCaller, if any.
+	Caller string
+	// Request is the JSON body sent, redacted by the Redactor of the client,
+	// or nil for requests without a JSON body, such as GET requests.
+	Request json.RawMessage
+	// Streamed reports whether a server-sent events stream was requested.
+	Streamed bool
+
+	// StatusCode is the status of the response, zero when none was received.
+	StatusCode int
+	// Latency is the time until the response headers were received. The body,
+	// e.g. a stream, may take longer.
+	Latency time.Duration
+	// Err is the redacted message of the transport error, if any.
+	Err string
+}
+
+// Auditor archives the requests of the client, for compliance. Unlike the
+// debug log, it receives every request, including ones retried or served by
+// the KeyPool, with its full body. Audit is called synchronously once the
+// response headers arrive, so slow archives should queue records. It must be
+// safe for concurrent use.
+type Auditor interface {
+	Audit(ctx context.Context, record AuditRecord)
+}
+
+// AuditorFunc adapts a function to the Auditor interface.
+type AuditorFunc func(ctx context.Context, record AuditRecord)
+
+func (f AuditorFunc) Audit(ctx context.Context, record AuditRecord) {
+	f(ctx, record)
+}
+
+// auditDoer sends requests through doer and records them to auditor.
+type auditDoer struct {
+	auditor  Auditor
+	redactor Redactor
+	doer     HTTPDoer
+}
+
+func (d auditDoer) Do(req *http.Request) (*http.Response, error) {
+	record := AuditRecord{
+		Time:     time.Now(),
+		Method:   req.Method,
+		URL:      req.URL.Redacted(),
+		Streamed: req.Header.Get("Accept") == "text/event-stream",
+		Request:  d.body(req),
+	}
+	record.Caller, _ = CallerFromContext(req.Context())
+
+	resp, err := d.doer.Do(req)
+	record.Latency = time.Since(record.Time)
+	if err != nil {
+		record.Err = d.redactor.Redact(err.Error())
+	} else {
+		record.StatusCode = resp.StatusCode
+	}
+	d.auditor.Audit(req.Context(), record)
+	return resp, err
+}
+
+// body returns the redacted JSON body of req, read from a copy so req is
+// sent unchanged.
+func (d auditDoer) body(req *http.Request) json.RawMessage {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil || !json.Valid(data) {
+		return nil
+	}
+	redacted := []byte(d.redactor.Redact(string(data)))
+	if !json.Valid(redacted) {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,81 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAuditor struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (a *recordingAuditor) Audit(_ context.Context, record AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.records = append(a.records, record)
+}
+
+func TestAuditorRecordsRequests(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet {
+			return jsonResponse(http.StatusUnauthorized, `{"error":{"code":401,"message":"no"}}`)
+		}
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`)
+	})
+	auditor := &recordingAuditor{}
+	client.config.Auditor = auditor
+
+	ctx := ContextWithCaller(context.Background(), "support-bot")
+	_, err := client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Mail me at a@b.io")},
+	})
+	require.NoError(t, err)
+	_, err = client.GetCurrentAPIKey(ctx)
+	require.Error(t, err)
+
+	require.Len(t, auditor.records, 2)
+	chat := auditor.records[0]
+	require.Equal(t, http.MethodPost, chat.Method)
+	require.Equal(t, "https://openrouter.ai/api/v1/chat/completions", chat.URL)
+	require.Equal(t, "support-bot", chat.Caller)
+	require.Equal(t, http.StatusOK, chat.StatusCode)
+	require.False(t, chat.Streamed)
+	require.JSONEq(t, `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"Mail me at [REDACTED_EMAIL]"}]}`,
+		string(chat.Request))
+
+	key := auditor.records[1]
+	require.Equal(t, http.MethodGet, key.Method)
+	require.Nil(t, key.Request)
+	require.Equal(t, http.StatusUnauthorized, key.StatusCode)
+}
+
+func TestAuditorRecordsTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig("test-token")
+	config.HTTPClient = failingDoer{errors.New("dial tcp: connection refused")}
+	auditor := &recordingAuditor{}
+	config.Auditor = auditor
+	client := NewClientWithConfig(*config)
+
+	_, err := client.GetCredits(context.Background())
+	require.Error(t, err)
+	require.Len(t, auditor.records, 1)
+	require.Zero(t, auditor.records[0].StatusCode)
+	require.Equal(t, "dial tcp: connection refused", auditor.records[0].Err)
+}
+
+type failingDoer struct{ err error }
+
+func (d failingDoer) Do(*http.Request) (*http.Response, error) {
+	return nil, d.err
+}
@@ -0,0 +1,227 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// BenchmarkOption configures Benchmark.
+type BenchmarkOption func(*benchmarkOptions)
+
+type benchmarkOptions struct {
+	stream    bool
+	maxTokens int
+	provider  *ChatProvider
+}
+
+// BenchmarkStreaming streams the completions so time to first token is measured.
+func BenchmarkStreaming() BenchmarkOption {
+	return func(o *benchmarkOptions) {
+		o.stream = true
+	}
+}
+
+// BenchmarkMaxTokens caps the completion length of every run.
+func BenchmarkMaxTokens(maxTokens int) BenchmarkOption {
+	return func(o *benchmarkOptions) {
+		o.maxTokens = maxTokens
+	}
+}
+
+// BenchmarkProvider sets the provider routing of every run, e.g. to compare
+// the providers of one model.
+func BenchmarkProvider(provider ChatProvider) BenchmarkOption {
+	return func(o *benchmarkOptions) {
+		o.provider = &provider
+	}
+}
+
+// LatencySummary summarizes a set of durations.
+type LatencySummary struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	Max  time.Duration
+}
+
+// BenchmarkResult holds the measurements of one model.
+type BenchmarkResult struct {
+	Model string
+	// Providers counts the runs served by each provider.
+	Providers map[string]int
+	Runs      int
+	// Errors holds the error of every failed run.
+	Errors []error
+	// Latency is the time to the complete response of the successful runs.
+	Latency LatencySummary
+	// TimeToFirstToken is only measured when streaming.
+	TimeToFirstToken LatencySummary
+	// TokensPerSecond is the mean completion throughput.
+	TokensPerSecond float64
+	// MeanCost is the mean reported cost in USD of a run.
+	MeanCost float64
+}
+
+// BenchmarkReport holds the results of Benchmark, fastest median latency first.
+// Models without a successful run come last.
+type BenchmarkReport struct {
+	Results []BenchmarkResult
+}
+
+// Benchmark sends prompt n times to each of models, one request at a time, and
+// reports their latency, throughput and cost so routing orders can be chosen
+// with data. The client-side caches are bypassed, so every run reaches
+// OpenRouter. Failed runs are recorded in the results; only cancelling ctx
+// stops the benchmark early, returning the results so far and ctx.Err().
+func (c *Client) Benchmark(ctx context.Context, models []string, prompt string, n int, opts ...BenchmarkOption) (BenchmarkReport, error) {
+	var options benchmarkOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var report BenchmarkReport
+	for _, model := range models {
+		request := ChatCompletionRequest{
+			Model:     model,
+			Messages:  []ChatCompletionMessage{UserMessage(prompt)},
+			MaxTokens: options.maxTokens,
+			Provider:  options.provider,
+		}
+
+		result := BenchmarkResult{Model: model, Providers: make(map[string]int)}
+		var runs []benchmarkRun
+		for i := 0; i < n; i++ {
+			if ctx.Err() != nil {
+				report.add(result.summarize(runs))
+				return report, ctx.Err()
+			}
+
+			run, err := c.benchmarkRun(ctx, request, options.stream)
+			result.Runs++
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+				continue
+			}
+			result.Providers[run.provider]++
+			runs = append(runs, run)
+		}
+		report.add(result.summarize(runs))
+	}
+	return report, nil
+}
+
+type benchmarkRun struct {
+	provider         string
+	latency          time.Duration
+	timeToFirstToken time.Duration
+	tokensPerSecond  float64
+	cost             float64
+}
+
+func (c *Client) benchmarkRun(ctx context.Context, request ChatCompletionRequest, stream bool) (benchmarkRun, error) {
+	startedAt := time.Now()
+	if !stream {
+		resp, err := c.sendFreshChatCompletion(ctx, request)
+		if err != nil {
+			return benchmarkRun{}, err
+		}
+		run := benchmarkRun{provider: resp.Provider, latency: time.Since(startedAt)}
+		if resp.Usage != nil {
+			run.cost = resp.Usage.Cost
+			run.tokensPerSecond = float64(resp.Usage.CompletionTokens) / run.latency.Seconds()
+		}
+		return run, nil
+	}
+
+	request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	s, err := c.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return benchmarkRun{}, err
+	}
+	defer s.Close()
+
+	var run benchmarkRun
+	for {
+		chunk, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return benchmarkRun{}, err
+		}
+		if chunk.Provider != "" {
+			run.provider = chunk.Provider
+		}
+	}
+
+	stats := s.Stats()
+	run.latency = stats.Duration
+	run.timeToFirstToken = stats.TimeToFirstToken
+	run.tokensPerSecond = stats.TokensPerSecond
+	if usage := s.Usage(); usage != nil {
+		run.cost = usage.Cost
+	}
+	return run, nil
+}
+
+func (r BenchmarkResult) summarize(runs []benchmarkRun) BenchmarkResult {
+	if len(runs) == 0 {
+		return r
+	}
+
+	latencies := make([]time.Duration, 0, len(runs))
+	var ttfts []time.Duration
+	for _, run := range runs {
+		latencies = append(latencies, run.latency)
+		if run.timeToFirstToken > 0 {
+			ttfts = append(ttfts, run.timeToFirstToken)
+		}
+		r.TokensPerSecond += run.tokensPerSecond
+		r.MeanCost += run.cost
+	}
+	r.TokensPerSecond /= float64(len(runs))
+	r.MeanCost /= float64(len(runs))
+	r.Latency = summarizeLatencies(latencies)
+	r.TimeToFirstToken = summarizeLatencies(ttfts)
+	return r
+}
+
+func summarizeLatencies(durations []time.Duration) LatencySummary {
+	if len(durations) == 0 {
+		return LatencySummary{}
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+	return LatencySummary{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(0.5),
+		P95:  percentile(0.95),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// add inserts result keeping the report ordered by median latency.
+func (r *BenchmarkReport) add(result BenchmarkResult) {
+	r.Results = append(r.Results, result)
+	sort.SliceStable(r.Results, func(i, j int) bool {
+		a, b := r.Results[i].Latency.P50, r.Results[j].Latency.P50
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+}
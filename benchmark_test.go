@@ -0,0 +1,96 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmark(t *testing.T) {
+	t.Parallel()
+
+	ok := func(provider string) *http.Response {
+		return jsonResponse(http.StatusOK, `{"id":"1","provider":"`+provider+`","choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":10,"total_tokens":13,"cost":0.002}}`)
+	}
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusServiceUnavailable, `{"error":{"code":503,"message":"down"}}`),
+		jsonResponse(http.StatusServiceUnavailable, `{"error":{"code":503,"message":"down"}}`),
+		ok("OpenAI"),
+		jsonResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"slow down"}}`),
+	)
+
+	report, err := client.Benchmark(context.Background(), []string{"a", "b"}, "Say hi", 2, BenchmarkMaxTokens(16))
+	require.NoError(t, err)
+	require.Len(t, httpClient.requests, 4)
+	require.Equal(t, 16, httpClient.requests[0].MaxTokens)
+
+	require.Len(t, report.Results, 2)
+	b := report.Results[0]
+	require.Equal(t, "b", b.Model, "models with successful runs come first")
+	require.Equal(t, 2, b.Runs)
+	require.Len(t, b.Errors, 1)
+	require.Equal(t, map[string]int{"OpenAI": 1}, b.Providers)
+	require.InDelta(t, 0.002, b.MeanCost, 1e-12)
+	require.Positive(t, b.Latency.P50)
+	require.Positive(t, b.TokensPerSecond)
+	require.Zero(t, b.TimeToFirstToken)
+
+	a := report.Results[1]
+	require.Len(t, a.Errors, 2)
+	require.Zero(t, a.Latency)
+}
+
+func TestBenchmarkBypassesCaches(t *testing.T) {
+	t.Parallel()
+
+	ok := func() *http.Response {
+		return jsonResponse(http.StatusOK, `{"id":"1","provider":"OpenAI","choices":[{"message":{"role":"assistant","content":"hi"}}]}`)
+	}
+	client, httpClient := newSequenceClient(t, ok(), ok(), ok())
+	client.config.CompletionCache = NewCompletionCache(time.Minute)
+
+	report, err := client.Benchmark(context.Background(), []string{"a"}, "Say hi", 3)
+	require.NoError(t, err)
+	require.Len(t, httpClient.requests, 3, "every run reaches the server")
+	require.Equal(t, 3, report.Results[0].Providers["OpenAI"])
+}
+
+func TestBenchmarkStreaming(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","provider":"Groq","choices":[{"delta":{"content":"h"}}]}`,
+		`data: {"id":"1","provider":"Groq","choices":[{"delta":{"content":"i"}}]}`,
+		`data: {"id":"1","provider":"Groq","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5,"cost":0.001}}`,
+		`data: [DONE]`,
+	)))
+
+	report, err := client.Benchmark(context.Background(), []string{"a"}, "Say hi", 1, BenchmarkStreaming())
+	require.NoError(t, err)
+	require.True(t, httpClient.requests[0].StreamOptions.IncludeUsage)
+
+	result := report.Results[0]
+	require.Empty(t, result.Errors)
+	require.Equal(t, map[string]int{"Groq": 1}, result.Providers)
+	require.Positive(t, result.TimeToFirstToken.P50)
+	require.InDelta(t, 0.001, result.MeanCost, 1e-12)
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	t.Parallel()
+
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	summary := summarizeLatencies(durations)
+	require.Equal(t, time.Millisecond, summary.Min)
+	require.Equal(t, 20*time.Millisecond, summary.Max)
+	require.Equal(t, 11*time.Millisecond, summary.P50)
+	require.Equal(t, 19*time.Millisecond, summary.P95)
+	require.Equal(t, 10500*time.Microsecond, summary.Mean)
+}
@@ -0,0 +1,219 @@
+package openrouter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BulkOption configures the helpers that send many requests at once, such as
+// CreateAPIKeys and GetGenerations.
+type BulkOption func(*bulkOptions)
+
+type bulkOptions struct {
+	concurrency int
+	attempts    int
+	backoff     BackoffFunc
+	limiter     *intervalLimiter
+	batchSize   int
+	batchTokens int
+	onProgress  func(BulkProgress)
+	drain       time.Duration
+}
+
+// BulkConcurrency sets how many requests run at once, 4 by default.
+func BulkConcurrency(n int) BulkOption {
+	return func(o *bulkOptions) {
+		o.concurrency = n
+	}
+}
+
+// BulkRetries sets how many times each item is attempted, 3 by default, and
+// the delay between attempts.
+func BulkRetries(attempts int, backoff BackoffFunc) BulkOption {
+	return func(o *bulkOptions) {
+		o.attempts = attempts
+		o.backoff = backoff
+	}
+}
+
+// BulkRateLimit caps the requests started per second, counting retries.
+func BulkRateLimit(perSecond float64) BulkOption {
+	return func(o *bulkOptions) {
+		o.limiter = nil
+		if perSecond > 0 {
+			o.limiter = &intervalLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+		}
+	}
+}
+
+// BulkBatchSize caps the items and the approximate tokens sent in one request
+// by helpers that batch their input, such as CreateEmbeddingsBatched. Zero
+// keeps the helper's default.
+func BulkBatchSize(items, tokens int) BulkOption {
+	return func(o *bulkOptions) {
+		o.batchSize = items
+		o.batchTokens = tokens
+	}
+}
+
+// DefaultBulkDrainTimeout is how long requests in flight when the context of a
+// bulk call is done may take to finish, unless set by BulkDrainTimeout.
+const DefaultBulkDrainTimeout = 30 * time.Second
+
+// BulkDrainTimeout sets how long requests in flight when the context of
+// CreateChatCompletionsBulk is done may take to finish before they are
+// aborted, DefaultBulkDrainTimeout by default. Zero aborts them at once.
+func BulkDrainTimeout(d time.Duration) BulkOption {
+	return func(o *bulkOptions) {
+		o.drain = max(d, 0)
+	}
+}
+
+// BulkProgress is a snapshot of the progress of CreateChatCompletionsBulk.
+type BulkProgress struct {
+	Total     int
+	Completed int
+	Failed    int
+	InFlight  int
+	// Cost is the sum of the cost reported for the completed requests.
+	Cost float64
+}
+
+// BulkOnProgress calls fn with the progress every time a request starts or
+// ends, e.g. to drive a progress bar. Calls are serialized and must not block.
+func BulkOnProgress(fn func(BulkProgress)) BulkOption {
+	return func(o *bulkOptions) {
+		o.onProgress = fn
+	}
+}
+
+func newBulkOptions(opts []BulkOption) bulkOptions {
+	options := bulkOptions{concurrency: 4, attempts: 3, backoff: defaultBackoff, drain: DefaultBulkDrainTimeout}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.concurrency = max(options.concurrency, 1)
+	options.attempts = max(options.attempts, 1)
+	if options.backoff == nil {
+		options.backoff = defaultBackoff
+	}
+	return options
+}
+
+// wait blocks until the rate limit allows the next request.
+func (o bulkOptions) wait(ctx context.Context) error {
+	if o.limiter == nil {
+		return ctx.Err()
+	}
+	return o.limiter.wait(ctx)
+}
+
+// runBounded calls fn for every index below n with at most concurrency calls
+// running at once. Once ctx is done, remaining indexes are still passed to fn,
+// whose requests then fail fast with ctx.Err().
+func runBounded(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// drainContext returns a context carrying the values of ctx that is cancelled
+// drain after ctx is done, so that requests in flight can finish. The returned
+// function releases its resources.
+func drainContext(ctx context.Context, drain time.Duration) (context.Context, context.CancelFunc) {
+	drained, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(drain)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-drained.Done():
+		}
+	})
+	return drained, func() {
+		stop()
+		cancel()
+	}
+}
+
+// bulkProgress tracks a BulkProgress and reports its changes.
+type bulkProgress struct {
+	mu       sync.Mutex
+	progress BulkProgress
+	report   func(BulkProgress)
+}
+
+func newBulkProgress(total int, report func(BulkProgress)) *bulkProgress {
+	return &bulkProgress{progress: BulkProgress{Total: total}, report: report}
+}
+
+func (p *bulkProgress) start() {
+	p.update(func(progress *BulkProgress) {
+		progress.InFlight++
+	})
+}
+
+// finish records the end of a request, which was never started when it
+// failed before start was called.
+func (p *bulkProgress) finish(started bool, err error, cost float64) {
+	p.update(func(progress *BulkProgress) {
+		if started {
+			progress.InFlight--
+		}
+		if err != nil {
+			progress.Failed++
+		} else {
+			progress.Completed++
+		}
+		progress.Cost += cost
+	})
+}
+
+func (p *bulkProgress) update(fn func(*BulkProgress)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.progress)
+	if p.report != nil {
+		p.report(p.progress)
+	}
+}
+
+// intervalLimiter spaces calls to wait at least interval apart.
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *intervalLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
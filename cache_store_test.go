@@ -0,0 +1,128 @@
+package openrouter
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCacheStore(t *testing.T, store CacheStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, ok, err := store.Get(ctx, "missing")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, store.Set(ctx, "b/with:any chars", []byte("2"), time.Hour))
+	require.NoError(t, store.Set(ctx, "short", []byte("3"), time.Millisecond))
+
+	value, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	value, ok, err = store.Get(ctx, "b/with:any chars")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), value)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok, err = store.Get(ctx, "short")
+	require.NoError(t, err)
+	require.False(t, ok, "expired values are not returned")
+
+	require.NoError(t, store.Set(ctx, "a", []byte("4"), 0))
+	value, _, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("4"), value)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	require.NoError(t, store.Delete(ctx, "a"), "deleting a missing key is not an error")
+	_, ok, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	t.Parallel()
+	testCacheStore(t, NewMemoryCacheStore())
+}
+
+func TestMemoryCacheStoreSweepsExpiredValues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryCacheStore()
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Millisecond))
+	store.lastSweep = time.Now().Add(-memorySweepInterval)
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), 0))
+	require.Len(t, store.entries, 1)
+}
+
+func TestFileCacheStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := NewFileCacheStore(dir)
+	require.NoError(t, err)
+	testCacheStore(t, store)
+
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "kept", []byte("value"), time.Hour))
+	reopened, err := NewFileCacheStore(dir)
+	require.NoError(t, err)
+	value, ok, err := reopened.Get(ctx, "kept")
+	require.NoError(t, err)
+	require.True(t, ok, "values survive reopening the directory")
+	require.Equal(t, []byte("value"), value)
+}
+
+func TestCompletionCacheWithFileStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewFileCacheStore(t.TempDir())
+	require.NoError(t, err)
+	cache := &CompletionCache{TTL: time.Hour, Store: store}
+
+	ctx := context.Background()
+	_, ok := cache.get(ctx, slog.Default(), "key")
+	require.False(t, ok)
+
+	cache.set(ctx, slog.Default(), "key", ChatCompletionResponse{ID: "gen-1"})
+	resp, ok := cache.get(ctx, slog.Default(), "key")
+	require.True(t, ok)
+	require.True(t, resp.Cached)
+	require.Equal(t, "gen-1", resp.ID)
+}
+
+func TestCacheSemanticStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewCacheSemanticStore(NewMemoryCacheStore(), time.Hour, 2)
+
+	_, _, ok, err := store.Nearest(ctx, "p", []float64{1, 0}, time.Time{})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	for i, vector := range [][]float64{{1, 0}, {0, 1}, {1, 1}} {
+		require.NoError(t, store.Add(ctx, SemanticCacheEntry{
+			Partition: "p",
+			Vector:    vector,
+			Response:  ChatCompletionResponse{ID: string(rune('a' + i))},
+		}, time.Time{}))
+	}
+	require.NoError(t, store.Add(ctx, SemanticCacheEntry{Partition: "q", Vector: []float64{1, 0}}, time.Time{}))
+
+	entry, similarity, ok, err := store.Nearest(ctx, "p", []float64{1, 0}, time.Time{})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "c", entry.Response.ID, "the oldest entry of the partition was dropped")
+	require.InDelta(t, 0.707, similarity, 0.001)
+}
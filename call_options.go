@@ -0,0 +1,110 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// BetaHeader is the header listing the Anthropic beta features a request opts
+// into, which OpenRouter forwards to Anthropic.
+const BetaHeader = "X-Anthropic-Beta"
+
+// CallOption customizes the HTTP requests made with a context returned by
+// ContextWithCallOptions. The options are applied when the client builds a
+// request: query parameters are added to those of the endpoint, and headers
+// are set before the client's own, so the Authorization, HTTP-Referer and
+// X-OpenRouter-Title headers cannot be overridden.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	query url.Values
+	betas []string
+}
+
+// WithQueryParam adds the query parameter key with value to the URL of the
+// request.
+func WithQueryParam(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		o.query.Add(key, value)
+	}
+}
+
+// WithBetaHeader opts the request into the beta feature name, e.g.
+// "fine-grained-tool-streaming-2025-05-14", by adding it to BetaHeader.
+func WithBetaHeader(name string) CallOption {
+	return func(o *callOptions) {
+		if !slices.Contains(o.betas, name) {
+			o.betas = append(o.betas, name)
+		}
+	}
+}
+
+type callOptionsContextKey struct{}
+
+// ContextWithCallOptions returns a copy of ctx applying opts to the requests
+// made with it, in addition to the options ctx already carries:
+//
+//	ctx = openrouter.ContextWithCallOptions(ctx, openrouter.WithBetaHeader("interleaved-thinking-2025-05-14"))
+//	resp, err := client.CreateChatCompletion(ctx, request)
+func ContextWithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	options := &callOptions{}
+	if parent, ok := ctx.Value(callOptionsContextKey{}).(*callOptions); ok {
+		options.query = cloneValues(parent.query)
+		options.betas = slices.Clone(parent.betas)
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return context.WithValue(ctx, callOptionsContextKey{}, options)
+}
+
+func cloneValues(values url.Values) url.Values {
+	if values == nil {
+		return nil
+	}
+	cloned := make(url.Values, len(values))
+	for key, value := range values {
+		cloned[key] = slices.Clone(value)
+	}
+	return cloned
+}
+
+// callOptionsFrom returns the call options ctx carries.
+func callOptionsFrom(ctx context.Context) callOptions {
+	if options, ok := ctx.Value(callOptionsContextKey{}).(*callOptions); ok {
+		return *options
+	}
+	return callOptions{}
+}
+
+// url returns rawURL with the query parameters of o added to those it has.
+func (o callOptions) url(rawURL string) (string, error) {
+	if len(o.query) == 0 {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for key, values := range o.query {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// setHeaders sets the headers of o in header.
+func (o callOptions) setHeaders(header http.Header) {
+	if len(o.betas) > 0 {
+		header.Set(BetaHeader, strings.Join(o.betas, ","))
+	}
+}
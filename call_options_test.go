@@ -0,0 +1,66 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallOptions(t *testing.T) {
+	t.Parallel()
+
+	var requests []*http.Request
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		requests = append(requests, req)
+		return jsonResponse(http.StatusOK, `{"data":{"id":"gen-1"}}`)
+	})
+
+	ctx := ContextWithCallOptions(context.Background(), WithQueryParam("debug", "true"), WithBetaHeader("a"))
+	ctx = ContextWithCallOptions(ctx, WithBetaHeader("b"), WithBetaHeader("a"))
+	_, err := client.GetGeneration(ctx, "gen-1")
+	require.NoError(t, err)
+	_, err = client.GetGeneration(context.Background(), "gen-1")
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	require.Equal(t, "debug=true&id=gen-1", requests[0].URL.RawQuery)
+	require.Equal(t, "a,b", requests[0].Header.Get(BetaHeader))
+	require.Equal(t, "id=gen-1", requests[1].URL.RawQuery)
+	require.Empty(t, requests[1].Header.Get(BetaHeader))
+}
+
+func TestCallOptionsOnStreams(t *testing.T) {
+	t.Parallel()
+
+	var request *http.Request
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		request = req
+		return jsonResponse(http.StatusOK, sseBody(`data: [DONE]`))
+	})
+	ctx := ContextWithCallOptions(context.Background(), WithQueryParam("debug", "true"))
+	stream, err := client.CreateChatCompletionStream(ctx, ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	stream.Close()
+	require.Equal(t, "debug=true", request.URL.RawQuery)
+}
+
+func TestCallOptionsPrecedence(t *testing.T) {
+	t.Parallel()
+
+	var request *http.Request
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		request = req
+		return jsonResponse(http.StatusOK, `{"data":{"id":"gen-1"}}`)
+	})
+
+	ctx := ContextWithCallOptions(context.Background(), WithQueryParam("id", "gen-2"), WithBetaHeader("a"))
+	_, err := client.GetGeneration(ctx, "gen-1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"gen-1", "gen-2"}, request.URL.Query()["id"], "call parameters are added to the endpoint's")
+	require.Equal(t, "Bearer test-token", request.Header.Get("Authorization"))
+	require.Equal(t, "a", request.Header.Get(BetaHeader))
+}
@@ -1,17 +1,26 @@
 package openrouter
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 )
 
+// Constants for the widely used models, named Model<Vendor><Name>, are
+// generated into models_gen.go from a curated snapshot of the catalog in
+// cmd/gencatalog/catalog; see cmd/gencatalog for how to refresh it. The
+// constants below predate it and are kept for compatibility.
+//
+//go:generate go run ./cmd/gencatalog -kind models -save cmd/gencatalog/catalog/models.json -o models_gen.go
+
 const (
 	GPT4o                  = "openai/chatgpt-4o-latest"
 	DeepseekV3             = "deepseek/deepseek-chat"
@@ -39,6 +48,9 @@ var (
 	ErrChatCompletionInvalidModel       = errors.New("this model is not supported with this method, please use CreateCompletion client method instead") //nolint:lll
 	ErrChatCompletionStreamNotSupported = errors.New("streaming is not supported with this method, please use CreateChatCompletion")                    //nolint:lll
 	ErrContentFieldsMisused             = errors.New("can't use both Content and MultiContent properties simultaneously")
+	// ErrUnrecognizedContent is returned in strict mode for message content
+	// that is neither a string nor an array of parts.
+	ErrUnrecognizedContent = errors.New("unrecognized message content")
 )
 
 type ChatCompletionReasoning struct {
@@ -161,6 +173,7 @@ type ChatCompletionRequest struct {
 	// refs: https://platform.openai.com/docs/api-reference/chat/create#chat-create-max_completion_tokens
 	MaxCompletionTokens int                           `json:"max_completion_tokens,omitempty"`
 	Temperature         float32                       `json:"temperature,omitempty"`
+	SendTemperature     bool                          `json:"-"` // send Temperature even when 0, see MarshalJSON
 	TopP                float32                       `json:"top_p,omitempty"`
 	TopK                int                           `json:"top_k,omitempty"`
 	TopA                float32                       `json:"top_a,omitempty"`
@@ -208,7 +221,7 @@ type ChatCompletionRequest struct {
 	// Trace provides structured tracing metadata for observability integrations.
 	// https://openrouter.ai/docs/guides/features/broadcast/overview#custom-metadata
 	Trace *ChatCompletionTrace `json:"trace,omitempty"`
-	// Apply message transforms
+	// Apply message transforms, e.g. TransformMiddleOut
 	// https://openrouter.ai/docs/features/message-transforms
 	Transforms []string `json:"transforms,omitempty"`
 	// Optional web search options
@@ -216,6 +229,16 @@ type ChatCompletionRequest struct {
 	WebSearchOptions *WebSearchOptions `json:"web_search_options,omitempty"`
 
 	Usage *IncludeUsage `json:"usage,omitempty"`
+
+	// ExtraBody holds vendor extensions and parameters this struct does not
+	// have yet, merged into the top level of the request, overriding the
+	// fields above.
+	ExtraBody map[string]any `json:"-"`
+	// ExtraFields holds parameters this struct does not have yet, merged into
+	// the top level of the request. Unlike ExtraBody, a key naming a field of
+	// the request or of ExtraBody fails the encoding with
+	// ErrExtraFieldConflict.
+	ExtraFields map[string]any `json:"-"`
 }
 
 type SearchContextSize string
@@ -283,6 +306,20 @@ type ChatProvider struct {
 	Quantizations []string `json:"quantizations,omitempty"`
 	// Sort providers by price or throughput. (e.g. "price" or "throughput").
 	Sort ProviderSorting `json:"sort,omitempty"`
+	// Only use providers whose prices do not exceed these.
+	MaxPrice *ProviderMaxPrice `json:"max_price,omitempty"`
+	// ExtraBody holds provider-specific parameters, merged into the provider
+	// object, overriding the fields above.
+	ExtraBody map[string]any `json:"-"`
+}
+
+// ProviderMaxPrice is the highest price a request accepts, in USD. Prompt and
+// completion prices are per million tokens.
+type ProviderMaxPrice struct {
+	Prompt     float64 `json:"prompt,omitempty"`
+	Completion float64 `json:"completion,omitempty"`
+	Request    float64 `json:"request,omitempty"`
+	Image      float64 `json:"image,omitempty"`
 }
 
 // ChatCompletionResponse represents a response structure for chat completion API.
@@ -297,6 +334,10 @@ type ChatCompletionResponse struct {
 	Usage             *Usage                 `json:"usage,omitempty"`
 	SystemFingerprint string                 `json:"system_fingerprint"`
 
+	// Cached is true when the response was served from a client-side cache,
+	// such as the one set with WithSemanticCache, instead of the API.
+	Cached bool `json:"-"`
+
 	// http.Header
 }
 
@@ -330,7 +371,10 @@ const (
 	FinishReasonFunctionCall  FinishReason = "function_call"
 	FinishReasonToolCalls     FinishReason = "tool_calls"
 	FinishReasonContentFilter FinishReason = "content_filter"
-	FinishReasonNull          FinishReason = "null"
+	// FinishReasonError is reported when the provider aborted the generation
+	// midway. The choice's Error field carries the details, if any.
+	FinishReasonError FinishReason = "error"
+	FinishReasonNull  FinishReason = "null"
 )
 
 func (r FinishReason) MarshalJSON() ([]byte, error) {
@@ -393,10 +437,18 @@ type ChatCompletionChoice struct {
 	// length: Incomplete model output due to max_tokens parameter or token limit
 	// function_call: The model decided to call a function
 	// content_filter: Omitted content due to a flag from our content filters
+	// error: The provider aborted the generation, see Error
 	// null: API response still in progress or incomplete
 	FinishReason       FinishReason `json:"finish_reason"`
 	NativeFinishReason string       `json:"native_finish_reason"`
 	LogProbs           *LogProbs    `json:"logprobs,omitempty"`
+	// Error describes why the generation was aborted when FinishReason is error.
+	Error *APIError `json:"error,omitempty"`
+}
+
+// Err returns a *GenerationError when the provider aborted this choice, nil otherwise.
+func (c ChatCompletionChoice) Err() error {
+	return generationError(c.Index, c.FinishReason, c.Error)
 }
 
 type PromptAnnotation struct {
@@ -531,6 +583,7 @@ const (
 	AudioFormatAac   AudioFormat = AudioFormat("aac")
 	AudioFormatOgg   AudioFormat = AudioFormat("ogg")
 	AudioFormatM4a   AudioFormat = AudioFormat("m4a")
+	AudioFormatWebm  AudioFormat = AudioFormat("webm")
 )
 
 // Here we define the supported audio voices for the ChatCompletionAudioConfig struct, based on the OpenRouter documentation.
@@ -561,6 +614,29 @@ type FileContent struct {
 type Content struct {
 	Text  string
 	Multi []ChatMessagePart
+	// Raw holds content received in another shape than a string or an array
+	// of parts, which leaves Text and Multi empty. It is sent back unchanged.
+	Raw json.RawMessage
+
+	// empty records how empty content was received, so it is sent back in the
+	// same form.
+	empty emptyContent
+}
+
+// emptyContent is the form of received content without text or parts.
+type emptyContent uint8
+
+const (
+	emptyContentNone emptyContent = iota
+	emptyContentNull
+	emptyContentString
+	emptyContentArray
+)
+
+// IsZero reports whether c is empty and was not received as null, "" or [],
+// in which case the content field of a message is omitted.
+func (c Content) IsZero() bool {
+	return c.Text == "" && len(c.Multi) == 0 && c.Raw == nil && c.empty == emptyContentNone
 }
 
 type Annotation struct {
@@ -621,35 +697,69 @@ type ChatCompletionMessage struct {
 	Audio *ChatCompletionAudio `json:"audio,omitempty"`
 }
 
-// MarshalJSON serializes ContentType as a string or array.
+// MarshalJSON serializes ContentType as a string or array. It fails with
+// ErrContentFieldsMisused when both Text and Multi are set.
 func (c Content) MarshalJSON() ([]byte, error) {
-	if c.Text != "" && len(c.Multi) == 0 {
-		return json.Marshal(c.Text)
+	if c.Text != "" && len(c.Multi) > 0 {
+		return nil, ErrContentFieldsMisused
 	}
-	if len(c.Multi) > 0 && c.Text == "" {
+	switch {
+	case c.Text != "":
+		return json.Marshal(c.Text)
+	case len(c.Multi) > 0:
 		return json.Marshal(c.Multi)
+	case c.Raw != nil:
+		return c.Raw, nil
+	case c.empty == emptyContentString:
+		return []byte(`""`), nil
+	case c.empty == emptyContentArray:
+		return []byte(`[]`), nil
 	}
-	return json.Marshal(nil)
+	return []byte("null"), nil
 }
 
-// UnmarshalJSON deserializes ContentType from a string or array.
+// UnmarshalJSON deserializes ContentType from a string or array. Content in
+// any other shape is kept in Raw.
 func (c *Content) UnmarshalJSON(data []byte) error {
+	*c = Content{}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("null")) {
+		c.empty = emptyContentNull
+		return nil
+	}
+
 	var s string
-	if err := json.Unmarshal(data, &s); err == nil && s != "" {
+	if err := json.Unmarshal(data, &s); err == nil {
 		c.Text = s
-		c.Multi = nil
+		if s == "" {
+			c.empty = emptyContentString
+		}
 		return nil
 	}
 
 	var parts []ChatMessagePart
-	if err := json.Unmarshal(data, &parts); err == nil && len(parts) > 0 {
-		c.Text = ""
+	if err := json.Unmarshal(data, &parts); err == nil {
 		c.Multi = parts
+		if len(parts) == 0 {
+			c.Multi = nil
+			c.empty = emptyContentArray
+		}
 		return nil
 	}
 
-	c.Text = ""
-	c.Multi = nil
+	c.Raw = append(json.RawMessage(nil), trimmed...)
+	return nil
+}
+
+// contentError returns an error wrapping ErrUnrecognizedContent when the
+// message of a choice has content in an unrecognized shape.
+func (r ChatCompletionResponse) contentError() error {
+	for _, choice := range r.Choices {
+		if raw := choice.Message.Content.Raw; raw != nil {
+			return fmt.Errorf("choice %d: %w: %s", choice.Index, ErrUnrecognizedContent, raw)
+		}
+	}
 	return nil
 }
 
@@ -730,9 +840,25 @@ func (p ChatCompletionFallbackPolicy) shouldFallback(err error) bool {
 }
 
 // CreateChatCompletion — API call to Create a completion for the chat message.
+//
+// Messages ending with an assistant message, see AssistantPrefill, ask the
+// model to continue that message. The returned content is the continuation
+// only, unless the client was created with WithMergedPrefill.
 func (c *Client) CreateChatCompletion(
 	ctx context.Context,
 	request ChatCompletionRequest,
+) (ChatCompletionResponse, error) {
+	response, err := c.createChatCompletion(ctx, request)
+	if err == nil && c.config.MergePrefill {
+		response = mergePrefill(request.Messages, response)
+	}
+	return response, err
+}
+
+// createChatCompletion creates a chat completion without merging the prefill.
+func (c *Client) createChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
 ) (response ChatCompletionResponse, err error) {
 	if request.Stream {
 		err = ErrChatCompletionStreamNotSupported
@@ -743,7 +869,84 @@ func (c *Client) CreateChatCompletion(
 		err = ErrChatCompletionInvalidModel
 		return
 	}
+	if err = c.checkTransforms(request.Transforms); err != nil {
+		return
+	}
+
+	ctx, span := c.startChatSpan(ctx, request)
+
+	startedAt := time.Now()
+	cacheHit := false
+	defer func() {
+		event := UsageEvent{Endpoint: "chat", RequestID: response.ID, Model: response.Model,
+			Provider: response.Provider, Metadata: request.Metadata, CacheHit: cacheHit, Err: err}
+		if event.Model == "" {
+			event.Model = request.Model
+		}
+		if len(response.Choices) > 0 {
+			event.FinishReason = response.Choices[0].FinishReason
+		}
+		c.recordUsage(ctx, event, response.Usage, startedAt)
+		finishReasons, messages := chatSpanOutput(response.Choices)
+		span.end(event, response.Usage, finishReasons, messages, err)
+	}()
+
+	var key string
+	if cache := c.config.CompletionCache; cache != nil {
+		if key, err = HashRequest(request); err != nil {
+			return
+		}
+		if cached, ok := cache.get(ctx, c.logger(), key); ok {
+			cacheHit = true
+			return cached, nil
+		}
+	}
+	var lookup *semanticCacheLookup
+	if cache := c.config.SemanticCache; cache != nil {
+		if lookup = cache.lookup(ctx, c, request); lookup != nil && lookup.hit != nil {
+			cacheHit = true
+			return *lookup.hit, nil
+		}
+	}
+
+	if err = c.checkQuota(ctx); err != nil {
+		return
+	}
+	response, err = c.sendChatCompletion(ctx, request)
+	if err != nil {
+		return
+	}
+	if key != "" {
+		c.config.CompletionCache.set(ctx, c.logger(), key, response)
+	}
+	if lookup != nil {
+		lookup.store(ctx, response)
+	}
+	return
+}
 
+// sendFreshChatCompletion sends request bypassing the client-side caches,
+// with the quota check and usage recording of CreateChatCompletion.
+func (c *Client) sendFreshChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+) (ChatCompletionResponse, error) {
+	if err := c.checkQuota(ctx); err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	startedAt := time.Now()
+	response, err := c.sendChatCompletion(ctx, request)
+	event := UsageEvent{Endpoint: "chat", RequestID: response.ID, Model: request.Model,
+		Provider: response.Provider, Metadata: request.Metadata, Err: err}
+	c.recordUsage(ctx, event, response.Usage, startedAt)
+	return response, err
+}
+
+// sendChatCompletion sends request, bypassing the client-side caches.
+func (c *Client) sendChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+) (response ChatCompletionResponse, err error) {
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
@@ -755,6 +958,16 @@ func (c *Client) CreateChatCompletion(
 	}
 
 	err = c.sendRequest(req, &response)
+	if transforms, retry := c.middleOutRetry(err, request.Transforms); retry {
+		request.Transforms = transforms
+		return c.sendChatCompletion(ctx, request)
+	}
+	if err == nil && c.config.StrictMode {
+		err = response.contentError()
+	}
+	if err == nil {
+		c.reconcileCost(response.ID)
+	}
 	return
 }
 
@@ -771,42 +984,211 @@ func (c *Client) CreateChatCompletionWithFallback(
 }
 
 // CreateChatCompletionWithFallbackPolicy tries request.Model first, then
-// policy.Models according to policy's fallback rules.
+// policy.Models according to policy's fallback rules. When no model answers,
+// the error is a *FallbackError describing every attempt.
 func (c *Client) CreateChatCompletionWithFallbackPolicy(
 	ctx context.Context,
 	request ChatCompletionRequest,
 	policy ChatCompletionFallbackPolicy,
 ) (ChatCompletionResponse, error) {
-	resp, err := c.CreateChatCompletion(ctx, request)
-	if err == nil {
+	result, err := c.CreateChatCompletionWithFallbacks(ctx, request, policy)
+	return result.Response, err
+}
+
+// fallbackChain calls call with request, then with request for each of
+// policy.Models while the previous attempt failed with an error policy deems
+// fallbackable. It returns the result and model of the attempt that succeeded
+// and the failed attempts, or a *FallbackError.
+func fallbackChain[T any](
+	ctx context.Context,
+	request ChatCompletionRequest,
+	policy ChatCompletionFallbackPolicy,
+	call func(context.Context, ChatCompletionRequest) (T, error),
+) (T, string, []FallbackAttempt, error) {
+	var attempts []FallbackAttempt
+	models := append([]string{request.Model}, policy.Models...)
+	for i, model := range models {
+		if i > 0 && model == "" {
+			continue
+		}
+
+		attempt := request
+		attempt.Model = model
+		result, err := call(ctx, attempt)
+		if err == nil {
+			return result, model, attempts, nil
+		}
+		attempts = append(attempts, FallbackAttempt{Model: model, Err: err})
+		if ctx.Err() != nil || !policy.shouldFallback(err) {
+			break
+		}
+	}
+
+	var zero T
+	return zero, "", attempts, &FallbackError{Attempts: attempts}
+}
+
+// FallbackAttempt records a model that failed during a fallback chain.
+type FallbackAttempt struct {
+	Model string
+	Err   error
+}
+
+// FallbackError is returned by the fallback chains of chat completions when no
+// model answered. It unwraps to the error of every attempt, last attempt first,
+// so errors.As and helpers such as IsHTTPStatus see the final error first.
+type FallbackError struct {
+	Attempts []FallbackAttempt
+}
+
+func (e *FallbackError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "chat completion failed after %d attempts", len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		fmt.Fprintf(&b, "; %s: %v", attempt.Model, attempt.Err)
+	}
+	return b.String()
+}
+
+func (e *FallbackError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		errs[len(errs)-1-i] = attempt.Err
+	}
+	return errs
+}
+
+// FallbackResult is the outcome of CreateChatCompletionWithFallbacks.
+type FallbackResult struct {
+	Response ChatCompletionResponse
+	// Model is the requested model that answered.
+	Model string
+	// Attempts holds the models that failed before Model answered.
+	Attempts []FallbackAttempt
+}
+
+// CreateChatCompletionWithFallbacks tries request.Model, then each of
+// policy.Models in turn until one answers, moving on only on the errors policy
+// deems fallbackable. Unlike the server-side Models field, every attempt is a
+// separate request, so the chain also covers failures of OpenRouter itself.
+// The result records which model answered and the attempts that failed before
+// it. When no model answers, the error is a *FallbackError describing every
+// attempt.
+func (c *Client) CreateChatCompletionWithFallbacks(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	policy ChatCompletionFallbackPolicy,
+) (FallbackResult, error) {
+	resp, model, attempts, err := fallbackChain(ctx, request, policy, c.CreateChatCompletion)
+	return FallbackResult{Response: resp, Model: model, Attempts: attempts}, err
+}
+
+// CreateChatCompletionFull creates a chat completion and, while the model stops
+// with FinishReasonLength, re-sends the request with the text generated so far
+// as an assistant prefill so the model picks up where it left off. At most
+// maxContinuations follow-up requests are made.
+//
+// The returned response is the last one received with choices, with the
+// concatenated content in its first choice and the usage summed over all
+// requests. Only the first choice is continued. A continuation without
+// choices ends the loop, keeping the content received so far.
+func (c *Client) CreateChatCompletionFull(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	maxContinuations int,
+) (ChatCompletionResponse, error) {
+	resp, err := c.createChatCompletion(ctx, request)
+	if err != nil {
 		return resp, err
 	}
 
-	lastErr := err
-	for _, model := range policy.Models {
-		if model == "" {
-			continue
+	if len(resp.Choices) == 0 {
+		return resp, nil
+	}
+
+	usage := resp.Usage
+	var content strings.Builder
+	content.WriteString(resp.Choices[0].Message.Content.Text)
+	for continuations := 0; resp.Choices[0].FinishReason == FinishReasonLength && continuations < maxContinuations; continuations++ {
+		next, err := c.createChatCompletion(ctx, continuationRequest(request, content.String()))
+		if err != nil {
+			return ChatCompletionResponse{}, err
 		}
-		if !policy.shouldFallback(lastErr) {
+		usage = addUsage(usage, next.Usage)
+		if len(next.Choices) == 0 {
+			// Nothing more to append; the response keeps FinishReasonLength.
 			break
 		}
+		resp = next
+		content.WriteString(resp.Choices[0].Message.Content.Text)
+	}
 
-		fallbackReq := request
-		fallbackReq.Model = model
-		resp, err = c.CreateChatCompletion(ctx, fallbackReq)
-		if err == nil {
-			return resp, nil
+	resp.Choices[0].Message.Content.Text = content.String()
+	resp.Usage = usage
+	if c.config.MergePrefill {
+		resp = mergePrefill(request.Messages, resp)
+	}
+	return resp, nil
+}
+
+// addUsage returns the sum of the token counts and costs of a and b. A
+// modality count is nil only when neither reports it. IsBYOK is true when
+// either was served with the caller's own key, false when both report false,
+// and nil when neither says. The sum shares no pointers with a or b.
+func addUsage(a, b *Usage) *Usage {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
 		}
-		lastErr = err
+		return a
 	}
 
-	return ChatCompletionResponse{}, lastErr
+	sum := *a
+	sum.PromptTokens += b.PromptTokens
+	sum.CompletionTokens += b.CompletionTokens
+	sum.TotalTokens += b.TotalTokens
+	sum.IsBYOK = eitherTrue(a.IsBYOK, b.IsBYOK)
+	sum.Cost += b.Cost
+	sum.CompletionTokenDetails.ReasoningTokens += b.CompletionTokenDetails.ReasoningTokens
+	sum.CompletionTokenDetails.ImageTokens = addCounts(a.CompletionTokenDetails.ImageTokens, b.CompletionTokenDetails.ImageTokens)
+	sum.CompletionTokenDetails.AudioTokens = addCounts(a.CompletionTokenDetails.AudioTokens, b.CompletionTokenDetails.AudioTokens)
+	sum.PromptTokenDetails.CachedTokens += b.PromptTokenDetails.CachedTokens
+	sum.PromptTokenDetails.CacheWriteTokens += b.PromptTokenDetails.CacheWriteTokens
+	sum.PromptTokenDetails.AudioTokens = addCounts(a.PromptTokenDetails.AudioTokens, b.PromptTokenDetails.AudioTokens)
+	sum.PromptTokenDetails.VideoTokens = addCounts(a.PromptTokenDetails.VideoTokens, b.PromptTokenDetails.VideoTokens)
+	sum.CostDetails.UpstreamInferenceCost += b.CostDetails.UpstreamInferenceCost
+	sum.CostDetails.UpstreamInferencePromptCost += b.CostDetails.UpstreamInferencePromptCost
+	sum.CostDetails.UpstreamInferenceCompletionCost += b.CostDetails.UpstreamInferenceCompletionCost
+	return &sum
+}
+
+// addCounts returns the sum of the optional counts a and b, nil when both are.
+func addCounts(a, b *int) *int {
+	if a == nil && b == nil {
+		return nil
+	}
+	var sum int
+	if a != nil {
+		sum += *a
+	}
+	if b != nil {
+		sum += *b
+	}
+	return &sum
+}
+
+// eitherTrue returns whether a or b is true, nil when both are nil.
+func eitherTrue(a, b *bool) *bool {
+	if a == nil && b == nil {
+		return nil
+	}
+	either := (a != nil && *a) || (b != nil && *b)
+	return &either
 }
 
 type ChatCompletionStream struct {
-	stream   <-chan ChatCompletionStreamResponse
-	done     chan struct{}
-	response *http.Response
+	reader *sseStream[ChatCompletionStreamResponse]
+	usage  *Usage
 }
 
 // CreateChatCompletionStreamWithFallback tries request.Model first, then
@@ -823,35 +1205,15 @@ func (c *Client) CreateChatCompletionStreamWithFallback(
 
 // CreateChatCompletionStreamWithFallbackPolicy tries request.Model first, then
 // policy.Models according to policy's fallback rules before streaming starts.
+// When no model answers, the error is a *FallbackError describing every
+// attempt.
 func (c *Client) CreateChatCompletionStreamWithFallbackPolicy(
 	ctx context.Context,
 	request ChatCompletionRequest,
 	policy ChatCompletionFallbackPolicy,
 ) (*ChatCompletionStream, error) {
-	stream, err := c.CreateChatCompletionStream(ctx, request)
-	if err == nil {
-		return stream, nil
-	}
-
-	lastErr := err
-	for _, model := range policy.Models {
-		if model == "" {
-			continue
-		}
-		if !policy.shouldFallback(lastErr) {
-			break
-		}
-
-		fallbackReq := request
-		fallbackReq.Model = model
-		stream, err = c.CreateChatCompletionStream(ctx, fallbackReq)
-		if err == nil {
-			return stream, nil
-		}
-		lastErr = err
-	}
-
-	return nil, lastErr
+	stream, _, _, err := fallbackChain(ctx, request, policy, c.CreateChatCompletionStream)
+	return stream, err
 }
 
 // CreateChatCompletionStream — API call to Create a completion for the chat message with streaming.
@@ -862,97 +1224,122 @@ func (c *Client) CreateChatCompletionStream(
 	if !request.Stream {
 		request.Stream = true
 	}
+	if c.config.IncludeStreamUsage && request.StreamOptions == nil {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
 
 	if !isSupportingModel(chatCompletionsSuffix, request.Model) {
 		return nil, ErrChatCompletionInvalidModel
 	}
-
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL(chatCompletionsSuffix),
-		withBody(request),
-	)
-	if err != nil {
+	if err := c.checkTransforms(request.Transforms); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.config.HTTPClient.Do(req)
-	if err != nil {
+	if err := c.checkQuota(ctx); err != nil {
 		return nil, err
 	}
-	if isFailureStatusCode(resp) {
-		return nil, c.handleErrorResp(resp)
-	}
 
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, errors.New("unexpected status code: " + resp.Status)
+	ctx, span := c.startChatSpan(ctx, request)
+	ctx, cancel := context.WithCancel(ctx)
+	startedAt := time.Now()
+	resp, err := c.openStream(ctx, chatCompletionsSuffix, request)
+	if transforms, retry := c.middleOutRetry(err, request.Transforms); retry {
+		request.Transforms = transforms
+		resp, err = c.openStream(ctx, chatCompletionsSuffix, request)
+	}
+	if err != nil {
+		cancel()
+		span.end(UsageEvent{Model: request.Model}, nil, nil, nil, err)
+		return nil, err
 	}
 
-	stream := make(chan ChatCompletionStreamResponse)
-	done := make(chan struct{})
-
-	go func() {
-		defer close(stream)
-		defer resp.Body.Close()
-
-		reader := bufio.NewReader(resp.Body)
-		for {
-			select {
-			case <-done:
-				return
-			case <-ctx.Done():
-				slog.Info("Stream stopped due to context cancellation")
-				return
-			default:
-				line, err := reader.ReadBytes('\n')
-				if err != nil {
-					if err == io.EOF {
-						return
-					}
-					slog.Error("failed to read chat completion stream", "error", err)
-					return
-				}
-				// If stream ended with done, stop immediately
-				if strings.HasSuffix(string(line), "[DONE]\n") {
-					return
-				}
-				// Ignore openrouter comments, empty lines
-				if strings.HasPrefix(string(line), ": OPENROUTER PROCESSING") || string(line) == "\n" {
-					continue
-				}
-				// Trim everything before json object from line
-				line = bytes.TrimPrefix(line, []byte("data:"))
-				// Decode object into a ChatCompletionResponse
-				var chunk ChatCompletionStreamResponse
-				if err := json.Unmarshal(line, &chunk); err != nil {
-					slog.Error("failed to decode chat completion stream", "error", err, "line", string(line))
-					return
+	// content accumulates the first choice's text so a dropped stream can be resumed.
+	var content strings.Builder
+	// generationIDs holds the ID of every generation of the stream; each
+	// resumption starts a new one.
+	var generationIDs []string
+	usage := newStreamUsage("chat", request.Model)
+	usage.event.Metadata = request.Metadata
+	resumes := 0
+	opts := sseStreamOptions[ChatCompletionStreamResponse]{
+		name:        "chat completion",
+		idleTimeout: c.config.StreamIdleTimeout,
+		raw:         c.config.RawStreamEvents,
+		onComment:   c.config.StreamCommentHook,
+		onChunk: func(chunk ChatCompletionStreamResponse) {
+			if chunk.ID != "" && !slices.Contains(generationIDs, chunk.ID) {
+				generationIDs = append(generationIDs, chunk.ID)
+			}
+			usage.chunk(chunk.ID, chunk.Model, chunk.Provider, chunk.Usage)
+			var delta string
+			if len(chunk.Choices) > 0 {
+				delta = chunk.Choices[0].Delta.Content
+				content.WriteString(delta)
+				if reason := chunk.Choices[0].FinishReason; reason != "" {
+					usage.event.FinishReason = reason
 				}
-				stream <- chunk
 			}
+			span.chunk(chunk.hasOutput(), delta, chunk.Err())
+		},
+		onEnd: func(err error, stats StreamStats) {
+			for _, id := range generationIDs {
+				c.reconcileCost(id)
+			}
+			usage.event.Err = err
+			c.recordUsage(ctx, usage.event, usage.usage, startedAt)
+			var finishReasons []string
+			if reason := usage.event.FinishReason; reason != "" {
+				finishReasons = []string{string(reason)}
+			}
+			completion := []ChatCompletionMessage{AssistantMessage(content.String())}
+			span.endStream(stats, usage.event, usage.usage, finishReasons, completion, err)
+		},
+		startedAt: startedAt,
+		hasToken:  ChatCompletionStreamResponse.hasOutput,
+		usage: func(chunk ChatCompletionStreamResponse) *Usage {
+			return chunk.Usage
+		},
+		metrics: c.metricsSink(),
+		logger:  c.logger(),
+		tags:    map[string]string{"endpoint": "chat", "model": request.Model},
+	}
+	if c.config.StreamResumeAttempts > 0 {
+		opts.resume = func(ctx context.Context) (*http.Response, error) {
+			if resumes >= c.config.StreamResumeAttempts {
+				return nil, errors.New("stream resume attempts exhausted")
+			}
+			resumes++
+			return c.openStream(ctx, chatCompletionsSuffix, continuationRequest(request, content.String()))
 		}
-	}()
+	}
 
 	return &ChatCompletionStream{
-		stream:   stream,
-		done:     done,
-		response: resp,
+		reader: newSSEStream(ctx, cancel, resp, opts),
 	}, nil
 }
 
+// continuationRequest returns a copy of request that asks the model to continue
+// from partial, the assistant text received so far. A trailing assistant
+// message in request is treated as an existing prefill and extended.
+func continuationRequest(request ChatCompletionRequest, partial string) ChatCompletionRequest {
+	messages := append([]ChatCompletionMessage(nil), request.Messages...)
+	if n := len(messages); n > 0 && messages[n-1].Role == ChatMessageRoleAssistant && len(messages[n-1].Content.Multi) == 0 {
+		messages[n-1].Content.Text += partial
+	} else {
+		messages = append(messages, AssistantMessage(partial))
+	}
+
+	request.Messages = messages
+	return request
+}
+
 type ChatCompletionStreamChoiceDelta struct {
-	Content          string                           `json:"content,omitempty"`
-	Role             string                           `json:"role,omitempty"`
-	FunctionCall     *FunctionCall                    `json:"function_call,omitempty"`
-	ToolCalls        []ToolCall                       `json:"tool_calls,omitempty"`
-	Refusal          string                           `json:"refusal,omitempty"`
+	Content      string        `json:"content,omitempty"`
+	Role         string        `json:"role,omitempty"`
+	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+	Refusal      string        `json:"refusal,omitempty"`
+	// Web search annotations, delivered as soon as the provider emits them.
 	Annotations      []Annotation                     `json:"annotations,omitempty"`
 	Images           []ChatCompletionImage            `json:"images,omitempty"`
 	Audio            *ChatCompletionAudio             `json:"audio,omitempty"`
@@ -987,6 +1374,8 @@ type ChatCompletionStreamChoice struct {
 	FinishReason         FinishReason                        `json:"finish_reason"`
 	NativeFinishReason   string                              `json:"native_finish_reason"`
 	ContentFilterResults *ContentFilterResults               `json:"content_filter_results,omitempty"`
+	// Error describes why the generation was aborted when FinishReason is error.
+	Error *APIError `json:"error,omitempty"`
 }
 
 type ChatCompletionStreamResponse struct {
@@ -996,6 +1385,7 @@ type ChatCompletionStreamResponse struct {
 	Model               string                       `json:"model"`
 	Provider            string                       `json:"provider"`
 	Choices             []ChatCompletionStreamChoice `json:"choices"`
+	Citations           []string                     `json:"citations,omitempty"`
 	SystemFingerprint   string                       `json:"system_fingerprint"`
 	PromptAnnotations   []PromptAnnotation           `json:"prompt_annotations,omitempty"`
 	PromptFilterResults []PromptFilterResult         `json:"prompt_filter_results,omitempty"`
@@ -1003,27 +1393,81 @@ type ChatCompletionStreamResponse struct {
 	// When present, it contains a null value except for the last chunk which contains the token usage statistics
 	// for the entire request.
 	Usage *Usage `json:"usage,omitempty"`
+	// Error is set when the provider failed after the stream had started. The
+	// affected choices then finish with FinishReasonError.
+	Error *APIError `json:"error,omitempty"`
+}
+
+// Err returns a *GenerationError when the provider aborted the generation in
+// this chunk, nil otherwise.
+func (r ChatCompletionStreamResponse) Err() error {
+	for _, choice := range r.Choices {
+		details := choice.Error
+		if details == nil {
+			details = r.Error
+		}
+		if err := generationError(choice.Index, choice.FinishReason, details); err != nil {
+			return err
+		}
+	}
+	if r.Error != nil {
+		return &GenerationError{Err: r.Error}
+	}
+	return nil
+}
+
+// hasOutput reports whether the chunk carries generated content, reasoning or tool calls.
+func (r ChatCompletionStreamResponse) hasOutput() bool {
+	for _, choice := range r.Choices {
+		delta := choice.Delta
+		if delta.Content != "" || delta.ReasoningContent != "" || len(delta.ToolCalls) > 0 ||
+			(delta.Reasoning != nil && *delta.Reasoning != "") || delta.Audio != nil || len(delta.Images) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Recv reads the next chunk from the stream.
+// It returns io.EOF once the stream has ended, or the error that terminated it.
 func (s *ChatCompletionStream) Recv() (ChatCompletionStreamResponse, error) {
-	select {
-	case chunk, ok := <-s.stream:
-		if !ok {
-			return ChatCompletionStreamResponse{}, io.EOF
-		}
-		return chunk, nil
-	case <-s.done:
-		return ChatCompletionStreamResponse{}, io.EOF
+	chunk, err := s.reader.Recv()
+	if err == nil && chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+	return chunk, err
+}
+
+// RecvEvent reads the next event from the stream. With WithRawStreamEvents
+// every line of the body is returned, including comments and the [DONE]
+// terminator, so it can be forwarded downstream unchanged; otherwise only
+// chunk events are returned. Recv and RecvEvent share the same stream.
+func (s *ChatCompletionStream) RecvEvent() (StreamEvent[ChatCompletionStreamResponse], error) {
+	event, err := s.reader.RecvEvent()
+	if err == nil && event.Chunk != nil && event.Chunk.Usage != nil {
+		s.usage = event.Chunk.Usage
 	}
+	return event, err
+}
+
+// Stats returns the timing of the stream, such as time to first token and
+// tokens per second. It is complete once Recv has returned an error, including io.EOF.
+func (s *ChatCompletionStream) Stats() StreamStats {
+	return s.reader.Stats()
+}
+
+// Usage returns the token usage reported by the stream, or nil if none was received.
+// OpenRouter sends the usage on the last chunk, so it is only complete once Recv
+// has returned io.EOF. Set StreamOptions.IncludeUsage (or use WithStreamUsage)
+// to request it.
+func (s *ChatCompletionStream) Usage() *Usage {
+	return s.usage
 }
 
 // Close terminates the stream and cleans up resources.
+// It is safe to call from any goroutine, any number of times.
 func (s *ChatCompletionStream) Close() {
-	close(s.done)
-	if s.response != nil {
-		s.response.Body.Close()
-	}
+	s.reader.Close()
 }
 
 // String is a helper function returns a pointer to the string value passed in.
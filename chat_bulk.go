@@ -0,0 +1,79 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ChatCompletionResult is the outcome of one request of
+// CreateChatCompletionsBulk.
+type ChatCompletionResult struct {
+	Request  ChatCompletionRequest
+	Response ChatCompletionResponse
+	Err      error
+}
+
+// CreateChatCompletionsBulk creates a chat completion for every request, with
+// at most concurrency requests running at once, or the BulkConcurrency of opts
+// when concurrency is zero. Transient failures are retried as configured by
+// BulkRetries, and requests are paced by BulkRateLimit.
+//
+// Results are in the order of requests and hold the error of every request
+// that failed; the returned error joins them. Progress is reported to the
+// BulkOnProgress callback of opts.
+//
+// Cancelling ctx, or reaching its deadline, drains the pool: the requests not
+// yet sent fail with ctx.Err() and are not retried, while those in flight are
+// left to finish within BulkDrainTimeout, so responses already paid for are not
+// lost. Requests still running after it are aborted.
+func (c *Client) CreateChatCompletionsBulk(
+	ctx context.Context,
+	requests []ChatCompletionRequest,
+	concurrency int,
+	opts ...BulkOption,
+) ([]ChatCompletionResult, error) {
+	options := newBulkOptions(opts)
+	if concurrency > 0 {
+		options.concurrency = concurrency
+	}
+
+	// Requests are sent with inflight, which outlives ctx by the drain timeout.
+	inflight, cancel := drainContext(ctx, options.drain)
+	defer cancel()
+
+	progress := newBulkProgress(len(requests), options.onProgress)
+	results := make([]ChatCompletionResult, len(requests))
+	runBounded(ctx, len(requests), options.concurrency, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Request = requests[i]
+		started := false
+		result.Err = retry(ctx, options.attempts, options.backoff, isTransient, func(int) error {
+			if err := options.wait(ctx); err != nil {
+				return err
+			}
+			if !started {
+				started = true
+				progress.start()
+			}
+			var err error
+			result.Response, err = c.CreateChatCompletion(inflight, requests[i])
+			return err
+		})
+
+		var cost float64
+		if usage := result.Response.Usage; result.Err == nil && usage != nil {
+			cost = usage.Cost
+		}
+		progress.finish(started, result.Err, cost)
+		if result.Err != nil {
+			result.Err = fmt.Errorf("chat completion %d (%s): %w", i, requests[i].Model, result.Err)
+		}
+	})
+
+	errs := make([]error, 0, len(results))
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,215 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateChatCompletionsBulk(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight, flakyAttempts atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		var body ChatCompletionRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"bad body"}}`)
+		}
+		prompt := body.Messages[0].Content.Text
+		switch {
+		case prompt == "flaky" && flakyAttempts.Add(1) == 1:
+			return jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"bad gateway"}}`)
+		case prompt == "invalid":
+			return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"invalid"}}`)
+		}
+		return completionResponse("echo " + prompt)
+	})
+
+	var requests []ChatCompletionRequest
+	for _, prompt := range []string{"a", "flaky", "invalid", "b", "c", "d"} {
+		requests = append(requests, ChatCompletionRequest{
+			Model:    "openai/gpt-4o",
+			Messages: []ChatCompletionMessage{UserMessage(prompt)},
+		})
+	}
+	results, err := client.CreateChatCompletionsBulk(context.Background(), requests, 2,
+		BulkRetries(3, ExponentialBackoff(time.Millisecond, time.Millisecond)))
+
+	require.Error(t, err)
+	require.Len(t, results, len(requests))
+	for i, result := range results {
+		prompt := requests[i].Messages[0].Content.Text
+		if prompt == "invalid" {
+			require.True(t, IsErrorCode(result.Err, http.StatusBadRequest))
+			require.ErrorContains(t, err, fmt.Sprintf("chat completion %d", i))
+			continue
+		}
+		require.NoError(t, result.Err)
+		require.Equal(t, "echo "+prompt, result.Response.Choices[0].Message.Content.Text)
+	}
+	require.Equal(t, int32(2), flakyAttempts.Load())
+	require.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestCreateChatCompletionsBulkDrainsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		<-release
+		return jsonResponse(http.StatusOK, `{"id":"gen","choices":[{"message":{"role":"assistant","content":"ok"}}],`+
+			`"usage":{"total_tokens":10,"cost":0.25}}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var snapshots []BulkProgress
+	onProgress := func(progress BulkProgress) {
+		snapshots = append(snapshots, progress)
+		if progress.InFlight == 2 && progress.Completed == 0 {
+			cancel()
+			close(release)
+		}
+	}
+
+	requests := make([]ChatCompletionRequest, 5)
+	for i := range requests {
+		requests[i] = ChatCompletionRequest{Model: "openai/gpt-4o", Messages: []ChatCompletionMessage{UserMessage("hi")}}
+	}
+	results, err := client.CreateChatCompletionsBulk(ctx, requests, 2, BulkOnProgress(onProgress))
+
+	require.ErrorIs(t, err, context.Canceled)
+	succeeded := 0
+	for _, result := range results {
+		if result.Err == nil {
+			succeeded++
+			require.Equal(t, "ok", result.Response.Choices[0].Message.Content.Text)
+		} else {
+			require.ErrorIs(t, result.Err, context.Canceled)
+		}
+	}
+	require.Equal(t, 2, succeeded)
+	require.Equal(t, BulkProgress{Total: 5, Completed: 2, Failed: 3, Cost: 0.5}, snapshots[len(snapshots)-1])
+}
+
+func TestCreateChatCompletionsBulkKeepsDrainedFailure(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var attempts atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		attempts.Add(1)
+		<-release
+		return jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"provider down"}}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	onProgress := func(progress BulkProgress) {
+		if progress.InFlight == 1 && progress.Completed == 0 && progress.Failed == 0 {
+			cancel()
+			close(release)
+		}
+	}
+
+	results, err := client.CreateChatCompletionsBulk(ctx, []ChatCompletionRequest{{Model: "openai/gpt-4o"}}, 1,
+		BulkOnProgress(onProgress), BulkRetries(3, nil))
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, results[0].Err, context.Canceled)
+	require.True(t, IsErrorCode(results[0].Err, http.StatusBadGateway), "the drained failure must be kept: %v", results[0].Err)
+	require.Equal(t, int32(1), attempts.Load(), "no retry once ctx is done")
+}
+
+func TestCreateChatCompletionsBulkDoesNotResendProcessedRequests(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		attempts.Add(1)
+		// The completion was generated, and paid for, but its body broke off.
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(io.MultiReader(strings.NewReader(`{"id":"gen-1","choices":[`), failingReader{err: io.ErrUnexpectedEOF})),
+		}
+	})
+	results, err := client.CreateChatCompletionsBulk(context.Background(), []ChatCompletionRequest{{Model: "openai/gpt-4o"}}, 1,
+		BulkRetries(3, ExponentialBackoff(time.Millisecond, time.Millisecond)))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Error(t, results[0].Err)
+	require.Equal(t, int32(1), attempts.Load(), "a POST answered with 200 must not be sent again")
+
+	client.config.QuotaManager = NewQuotaManager(QuotaLimits{DailyTokens: 10}, nil)
+	alice := ContextWithCaller(context.Background(), "alice")
+	require.NoError(t, client.config.QuotaManager.Record(alice, "alice", QuotaUsage{Tokens: 100}))
+	started := time.Now()
+	results, err = client.CreateChatCompletionsBulk(alice, []ChatCompletionRequest{{Model: "openai/gpt-4o"}}, 1,
+		BulkRetries(3, ExponentialBackoff(time.Hour, time.Hour)))
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	require.ErrorIs(t, results[0].Err, ErrQuotaExceeded)
+	require.Less(t, time.Since(started), time.Second, "a quota failure must fail at once")
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+// hangingDoer answers no request, failing each when its context is done.
+type hangingDoer struct{}
+
+func (hangingDoer) Do(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestCreateChatCompletionsBulkAbortsHungRequestsAfterDrain(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig("test-token")
+	config.HTTPClient = hangingDoer{}
+	client := NewClientWithConfig(*config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	results, err := client.CreateChatCompletionsBulk(ctx, []ChatCompletionRequest{{Model: "openai/gpt-4o"}}, 1,
+		BulkDrainTimeout(30*time.Millisecond), BulkRetries(1, nil))
+
+	require.Error(t, err)
+	require.ErrorIs(t, results[0].Err, context.Canceled)
+	require.Less(t, time.Since(started), 2*time.Second, "the hung request is aborted once the drain timeout elapses")
+}
+
+func TestDrainContext(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	drained, release := drainContext(ctx, 20*time.Millisecond)
+	defer release()
+
+	require.Equal(t, "value", drained.Value(key{}))
+	cancel()
+	require.NoError(t, drained.Err(), "requests in flight may finish")
+	select {
+	case <-drained.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("the drain context was not cancelled after the drain timeout")
+	}
+}
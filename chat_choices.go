@@ -0,0 +1,99 @@
+package openrouter
+
+import (
+	"iter"
+	"math"
+	"sort"
+)
+
+// OrderedChoices yields the choices of a response requested with N > 1 in
+// ascending order of their Index, which providers do not always preserve.
+func (r ChatCompletionResponse) OrderedChoices() iter.Seq2[int, ChatCompletionChoice] {
+	choices := make([]ChatCompletionChoice, len(r.Choices))
+	copy(choices, r.Choices)
+	sort.SliceStable(choices, func(i, j int) bool {
+		return choices[i].Index < choices[j].Index
+	})
+
+	return func(yield func(int, ChatCompletionChoice) bool) {
+		for _, choice := range choices {
+			if !yield(choice.Index, choice) {
+				return
+			}
+		}
+	}
+}
+
+// ChoiceAt returns the choice whose Index is index.
+func (r ChatCompletionResponse) ChoiceAt(index int) (ChatCompletionChoice, bool) {
+	for _, choice := range r.Choices {
+		if choice.Index == index {
+			return choice, true
+		}
+	}
+	return ChatCompletionChoice{}, false
+}
+
+// ChoicesWithFinishReason returns the choices that finished for one of reasons,
+// e.g. FinishReasonStop to drop truncated or aborted generations.
+func (r ChatCompletionResponse) ChoicesWithFinishReason(reasons ...FinishReason) []ChatCompletionChoice {
+	var choices []ChatCompletionChoice
+	for _, choice := range r.Choices {
+		for _, reason := range reasons {
+			if choice.FinishReason == reason {
+				choices = append(choices, choice)
+				break
+			}
+		}
+	}
+	return choices
+}
+
+// MostLikelyChoice returns the choice with the highest mean log probability
+// per token, as CompletionResponse.BestText does, so longer answers are not
+// penalized for their length. It requires the request to set LogProbs;
+// choices without log probabilities are ignored, and false is returned if
+// none have them.
+func (r ChatCompletionResponse) MostLikelyChoice() (ChatCompletionChoice, bool) {
+	var (
+		best     ChatCompletionChoice
+		bestProb = math.Inf(-1)
+		found    bool
+	)
+	for _, choice := range r.Choices {
+		logProb, ok := choice.MeanLogProb()
+		if !ok {
+			continue
+		}
+		if !found || logProb > bestProb {
+			best, bestProb, found = choice, logProb, true
+		}
+	}
+	return best, found
+}
+
+// TotalLogProb returns the sum of the log probabilities of the choice's content
+// tokens, i.e. the log probability of the whole message. It reports false when
+// the choice carries no log probabilities.
+func (c ChatCompletionChoice) TotalLogProb() (float64, bool) {
+	if c.LogProbs == nil || len(c.LogProbs.Content) == 0 {
+		return 0, false
+	}
+
+	var total float64
+	for _, token := range c.LogProbs.Content {
+		total += token.LogProb
+	}
+	return total, true
+}
+
+// MeanLogProb returns the mean log probability per token of the choice's
+// content, which compares messages of different lengths fairly. It reports
+// false when the choice carries no log probabilities.
+func (c ChatCompletionChoice) MeanLogProb() (float64, bool) {
+	total, ok := c.TotalLogProb()
+	if !ok {
+		return math.Inf(-1), false
+	}
+	return total / float64(len(c.LogProbs.Content)), true
+}
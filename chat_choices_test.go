@@ -0,0 +1,119 @@
+package openrouter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalMultiChoiceResponse(t *testing.T) {
+	var resp openrouter.ChatCompletionResponse
+	err := json.Unmarshal([]byte(exampleMultiChoiceResponse), &resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 3)
+
+	var (
+		indexes  []int
+		contents []string
+	)
+	for index, choice := range resp.OrderedChoices() {
+		indexes = append(indexes, index)
+		contents = append(contents, choice.Message.Content.Text)
+	}
+	require.Equal(t, []int{0, 1, 2}, indexes)
+	require.Equal(t, []string{"Paris.", "The capital of France is Paris.", "Paris, of"}, contents)
+
+	choice, ok := resp.ChoiceAt(1)
+	require.True(t, ok)
+	require.Equal(t, "The capital of France is Paris.", choice.Message.Content.Text)
+	_, ok = resp.ChoiceAt(3)
+	require.False(t, ok)
+
+	stopped := resp.ChoicesWithFinishReason(openrouter.FinishReasonStop)
+	require.Len(t, stopped, 2)
+	truncated := resp.ChoicesWithFinishReason(openrouter.FinishReasonLength, openrouter.FinishReasonError)
+	require.Len(t, truncated, 1)
+	require.Equal(t, 2, truncated[0].Index)
+
+	best, ok := resp.MostLikelyChoice()
+	require.True(t, ok)
+	require.Equal(t, 0, best.Index)
+	logProb, ok := best.TotalLogProb()
+	require.True(t, ok)
+	require.InDelta(t, -0.15, logProb, 1e-9)
+}
+
+func TestMostLikelyChoiceRanksByMeanLogProb(t *testing.T) {
+	logProbs := func(values ...float64) *openrouter.LogProbs {
+		content := make([]openrouter.LogProb, len(values))
+		for i, value := range values {
+			content[i] = openrouter.LogProb{LogProb: value}
+		}
+		return &openrouter.LogProbs{Content: content}
+	}
+	resp := openrouter.ChatCompletionResponse{Choices: []openrouter.ChatCompletionChoice{
+		{Index: 0, LogProbs: logProbs(-0.9)},
+		{Index: 1, LogProbs: logProbs(-0.3, -0.3, -0.3, -0.3)},
+	}}
+
+	best, ok := resp.MostLikelyChoice()
+	require.True(t, ok)
+	require.Equal(t, 1, best.Index, "the longer answer is more likely per token")
+	mean, ok := best.MeanLogProb()
+	require.True(t, ok)
+	require.InDelta(t, -0.3, mean, 1e-9)
+}
+
+func TestMostLikelyChoiceWithoutLogProbs(t *testing.T) {
+	resp := openrouter.ChatCompletionResponse{Choices: []openrouter.ChatCompletionChoice{
+		{Index: 0}, {Index: 1},
+	}}
+
+	_, ok := resp.MostLikelyChoice()
+	require.False(t, ok)
+}
+
+// exampleMultiChoiceResponse is a response to a request with n=3 and logprobs,
+// with the choices out of order as some providers return them.
+const exampleMultiChoiceResponse = `{
+    "id": "gen-1777603779-00000000000",
+    "object": "chat.completion",
+    "created": 1777603779,
+    "model": "openai/gpt-4o-mini",
+    "provider": "OpenAI",
+    "choices": [
+        {
+            "index": 1,
+            "finish_reason": "stop",
+            "native_finish_reason": "stop",
+            "message": {"role": "assistant", "content": "The capital of France is Paris."},
+            "logprobs": {"content": [
+                {"token": "The", "logprob": -0.5, "top_logprobs": []},
+                {"token": " capital", "logprob": -0.25, "top_logprobs": []}
+            ]}
+        },
+        {
+            "index": 2,
+            "finish_reason": "length",
+            "native_finish_reason": "length",
+            "message": {"role": "assistant", "content": "Paris, of"},
+            "logprobs": {"content": [
+                {"token": "Paris", "logprob": -0.1, "top_logprobs": []},
+                {"token": ",", "logprob": -1.5, "top_logprobs": []}
+            ]}
+        },
+        {
+            "index": 0,
+            "finish_reason": "stop",
+            "native_finish_reason": "stop",
+            "message": {"role": "assistant", "content": "Paris."},
+            "logprobs": {"content": [
+                {"token": "Paris", "logprob": -0.1, "top_logprobs": []},
+                {"token": ".", "logprob": -0.05, "top_logprobs": []}
+            ]}
+        }
+    ],
+    "usage": {"prompt_tokens": 14, "completion_tokens": 15, "total_tokens": 29}
+}`
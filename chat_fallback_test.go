@@ -3,6 +3,7 @@ package openrouter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -245,3 +246,86 @@ func jsonResponse(status int, body string) *http.Response {
 		Header:     make(http.Header),
 	}
 }
+
+func TestCreateChatCompletionWithFallbacksRecordsAttempts(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusServiceUnavailable, `{"error":{"code":503,"message":"no provider"}}`),
+		jsonResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"rate limited"}}`),
+		jsonResponse(http.StatusOK, `{"id":"1","model":"c","choices":[{"message":{"role":"assistant","content":"ok"}}]}`),
+	)
+
+	result, err := client.CreateChatCompletionWithFallbacks(context.Background(), ChatCompletionRequest{
+		Model:    "a",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, ChatCompletionFallbackPolicy{Models: []string{"b", "c"}})
+	require.NoError(t, err)
+	require.Equal(t, "c", result.Model)
+	require.Equal(t, "ok", result.Response.Choices[0].Message.Content.Text)
+	require.Len(t, result.Attempts, 2)
+	require.Equal(t, "a", result.Attempts[0].Model)
+	require.True(t, IsHTTPStatus(result.Attempts[1].Err, http.StatusTooManyRequests))
+	require.Equal(t, []string{"a", "b", "c"}, []string{
+		httpClient.requests[0].Model, httpClient.requests[1].Model, httpClient.requests[2].Model,
+	})
+}
+
+func TestCreateChatCompletionWithFallbacksAggregatesErrors(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"provider down"}}`),
+		jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"invalid tools"}}`),
+	)
+
+	result, err := client.CreateChatCompletionWithFallbacks(context.Background(), ChatCompletionRequest{
+		Model:    "a",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, ChatCompletionFallbackPolicy{Models: []string{"b", "c"}})
+
+	var fallbackErr *FallbackError
+	require.ErrorAs(t, err, &fallbackErr)
+	require.Len(t, fallbackErr.Attempts, 2, "a non-retryable error stops the chain")
+	require.Len(t, httpClient.requests, 2)
+	require.Equal(t, fallbackErr.Attempts, result.Attempts)
+	require.True(t, IsHTTPStatus(err, http.StatusBadRequest), "the last error is seen first")
+	require.True(t, errors.Is(err, fallbackErr.Attempts[0].Err))
+	require.EqualError(t, err, "chat completion failed after 2 attempts; a: provider down; b: invalid tools")
+}
+
+func TestCreateChatCompletionWithFallbacksUsesPolicyErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"context too long"}}`),
+		jsonResponse(http.StatusServiceUnavailable, `{"error":{"code":503,"message":"no provider"}}`),
+	)
+
+	result, err := client.CreateChatCompletionWithFallbacks(context.Background(), ChatCompletionRequest{
+		Model:    "a",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, ChatCompletionFallbackPolicy{Models: []string{"b", "c"}, ErrorCodes: []int{http.StatusBadRequest}})
+
+	var fallbackErr *FallbackError
+	require.ErrorAs(t, err, &fallbackErr)
+	require.Len(t, result.Attempts, 2, "503 is not in the error codes of the policy")
+	require.Len(t, httpClient.requests, 2)
+	require.True(t, IsHTTPStatus(err, http.StatusServiceUnavailable))
+}
+
+func TestCreateChatCompletionStreamWithFallbackReportsAttempts(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t,
+		jsonResponse(http.StatusPaymentRequired, `{"error":{"code":402,"message":"insufficient funds"}}`),
+		jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"invalid tools"}}`),
+	)
+
+	_, err := client.CreateChatCompletionStreamWithFallback(context.Background(), ChatCompletionRequest{
+		Model:    "a",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, "b", "c")
+
+	require.EqualError(t, err, "chat completion failed after 2 attempts; a: insufficient credits: insufficient funds; b: invalid tools")
+}
@@ -0,0 +1,153 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func lengthTruncatedResponse(content string, finish FinishReason) *http.Response {
+	return jsonResponse(http.StatusOK, `{
+		"id": "gen",
+		"choices": [{"index": 0, "finish_reason": "`+string(finish)+`", "message": {"role": "assistant", "content": "`+content+`"}}],
+		"usage": {"prompt_tokens": 10, "completion_tokens": 4, "total_tokens": 14, "cost": 0.5}
+	}`)
+}
+
+func TestCreateChatCompletionFullContinuesAfterLength(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		lengthTruncatedResponse("Once upon", FinishReasonLength),
+		lengthTruncatedResponse(" a time", FinishReasonLength),
+		lengthTruncatedResponse(" there was.", FinishReasonStop),
+	)
+
+	resp, err := client.CreateChatCompletionFull(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []ChatCompletionMessage{UserMessage("Tell me a story")},
+	}, 5)
+	require.NoError(t, err)
+
+	require.Equal(t, "Once upon a time there was.", resp.Choices[0].Message.Content.Text)
+	require.Equal(t, FinishReasonStop, resp.Choices[0].FinishReason)
+	require.Equal(t, 42, resp.Usage.TotalTokens)
+	require.InDelta(t, 1.5, resp.Usage.Cost, 1e-9)
+
+	require.Len(t, httpClient.requests, 3)
+	require.Len(t, httpClient.requests[0].Messages, 1)
+	last := httpClient.requests[2].Messages
+	require.Len(t, last, 2)
+	require.Equal(t, ChatMessageRoleAssistant, last[1].Role)
+	require.Equal(t, "Once upon a time", last[1].Content.Text)
+}
+
+func TestCreateChatCompletionFullStopsAtBudget(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		lengthTruncatedResponse("Once upon", FinishReasonLength),
+		lengthTruncatedResponse(" a time", FinishReasonLength),
+	)
+
+	resp, err := client.CreateChatCompletionFull(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("Tell me a story")},
+	}, 1)
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 2)
+	require.Equal(t, "Once upon a time", resp.Choices[0].Message.Content.Text)
+	require.Equal(t, FinishReasonLength, resp.Choices[0].FinishReason)
+}
+
+func TestCreateChatCompletionFullKeepsContentWhenContinuationIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		lengthTruncatedResponse("Once upon", FinishReasonLength),
+		jsonResponse(http.StatusOK, `{"id":"gen","choices":[],"usage":{"total_tokens":10,"cost":0.25}}`),
+	)
+
+	resp, err := client.CreateChatCompletionFull(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("Tell me a story")},
+	}, 5)
+	require.NoError(t, err)
+
+	require.Len(t, httpClient.requests, 2)
+	require.Equal(t, "Once upon", resp.Choices[0].Message.Content.Text)
+	require.Equal(t, FinishReasonLength, resp.Choices[0].FinishReason)
+	require.Equal(t, 24, resp.Usage.TotalTokens)
+	require.InDelta(t, 0.75, resp.Usage.Cost, 1e-9)
+}
+
+func TestCreateChatCompletionFullSumsModalityUsage(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t,
+		jsonResponse(http.StatusOK, `{"id":"gen","choices":[{"finish_reason":"length","message":{"role":"assistant","content":"Once"}}],`+
+			`"usage":{"total_tokens":10,"is_byok":false,"completion_tokens_details":{"image_tokens":3},"prompt_tokens_details":{"audio_tokens":5}}}`),
+		jsonResponse(http.StatusOK, `{"id":"gen","choices":[{"finish_reason":"stop","message":{"role":"assistant","content":" upon"}}],`+
+			`"usage":{"total_tokens":10,"is_byok":true,"completion_tokens_details":{"image_tokens":4,"audio_tokens":1},"prompt_tokens_details":{"audio_tokens":6}}}`),
+	)
+
+	resp, err := client.CreateChatCompletionFull(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("Tell me a story.")},
+	}, 1)
+	require.NoError(t, err)
+	usage := resp.Usage
+	require.Equal(t, 20, usage.TotalTokens)
+	require.Equal(t, 7, *usage.CompletionTokenDetails.ImageTokens)
+	require.Equal(t, 1, *usage.CompletionTokenDetails.AudioTokens)
+	require.Equal(t, 11, *usage.PromptTokenDetails.AudioTokens)
+	require.Nil(t, usage.PromptTokenDetails.VideoTokens)
+	require.True(t, *usage.IsBYOK)
+}
+
+func TestAddUsageSharesNoPointers(t *testing.T) {
+	t.Parallel()
+
+	images, byok := 2, false
+	a := &Usage{IsBYOK: &byok, CompletionTokenDetails: CompletionTokenDetails{ImageTokens: &images}}
+	sum := addUsage(a, &Usage{})
+	require.Equal(t, 2, *sum.CompletionTokenDetails.ImageTokens)
+	require.False(t, *sum.IsBYOK)
+	require.NotSame(t, a.CompletionTokenDetails.ImageTokens, sum.CompletionTokenDetails.ImageTokens)
+	require.NotSame(t, a.IsBYOK, sum.IsBYOK)
+	require.Nil(t, addUsage(&Usage{}, &Usage{}).IsBYOK)
+}
+
+func TestMergedPrefill(t *testing.T) {
+	t.Parallel()
+
+	request := ChatCompletionRequest{
+		Model:    "anthropic/claude-3.5-sonnet",
+		Messages: []ChatCompletionMessage{UserMessage("Answer in JSON."), AssistantPrefill(`{"answer":`)},
+	}
+
+	client, _ := newSequenceClient(t, completionResponse(` 42}`))
+	resp, err := client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, ` 42}`, resp.Choices[0].Message.Content.Text)
+
+	client, _ = newSequenceClient(t, completionResponse(` 42}`), completionResponse(`{"answer": 7}}`))
+	client.config.MergePrefill = true
+	resp, err = client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, `{"answer": 42}`, resp.Choices[0].Message.Content.Text)
+	resp, err = client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, `{"answer":{"answer": 7}}`, resp.Choices[0].Message.Content.Text,
+		"a continuation starting with the prefill is merged too")
+
+	client, httpClient := newSequenceClient(t,
+		lengthTruncatedResponse(" 4", FinishReasonLength),
+		lengthTruncatedResponse("2}", FinishReasonStop),
+	)
+	client.config.MergePrefill = true
+	resp, err = client.CreateChatCompletionFull(context.Background(), request, 1)
+	require.NoError(t, err)
+	require.Equal(t, `{"answer": 42}`, resp.Choices[0].Message.Content.Text)
+	require.Equal(t, `{"answer": 4`, httpClient.requests[1].Messages[1].Content.Text)
+}
@@ -0,0 +1,272 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+// StreamHandlers holds the callbacks invoked by StreamChatCompletion.
+// Every callback is optional. Returning an error from a callback stops the
+// stream and the error is returned from StreamChatCompletion.
+type StreamHandlers struct {
+	// OnContent is called with every non-empty content delta.
+	OnContent func(content string) error
+	// OnReasoning is called with every non-empty reasoning delta.
+	OnReasoning func(reasoning string) error
+	// OnToolCall is called once per tool call, after its arguments have been fully streamed.
+	OnToolCall func(toolCall ToolCall) error
+	// OnUsage is called with the token usage when the stream reports it.
+	OnUsage func(usage Usage) error
+	// OnFinish is called when a choice reports its finish reason.
+	OnFinish func(reason FinishReason) error
+}
+
+// StreamChatCompletion streams a chat completion and dispatches every chunk to
+// handlers. It owns the stream lifecycle: the stream is always closed and
+// io.EOF is treated as a successful end of stream. A generation aborted by the
+// provider is returned as a *GenerationError.
+func (c *Client) StreamChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	handlers StreamHandlers,
+) error {
+	stream, err := c.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	toolCalls := newToolCallAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return toolCalls.flush(handlers.OnToolCall)
+		}
+		if err != nil {
+			return err
+		}
+
+		if chunk.Usage != nil && handlers.OnUsage != nil {
+			if err := handlers.OnUsage(*chunk.Usage); err != nil {
+				return err
+			}
+		}
+
+		for _, choice := range chunk.Choices {
+			if err := dispatchStreamDelta(choice.Delta, handlers); err != nil {
+				return err
+			}
+			toolCalls.add(choice.Index, choice.Delta.ToolCalls)
+
+			if choice.FinishReason == "" || choice.FinishReason == FinishReasonNull {
+				continue
+			}
+			if err := toolCalls.flushChoice(choice.Index, handlers.OnToolCall); err != nil {
+				return err
+			}
+			if handlers.OnFinish != nil {
+				if err := handlers.OnFinish(choice.FinishReason); err != nil {
+					return err
+				}
+			}
+		}
+		if err := chunk.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+func dispatchStreamDelta(delta ChatCompletionStreamChoiceDelta, handlers StreamHandlers) error {
+	if delta.Content != "" && handlers.OnContent != nil {
+		if err := handlers.OnContent(delta.Content); err != nil {
+			return err
+		}
+	}
+
+	reasoning := delta.ReasoningContent
+	if delta.Reasoning != nil {
+		reasoning = *delta.Reasoning
+	}
+	if reasoning != "" && handlers.OnReasoning != nil {
+		if err := handlers.OnReasoning(reasoning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toolCallAccumulator merges streamed tool call fragments by the index of
+// their choice and their own index, so the calls of different choices of a
+// request with n > 1 are kept apart.
+type toolCallAccumulator struct {
+	calls map[toolCallKey]*ToolCall
+}
+
+type toolCallKey struct {
+	choice, index int
+}
+
+func newToolCallAccumulator() *toolCallAccumulator {
+	return &toolCallAccumulator{calls: make(map[toolCallKey]*ToolCall)}
+}
+
+func (a *toolCallAccumulator) add(choice int, fragments []ToolCall) {
+	for i, fragment := range fragments {
+		key := toolCallKey{choice: choice, index: i}
+		if fragment.Index != nil {
+			key.index = *fragment.Index
+		}
+
+		call, ok := a.calls[key]
+		if !ok {
+			call = &ToolCall{Index: fragment.Index}
+			a.calls[key] = call
+		}
+		if fragment.ID != "" {
+			call.ID = fragment.ID
+		}
+		if fragment.Type != "" {
+			call.Type = fragment.Type
+		}
+		if fragment.Function.Name != "" {
+			call.Function.Name = fragment.Function.Name
+		}
+		call.Function.Arguments += fragment.Function.Arguments
+	}
+}
+
+// flushChoice passes the accumulated tool calls of choice to fn in index order
+// and removes them from the accumulator.
+func (a *toolCallAccumulator) flushChoice(choice int, fn func(ToolCall) error) error {
+	return a.flushKeys(fn, func(key toolCallKey) bool { return key.choice == choice })
+}
+
+// flush passes every accumulated tool call to fn in choice and index order and
+// resets the accumulator.
+func (a *toolCallAccumulator) flush(fn func(ToolCall) error) error {
+	return a.flushKeys(fn, func(toolCallKey) bool { return true })
+}
+
+func (a *toolCallAccumulator) flushKeys(fn func(ToolCall) error, match func(toolCallKey) bool) error {
+	var keys []toolCallKey
+	for key := range a.calls {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].choice != keys[j].choice {
+			return keys[i].choice < keys[j].choice
+		}
+		return keys[i].index < keys[j].index
+	})
+
+	calls := make([]ToolCall, len(keys))
+	for i, key := range keys {
+		calls[i] = *a.calls[key]
+		delete(a.calls, key)
+	}
+	if fn == nil {
+		return nil
+	}
+
+	for _, call := range calls {
+		if err := fn(call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TextReader returns an io.Reader that yields the content deltas of the stream
+// as bytes and returns io.EOF when the stream ends. Reading from it consumes
+// the stream, so it must not be combined with direct calls to Recv.
+func (s *ChatCompletionStream) TextReader() io.Reader {
+	return &streamTextReader{stream: s}
+}
+
+type streamTextReader struct {
+	stream  *ChatCompletionStream
+	pending []byte
+}
+
+func (r *streamTextReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		for _, choice := range chunk.Choices {
+			r.pending = append(r.pending, choice.Delta.Content...)
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Tee fans the stream out to n independent streams that each receive every
+// chunk, including the final error. Each returned stream must be closed by its
+// consumer; closing one detaches it without affecting the others, and the
+// source stream is closed once all of them are closed or it is exhausted.
+// Chunks are delivered to the branches in lockstep, so a consumer that stops
+// reading without closing its branch blocks the others. The source stream
+// must not be read directly after calling Tee.
+func (s *ChatCompletionStream) Tee(n int) []*ChatCompletionStream {
+	var open atomic.Int32
+	open.Store(int32(n))
+	release := func() {
+		if open.Add(-1) == 0 {
+			s.Close()
+		}
+	}
+
+	branches := make([]*sseStream[ChatCompletionStreamResponse], n)
+	streams := make([]*ChatCompletionStream, n)
+	for i := range branches {
+		branches[i] = &sseStream[ChatCompletionStreamResponse]{
+			stream: make(chan StreamEvent[ChatCompletionStreamResponse]),
+			done:   make(chan struct{}),
+			cancel: release,
+		}
+		streams[i] = &ChatCompletionStream{reader: branches[i]}
+	}
+
+	go func() {
+		defer s.Close()
+
+		live := make([]bool, n)
+		for i := range live {
+			live[i] = true
+		}
+		for {
+			event, err := s.RecvEvent()
+			if err != nil {
+				for _, branch := range branches {
+					if !errors.Is(err, io.EOF) {
+						branch.err = err
+					}
+					close(branch.stream)
+				}
+				return
+			}
+
+			for i, branch := range branches {
+				if !live[i] {
+					continue
+				}
+				select {
+				case branch.stream <- event:
+				case <-branch.done:
+					live[i] = false
+				}
+			}
+		}
+	}()
+
+	return streams
+}
@@ -0,0 +1,488 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSequenceClient(t *testing.T, responses ...*http.Response) (*Client, *sequenceHTTPClient) {
+	t.Helper()
+
+	httpClient := &sequenceHTTPClient{responses: responses}
+	cfg := DefaultConfig("test-token")
+	cfg.HTTPClient = httpClient
+	cfg.BaseURL = "https://example.com/api/v1"
+	return NewClientWithConfig(*cfg), httpClient
+}
+
+func sseBody(lines ...string) string {
+	return strings.Join(append(lines, ""), "\n")
+}
+
+func TestStreamChatCompletionDispatchesHandlers(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`: OPENROUTER PROCESSING`,
+		``,
+		`data: {"id":"1","choices":[{"delta":{"role":"assistant","reasoning":"thinking"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"Hel"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"lo"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":"}}]}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"id":"1","choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: {"id":"1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":5,"total_tokens":8}}`,
+		`data: [DONE]`,
+	)))
+
+	var (
+		content   strings.Builder
+		reasoning strings.Builder
+		toolCalls []ToolCall
+		usage     *Usage
+		finish    []FinishReason
+	)
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, StreamHandlers{
+		OnContent: func(s string) error {
+			content.WriteString(s)
+			return nil
+		},
+		OnReasoning: func(s string) error {
+			reasoning.WriteString(s)
+			return nil
+		},
+		OnToolCall: func(call ToolCall) error {
+			toolCalls = append(toolCalls, call)
+			return nil
+		},
+		OnUsage: func(u Usage) error {
+			usage = &u
+			return nil
+		},
+		OnFinish: func(reason FinishReason) error {
+			finish = append(finish, reason)
+			return nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.True(t, httpClient.requests[0].Stream)
+	require.Equal(t, "Hello", content.String())
+	require.Equal(t, "thinking", reasoning.String())
+	require.Len(t, toolCalls, 1)
+	require.Equal(t, "call_1", toolCalls[0].ID)
+	require.Equal(t, "get_weather", toolCalls[0].Function.Name)
+	require.Equal(t, `{"city":"Paris"}`, toolCalls[0].Function.Arguments)
+	require.Equal(t, []FinishReason{FinishReasonToolCalls}, finish)
+	require.NotNil(t, usage)
+	require.Equal(t, 8, usage.TotalTokens)
+}
+
+func TestStreamChatCompletionKeepsToolCallsOfChoicesApart(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":"{\"city\":"}}]}},{"index":1,"delta":{"tool_calls":[{"index":0,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{\"zone\":"}}]}}]}`,
+		`data: {"id":"1","choices":[{"index":1,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"UTC\"}"}}]}},{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"id":"1","choices":[{"index":1,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: {"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: [DONE]`,
+	)))
+
+	var toolCalls []ToolCall
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+		N:        2,
+	}, StreamHandlers{
+		OnToolCall: func(call ToolCall) error {
+			toolCalls = append(toolCalls, call)
+			return nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, toolCalls, 2)
+	require.Equal(t, "call_b", toolCalls[0].ID, "each choice is flushed when it finishes")
+	require.Equal(t, `{"zone":"UTC"}`, toolCalls[0].Function.Arguments)
+	require.Equal(t, "call_a", toolCalls[1].ID)
+	require.Equal(t, `{"city":"Paris"}`, toolCalls[1].Function.Arguments)
+}
+
+func TestStreamChatCompletionStopsOnHandlerError(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"a"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"b"}}]}`,
+		`data: [DONE]`,
+	)))
+
+	errStop := errors.New("stop")
+	var calls int
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, StreamHandlers{
+		OnContent: func(string) error {
+			calls++
+			return errStop
+		},
+	})
+
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, calls)
+}
+
+func TestStreamChatCompletionReturnsRequestError(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t,
+		jsonResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"rate limited"}}`),
+	)
+
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, StreamHandlers{})
+
+	require.True(t, IsHTTPStatus(err, http.StatusTooManyRequests))
+}
+
+func TestChatCompletionStreamUsage(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"hi"}}],"usage":null}`,
+		`data: {"id":"1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4,"cost":0.5}}`,
+		`data: [DONE]`,
+	)))
+	client.config.IncludeStreamUsage = true
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.NotNil(t, httpClient.requests[0].StreamOptions)
+	require.True(t, httpClient.requests[0].StreamOptions.IncludeUsage)
+
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	require.NotNil(t, stream.Usage())
+	require.Equal(t, 4, stream.Usage().TotalTokens)
+	require.InDelta(t, 0.5, stream.Usage().Cost, 1e-9)
+}
+
+func TestChatCompletionStreamIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	body, writer := io.Pipe()
+	defer writer.Close()
+	go func() {
+		_, _ = writer.Write([]byte(sseBody(`data: {"id":"1","choices":[{"delta":{"content":"hi"}}]}`)))
+	}()
+
+	client, _ := newSequenceClient(t, &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Header:     make(http.Header),
+	})
+	client.config.StreamIdleTimeout = 50 * time.Millisecond
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "hi", chunk.Choices[0].Delta.Content)
+
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, ErrStreamStalled)
+}
+
+func TestChatCompletionStreamIdleTimeoutIgnoresSlowConsumer(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"a"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"b"}}]}`,
+		`data: [DONE]`,
+	)))
+	client.config.StreamIdleTimeout = 20 * time.Millisecond
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content string
+	for {
+		// The consumer is slower than the idle timeout, the network is not.
+		time.Sleep(60 * time.Millisecond)
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content += chunk.Choices[0].Delta.Content
+	}
+	require.Equal(t, "ab", content)
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestChatCompletionStreamResumesAfterDisconnect(t *testing.T) {
+	t.Parallel()
+
+	dropped := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body: io.NopCloser(io.MultiReader(
+			strings.NewReader(sseBody(`data: {"id":"1","choices":[{"delta":{"content":"Hel"}}]}`)),
+			failingReader{err: io.ErrUnexpectedEOF},
+		)),
+	}
+	client, httpClient := newSequenceClient(t, dropped, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"2","choices":[{"delta":{"content":"lo"}}]}`,
+		`data: [DONE]`,
+	)))
+	client.config.StreamResumeAttempts = 1
+
+	var content strings.Builder
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, StreamHandlers{
+		OnContent: func(s string) error {
+			content.WriteString(s)
+			return nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "Hello", content.String())
+	require.Len(t, httpClient.requests, 2)
+	resumed := httpClient.requests[1].Messages
+	require.Len(t, resumed, 2)
+	require.Equal(t, ChatMessageRoleAssistant, resumed[1].Role)
+	require.Equal(t, "Hel", resumed[1].Content.Text)
+}
+
+// closeTracker records whether its body was closed.
+type closeTracker struct {
+	io.ReadCloser
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return c.ReadCloser.Close()
+}
+
+func TestChatCompletionStreamCloseAfterResumeReleasesResumedBody(t *testing.T) {
+	t.Parallel()
+
+	dropped := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body: io.NopCloser(io.MultiReader(
+			strings.NewReader(sseBody(`data: {"id":"1","choices":[{"delta":{"content":"Hel"}}]}`)),
+			failingReader{err: io.ErrUnexpectedEOF},
+		)),
+	}
+	body, writer := io.Pipe()
+	defer writer.Close()
+	go func() {
+		_, _ = writer.Write([]byte(sseBody(`data: {"id":"2","choices":[{"delta":{"content":"lo"}}]}`)))
+	}()
+	resumedBody := &closeTracker{ReadCloser: body}
+	client, _ := newSequenceClient(t, dropped, &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       resumedBody,
+	})
+	client.config.StreamResumeAttempts = 1
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+
+	for _, want := range []string{"Hel", "lo"} {
+		chunk, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, want, chunk.Choices[0].Delta.Content)
+	}
+
+	stream.Close()
+	require.True(t, resumedBody.closed.Load())
+}
+
+func TestChatCompletionStreamReportsErrorWhenResumeExhausted(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(failingReader{err: io.ErrUnexpectedEOF}),
+	}, jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"bad gateway"}}`))
+	client.config.StreamResumeAttempts = 1
+
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, StreamHandlers{})
+
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestContinuationRequestExtendsExistingPrefill(t *testing.T) {
+	t.Parallel()
+
+	request := ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello"), AssistantMessage("{")},
+	}
+	next := continuationRequest(request, `"a":1`)
+
+	require.Len(t, next.Messages, 2)
+	require.Equal(t, `{"a":1`, next.Messages[1].Content.Text)
+	require.Equal(t, "{", request.Messages[1].Content.Text)
+}
+
+func TestChatCompletionStreamTextReader(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"role":"assistant"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"Hello, "}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"world"}}]}`,
+		`data: [DONE]`,
+	)))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	text, err := io.ReadAll(stream.TextReader())
+	require.NoError(t, err)
+	require.Equal(t, "Hello, world", string(text))
+}
+
+func TestChatCompletionStreamTee(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"a"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"b"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"c"}}]}`,
+		`data: [DONE]`,
+	)))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+
+	branches := stream.Tee(2)
+	require.Len(t, branches, 2)
+
+	// The first consumer gives up after one chunk.
+	chunk, err := branches[0].Recv()
+	require.NoError(t, err)
+	require.Equal(t, "a", chunk.Choices[0].Delta.Content)
+	branches[0].Close()
+
+	text, err := io.ReadAll(branches[1].TextReader())
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(text))
+	branches[1].Close()
+}
+
+func TestChatCompletionStreamDecodesAnnotationsAndCitations(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","citations":["https://example.com/a"],"choices":[{"delta":{"content":"See [a]","annotations":[{"type":"url_citation","url_citation":{"url":"https://example.com/a","title":"A","start_index":4,"end_index":7}}]}}]}`,
+		`data: [DONE]`,
+	)))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+		Plugins:  []ChatCompletionPlugin{{ID: PluginIDWeb}},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://example.com/a"}, chunk.Citations)
+
+	annotations := chunk.Choices[0].Delta.Annotations
+	require.Len(t, annotations, 1)
+	require.Equal(t, AnnotationTypeUrlCitation, annotations[0].Type)
+	require.Equal(t, "https://example.com/a", annotations[0].URLCitation.URL)
+	require.Equal(t, "A", annotations[0].URLCitation.Title)
+	require.Equal(t, 4, annotations[0].URLCitation.StartIndex)
+	require.Equal(t, 7, annotations[0].URLCitation.EndIndex)
+}
+
+func TestStreamChatCompletionReturnsGenerationError(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"Hel"}}]}`,
+		`data: {"id":"1","error":{"code":"server_error","message":"Provider disconnected"},"choices":[{"index":0,"delta":{"content":""},"finish_reason":"error"}]}`,
+		`data: [DONE]`,
+	)))
+
+	var (
+		content strings.Builder
+		finish  []FinishReason
+	)
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	}, StreamHandlers{
+		OnContent: func(s string) error {
+			content.WriteString(s)
+			return nil
+		},
+		OnFinish: func(reason FinishReason) error {
+			finish = append(finish, reason)
+			return nil
+		},
+	})
+
+	var genErr *GenerationError
+	require.ErrorAs(t, err, &genErr)
+	require.Equal(t, "server_error", genErr.Err.Code)
+	require.Equal(t, "Provider disconnected", genErr.Err.Message)
+	require.Equal(t, "Hel", content.String())
+	require.Equal(t, []FinishReason{FinishReasonError}, finish)
+}
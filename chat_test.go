@@ -1,10 +1,15 @@
 package openrouter_test
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
 )
 
 // ChatCompletionMessage json.Marshal tests
@@ -122,3 +127,81 @@ func TestChatCompletionMessagePromptCachingApplies(t *testing.T) {
 	expected := `{"role":"user","content":[{"text":"This is a simple content","cache_control":{"type":"ephemeral"}}]}`
 	marshalAndValidate(t, message, expected)
 }
+
+func TestUnmarshalContentKeepsUnrecognizedShapes(t *testing.T) {
+	var message openrouter.ChatCompletionMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":{"text":"Hi"}}`), &message))
+	require.Empty(t, message.Content.Text)
+	require.Empty(t, message.Content.Multi)
+	require.JSONEq(t, `{"text":"Hi"}`, string(message.Content.Raw))
+
+	result, err := json.Marshal(message)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"role":"assistant","content":{"text":"Hi"}}`, string(result))
+
+	for _, content := range []string{`null`, `""`, `[]`} {
+		message = openrouter.ChatCompletionMessage{}
+		require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":`+content+`}`), &message))
+		require.Nil(t, message.Content.Raw, content)
+	}
+}
+
+func TestStrictModeRejectsUnrecognizedContent(t *testing.T) {
+	body := `{"id":"gen","choices":[{"index":0,"message":{"role":"assistant","content":42}}]}`
+	newClient := func(opts ...openrouter.Option) *openrouter.Client {
+		config := openrouter.DefaultConfig("test-token")
+		config.HTTPClient = responseDoer(func() *http.Response {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+		})
+		for _, opt := range opts {
+			opt(config)
+		}
+		return openrouter.NewClientWithConfig(*config)
+	}
+	request := openrouter.ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Hello")},
+	}
+
+	resp, err := newClient().CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, json.RawMessage(`42`), resp.Choices[0].Message.Content.Raw)
+
+	_, err = newClient(openrouter.WithStrictMode()).CreateChatCompletion(context.Background(), request)
+	require.ErrorIs(t, err, openrouter.ErrUnrecognizedContent)
+	require.EqualError(t, err, "choice 0: unrecognized message content: 42")
+}
+
+func TestEmptyContentRoundTrips(t *testing.T) {
+	for _, input := range []string{
+		`{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"f"}}]}`,
+		`{"role":"assistant","content":""}`,
+		`{"role":"assistant","content":[]}`,
+		`{"role":"assistant"}`,
+	} {
+		var message openrouter.ChatCompletionMessage
+		require.NoError(t, json.Unmarshal([]byte(input), &message))
+		require.Empty(t, message.Content.Text)
+		require.Empty(t, message.Content.Multi)
+
+		result, err := json.Marshal(message)
+		require.NoError(t, err)
+		require.JSONEq(t, input, string(result))
+	}
+
+	result, err := json.Marshal(openrouter.ChatCompletionMessage{Role: openrouter.ChatMessageRoleAssistant})
+	require.NoError(t, err)
+	require.Equal(t, `{"role":"assistant"}`, string(result))
+}
+
+func TestMarshalContentWithTextAndMulti(t *testing.T) {
+	message := openrouter.ChatCompletionMessage{
+		Role: openrouter.ChatMessageRoleUser,
+		Content: openrouter.Content{
+			Text:  "Describe this image.",
+			Multi: []openrouter.ChatMessagePart{{Type: openrouter.ChatMessagePartTypeText, Text: "Describe this image."}},
+		},
+	}
+	_, err := json.Marshal(message)
+	require.ErrorIs(t, err, openrouter.ErrContentFieldsMisused)
+}
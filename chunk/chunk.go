@@ -0,0 +1,373 @@
+// Package chunk splits documents into chunks of a bounded number of tokens,
+// for embedding them or retrieving them into prompts.
+//
+// A Chunker splits text at the largest boundaries that keep chunks under its
+// MaxTokens: markdown sections, then paragraphs, then sentences, then words.
+// Token counts come from a tokencount.Tokenizer, so chunks fit the context of
+// the embedding model they are meant for.
+package chunk
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/revrost/go-openrouter/tokencount"
+)
+
+// DefaultMaxTokens is the chunk size of a Chunker without MaxTokens.
+const DefaultMaxTokens = 512
+
+// Chunk is a piece of a document.
+type Chunk struct {
+	Text string
+	// Start and End are the byte offsets of Text in the document.
+	Start, End int
+	Tokens     int
+	// Headings are the titles of the markdown sections enclosing the chunk,
+	// outermost first. Only set by ByMarkdownHeadings.
+	Headings []string
+}
+
+// Chunker splits text into chunks. The zero value splits into chunks of up to
+// DefaultMaxTokens heuristic tokens without overlap.
+type Chunker struct {
+	// Tokenizer counts tokens. Defaults to tokencount.Heuristic.
+	Tokenizer tokencount.Tokenizer
+	// MaxTokens is the most tokens of a chunk. A single word longer than that
+	// is a chunk of its own. Defaults to DefaultMaxTokens.
+	MaxTokens int
+	// Overlap is the most tokens of text repeated from the end of a chunk at
+	// the start of the next, so context cut at a boundary is kept in both.
+	// Overlap consists of whole units: words for ByTokens, sentences for
+	// BySentences and so on. It is capped at half of MaxTokens.
+	Overlap int
+}
+
+// ByTokens splits text between words.
+func (c Chunker) ByTokens(text string) []Chunk {
+	return c.chunks(text, span{0, len(text)}, nil, words)
+}
+
+// BySentences splits text between sentences, and between the words of
+// sentences longer than MaxTokens. Sentences never span paragraphs.
+func (c Chunker) BySentences(text string) []Chunk {
+	return c.chunks(text, span{0, len(text)}, nil, paragraphSentences, words)
+}
+
+// ByParagraphs splits text between paragraphs, which are separated by blank
+// lines, and between the sentences and then words of longer paragraphs.
+func (c Chunker) ByParagraphs(text string) []Chunk {
+	return c.chunks(text, span{0, len(text)}, nil, paragraphs, sentences, words)
+}
+
+// ByMarkdownHeadings splits markdown between sections, starting a chunk at
+// every ATX heading ("# Title") outside code blocks, and splits sections longer
+// than MaxTokens like ByParagraphs. Chunks hold the headings enclosing them.
+func (c Chunker) ByMarkdownHeadings(text string) []Chunk {
+	var chunks []Chunk
+	for _, section := range markdownSections(text) {
+		chunks = append(chunks, c.chunks(text, section.span, section.headings, paragraphs, sentences, words)...)
+	}
+	return chunks
+}
+
+// Texts returns the text of every chunk, e.g. for CreateEmbeddingsBatched.
+func Texts(chunks []Chunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	return texts
+}
+
+// span is a range of byte offsets of the document.
+type span struct {
+	start, end int
+}
+
+// splitter splits a span of text into the non-empty spans of its units,
+// without surrounding whitespace.
+type splitter func(text string, s span) []span
+
+func (c Chunker) tokenizer() tokencount.Tokenizer {
+	if c.Tokenizer == nil {
+		return tokencount.Heuristic{}
+	}
+	return c.Tokenizer
+}
+
+func (c Chunker) maxTokens() int {
+	if c.MaxTokens <= 0 {
+		return DefaultMaxTokens
+	}
+	return c.MaxTokens
+}
+
+// chunks splits s into units with the first splitter, splits units longer than
+// MaxTokens with the next ones, and packs the units into chunks.
+func (c Chunker) chunks(text string, s span, headings []string, splitters ...splitter) []Chunk {
+	tokenizer, maxTokens := c.tokenizer(), c.maxTokens()
+	overlap := min(c.Overlap, maxTokens/2)
+
+	units := c.units(text, s, splitters)
+	if len(units) == 0 {
+		return nil
+	}
+
+	// own[i] is the number of tokens of unit i alone, and joined[i] that of the
+	// unit with the whitespace before it, as counted when it follows another.
+	own := make([]int, len(units))
+	joined := make([]int, len(units))
+	for i, u := range units {
+		own[i] = tokenizer.Count(text[u.start:u.end])
+		joined[i] = own[i]
+		if i > 0 {
+			joined[i] = tokenizer.Count(text[units[i-1].end:u.end])
+		}
+	}
+
+	var chunks []Chunk
+	for first := 0; ; {
+		last, tokens := first, own[first]
+		for last+1 < len(units) && tokens+joined[last+1] <= maxTokens {
+			last++
+			tokens += joined[last]
+		}
+
+		chunkText := text[units[first].start:units[last].end]
+		chunks = append(chunks, Chunk{
+			Text:     chunkText,
+			Start:    units[first].start,
+			End:      units[last].end,
+			Tokens:   tokenizer.Count(chunkText),
+			Headings: headings,
+		})
+		next := last + 1
+		if next == len(units) {
+			break
+		}
+
+		// Start the next chunk with the trailing units that fit the overlap and
+		// leave room for the next unit, so every chunk adds at least one unit.
+		// suffix counts the tokens of the units after k.
+		previous := first
+		first = next
+		for k, suffix := last, 0; k > previous; k-- {
+			if own[k]+suffix > overlap || own[k]+suffix+joined[next] > maxTokens {
+				break
+			}
+			first = k
+			suffix += joined[k]
+		}
+	}
+	return chunks
+}
+
+// units splits s with the first splitter and units longer than MaxTokens with
+// the next ones.
+func (c Chunker) units(text string, s span, splitters []splitter) []span {
+	var units []span
+	for _, u := range splitters[0](text, s) {
+		if len(splitters) > 1 && c.tokenizer().Count(text[u.start:u.end]) > c.maxTokens() {
+			units = append(units, c.units(text, u, splitters[1:])...)
+			continue
+		}
+		units = append(units, u)
+	}
+	return units
+}
+
+// trim returns s without leading and trailing whitespace.
+func trim(text string, s span) span {
+	for s.start < s.end {
+		r, size := utf8.DecodeRuneInString(text[s.start:s.end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		s.start += size
+	}
+	for s.end > s.start {
+		r, size := utf8.DecodeLastRuneInString(text[s.start:s.end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		s.end -= size
+	}
+	return s
+}
+
+// appendTrimmed appends s to spans without surrounding whitespace, unless it is
+// blank.
+func appendTrimmed(spans []span, text string, s span) []span {
+	if s = trim(text, s); s.start < s.end {
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+func words(text string, s span) []span {
+	var spans []span
+	start := -1
+	for i, r := range text[s.start:s.end] {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			spans = append(spans, span{start, s.start + i})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = s.start + i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, s.end})
+	}
+	return spans
+}
+
+// sentences splits s after the terminal punctuation of sentences, and any
+// closing quotes and brackets after it, when followed by whitespace.
+func sentences(text string, s span) []span {
+	var spans []span
+	start := s.start
+	for i := s.start; i < s.end; {
+		r, size := utf8.DecodeRuneInString(text[i:s.end])
+		i += size
+		if !strings.ContainsRune(".!?", r) {
+			continue
+		}
+		for i < s.end {
+			r, size := utf8.DecodeRuneInString(text[i:s.end])
+			if !strings.ContainsRune(".!?\"')]”’", r) {
+				break
+			}
+			i += size
+		}
+		if r, _ := utf8.DecodeRuneInString(text[i:s.end]); i == s.end || unicode.IsSpace(r) {
+			spans = appendTrimmed(spans, text, span{start, i})
+			start = i
+		}
+	}
+	return appendTrimmed(spans, text, span{start, s.end})
+}
+
+// paragraphs splits s at blank lines.
+func paragraphs(text string, s span) []span {
+	var spans []span
+	start := s.start
+	for _, line := range lines(text, s) {
+		if strings.TrimSpace(text[line.start:line.end]) == "" {
+			spans = appendTrimmed(spans, text, span{start, line.start})
+			start = line.end
+		}
+	}
+	return appendTrimmed(spans, text, span{start, s.end})
+}
+
+func paragraphSentences(text string, s span) []span {
+	var spans []span
+	for _, paragraph := range paragraphs(text, s) {
+		spans = append(spans, sentences(text, paragraph)...)
+	}
+	return spans
+}
+
+// lines splits s into lines, including their line feed.
+func lines(text string, s span) []span {
+	var spans []span
+	for start := s.start; start < s.end; {
+		end := s.end
+		if i := strings.IndexByte(text[start:s.end], '\n'); i >= 0 {
+			end = start + i + 1
+		}
+		spans = append(spans, span{start, end})
+		start = end
+	}
+	return spans
+}
+
+type markdownSection struct {
+	span
+	headings []string
+}
+
+// markdownSections splits text into sections starting at ATX headings outside
+// fenced code blocks. Text before the first heading is a section without
+// headings.
+func markdownSections(text string) []markdownSection {
+	var sections []markdownSection
+	var headings []string
+	var levels []int
+	current := markdownSection{}
+	fence := ""
+	for _, line := range lines(text, span{0, len(text)}) {
+		content := strings.TrimRight(text[line.start:line.end], "\r\n")
+		trimmed := strings.TrimLeft(content, " ")
+		if len(content)-len(trimmed) > 3 {
+			continue
+		}
+
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(marker, fence) && strings.TrimSpace(trimmed[len(marker):]) == "":
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		level, title, ok := atxHeading(trimmed)
+		if !ok {
+			continue
+		}
+		current.end = line.start
+		sections = appendSection(sections, text, current)
+
+		for len(levels) > 0 && levels[len(levels)-1] >= level {
+			levels, headings = levels[:len(levels)-1], headings[:len(headings)-1]
+		}
+		levels, headings = append(levels, level), append(headings, title)
+		current = markdownSection{span: span{start: line.start}, headings: append([]string(nil), headings...)}
+	}
+	current.end = len(text)
+	return appendSection(sections, text, current)
+}
+
+func appendSection(sections []markdownSection, text string, section markdownSection) []markdownSection {
+	if strings.TrimSpace(text[section.start:section.end]) == "" {
+		return sections
+	}
+	return append(sections, section)
+}
+
+// fenceMarker returns the ``` or ~~~ run opening line, or "".
+func fenceMarker(line string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// atxHeading parses a "## Title ##" heading line.
+func atxHeading(line string) (level int, title string, ok bool) {
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, "", false
+	}
+	title = strings.TrimSpace(line[level:])
+	if closed := strings.TrimRight(title, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+		title = strings.TrimSpace(closed)
+	}
+	return level, title, true
+}
@@ -0,0 +1,122 @@
+package chunk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/revrost/go-openrouter/chunk"
+	"github.com/revrost/go-openrouter/tokencount"
+	"github.com/stretchr/testify/require"
+)
+
+// wordTokenizer counts every word as a token.
+type wordTokenizer struct{}
+
+func (wordTokenizer) Encode(text string) []int { return make([]int, len(strings.Fields(text))) }
+func (wordTokenizer) Count(text string) int    { return len(strings.Fields(text)) }
+
+func TestByTokens(t *testing.T) {
+	t.Parallel()
+
+	text := "one two three four five six seven"
+	chunks := chunk.Chunker{Tokenizer: wordTokenizer{}, MaxTokens: 3}.ByTokens(text)
+	require.Equal(t, []string{"one two three", "four five six", "seven"}, chunk.Texts(chunks))
+	for _, c := range chunks {
+		require.Equal(t, c.Text, text[c.Start:c.End])
+		require.LessOrEqual(t, c.Tokens, 3)
+	}
+
+	overlapping := chunk.Chunker{Tokenizer: wordTokenizer{}, MaxTokens: 4, Overlap: 1}.ByTokens(text)
+	require.Equal(t, []string{"one two three four", "four five six seven"}, chunk.Texts(overlapping))
+
+	require.Empty(t, chunk.Chunker{}.ByTokens(" \n\t "))
+}
+
+func TestBySentences(t *testing.T) {
+	t.Parallel()
+
+	text := "First one here. Second (really!) one? \"Third.\" Fourth\n\nFifth is in another paragraph."
+	chunker := chunk.Chunker{Tokenizer: wordTokenizer{}, MaxTokens: 6}
+	require.Equal(t, []string{
+		"First one here. Second (really!) one?",
+		"\"Third.\" Fourth",
+		"Fifth is in another paragraph.",
+	}, chunk.Texts(chunker.BySentences(text)))
+
+	chunker.MaxTokens = 2
+	require.Equal(t, []string{"First one", "here.", "Second (really!)", "one?"},
+		chunk.Texts(chunker.BySentences("First one here. Second (really!) one?")),
+		"long sentences are split between words")
+
+	chunker = chunk.Chunker{Tokenizer: wordTokenizer{}, MaxTokens: 5, Overlap: 2}
+	require.Equal(t, []string{"A b. C d.", "C d. E f. G."},
+		chunk.Texts(chunker.BySentences("A b. C d. E f. G.")), "overlap consists of whole sentences")
+}
+
+func TestByParagraphs(t *testing.T) {
+	t.Parallel()
+
+	text := "Alpha beta.\nGamma.\n\n  \nDelta epsilon zeta.\n\nEta theta. Iota kappa lambda mu."
+	chunks := chunk.Chunker{Tokenizer: wordTokenizer{}, MaxTokens: 4}.ByParagraphs(text)
+	require.Equal(t, []string{
+		"Alpha beta.\nGamma.",
+		"Delta epsilon zeta.",
+		"Eta theta.",
+		"Iota kappa lambda mu.",
+	}, chunk.Texts(chunks))
+	require.Equal(t, 3, chunks[0].Tokens)
+}
+
+func TestByMarkdownHeadings(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Join([]string{
+		"Intro text.",
+		"# Install",
+		"Download it.",
+		"## Linux ##",
+		"Use the package.",
+		"```sh",
+		"# not a heading",
+		"```",
+		"### Arch",
+		"## macOS",
+		"Use brew.",
+		"# Usage",
+		"Run it.",
+	}, "\n")
+
+	chunks := chunk.Chunker{}.ByMarkdownHeadings(text)
+	require.Equal(t, []string{
+		"Intro text.",
+		"# Install\nDownload it.",
+		"## Linux ##\nUse the package.\n```sh\n# not a heading\n```",
+		"### Arch",
+		"## macOS\nUse brew.",
+		"# Usage\nRun it.",
+	}, chunk.Texts(chunks))
+
+	var headings [][]string
+	for _, c := range chunks {
+		headings = append(headings, c.Headings)
+	}
+	require.Equal(t, [][]string{
+		nil,
+		{"Install"},
+		{"Install", "Linux"},
+		{"Install", "Linux", "Arch"},
+		{"Install", "macOS"},
+		{"Usage"},
+	}, headings)
+}
+
+func TestChunkerCountsWhitespace(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("hello world ", 50)
+	chunks := chunk.Chunker{Tokenizer: tokencount.Heuristic{CharsPerToken: 1}, MaxTokens: 40}.ByTokens(text)
+	require.Len(t, chunks, 17)
+	for _, c := range chunks {
+		require.LessOrEqual(t, c.Tokens, 40)
+	}
+}
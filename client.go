@@ -9,6 +9,21 @@ import (
 	"net/url"
 )
 
+// ClientInterface is the API of Client used by most applications, so code
+// depending on it can be tested with a fake such as openroutertest.FakeClient.
+type ClientInterface interface {
+	CreateChatCompletion(ctx context.Context, request ChatCompletionRequest) (ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, request ChatCompletionRequest) (*ChatCompletionStream, error)
+	CreateCompletion(ctx context.Context, request CompletionRequest) (CompletionResponse, error)
+	CreateEmbeddings(ctx context.Context, request EmbeddingsRequest) (EmbeddingsResponse, error)
+	CreateResponse(ctx context.Context, request ResponsesRequest) (Response, error)
+	ListModels(ctx context.Context) ([]Model, error)
+	GetGeneration(ctx context.Context, id string) (Generation, error)
+	GetCredits(ctx context.Context) (Credits, error)
+}
+
+var _ ClientInterface = (*Client)(nil)
+
 type Client struct {
 	config ClientConfig
 
@@ -44,7 +59,7 @@ func (c *Client) sendRequest(req *http.Request, v any) error {
 
 	c.setCommonHeaders(req)
 
-	res, err := c.config.HTTPClient.Do(req)
+	res, err := c.httpDoer().Do(req)
 	if err != nil {
 		return err
 	}
@@ -54,7 +69,41 @@ func (c *Client) sendRequest(req *http.Request, v any) error {
 		return c.handleErrorResp(res)
 	}
 
-	return decodeResponse(res.Body, v)
+	if err := decodeResponse(res.Body, v); err != nil {
+		return &responseBodyError{method: req.Method, err: err}
+	}
+	return nil
+}
+
+// responseBodyError is the failure to read or decode the body of a successful
+// response. The server has processed the request, so it is only retried for
+// idempotent methods: sending a POST again would, for instance, pay for a
+// completion twice.
+type responseBodyError struct {
+	method string
+	err    error
+}
+
+func (e *responseBodyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseBodyError) Unwrap() error {
+	return e.err
+}
+
+// httpDoer returns the HTTPDoer sending the requests of the client, which
+// picks their key from the KeyPool of the client and records them to its
+// Auditor, if any.
+func (c *Client) httpDoer() HTTPDoer {
+	doer := c.config.HTTPClient
+	if pool := c.config.KeyPool; pool != nil && len(pool.keys) > 0 {
+		doer = keyPoolDoer{pool: pool, doer: doer}
+	}
+	if auditor := c.config.Auditor; auditor != nil {
+		doer = auditDoer{auditor: auditor, redactor: c.redactor(), doer: doer}
+	}
+	return doer
 }
 
 func (c *Client) setCommonHeaders(req *http.Request) {
@@ -144,6 +193,12 @@ func (c *Client) newRequest(ctx context.Context, method, url string, setters ...
 	for _, setter := range setters {
 		setter(args)
 	}
+	call := callOptionsFrom(ctx)
+	url, err := call.url(url)
+	if err != nil {
+		return nil, err
+	}
+	call.setHeaders(args.header)
 	req, err := c.requestBuilder.Build(ctx, method, url, args.body, args.header)
 	if err != nil {
 		return nil, err
@@ -157,12 +212,19 @@ func (c *Client) newStreamRequest(
 	method string,
 	urlSuffix string,
 	body any) (*http.Request, error) {
-	req, err := c.requestBuilder.Build(ctx, method, c.fullURL(urlSuffix), body, http.Header{
+	call := callOptionsFrom(ctx)
+	url, err := call.url(c.fullURL(urlSuffix))
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{
 		"Content-Type":  []string{"application/json"},
 		"Accept":        []string{"text/event-stream"},
 		"Cache-Control": []string{"no-cache"},
 		"Connection":    []string{"keep-alive"},
-	})
+	}
+	call.setHeaders(header)
+	req, err := c.requestBuilder.Build(ctx, method, url, body, header)
 	if err != nil {
 		return nil, err
 	}
@@ -171,21 +233,38 @@ func (c *Client) newStreamRequest(
 	return req, nil
 }
 
+// maxErrorBodySize caps how much of an error response body is read and kept
+// in RequestError.Body.
+const maxErrorBodySize = 64 << 10
+
 func (c *Client) handleErrorResp(resp *http.Response) error {
-	var errRes ErrorResponse
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return &RequestError{
+			HTTPStatus:     resp.Status,
+			HTTPStatusCode: resp.StatusCode,
+			Err:            err,
+			Body:           body,
+		}
+	}
 
-	err := json.NewDecoder(resp.Body).Decode(&errRes)
+	var errRes ErrorResponse
+	err = json.Unmarshal(body, &errRes)
 	if err != nil || errRes.Error == nil {
-		reqErr := &RequestError{
+		if err == nil {
+			err = fmt.Errorf("unexpected error response: %s", resp.Status)
+		}
+		return &RequestError{
+			HTTPStatus:     resp.Status,
 			HTTPStatusCode: resp.StatusCode,
 			Err:            err,
+			Body:           body,
 		}
-		if errRes.Error != nil {
-			reqErr.Err = errRes.Error
-		}
-		return reqErr
 	}
 
 	errRes.Error.HTTPStatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusPaymentRequired {
+		return newInsufficientCreditsError(errRes.Error)
+	}
 	return errRes.Error
 }
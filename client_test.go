@@ -113,7 +113,7 @@ func TestCreateCompletion(t *testing.T) {
 			name: "basic completion",
 			request: openrouter.CompletionRequest{
 				Model:  "nousresearch/hermes-4-70b",
-				Prompt: "Hello! Respond with just 'world'",
+				Prompt: openrouter.TextPrompt("Hello! Respond with just 'world'"),
 			},
 			validate: func(t *testing.T, resp openrouter.CompletionResponse) {
 				if len(resp.Choices) == 0 {
@@ -128,7 +128,7 @@ func TestCreateCompletion(t *testing.T) {
 			name: "invalid model",
 			request: openrouter.CompletionRequest{
 				Model:  "invalid-model",
-				Prompt: "Hello",
+				Prompt: openrouter.TextPrompt("Hello"),
 			},
 			wantErr: true,
 		},
@@ -137,7 +137,7 @@ func TestCreateCompletion(t *testing.T) {
 			request: openrouter.CompletionRequest{
 				Model:  openrouter.LiquidLFM7B,
 				Stream: true,
-				Prompt: "Hello",
+				Prompt: openrouter.TextPrompt("Hello"),
 			},
 			wantErr: true,
 		},
@@ -0,0 +1,279 @@
+// Command gencatalog generates Go constants from the OpenRouter catalog.
+//
+// It is run by go generate in the openrouter package:
+//
+//	go run ./cmd/gencatalog -kind models -o models_gen.go
+//	go run ./cmd/gencatalog -kind providers -o providers_gen.go
+//
+// The catalog is fetched from the API, authenticated with OPENROUTER_API_KEY
+// when set, or read from a saved response with -in. With -save, the IDs and
+// names of the catalog are written to a snapshot, such as the ones in the
+// catalog directory, which the tests of this command use to check that the
+// generated files are up to date:
+//
+//	go run ./cmd/gencatalog -kind models -in cmd/gencatalog/catalog/models.json -o models_gen.go
+//
+// The committed models snapshot is a curated subset of the catalog, the widely
+// used models of the major vendors, rather than a full dump: the full catalog
+// lists hundreds of short-lived previews and free variants whose constants
+// would churn on every refresh. Model IDs are plain strings, so models without
+// a constant can still be used. To refresh the constants, run go generate in
+// the repository root with network access. It fetches the full catalog and
+// rewrites both the snapshot and models_gen.go. Trim the snapshot back to the
+// models worth a constant, then generate from it with -in as above.
+// TestModelsSnapshotListed, run when OPENROUTER_API_KEY is set, reports models
+// of the snapshot that left the catalog.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/revrost/go-openrouter"
+)
+
+func main() {
+	kind := flag.String("kind", "models", "catalog to generate constants for: models or providers")
+	in := flag.String("in", "", "read the catalog from this saved API response instead of fetching it")
+	out := flag.String("o", "", "output file (default stdout)")
+	pkg := flag.String("pkg", "openrouter", "package name of the generated file")
+	save := flag.String("save", "", "write a snapshot of the catalog to this file")
+	flag.Parse()
+
+	var (
+		src []byte
+		err error
+	)
+	switch *kind {
+	case "models":
+		var models []openrouter.Model
+		if *in != "" {
+			models, err = readModels(*in)
+		} else {
+			client := openrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+			models, err = client.ListModels(context.Background())
+		}
+		if err != nil {
+			log.Fatalf("gencatalog: list models: %v", err)
+		}
+		if *save != "" {
+			err = saveModels(*save, models)
+		}
+		if err == nil {
+			src, err = generateModels(*pkg, models)
+		}
+	case "providers":
+		var providers []openrouter.Provider
+		if *in != "" {
+			providers, err = readProviders(*in)
+		} else {
+			client := openrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+			providers, err = client.ListProviders(context.Background())
+		}
+		if err != nil {
+			log.Fatalf("gencatalog: list providers: %v", err)
+		}
+		if *save != "" {
+			err = saveProviders(*save, providers)
+		}
+		if err == nil {
+			src, err = generateProviders(*pkg, providers)
+		}
+	default:
+		log.Fatalf("gencatalog: unknown kind %q", *kind)
+	}
+	if err != nil {
+		log.Fatalf("gencatalog: %v", err)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*out, src, 0o644)
+	}
+	if err != nil {
+		log.Fatalf("gencatalog: %v", err)
+	}
+}
+
+func readModels(path string) ([]openrouter.Model, error) {
+	var response struct {
+		Data []openrouter.Model `json:"data"`
+	}
+	err := readResponse(path, &response)
+	return response.Data, err
+}
+
+func readProviders(path string) ([]openrouter.Provider, error) {
+	var response struct {
+		Data []openrouter.Provider `json:"data"`
+	}
+	err := readResponse(path, &response)
+	return response.Data, err
+}
+
+func readResponse(path string, v any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
+
+// saveModels writes the IDs and names of models to path, sorted by ID, in the
+// shape of the API response.
+func saveModels(path string, models []openrouter.Model) error {
+	type entry struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	entries := make([]entry, len(models))
+	for i, model := range models {
+		entries[i] = entry{ID: model.ID, Name: model.Name}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return saveResponse(path, entries)
+}
+
+// saveProviders writes the slugs and names of providers to path, sorted by
+// slug, in the shape of the API response.
+func saveProviders(path string, providers []openrouter.Provider) error {
+	type entry struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	}
+	entries := make([]entry, len(providers))
+	for i, provider := range providers {
+		entries[i] = entry{Name: provider.Name, Slug: provider.Slug}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Slug < entries[j].Slug })
+	return saveResponse(path, entries)
+}
+
+func saveResponse(path string, data any) error {
+	src, err := json.MarshalIndent(map[string]any{"data": data}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(src, '\n'), 0o644)
+}
+
+// constant is a generated constant declaration.
+type constant struct {
+	name  string
+	value string
+}
+
+// generateModels returns the source of a file declaring a constant for the ID
+// of every model, in one block per vendor.
+func generateModels(pkg string, models []openrouter.Model) ([]byte, error) {
+	groups := make(map[string][]string)
+	for _, model := range models {
+		vendor, _, ok := strings.Cut(model.ID, "/")
+		if !ok {
+			vendor = ""
+		}
+		groups[vendor] = append(groups[vendor], model.ID)
+	}
+
+	vendors := make([]string, 0, len(groups))
+	for vendor := range groups {
+		vendors = append(vendors, vendor)
+	}
+	sort.Strings(vendors)
+
+	used := make(map[string]string)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gencatalog; DO NOT EDIT.\n\npackage %s\n", pkg)
+	for _, vendor := range vendors {
+		ids := groups[vendor]
+		sort.Strings(ids)
+
+		var constants []constant
+		for _, id := range ids {
+			name := "Model" + identifier(id)
+			if other, ok := used[name]; ok {
+				log.Printf("gencatalog: skipping %q: %s already names %q", id, name, other)
+				continue
+			}
+			used[name] = id
+			constants = append(constants, constant{name: name, value: id})
+		}
+
+		if vendor == "" {
+			vendor = "Unprefixed"
+		}
+		fmt.Fprintf(&buf, "\n// %s models.\nconst (\n", vendor)
+		for _, c := range constants {
+			fmt.Fprintf(&buf, "\t%s = %q\n", c.name, c.value)
+		}
+		buf.WriteString(")\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+// generateProviders returns the source of a file declaring a ProviderSlug
+// constant for the slug of every provider.
+func generateProviders(pkg string, providers []openrouter.Provider) ([]byte, error) {
+	sorted := append([]openrouter.Provider(nil), providers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Slug < sorted[j].Slug })
+
+	used := make(map[string]string)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gencatalog; DO NOT EDIT.\n\npackage %s\n", pkg)
+	buf.WriteString("\n// Provider slugs for ChatProvider.Order, Only and Ignore.\nconst (\n")
+	for _, provider := range sorted {
+		name := "ProviderSlug" + identifier(provider.Slug)
+		if other, ok := used[name]; ok {
+			log.Printf("gencatalog: skipping %q: %s already names %q", provider.Slug, name, other)
+			continue
+		}
+		used[name] = provider.Slug
+		fmt.Fprintf(&buf, "\t%s ProviderSlug = %q // %s\n", name, provider.Slug, provider.Name)
+	}
+	buf.WriteString(")\n")
+	return format.Source(buf.Bytes())
+}
+
+// initialisms are words spelled in upper case or with a fixed case in
+// identifiers.
+var initialisms = map[string]string{
+	"ai":         "AI",
+	"gpt":        "GPT",
+	"llm":        "LLM",
+	"oss":        "OSS",
+	"openai":     "OpenAI",
+	"openrouter": "OpenRouter",
+	"vl":         "VL",
+	"xai":        "XAI",
+}
+
+// identifier converts a catalog ID such as "openai/gpt-4o-mini:free" to an
+// exported Go identifier such as "OpenAIGPT4oMiniFree".
+func identifier(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		if fixed, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(fixed)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifier(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"openai/gpt-4o-mini":                       "OpenAIGPT4oMini",
+		"anthropic/claude-3.5-sonnet":              "AnthropicClaude35Sonnet",
+		"google/gemini-2.0-flash-exp:free":         "GoogleGemini20FlashExpFree",
+		"meta-llama/llama-3.1-8b-instruct":         "MetaLlamaLlama318bInstruct",
+		"x-ai/grok-4":                              "XAIGrok4",
+		"qwen/qwen2.5-vl-72b-instruct":             "QwenQwen25VL72bInstruct",
+		"nousresearch/hermes-3-llama-3.1-405b":     "NousresearchHermes3Llama31405b",
+		"mistralai/mistral-small-3.2-24b-instruct": "MistralaiMistralSmall3224bInstruct",
+	}
+	for id, want := range cases {
+		require.Equal(t, want, identifier(id), id)
+	}
+}
+
+func TestGenerateModels(t *testing.T) {
+	t.Parallel()
+
+	src, err := generateModels("openrouter", []openrouter.Model{
+		{ID: "openai/gpt-4o-mini"},
+		{ID: "anthropic/claude-3.5-sonnet"},
+		{ID: "openai/gpt-4o"},
+		{ID: "openai/gpt_4o"}, // same identifier as gpt-4o, skipped
+	})
+	require.NoError(t, err)
+
+	got := string(src)
+	require.True(t, strings.HasPrefix(got, "// Code generated by gencatalog; DO NOT EDIT.\n\npackage openrouter\n"))
+	require.Contains(t, got, "// anthropic models.\nconst (\n\tModelAnthropicClaude35Sonnet = \"anthropic/claude-3.5-sonnet\"\n)")
+	require.Contains(t, got, "\tModelOpenAIGPT4o     = \"openai/gpt-4o\"\n\tModelOpenAIGPT4oMini = \"openai/gpt-4o-mini\"\n")
+	require.NotContains(t, got, "\"openai/gpt_4o\"")
+	require.Less(t, strings.Index(got, "anthropic"), strings.Index(got, "openai"))
+}
+
+func TestGenerateProviders(t *testing.T) {
+	t.Parallel()
+
+	src, err := generateProviders("openrouter", []openrouter.Provider{
+		{Name: "Together", Slug: "together"},
+		{Name: "Google Vertex", Slug: "google-vertex"},
+		{Name: "DeepInfra", Slug: "deepinfra"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, `// Code generated by gencatalog; DO NOT EDIT.
+
+package openrouter
+
+// Provider slugs for ChatProvider.Order, Only and Ignore.
+const (
+	ProviderSlugDeepinfra    ProviderSlug = "deepinfra"     // DeepInfra
+	ProviderSlugGoogleVertex ProviderSlug = "google-vertex" // Google Vertex
+	ProviderSlugTogether     ProviderSlug = "together"      // Together
+)
+`, string(src))
+}
+
+// TestModelsGenUpToDate checks that models_gen.go was generated from the
+// snapshot of the catalog.
+func TestModelsGenUpToDate(t *testing.T) {
+	t.Parallel()
+
+	models, err := readModels("catalog/models.json")
+	require.NoError(t, err)
+	want, err := generateModels("openrouter", models)
+	require.NoError(t, err)
+	got, err := os.ReadFile("../../models_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate in the repository root")
+}
+
+// TestProvidersGenUpToDate checks that providers_gen.go was generated from the
+// snapshot of the catalog.
+func TestProvidersGenUpToDate(t *testing.T) {
+	t.Parallel()
+
+	providers, err := readProviders("catalog/providers.json")
+	require.NoError(t, err)
+	want, err := generateProviders("openrouter", providers)
+	require.NoError(t, err)
+	got, err := os.ReadFile("../../providers_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate in the repository root")
+}
+
+// TestModelsSnapshotListed checks that every model of the curated snapshot is
+// still in the live catalog, so that no constant names a removed model.
+func TestModelsSnapshotListed(t *testing.T) {
+	token := os.Getenv("OPENROUTER_API_KEY")
+	if token == "" {
+		t.Skip("Skipping integration test: OPENROUTER_API_KEY not set")
+	}
+
+	snapshot, err := readModels("catalog/models.json")
+	require.NoError(t, err)
+	live, err := openrouter.NewClient(token).ListModels(context.Background())
+	require.NoError(t, err)
+
+	listed := make(map[string]bool, len(live))
+	for _, model := range live {
+		listed[model.ID] = true
+	}
+	for _, model := range snapshot {
+		require.True(t, listed[model.ID], "%s is no longer listed; remove it from the snapshot and run go generate", model.ID)
+	}
+}
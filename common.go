@@ -6,7 +6,9 @@ type Usage struct {
 	CompletionTokens       int                    `json:"completion_tokens"`
 	CompletionTokenDetails CompletionTokenDetails `json:"completion_tokens_details"`
 	TotalTokens            int                    `json:"total_tokens"`
-	IsBYOK                 bool                   `json:"is_byok"`
+	// IsBYOK reports whether the request was served with the caller's own
+	// provider key. Nil when the response did not say.
+	IsBYOK *bool `json:"is_byok,omitempty"`
 
 	Cost        float64     `json:"cost"`
 	CostDetails CostDetails `json:"cost_details"`
@@ -20,15 +22,19 @@ type CostDetails struct {
 	UpstreamInferenceCompletionCost float64 `json:"upstream_inference_completions_cost"`
 }
 
+// CompletionTokenDetails breaks down the completion tokens. The modality
+// counts are nil when the provider did not report them, as opposed to zero.
 type CompletionTokenDetails struct {
-	ReasoningTokens int `json:"reasoning_tokens"`
-	ImageTokens     int `json:"image_tokens"`
-	AudioTokens     int `json:"audio_tokens"`
+	ReasoningTokens int  `json:"reasoning_tokens"`
+	ImageTokens     *int `json:"image_tokens,omitempty"`
+	AudioTokens     *int `json:"audio_tokens,omitempty"`
 }
 
+// PromptTokenDetails breaks down the prompt tokens. The modality counts are
+// nil when the provider did not report them, as opposed to zero.
 type PromptTokenDetails struct {
-	CachedTokens     int `json:"cached_tokens"`
-	CacheWriteTokens int `json:"cache_write_tokens"`
-	AudioTokens      int `json:"audio_tokens"`
-	VideoTokens      int `json:"video_tokens"`
+	CachedTokens     int  `json:"cached_tokens"`
+	CacheWriteTokens int  `json:"cache_write_tokens"`
+	AudioTokens      *int `json:"audio_tokens,omitempty"`
+	VideoTokens      *int `json:"video_tokens,omitempty"`
 }
@@ -45,11 +45,12 @@ func TestUnmarshalChatCompletionResponse(t *testing.T) {
 	require.Equal(t, 42, usage.CompletionTokens)
 	require.Equal(t, 7367, usage.TotalTokens)
 	require.InDelta(t, 0.0110382, usage.Cost, 1e-9)
-	require.False(t, usage.IsBYOK)
+	require.NotNil(t, usage.IsBYOK)
+	require.False(t, *usage.IsBYOK)
 
 	require.Equal(t, 29, usage.CompletionTokenDetails.ReasoningTokens)
-	require.Equal(t, 0, usage.CompletionTokenDetails.ImageTokens)
-	require.Equal(t, 0, usage.CompletionTokenDetails.AudioTokens)
+	require.Equal(t, 0, *usage.CompletionTokenDetails.ImageTokens)
+	require.Equal(t, 0, *usage.CompletionTokenDetails.AudioTokens)
 
 	require.InDelta(t, 0.0110382, usage.CostDetails.UpstreamInferenceCost, 1e-9)
 	require.InDelta(t, 0.0104082, usage.CostDetails.UpstreamInferencePromptCost, 1e-9)
@@ -58,6 +59,84 @@ func TestUnmarshalChatCompletionResponse(t *testing.T) {
 	// NOTE: JSON field is "prompt_tokens_details" but struct tag is "prompt_token_details" — mismatch causes zero values here
 	require.Equal(t, 4284, usage.PromptTokenDetails.CachedTokens)
 	require.Equal(t, 0, usage.PromptTokenDetails.CacheWriteTokens)
+	require.Equal(t, 0, *usage.PromptTokenDetails.AudioTokens)
+	require.Equal(t, 0, *usage.PromptTokenDetails.VideoTokens)
+}
+
+func TestUnmarshalUsageDistinguishesAbsentFromZero(t *testing.T) {
+	var usage openrouter.Usage
+	err := json.Unmarshal([]byte(exampleAudioUsage), &usage)
+	require.NoError(t, err)
+
+	require.NotNil(t, usage.IsBYOK)
+	require.True(t, *usage.IsBYOK)
+	require.Equal(t, 1200, *usage.PromptTokenDetails.AudioTokens)
+	require.Nil(t, usage.PromptTokenDetails.VideoTokens)
+	require.Equal(t, 310, *usage.CompletionTokenDetails.AudioTokens)
+	require.Equal(t, 0, *usage.CompletionTokenDetails.ImageTokens)
+
+	var minimal openrouter.Usage
+	err = json.Unmarshal([]byte(`{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}`), &minimal)
+	require.NoError(t, err)
+	require.Nil(t, minimal.IsBYOK)
+	require.Nil(t, minimal.PromptTokenDetails.AudioTokens)
+	require.Nil(t, minimal.CompletionTokenDetails.AudioTokens)
+	require.Nil(t, minimal.CompletionTokenDetails.ImageTokens)
+
+	data, err := json.Marshal(minimal)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "is_byok")
+	require.NotContains(t, string(data), "audio_tokens")
+}
+
+// exampleAudioUsage mirrors the usage block OpenRouter returns for an
+// audio-output request served with a BYOK provider key.
+const exampleAudioUsage = `{
+    "prompt_tokens": 1228,
+    "completion_tokens": 354,
+    "total_tokens": 1582,
+    "cost": 0.004813,
+    "is_byok": true,
+    "prompt_tokens_details": {
+        "cached_tokens": 0,
+        "cache_write_tokens": 0,
+        "audio_tokens": 1200
+    },
+    "cost_details": {
+        "upstream_inference_cost": 0.0481,
+        "upstream_inference_prompt_cost": 0.0123,
+        "upstream_inference_completions_cost": 0.0358
+    },
+    "completion_tokens_details": {
+        "reasoning_tokens": 0,
+        "image_tokens": 0,
+        "audio_tokens": 310
+    }
+}`
+
+func TestUnmarshalChatCompletionResponseFinishReasonError(t *testing.T) {
+	var resp openrouter.ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{
+		"id": "gen-1",
+		"choices": [{
+			"index": 0,
+			"finish_reason": "error",
+			"native_finish_reason": "error",
+			"message": {"role": "assistant", "content": "Partial"},
+			"error": {"code": 502, "message": "Provider disconnected"}
+		}]
+	}`), &resp)
+	require.NoError(t, err)
+
+	choice := resp.Choices[0]
+	require.Equal(t, openrouter.FinishReasonError, choice.FinishReason)
+	require.Equal(t, "Partial", choice.Message.Content.Text)
+
+	var genErr *openrouter.GenerationError
+	require.ErrorAs(t, choice.Err(), &genErr)
+	require.Equal(t, 0, genErr.ChoiceIndex)
+	require.True(t, openrouter.IsAPIErrorCode(choice.Err(), 502))
+	require.Contains(t, choice.Err().Error(), "Provider disconnected")
 }
 
 // exampleJsonResonse is a real response from openrouter. Used for testing if the marshaller constructs it correctly
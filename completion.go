@@ -1,15 +1,13 @@
 package openrouter
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"log/slog"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const completionsSuffix = "/completions"
@@ -22,16 +20,18 @@ var (
 type CompletionRequest struct {
 	Model string `json:"model,omitempty"`
 	// The prompt to complete
-	Prompt string `json:"prompt"`
+	Prompt CompletionPrompt `json:"prompt"`
 	// Optional model fallbacks: https://openrouter.ai/docs/features/model-routing#the-models-parameter
 	Models    []string                 `json:"models,omitempty"`
 	Provider  *ChatProvider            `json:"provider,omitempty"`
 	Reasoning *ChatCompletionReasoning `json:"reasoning,omitempty"`
 	Usage     *IncludeUsage            `json:"usage,omitempty"`
-	// Apply message transforms
+	// Apply message transforms, e.g. TransformMiddleOut
 	// https://openrouter.ai/docs/features/message-transforms
 	Transforms []string `json:"transforms,omitempty"`
 	Stream     bool     `json:"stream,omitempty"`
+	// Options for streaming response. Only set this when you set stream: true.
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
 	// MaxTokens The maximum number of tokens that can be generated in the chat completion.
 	// This value can be used to control costs for text generated via API.
 	MaxTokens         int     `json:"max_tokens,omitempty"`
@@ -49,12 +49,121 @@ type CompletionRequest struct {
 	TopLogProbs int            `json:"top_logprobs,omitempty"`
 	MinP        float32        `json:"min_p,omitempty"`
 	TopA        float32        `json:"top_a,omitempty"`
-	User        string         `json:"user,omitempty"`
+	// Stop generating at any of these sequences. It is sent as an array, and
+	// accepts a single string when decoded.
+	Stop StopSequences `json:"stop,omitempty"`
+	// Suffix is the text that comes after the completion, for insertion.
+	Suffix string `json:"suffix,omitempty"`
+	// Echo returns the prompt in the text of the choices, before the
+	// completion.
+	Echo bool `json:"echo,omitempty"`
+	// N is the number of completions to generate for the prompt.
+	N int `json:"n,omitempty"`
+	// BestOf generates this many completions and returns the N with the
+	// highest log probability per token. It must be at least N.
+	BestOf int `json:"best_of,omitempty"`
+	// LogProbs returns the log probabilities of this many most likely tokens
+	// at each position, as well as the chosen token. Zero returns only the
+	// log probability of the chosen tokens.
+	LogProbs *int   `json:"logprobs,omitempty"`
+	User     string `json:"user,omitempty"`
 	// For usage with the broadcast feature. Group related requests together (such as a conversation or agent workflow) by including the session_id field (up to 128 characters).
 	// https://openrouter.ai/docs/guides/features/broadcast/overview#optional-trace-data
 	SessionId string `json:"session_id,omitempty"`
 }
 
+// ErrCompletionPromptFieldsMisused is returned when encoding a
+// CompletionPrompt with more than one of its fields set.
+var ErrCompletionPromptFieldsMisused = errors.New("can't use more than one CompletionPrompt field simultaneously")
+
+// CompletionPrompt is the prompt of a completion, which is a single text, a
+// batch of texts, or their token IDs. A batch is completed prompt by prompt,
+// the choices of each identified by their index.
+type CompletionPrompt struct {
+	Text       string
+	Batch      []string
+	Tokens     []int
+	TokenBatch [][]int
+}
+
+// TextPrompt returns a prompt of text.
+func TextPrompt(text string) CompletionPrompt {
+	return CompletionPrompt{Text: text}
+}
+
+// BatchPrompt returns a prompt of several texts, completed separately.
+func BatchPrompt(texts ...string) CompletionPrompt {
+	return CompletionPrompt{Batch: texts}
+}
+
+// TokenPrompt returns a prompt of the token IDs of a text, in the tokenizer of
+// the model.
+func TokenPrompt(tokens ...int) CompletionPrompt {
+	return CompletionPrompt{Tokens: tokens}
+}
+
+func (p CompletionPrompt) MarshalJSON() ([]byte, error) {
+	var set []any
+	if p.Text != "" {
+		set = append(set, p.Text)
+	}
+	if p.Batch != nil {
+		set = append(set, p.Batch)
+	}
+	if p.Tokens != nil {
+		set = append(set, p.Tokens)
+	}
+	if p.TokenBatch != nil {
+		set = append(set, p.TokenBatch)
+	}
+	switch len(set) {
+	case 0:
+		return []byte(`""`), nil
+	case 1:
+		return json.Marshal(set[0])
+	}
+	return nil, ErrCompletionPromptFieldsMisused
+}
+
+func (p *CompletionPrompt) UnmarshalJSON(data []byte) error {
+	*p = CompletionPrompt{}
+	if json.Unmarshal(data, &p.Text) == nil {
+		return nil
+	}
+	// Token IDs are tried first, as an empty array decodes as any of them.
+	if json.Unmarshal(data, &p.Tokens) == nil {
+		return nil
+	}
+	p.Tokens = nil
+	if json.Unmarshal(data, &p.Batch) == nil {
+		return nil
+	}
+	p.Batch = nil
+	if json.Unmarshal(data, &p.TokenBatch) == nil {
+		return nil
+	}
+	p.TokenBatch = nil
+	return fmt.Errorf("prompt must be a string, an array of strings or an array of tokens: %s", data)
+}
+
+// StopSequences are the sequences that stop generation, which the API
+// accepts as a single string or an array of strings.
+type StopSequences []string
+
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	var sequence string
+	if err := json.Unmarshal(data, &sequence); err == nil {
+		*s = StopSequences{sequence}
+		return nil
+	}
+	var sequences []string
+	if err := json.Unmarshal(data, &sequences); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings: %w", err)
+	}
+	*s = sequences
+	return nil
+}
+
 type CompletionChoice struct {
 	Index int    `json:"index"`
 	Text  string `json:"text"`
@@ -66,9 +175,35 @@ type CompletionChoice struct {
 	// length: Incomplete model output due to max_tokens parameter or token limit
 	// function_call: The model decided to call a function
 	// content_filter: Omitted content due to a flag from our content filters
+	// error: The provider aborted the generation, see Error
 	// null: API response still in progress or incomplete
-	FinishReason FinishReason `json:"finish_reason"`
-	LogProbs     *LogProbs    `json:"logprobs,omitempty"`
+	FinishReason FinishReason        `json:"finish_reason"`
+	LogProbs     *CompletionLogProbs `json:"logprobs,omitempty"`
+	// Error describes why the generation was aborted when FinishReason is error.
+	Error *APIError `json:"error,omitempty"`
+}
+
+// CompletionLogProbs is the log probability information of a completion
+// choice. Unlike LogProbs of chat completions, it holds parallel arrays indexed
+// by token position.
+type CompletionLogProbs struct {
+	Tokens []string `json:"tokens"`
+	// TokenLogProbs is the log probability of each token. The first token of an
+	// echoed prompt has none, and is 0.
+	TokenLogProbs []float64 `json:"token_logprobs"`
+	// TopLogProbs maps the most likely tokens at each position to their log
+	// probability.
+	TopLogProbs []map[string]float64 `json:"top_logprobs"`
+	// TextOffset is the offset of each token in the text of the choice.
+	TextOffset []int `json:"text_offset"`
+	// Content holds the log probabilities of the providers that return them in
+	// the shape of chat completions instead.
+	Content []LogProb `json:"content,omitempty"`
+}
+
+// Err returns a *GenerationError when the provider aborted this choice, nil otherwise.
+func (c CompletionChoice) Err() error {
+	return generationError(c.Index, c.FinishReason, c.Error)
 }
 
 // CompletionResponse represents a response structure for completion API.
@@ -83,6 +218,17 @@ type CompletionResponse struct {
 	SystemFingerprint string             `json:"system_fingerprint"`
 }
 
+// Err returns a *GenerationError when the provider aborted the generation of
+// a choice, nil otherwise.
+func (r CompletionResponse) Err() error {
+	for _, choice := range r.Choices {
+		if err := choice.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CreateCompletion — API call to Create a completion for the prompt.
 func (c *Client) CreateCompletion(
 	ctx context.Context,
@@ -97,7 +243,37 @@ func (c *Client) CreateCompletion(
 		err = ErrCompletionInvalidModel
 		return
 	}
+	if err = c.checkTransforms(request.Transforms); err != nil {
+		return
+	}
 
+	if err = c.checkQuota(ctx); err != nil {
+		return
+	}
+
+	ctx, span := c.startCompletionSpan(ctx, request)
+
+	startedAt := time.Now()
+	response, err = c.sendCompletion(ctx, request)
+	event := UsageEvent{Endpoint: "completion", RequestID: response.ID, Model: response.Model, Err: err}
+	if event.Model == "" {
+		event.Model = request.Model
+	}
+	if len(response.Choices) > 0 {
+		event.FinishReason = response.Choices[0].FinishReason
+	}
+	c.recordUsage(ctx, event, response.Usage, startedAt)
+	finishReasons, texts := completionSpanOutput(response.Choices)
+	span.end(event, response.Usage, finishReasons, texts, err)
+	return
+}
+
+// sendCompletion sends request, retrying with the middle-out transform when
+// the prompt is too long for the model.
+func (c *Client) sendCompletion(
+	ctx context.Context,
+	request CompletionRequest,
+) (response CompletionResponse, err error) {
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
@@ -109,13 +285,19 @@ func (c *Client) CreateCompletion(
 	}
 
 	err = c.sendRequest(req, &response)
+	if transforms, retry := c.middleOutRetry(err, request.Transforms); retry {
+		request.Transforms = transforms
+		return c.sendCompletion(ctx, request)
+	}
+	if err == nil {
+		c.reconcileCost(response.ID)
+	}
 	return
 }
 
 type CompletionStream struct {
-	stream   <-chan CompletionResponse
-	done     chan struct{}
-	response *http.Response
+	reader *sseStream[CompletionResponse]
+	usage  *Usage
 }
 
 // CreateCompletionStream — API call to Create a completion for the prompt with streaming.
@@ -126,108 +308,126 @@ func (c *Client) CreateCompletionStream(
 	if !request.Stream {
 		request.Stream = true
 	}
+	if c.config.IncludeStreamUsage && request.StreamOptions == nil {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
 
 	if !isSupportingModel(completionsSuffix, request.Model) {
 		return nil, ErrCompletionInvalidModel
 	}
-
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL(completionsSuffix),
-		withBody(request),
-	)
-	if err != nil {
+	if err := c.checkTransforms(request.Transforms); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.config.HTTPClient.Do(req)
-	if err != nil {
+	if err := c.checkQuota(ctx); err != nil {
 		return nil, err
 	}
-	if isFailureStatusCode(resp) {
-		return nil, c.handleErrorResp(resp)
-	}
 
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, errors.New("unexpected status code: " + resp.Status)
+	ctx, span := c.startCompletionSpan(ctx, request)
+	ctx, cancel := context.WithCancel(ctx)
+	startedAt := time.Now()
+	resp, err := c.openStream(ctx, completionsSuffix, request)
+	if transforms, retry := c.middleOutRetry(err, request.Transforms); retry {
+		request.Transforms = transforms
+		resp, err = c.openStream(ctx, completionsSuffix, request)
+	}
+	if err != nil {
+		cancel()
+		span.end(UsageEvent{Model: request.Model}, nil, nil, nil, err)
+		return nil, err
 	}
 
-	stream := make(chan CompletionResponse)
-	done := make(chan struct{})
-
-	go func() {
-		defer close(stream)
-		defer resp.Body.Close()
-
-		reader := bufio.NewReader(resp.Body)
-		for {
-			select {
-			case <-done:
-				return
-			case <-ctx.Done():
-				slog.Info("Stream stopped due to context cancellation")
-				return
-			default:
-				line, err := reader.ReadBytes('\n')
-				if err != nil {
-					if err == io.EOF {
-						return
+	var responseID string
+	var text strings.Builder
+	usage := newStreamUsage("completion", request.Model)
+	return &CompletionStream{
+		reader: newSSEStream(ctx, cancel, resp, sseStreamOptions[CompletionResponse]{
+			name:        "completion",
+			idleTimeout: c.config.StreamIdleTimeout,
+			raw:         c.config.RawStreamEvents,
+			onComment:   c.config.StreamCommentHook,
+			onChunk: func(chunk CompletionResponse) {
+				responseID = chunk.ID
+				usage.chunk(chunk.ID, chunk.Model, "", chunk.Usage)
+				var delta string
+				if len(chunk.Choices) > 0 {
+					delta = chunk.Choices[0].Text
+					text.WriteString(delta)
+					if reason := chunk.Choices[0].FinishReason; reason != "" {
+						usage.event.FinishReason = reason
 					}
-					slog.Error("failed to read completion stream", "error", err)
-					return
 				}
-				// If stream ended with done, stop immediately
-				if strings.HasSuffix(string(line), "[DONE]\n") {
-					return
+				span.chunk(chunk.hasOutput(), delta, chunk.Err())
+			},
+			onEnd: func(err error, stats StreamStats) {
+				c.reconcileCost(responseID)
+				usage.event.Err = err
+				c.recordUsage(ctx, usage.event, usage.usage, startedAt)
+				var finishReasons []string
+				if reason := usage.event.FinishReason; reason != "" {
+					finishReasons = []string{string(reason)}
 				}
-				// Ignore openrouter comments, empty lines
-				if strings.HasPrefix(string(line), ": OPENROUTER PROCESSING") || string(line) == "\n" {
-					continue
-				}
-				// Trim everything before json object from line
-				line = bytes.TrimPrefix(line, []byte("data:"))
-				// Decode object into a CompletionResponse
-				var chunk CompletionResponse
-				if err := json.Unmarshal(line, &chunk); err != nil {
-					slog.Error("failed to decode completion stream", "error", err, "line", string(line))
-					return
-				}
-				stream <- chunk
-			}
-		}
-	}()
-
-	return &CompletionStream{
-		stream:   stream,
-		done:     done,
-		response: resp,
+				span.endStream(stats, usage.event, usage.usage, finishReasons, []string{text.String()}, err)
+			},
+			startedAt: startedAt,
+			hasToken:  CompletionResponse.hasOutput,
+			usage: func(chunk CompletionResponse) *Usage {
+				return chunk.Usage
+			},
+			metrics: c.metricsSink(),
+			logger:  c.logger(),
+			tags:    map[string]string{"endpoint": "completion", "model": request.Model},
+		}),
 	}, nil
 }
 
+// hasOutput reports whether the chunk carries generated text.
+func (r CompletionResponse) hasOutput() bool {
+	for _, choice := range r.Choices {
+		if choice.Text != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // Recv reads the next chunk from the stream.
+// It returns io.EOF once the stream has ended, or the error that terminated it.
 func (s *CompletionStream) Recv() (CompletionResponse, error) {
-	select {
-	case chunk, ok := <-s.stream:
-		if !ok {
-			return CompletionResponse{}, io.EOF
-		}
-		return chunk, nil
-	case <-s.done:
-		return CompletionResponse{}, io.EOF
+	chunk, err := s.reader.Recv()
+	if err == nil && chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+	return chunk, err
+}
+
+// RecvEvent reads the next event from the stream. With WithRawStreamEvents
+// every line of the body is returned, including comments and the [DONE]
+// terminator; otherwise only chunk events are returned.
+func (s *CompletionStream) RecvEvent() (StreamEvent[CompletionResponse], error) {
+	event, err := s.reader.RecvEvent()
+	if err == nil && event.Chunk != nil && event.Chunk.Usage != nil {
+		s.usage = event.Chunk.Usage
 	}
+	return event, err
+}
+
+// Usage returns the token usage reported by the stream, or nil if none was received.
+// OpenRouter sends the usage on the last chunk, so it is only complete once Recv
+// has returned io.EOF. Set StreamOptions.IncludeUsage (or use WithStreamUsage)
+// to request it.
+func (s *CompletionStream) Usage() *Usage {
+	return s.usage
+}
+
+// Stats returns the timing of the stream, such as time to first token and
+// tokens per second. It is complete once Recv has returned an error, including io.EOF.
+func (s *CompletionStream) Stats() StreamStats {
+	return s.reader.Stats()
 }
 
 // Close terminates the stream and cleans up resources.
+// It is safe to call from any goroutine, any number of times.
 func (s *CompletionStream) Close() {
-	close(s.done)
-	if s.response != nil {
-		s.response.Body.Close()
-	}
+	s.reader.Close()
 }
@@ -0,0 +1,60 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// CompletionCache answers repeated chat completion requests with the response
+// to the first, for idempotent workloads such as classification. Requests are
+// identical when their HashRequest is, which ignores attribution fields such
+// as User, SessionId, Metadata and Trace. Responses are cached
+// whatever the request's temperature, so only enable the cache for requests
+// where any earlier answer is acceptable.
+type CompletionCache struct {
+	// TTL is how long a response answers repeats. Zero keeps it forever.
+	TTL   time.Duration
+	Store CacheStore
+}
+
+// NewCompletionCache returns a CompletionCache keeping responses in memory
+// for ttl.
+func NewCompletionCache(ttl time.Duration) *CompletionCache {
+	return &CompletionCache{TTL: ttl, Store: NewMemoryCacheStore()}
+}
+
+// completionCachePrefix namespaces the keys of CompletionCache in its store.
+const completionCachePrefix = "openrouter:completion:"
+
+// get returns the cached response for key. Store failures are logged to logger
+// and treated as misses.
+func (c *CompletionCache) get(ctx context.Context, logger *slog.Logger, key string) (ChatCompletionResponse, bool) {
+	data, ok, err := c.Store.Get(ctx, completionCachePrefix+key)
+	if err != nil {
+		logger.Error("failed to read the completion cache", "error", err)
+		return ChatCompletionResponse{}, false
+	}
+	if !ok {
+		return ChatCompletionResponse{}, false
+	}
+
+	var response ChatCompletionResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		logger.Error("failed to decode a cached completion", "error", err)
+		return ChatCompletionResponse{}, false
+	}
+	response.Cached = true
+	return response, true
+}
+
+func (c *CompletionCache) set(ctx context.Context, logger *slog.Logger, key string, response ChatCompletionResponse) {
+	data, err := json.Marshal(response)
+	if err == nil {
+		err = c.Store.Set(ctx, completionCachePrefix+key, data, c.TTL)
+	}
+	if err != nil {
+		logger.Error("failed to add to the completion cache", "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCache(t *testing.T) {
+	t.Parallel()
+
+	var completions atomic.Int32
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		completions.Add(1)
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","choices":[{"message":{"role":"assistant","content":"positive"}}]}`)
+	})
+	client.config.CompletionCache = NewCompletionCache(50 * time.Millisecond)
+
+	classify := func(user, text string) ChatCompletionResponse {
+		t.Helper()
+		resp, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+			Model:    "openai/gpt-4o-mini",
+			Messages: []ChatCompletionMessage{SystemMessage("Classify the sentiment."), UserMessage(text)},
+			User:     user,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	require.False(t, classify("alice", "I love it").Cached)
+	cached := classify("bob", "I love it")
+	require.True(t, cached.Cached, "attribution fields are not part of the key")
+	require.Equal(t, "positive", cached.Choices[0].Message.Content.Text)
+	require.False(t, classify("alice", "I hate it").Cached)
+	require.Equal(t, int32(2), completions.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	require.False(t, classify("alice", "I love it").Cached, "expired entries are not served")
+	require.Equal(t, int32(3), completions.Load())
+}
+
+func TestCacheKeyIgnoresAttribution(t *testing.T) {
+	t.Parallel()
+
+	request := ChatCompletionRequest{Model: "m", Messages: []ChatCompletionMessage{UserMessage("hi")}}
+	key, err := HashRequest(request)
+	require.NoError(t, err)
+
+	attributed := request
+	attributed.User = "u"
+	attributed.SessionId = "s"
+	attributed.Metadata = map[string]string{"k": "v"}
+	attributedKey, err := HashRequest(attributed)
+	require.NoError(t, err)
+	require.Equal(t, key, attributedKey)
+
+	request.Temperature = 0.5
+	otherKey, err := HashRequest(request)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+}
@@ -0,0 +1,115 @@
+package openrouter
+
+import (
+	"math"
+	"sort"
+)
+
+// ChoicesByPrompt groups the choices of a response to a request with N set to
+// n by prompt, in the order of the prompts of a BatchPrompt, each group in
+// ascending order of Index. Providers number the choices of the i-th prompt
+// from i*n. An n below 1 is taken as 1.
+func (r CompletionResponse) ChoicesByPrompt(n int) [][]CompletionChoice {
+	n = max(n, 1)
+	var groups [][]CompletionChoice
+	for _, choice := range r.orderedChoices() {
+		prompt := choice.Index / n
+		for len(groups) <= prompt {
+			groups = append(groups, nil)
+		}
+		groups[prompt] = append(groups[prompt], choice)
+	}
+	return groups
+}
+
+// ChoicesByLogProb returns the choices in descending order of their mean log
+// probability per token, the order best_of ranks completions in. Choices
+// without log probabilities come last, in ascending order of Index.
+func (r CompletionResponse) ChoicesByLogProb() []CompletionChoice {
+	return sortByLogProb(r.orderedChoices())
+}
+
+// BestText returns the text of the choice with the highest mean log
+// probability per token, or of the first choice when none carry log
+// probabilities. It reports false when the response has no choices.
+func (r CompletionResponse) BestText() (string, bool) {
+	choices := r.ChoicesByLogProb()
+	if len(choices) == 0 {
+		return "", false
+	}
+	return choices[0].Text, true
+}
+
+// BestTexts returns the best text for each prompt of a response to a request
+// with N set to n, as BestText does for a single prompt.
+func (r CompletionResponse) BestTexts(n int) []string {
+	groups := r.ChoicesByPrompt(n)
+	texts := make([]string, len(groups))
+	for i, group := range groups {
+		if group = sortByLogProb(group); len(group) > 0 {
+			texts[i] = group[0].Text
+		}
+	}
+	return texts
+}
+
+func (r CompletionResponse) orderedChoices() []CompletionChoice {
+	choices := make([]CompletionChoice, len(r.Choices))
+	copy(choices, r.Choices)
+	sort.SliceStable(choices, func(i, j int) bool {
+		return choices[i].Index < choices[j].Index
+	})
+	return choices
+}
+
+func sortByLogProb(choices []CompletionChoice) []CompletionChoice {
+	sorted := make([]CompletionChoice, len(choices))
+	copy(sorted, choices)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, aOK := sorted[i].MeanLogProb()
+		b, bOK := sorted[j].MeanLogProb()
+		if !aOK || !bOK {
+			return aOK && !bOK
+		}
+		return a > b
+	})
+	return sorted
+}
+
+// TotalLogProb returns the sum of the log probabilities of the choice's
+// tokens, i.e. the log probability of the whole text. It reports false when
+// the choice carries no log probabilities.
+func (c CompletionChoice) TotalLogProb() (float64, bool) {
+	if c.LogProbs == nil {
+		return 0, false
+	}
+	var total float64
+	switch {
+	case len(c.LogProbs.TokenLogProbs) > 0:
+		for _, logProb := range c.LogProbs.TokenLogProbs {
+			total += logProb
+		}
+	case len(c.LogProbs.Content) > 0:
+		for _, token := range c.LogProbs.Content {
+			total += token.LogProb
+		}
+	default:
+		return 0, false
+	}
+	return total, true
+}
+
+// MeanLogProb returns the mean log probability per token of the choice, which
+// compares texts of different lengths fairly. It reports false when the
+// choice carries no log probabilities.
+func (c CompletionChoice) MeanLogProb() (float64, bool) {
+	total, ok := c.TotalLogProb()
+	if !ok {
+		return math.Inf(-1), false
+	}
+	tokens := len(c.LogProbs.TokenLogProbs)
+	if tokens == 0 {
+		tokens = len(c.LogProbs.Content)
+	}
+	return total / float64(tokens), true
+}
@@ -0,0 +1,93 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionLogProbsDecoding(t *testing.T) {
+	t.Parallel()
+
+	var response CompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "gen-1",
+		"choices": [{
+			"index": 0,
+			"text": "Hello world",
+			"finish_reason": "stop",
+			"logprobs": {
+				"tokens": ["Say", " Hello", " world"],
+				"token_logprobs": [null, -0.5, -0.25],
+				"top_logprobs": [null, {" Hello": -0.5, " Hi": -1.2}, {" world": -0.25}],
+				"text_offset": [0, 3, 9]
+			}
+		}, {
+			"index": 1,
+			"text": "Hi",
+			"logprobs": {"content": [{"token": "Hi", "logprob": -1.5, "top_logprobs": []}]}
+		}]
+	}`), &response))
+
+	logProbs := response.Choices[0].LogProbs
+	require.Equal(t, []string{"Say", " Hello", " world"}, logProbs.Tokens)
+	require.Equal(t, []float64{0, -0.5, -0.25}, logProbs.TokenLogProbs)
+	require.Equal(t, map[string]float64{" Hello": -0.5, " Hi": -1.2}, logProbs.TopLogProbs[1])
+	require.Equal(t, []int{0, 3, 9}, logProbs.TextOffset)
+
+	total, ok := response.Choices[0].TotalLogProb()
+	require.True(t, ok)
+	require.InDelta(t, -0.75, total, 1e-9)
+	total, ok = response.Choices[1].TotalLogProb()
+	require.True(t, ok)
+	require.InDelta(t, -1.5, total, 1e-9)
+}
+
+func completionChoice(index int, text string, logProbs ...float64) CompletionChoice {
+	choice := CompletionChoice{Index: index, Text: text}
+	if logProbs != nil {
+		choice.LogProbs = &CompletionLogProbs{TokenLogProbs: logProbs}
+	}
+	return choice
+}
+
+func TestCompletionBestChoices(t *testing.T) {
+	t.Parallel()
+
+	response := CompletionResponse{Choices: []CompletionChoice{
+		completionChoice(3, "violets are blue", -0.1, -0.1, -0.1),
+		completionChoice(0, "roses are red", -0.2, -0.2, -0.2),
+		completionChoice(1, "roses are nice", -0.1, -0.2, -0.3, -2.0),
+		completionChoice(2, "violets are violet", -1.0),
+	}}
+
+	groups := response.ChoicesByPrompt(2)
+	require.Len(t, groups, 2)
+	require.Equal(t, 0, groups[0][0].Index)
+	require.Equal(t, 1, groups[0][1].Index)
+	require.Equal(t, 2, groups[1][0].Index)
+	require.Equal(t, 3, groups[1][1].Index)
+
+	var texts []string
+	for _, choice := range response.ChoicesByLogProb() {
+		texts = append(texts, choice.Text)
+	}
+	require.Equal(t, []string{"violets are blue", "roses are red", "roses are nice", "violets are violet"}, texts)
+
+	best, ok := response.BestText()
+	require.True(t, ok)
+	require.Equal(t, "violets are blue", best)
+	require.Equal(t, []string{"roses are red", "violets are blue"}, response.BestTexts(2))
+
+	unscored := CompletionResponse{Choices: []CompletionChoice{
+		completionChoice(1, "second"),
+		completionChoice(0, "first"),
+	}}
+	best, ok = unscored.BestText()
+	require.True(t, ok)
+	require.Equal(t, "first", best)
+
+	_, ok = CompletionResponse{}.BestText()
+	require.False(t, ok)
+}
@@ -0,0 +1,112 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionRequestStop(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(CompletionRequest{Prompt: TextPrompt("Q: 1+1?\nA:"), Stop: StopSequences{"\n", "Q:"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"prompt":"Q: 1+1?\nA:","stop":["\n","Q:"]}`, string(data))
+
+	data, err = json.Marshal(CompletionRequest{Prompt: TextPrompt("Hi")})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "stop")
+
+	var request CompletionRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"prompt":"Hi","stop":"\n"}`), &request))
+	require.Equal(t, StopSequences{"\n"}, request.Stop)
+	require.NoError(t, json.Unmarshal([]byte(`{"prompt":"Hi","stop":["a","b"]}`), &request))
+	require.Equal(t, StopSequences{"a", "b"}, request.Stop)
+	require.Error(t, json.Unmarshal([]byte(`{"prompt":"Hi","stop":42}`), &request))
+}
+
+func TestCompletionRequestLegacyParameters(t *testing.T) {
+	t.Parallel()
+
+	logProbs := 0
+	data, err := json.Marshal(CompletionRequest{
+		Prompt:   TextPrompt("def add(a, b):"),
+		Suffix:   "\n\nprint(add(1, 2))",
+		Echo:     true,
+		N:        2,
+		BestOf:   4,
+		LogProbs: &logProbs,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"prompt": "def add(a, b):",
+		"suffix": "\n\nprint(add(1, 2))",
+		"echo": true,
+		"n": 2,
+		"best_of": 4,
+		"logprobs": 0
+	}`, string(data))
+}
+
+func TestCompletionPrompt(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		prompt CompletionPrompt
+		json   string
+	}{
+		{TextPrompt("Once upon a time"), `"Once upon a time"`},
+		{BatchPrompt("Roses are", "Violets are"), `["Roses are","Violets are"]`},
+		{TokenPrompt(9906, 1917), `[9906,1917]`},
+		{CompletionPrompt{TokenBatch: [][]int{{9906}, {1917}}}, `[[9906],[1917]]`},
+		{CompletionPrompt{}, `""`},
+	} {
+		data, err := json.Marshal(tt.prompt)
+		require.NoError(t, err)
+		require.Equal(t, tt.json, string(data))
+
+		var decoded CompletionPrompt
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, tt.prompt, decoded)
+	}
+
+	_, err := json.Marshal(CompletionPrompt{Text: "a", Batch: []string{"b"}})
+	require.ErrorIs(t, err, ErrCompletionPromptFieldsMisused)
+
+	var decoded CompletionPrompt
+	require.Error(t, json.Unmarshal([]byte(`{"text":"a"}`), &decoded))
+}
+
+func TestCompletionStreamUsage(t *testing.T) {
+	t.Parallel()
+
+	var body []byte
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		body, _ = io.ReadAll(req.Body)
+		return jsonResponse(http.StatusOK, sseBody(
+			`data: {"id":"1","choices":[{"text":"Hello"}]}`,
+			`data: {"id":"1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`,
+			`data: [DONE]`,
+		))
+	})
+	client.config.IncludeStreamUsage = true
+
+	stream, err := client.CreateCompletionStream(context.Background(), CompletionRequest{Prompt: TextPrompt("Say hello")})
+	require.NoError(t, err)
+	defer stream.Close()
+	require.JSONEq(t, `{"prompt":"Say hello","stream":true,"stream_options":{"include_usage":true}}`, string(body))
+
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "Hello", chunk.Choices[0].Text)
+	require.Nil(t, stream.Usage())
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, &Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4}, stream.Usage())
+}
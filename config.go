@@ -1,6 +1,10 @@
 package openrouter
 
-import "net/http"
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
 
 // ClientConfig is a configuration for the openrouter client.
 type ClientConfig struct {
@@ -14,6 +18,85 @@ type ClientConfig struct {
 	XTitle           string
 
 	EmptyMessagesLimit uint
+
+	// IncludeStreamUsage requests the final usage chunk on every chat and text
+	// completion stream that does not set StreamOptions itself.
+	IncludeStreamUsage bool
+
+	// StreamIdleTimeout terminates a stream with ErrStreamStalled when no SSE
+	// event, including keep-alive comments, arrives within the duration.
+	// Zero disables the check.
+	StreamIdleTimeout time.Duration
+
+	// StreamResumeAttempts is how many times a chat completion stream that drops
+	// mid-response is re-requested with the received text as an assistant prefill.
+	// Zero disables resuming.
+	StreamResumeAttempts int
+
+	// RawStreamEvents makes RecvEvent surface every line of a stream, including
+	// comments, with its raw bytes.
+	RawStreamEvents bool
+
+	// StreamCommentHook is called with the text of every SSE comment line, such
+	// as the "OPENROUTER PROCESSING" keep-alives sent while a provider is queued.
+	StreamCommentHook func(comment string)
+
+	// MiddleOutOnContextOverflow retries a request that failed with a context
+	// length error once with the TransformMiddleOut transform added.
+	MiddleOutOnContextOverflow bool
+
+	// MetricsSink receives client metrics such as stream latencies. Nil disables metrics.
+	MetricsSink MetricsSink
+
+	// UsageSink receives a UsageEvent after every call, for billing. Nil
+	// disables usage events.
+	UsageSink UsageSink
+
+	// MergePrefill makes CreateChatCompletion return the content of responses
+	// to an assistant prefill with the prefill prepended.
+	MergePrefill bool
+
+	// RequestLogger, if set, receives one record per call, with the fields
+	// named by the LogField constants.
+	RequestLogger *slog.Logger
+
+	// Tracer, if set, starts a span around every chat and text completion
+	// call. TraceContent also records prompts and completions as span events,
+	// which may expose sensitive data to the tracing backend.
+	Tracer       Tracer
+	TraceContent bool
+
+	// StrictMode makes the client fail on input it otherwise accepts
+	// leniently, such as message content in an unrecognized shape or unknown
+	// transforms.
+	StrictMode bool
+
+	// QuotaManager, if set, rejects the requests of callers who have reached
+	// their limits and records the usage of the others.
+	QuotaManager *QuotaManager
+
+	// CostReconciliation, if set, is called in the background after every chat
+	// and text completion with the generation record fetched from GetGeneration.
+	CostReconciliation func(responseID string, generation Generation, err error)
+
+	// SemanticCache, if set, answers chat completions whose prompt is similar
+	// enough to one answered before from the cache.
+	SemanticCache *SemanticCache
+
+	// CompletionCache, if set, answers repeated chat completion requests from
+	// the cache.
+	CompletionCache *CompletionCache
+
+	// KeyPool, if set, supplies the API key of every request instead of the
+	// key the client was created with.
+	KeyPool *KeyPool
+
+	// Auditor, if set, receives a record of every request sent.
+	Auditor Auditor
+
+	// Redactor strips secrets and personal data from everything the client
+	// logs and from metrics tags. Nil uses DefaultRedactor.
+	Redactor Redactor
 }
 
 type HTTPDoer interface {
@@ -50,3 +133,183 @@ func WithHTTPReferer(referer string) Option {
 		c.HttpReferer = referer
 	}
 }
+
+// WithStreamUsage makes chat and text completion streams request the final
+// usage chunk by default, so ChatCompletionStream.Usage and
+// CompletionStream.Usage are populated after io.EOF.
+func WithStreamUsage() Option {
+	return func(c *ClientConfig) {
+		c.IncludeStreamUsage = true
+	}
+}
+
+// WithStreamIdleTimeout terminates streams with ErrStreamStalled when the
+// connection stays silent for longer than timeout.
+func WithStreamIdleTimeout(timeout time.Duration) Option {
+	return func(c *ClientConfig) {
+		c.StreamIdleTimeout = timeout
+	}
+}
+
+// WithStreamResume resumes chat completion streams that fail mid-response with
+// a transport error, up to maxAttempts times. The text received so far is sent
+// back as an assistant prefill and the continuation is stitched into the same
+// stream, so callers keep reading from Recv as if nothing happened.
+func WithStreamResume(maxAttempts int) Option {
+	return func(c *ClientConfig) {
+		c.StreamResumeAttempts = maxAttempts
+	}
+}
+
+// WithMetricsSink sends client metrics, such as stream latencies, to sink.
+func WithMetricsSink(sink MetricsSink) Option {
+	return func(c *ClientConfig) {
+		c.MetricsSink = sink
+	}
+}
+
+// WithUsageSink sends a UsageEvent to sink after every chat, completion,
+// embeddings and responses call, including streamed ones.
+func WithUsageSink(sink UsageSink) Option {
+	return func(c *ClientConfig) {
+		c.UsageSink = sink
+	}
+}
+
+// WithMergedPrefill makes CreateChatCompletion and CreateChatCompletionFull
+// prepend the prefill to the content of the choices, when the messages of the
+// request end with an assistant message, so the content is the whole answer.
+// Only use it with providers that return the continuation alone: the prefill is
+// prepended even when the content already starts with it. Streams are not
+// affected.
+func WithMergedPrefill() Option {
+	return func(c *ClientConfig) {
+		c.MergePrefill = true
+	}
+}
+
+// WithRequestLogging logs one record per chat, completion, embeddings and
+// responses call to logger, with stable field names for log pipelines; see
+// the LogField constants. Records are redacted by the Redactor of the client.
+func WithRequestLogging(logger *slog.Logger) Option {
+	return func(c *ClientConfig) {
+		c.RequestLogger = logger
+	}
+}
+
+// WithTracer traces chat and text completion calls with tracer, with the
+// attributes of the OpenTelemetry GenAI semantic conventions.
+func WithTracer(tracer Tracer) Option {
+	return func(c *ClientConfig) {
+		c.Tracer = tracer
+	}
+}
+
+// WithTracedContent records the prompts and completions of traced calls as
+// span events. They are redacted by the Redactor of the client, but may still
+// hold personal data, so it is off by default.
+func WithTracedContent() Option {
+	return func(c *ClientConfig) {
+		c.TraceContent = true
+	}
+}
+
+// WithKeyPool spreads the requests of the client over the keys of pool.
+func WithKeyPool(pool *KeyPool) Option {
+	return func(c *ClientConfig) {
+		c.KeyPool = pool
+	}
+}
+
+// WithAuditor records every request of the client, with its redacted body,
+// the caller and the response status, to auditor.
+func WithAuditor(auditor Auditor) Option {
+	return func(c *ClientConfig) {
+		c.Auditor = auditor
+	}
+}
+
+// WithStrictMode makes the client fail instead of silently accepting
+// malformed input: chat completions whose message content is neither a string
+// nor an array of parts fail with ErrUnrecognizedContent, and requests naming
+// an unknown transform with ErrUnknownTransform.
+func WithStrictMode() Option {
+	return func(c *ClientConfig) {
+		c.StrictMode = true
+	}
+}
+
+// WithQuotaManager enforces the limits of manager on the callers set with
+// ContextWithCaller, failing their requests with ErrQuotaExceeded.
+func WithQuotaManager(manager *QuotaManager) Option {
+	return func(c *ClientConfig) {
+		c.QuotaManager = manager
+	}
+}
+
+// WithRawStreamEvents makes stream RecvEvent return every SSE line with its raw
+// bytes, including keep-alive comments and the [DONE] terminator, for proxies
+// that forward streams byte for byte.
+func WithRawStreamEvents() Option {
+	return func(c *ClientConfig) {
+		c.RawStreamEvents = true
+	}
+}
+
+// WithStreamCommentHook calls hook whenever a stream receives a comment line,
+// e.g. "OPENROUTER PROCESSING" while the request waits in a provider queue,
+// so UIs can show that the model is still working. The hook runs on the stream
+// reader goroutine and should return quickly.
+func WithStreamCommentHook(hook func(comment string)) Option {
+	return func(c *ClientConfig) {
+		c.StreamCommentHook = hook
+	}
+}
+
+// WithMiddleOutFallback retries chat and text completion requests that fail
+// because the prompt exceeds the model's context window once, with the
+// TransformMiddleOut transform added so OpenRouter compresses the middle of
+// the prompt. Requests that already use the transform are not retried.
+func WithMiddleOutFallback() Option {
+	return func(c *ClientConfig) {
+		c.MiddleOutOnContextOverflow = true
+	}
+}
+
+// WithCostReconciliation fetches the generation record of every chat and text
+// completion, streamed or not, in the background and passes it to callback
+// keyed by the response ID, since Usage.Cost is not always populated. The
+// record holds the authoritative total cost, cache discount and native token
+// counts. A stream resumed after a disconnect spans several generations, and
+// callback is called for each. callback receives the error instead when the
+// record could not be fetched within a few minutes.
+func WithCostReconciliation(callback func(responseID string, generation Generation, err error)) Option {
+	return func(c *ClientConfig) {
+		c.CostReconciliation = callback
+	}
+}
+
+// WithSemanticCache answers chat completion requests from cache when their
+// last user message is similar enough to one answered before, see SemanticCache.
+func WithSemanticCache(cache *SemanticCache) Option {
+	return func(c *ClientConfig) {
+		c.SemanticCache = cache
+	}
+}
+
+// WithCompletionCache answers chat completion requests identical to one
+// answered before from cache, see CompletionCache. It is consulted before the
+// semantic cache.
+func WithCompletionCache(cache *CompletionCache) Option {
+	return func(c *ClientConfig) {
+		c.CompletionCache = cache
+	}
+}
+
+// WithRedactor sets the Redactor applied to logs and metrics tags, e.g.
+// Redactors{DefaultRedactor, custom} to extend the default, or NoRedaction.
+func WithRedactor(redactor Redactor) Option {
+	return func(c *ClientConfig) {
+		c.Redactor = redactor
+	}
+}
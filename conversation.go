@@ -0,0 +1,58 @@
+package openrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ConversationVersion is the version of the format written by
+// MarshalConversation.
+const ConversationVersion = 1
+
+// conversation is the persisted form of a chat history. Messages use the wire
+// format of the chat completions API, which keeps tool calls, reasoning details
+// and multipart content, and which the API keeps compatible. Version is
+// incremented when a release changes the format incompatibly, and
+// UnmarshalConversation keeps reading every earlier version.
+type conversation struct {
+	Version  int                     `json:"version"`
+	Messages []ChatCompletionMessage `json:"messages"`
+}
+
+// MarshalConversation encodes messages in a stable, versioned JSON format, so
+// chat histories can be stored, e.g. in a database, and resumed with
+// UnmarshalConversation by later versions of the library.
+func MarshalConversation(messages []ChatCompletionMessage) ([]byte, error) {
+	if messages == nil {
+		messages = []ChatCompletionMessage{}
+	}
+	return json.Marshal(conversation{Version: ConversationVersion, Messages: messages})
+}
+
+// UnmarshalConversation decodes messages encoded by MarshalConversation. It
+// also accepts a plain JSON array of messages, as written by json.Marshal, and
+// fails on versions newer than ConversationVersion.
+func UnmarshalConversation(data []byte) ([]ChatCompletionMessage, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var messages []ChatCompletionMessage
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return nil, fmt.Errorf("decode conversation: %w", err)
+		}
+		return messages, nil
+	}
+
+	var c conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("decode conversation: %w", err)
+	}
+	switch {
+	case c.Version < 1:
+		return nil, errors.New("decode conversation: missing version")
+	case c.Version > ConversationVersion:
+		return nil, fmt.Errorf("decode conversation: unsupported version %d, the latest supported is %d",
+			c.Version, ConversationVersion)
+	}
+	return c.Messages, nil
+}
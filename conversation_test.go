@@ -0,0 +1,64 @@
+package openrouter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	reasoning := "The user wants the weather."
+	messages := []openrouter.ChatCompletionMessage{
+		openrouter.SystemMessage("You are helpful."),
+		openrouter.UserMessageWithImage("What is the weather where this was taken?", "https://example.com/a.png"),
+		{
+			Role:      openrouter.ChatMessageRoleAssistant,
+			Reasoning: &reasoning,
+			ReasoningDetails: []openrouter.ChatCompletionReasoningDetails{
+				{Type: openrouter.ReasoningDetailsTypeEncrypted, Data: "opaque", Format: "anthropic-claude-v1"},
+			},
+			ToolCalls: []openrouter.ToolCall{{
+				ID:       "call_1",
+				Type:     openrouter.ToolTypeFunction,
+				Function: openrouter.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`},
+			}},
+		},
+		openrouter.ToolMessage("call_1", `{"celsius":21}`),
+		openrouter.AssistantMessage("It is 21°C in Paris."),
+	}
+
+	data, err := openrouter.MarshalConversation(messages)
+	require.NoError(t, err)
+	restored, err := openrouter.UnmarshalConversation(data)
+	require.NoError(t, err)
+	require.Equal(t, messages, restored)
+
+	plain, err := json.Marshal(messages)
+	require.NoError(t, err)
+	restored, err = openrouter.UnmarshalConversation(plain)
+	require.NoError(t, err)
+	require.Equal(t, messages, restored, "plain arrays of messages are accepted")
+}
+
+func TestConversationFormat(t *testing.T) {
+	t.Parallel()
+
+	data, err := openrouter.MarshalConversation([]openrouter.ChatCompletionMessage{openrouter.UserMessage("hi")})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"version":1,"messages":[{"role":"user","content":"hi"}]}`, string(data))
+
+	data, err = openrouter.MarshalConversation(nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"version":1,"messages":[]}`, string(data))
+
+	_, err = openrouter.UnmarshalConversation([]byte(`{"version":2,"messages":[]}`))
+	require.EqualError(t, err, "decode conversation: unsupported version 2, the latest supported is 1")
+	_, err = openrouter.UnmarshalConversation([]byte(`{"messages":[]}`))
+	require.EqualError(t, err, "decode conversation: missing version")
+	_, err = openrouter.UnmarshalConversation([]byte(`not json`))
+	require.Error(t, err)
+}
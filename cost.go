@@ -0,0 +1,159 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnboundedCompletion is returned by EstimateCost when neither the request
+// nor the model bounds the number of completion tokens.
+var ErrUnboundedCompletion = errors.New("completion length is unbounded: set MaxTokens on the request")
+
+// CostEstimate is the expected cost range in USD of a chat completion request.
+type CostEstimate struct {
+	// PromptTokens is the estimated size of the prompt.
+	PromptTokens int
+	// MaxCompletionTokens is the most tokens the model may generate.
+	MaxCompletionTokens int
+	// Images is the number of images in the prompt.
+	Images int
+	// Min is the cost if the model generates nothing, Max the cost if it
+	// generates MaxCompletionTokens.
+	Min float64
+	Max float64
+}
+
+// EstimateCost estimates the cost of sending request to model before it is
+// sent. The prompt size is approximated from its length, so the estimate is
+// only as precise as that approximation. The completion is bounded by
+// MaxTokens or MaxCompletionTokens, then by the model's limits.
+func EstimateCost(request ChatCompletionRequest, model Model) (CostEstimate, error) {
+	pricing := model.Pricing
+	promptPrice, err := pricing.PromptPerToken()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	completionPrice, err := pricing.CompletionPerToken()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	imagePrice, err := pricing.ImagePerUnit()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	requestPrice, err := pricing.RequestPerUnit()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	if promptPrice < 0 || completionPrice < 0 {
+		return CostEstimate{}, fmt.Errorf("model %s has variable pricing", model.ID)
+	}
+
+	estimate := CostEstimate{
+		PromptTokens: estimatePromptTokens(request),
+		Images:       countImages(request.Messages),
+	}
+	estimate.MaxCompletionTokens, err = completionBound(request, model, estimate.PromptTokens)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	choices := max(request.N, 1)
+	estimate.Min = float64(estimate.PromptTokens)*promptPrice + float64(estimate.Images)*imagePrice + requestPrice
+	estimate.Max = estimate.Min + float64(estimate.MaxCompletionTokens*choices)*completionPrice
+	return estimate, nil
+}
+
+// completionBound returns the most tokens model may generate for request.
+func completionBound(request ChatCompletionRequest, model Model, promptTokens int) (int, error) {
+	limit := request.MaxTokens
+	if limit == 0 {
+		limit = request.MaxCompletionTokens
+	}
+	if maxTokens := model.TopProvider.MaxCompletionTokens; maxTokens != nil && *maxTokens > 0 {
+		if limit == 0 || int64(limit) > *maxTokens {
+			limit = int(*maxTokens)
+		}
+	}
+	if limit == 0 {
+		if model.ContextLength == nil {
+			return 0, ErrUnboundedCompletion
+		}
+		limit = int(*model.ContextLength) - promptTokens
+	}
+	return max(limit, 0), nil
+}
+
+// Heuristic token accounting used when no tokenizer is available: about four
+// characters per token, plus a few tokens of framing per message.
+const (
+	charsPerToken         = 4
+	tokensPerMessage      = 4
+	tokensPerConversation = 3
+)
+
+// estimatePromptTokens approximates the number of prompt tokens of request.
+func estimatePromptTokens(request ChatCompletionRequest) int {
+	tokens := estimateMessageTokens(request.Messages)
+	if len(request.Tools) > 0 {
+		if definitions, err := json.Marshal(request.Tools); err == nil {
+			tokens += estimateTextTokens(string(definitions))
+		}
+	}
+	return tokens
+}
+
+// estimateMessageTokens approximates the number of prompt tokens of messages.
+func estimateMessageTokens(messages []ChatCompletionMessage) int {
+	tokens := tokensPerConversation
+	for _, message := range messages {
+		tokens += tokensPerMessage + estimateTextTokens(message.Role)
+		tokens += estimateTextTokens(message.Content.Text)
+		for _, part := range message.Content.Multi {
+			tokens += estimateTextTokens(part.Text)
+		}
+		for _, call := range message.ToolCalls {
+			tokens += estimateTextTokens(call.Function.Name) + estimateTextTokens(call.Function.Arguments)
+		}
+	}
+	return tokens
+}
+
+func estimateTextTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+func countImages(messages []ChatCompletionMessage) int {
+	var images int
+	for _, message := range messages {
+		for _, part := range message.Content.Multi {
+			if part.ImageURL != nil {
+				images++
+			}
+		}
+	}
+	return images
+}
+
+// costReconciliationTimeout bounds how long reconcileCost waits for a
+// generation record to become available.
+const costReconciliationTimeout = 2 * time.Minute
+
+// reconcileCost passes the generation record of id to the configured
+// CostReconciliation callback, fetching it in the background.
+func (c *Client) reconcileCost(id string) {
+	callback := c.config.CostReconciliation
+	if callback == nil || id == "" {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), costReconciliationTimeout)
+		defer cancel()
+		generation, err := c.WaitForGeneration(ctx, id, nil)
+		callback(id, generation, err)
+	}()
+}
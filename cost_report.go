@@ -0,0 +1,167 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CostTotals is the usage of a group of calls.
+type CostTotals struct {
+	Requests         int     `json:"requests"`
+	Errors           int     `json:"errors"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+func (t *CostTotals) add(event UsageEvent) {
+	t.Requests++
+	if event.Err != nil {
+		t.Errors++
+	}
+	t.PromptTokens += event.PromptTokens
+	t.CompletionTokens += event.CompletionTokens
+	t.Cost += event.Cost
+}
+
+// CostReport is a snapshot of the spend aggregated by a CostReporter.
+type CostReport struct {
+	Since      time.Time             `json:"since"`
+	Until      time.Time             `json:"until"`
+	Total      CostTotals            `json:"total"`
+	ByModel    map[string]CostTotals `json:"by_model"`
+	ByProvider map[string]CostTotals `json:"by_provider"`
+	// ByCaller groups by the caller set with ContextWithCaller; calls without
+	// one are grouped under the empty string.
+	ByCaller map[string]CostTotals `json:"by_caller"`
+	// ByTag groups by the values of the metadata keys of the reporter, keyed
+	// by metadata key and then value.
+	ByTag map[string]map[string]CostTotals `json:"by_tag"`
+}
+
+// CostReporter is a UsageSink aggregating spend by model, provider, caller
+// and request metadata, for chargeback without an external pipeline:
+//
+//	reporter := openrouter.NewCostReporter("team", "feature")
+//	client := openrouter.NewClient(token, openrouter.WithUsageSink(reporter))
+//	// ...
+//	reporter.Snapshot().WriteCSV(w)
+//
+// It is safe for concurrent use.
+type CostReporter struct {
+	tagKeys []string
+
+	mu     sync.Mutex
+	report CostReport
+}
+
+// NewCostReporter returns a reporter grouping spend by the values of the
+// metadata keys tagKeys, in addition to model, provider and caller.
+func NewCostReporter(tagKeys ...string) *CostReporter {
+	r := &CostReporter{tagKeys: tagKeys}
+	r.Reset()
+	return r
+}
+
+func (r *CostReporter) RecordUsage(_ context.Context, event UsageEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.Total.add(event)
+	addCostTotals(r.report.ByModel, event.Model, event)
+	addCostTotals(r.report.ByProvider, event.Provider, event)
+	addCostTotals(r.report.ByCaller, event.Caller, event)
+	for _, key := range r.tagKeys {
+		if value, ok := event.Metadata[key]; ok {
+			addCostTotals(r.report.ByTag[key], value, event)
+		}
+	}
+}
+
+func addCostTotals(groups map[string]CostTotals, key string, event UsageEvent) {
+	totals := groups[key]
+	totals.add(event)
+	groups[key] = totals
+}
+
+// Snapshot returns the spend recorded since the reporter was created or last
+// reset.
+func (r *CostReporter) Snapshot() CostReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.report
+	report.Until = time.Now()
+	report.ByModel = maps.Clone(r.report.ByModel)
+	report.ByProvider = maps.Clone(r.report.ByProvider)
+	report.ByCaller = maps.Clone(r.report.ByCaller)
+	report.ByTag = make(map[string]map[string]CostTotals, len(r.report.ByTag))
+	for key, values := range r.report.ByTag {
+		report.ByTag[key] = maps.Clone(values)
+	}
+	return report
+}
+
+// Reset discards the recorded spend, starting a new period.
+func (r *CostReporter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report = CostReport{
+		Since:      time.Now(),
+		ByModel:    make(map[string]CostTotals),
+		ByProvider: make(map[string]CostTotals),
+		ByCaller:   make(map[string]CostTotals),
+		ByTag:      make(map[string]map[string]CostTotals, len(r.tagKeys)),
+	}
+	for _, key := range r.tagKeys {
+		r.report.ByTag[key] = make(map[string]CostTotals)
+	}
+}
+
+// WriteJSON writes the report to w as JSON.
+func (r CostReport) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// WriteCSV writes the report to w as CSV, one row per group with the columns
+// dimension, key, requests, errors, prompt_tokens, completion_tokens and
+// cost. The dimension is "total", "model", "provider", "caller", or "tag:"
+// followed by the metadata key. Rows are sorted by dimension and key.
+func (r CostReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	row := func(dimension, key string, totals CostTotals) {
+		writer.Write([]string{
+			dimension,
+			key,
+			strconv.Itoa(totals.Requests),
+			strconv.Itoa(totals.Errors),
+			strconv.Itoa(totals.PromptTokens),
+			strconv.Itoa(totals.CompletionTokens),
+			strconv.FormatFloat(totals.Cost, 'f', -1, 64),
+		})
+	}
+	rows := func(dimension string, groups map[string]CostTotals) {
+		for _, key := range slices.Sorted(maps.Keys(groups)) {
+			row(dimension, key, groups[key])
+		}
+	}
+
+	writer.Write([]string{"dimension", "key", "requests", "errors", "prompt_tokens", "completion_tokens", "cost"})
+	row("total", "", r.Total)
+	rows("model", r.ByModel)
+	rows("provider", r.ByProvider)
+	rows("caller", r.ByCaller)
+	for _, key := range slices.Sorted(maps.Keys(r.ByTag)) {
+		rows("tag:"+key, r.ByTag[key])
+	}
+	writer.Flush()
+	return writer.Error()
+}
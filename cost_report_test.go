@@ -0,0 +1,76 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostReporter(t *testing.T) {
+	t.Parallel()
+
+	reporter := NewCostReporter("team")
+	ctx := context.Background()
+	reporter.RecordUsage(ctx, UsageEvent{Model: "openai/gpt-4o", Provider: "OpenAI", Caller: "alice",
+		Metadata: map[string]string{"team": "search"}, PromptTokens: 100, CompletionTokens: 20, Cost: 0.5})
+	reporter.RecordUsage(ctx, UsageEvent{Model: "openai/gpt-4o", Provider: "Azure", Caller: "bob",
+		Metadata: map[string]string{"team": "search", "trace": "1"}, PromptTokens: 50, CompletionTokens: 10, Cost: 0.25})
+	reporter.RecordUsage(ctx, UsageEvent{Model: "anthropic/claude-sonnet-4", Provider: "Anthropic",
+		Err: errors.New("boom")})
+
+	report := reporter.Snapshot()
+	require.Equal(t, CostTotals{Requests: 3, Errors: 1, PromptTokens: 150, CompletionTokens: 30, Cost: 0.75}, report.Total)
+	require.Equal(t, CostTotals{Requests: 2, PromptTokens: 150, CompletionTokens: 30, Cost: 0.75}, report.ByModel["openai/gpt-4o"])
+	require.Equal(t, 0.25, report.ByProvider["Azure"].Cost)
+	require.Equal(t, 1, report.ByCaller[""].Errors)
+	require.Equal(t, map[string]map[string]CostTotals{
+		"team": {"search": {Requests: 2, PromptTokens: 150, CompletionTokens: 30, Cost: 0.75}},
+	}, report.ByTag)
+
+	var csv bytes.Buffer
+	require.NoError(t, report.WriteCSV(&csv))
+	require.Equal(t, `dimension,key,requests,errors,prompt_tokens,completion_tokens,cost
+total,,3,1,150,30,0.75
+model,anthropic/claude-sonnet-4,1,1,0,0,0
+model,openai/gpt-4o,2,0,150,30,0.75
+provider,Anthropic,1,1,0,0,0
+provider,Azure,1,0,50,10,0.25
+provider,OpenAI,1,0,100,20,0.5
+caller,,1,1,0,0,0
+caller,alice,1,0,100,20,0.5
+caller,bob,1,0,50,10,0.25
+tag:team,search,2,0,150,30,0.75
+`, csv.String())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded CostReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, report.ByTag, decoded.ByTag)
+
+	reporter.Reset()
+	require.Zero(t, reporter.Snapshot().Total)
+	require.Equal(t, 2, report.ByModel["openai/gpt-4o"].Requests, "snapshots are not affected by later changes")
+}
+
+func TestCostReporterRecordsMetadata(t *testing.T) {
+	t.Parallel()
+
+	reporter := NewCostReporter("feature")
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusOK, `{"id":"1","model":"openai/gpt-4o","choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"cost":0.1}}`)
+	})
+	client.config.UsageSink = reporter
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+		Metadata: map[string]string{"feature": "summaries"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0.1, reporter.Snapshot().ByTag["feature"]["summaries"].Cost)
+}
@@ -0,0 +1,174 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	t.Parallel()
+
+	contextLength := int64(128000)
+	model := Model{
+		ID:            "openai/gpt-4o-mini",
+		ContextLength: &contextLength,
+		Pricing: ModelPricing{
+			Prompt:     "0.00000015",
+			Completion: "0.0000006",
+			Image:      "0.000217",
+			Request:    "0",
+		},
+	}
+	request := ChatCompletionRequest{
+		Model: model.ID,
+		Messages: []ChatCompletionMessage{
+			SystemMessage(strings.Repeat("a", 400)),
+			{Role: ChatMessageRoleUser, Content: Content{Multi: []ChatMessagePart{
+				{Type: ChatMessagePartTypeText, Text: strings.Repeat("b", 80)},
+				{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+			}}},
+		},
+		MaxTokens: 500,
+	}
+
+	estimate, err := EstimateCost(request, model)
+	require.NoError(t, err)
+	// 3 conversation + 2*4 message framing + 2+1 roles + 100+20 text.
+	require.Equal(t, 134, estimate.PromptTokens)
+	require.Equal(t, 1, estimate.Images)
+	require.Equal(t, 500, estimate.MaxCompletionTokens)
+	require.InDelta(t, 134*0.00000015+0.000217, estimate.Min, 1e-12)
+	require.InDelta(t, estimate.Min+500*0.0000006, estimate.Max, 1e-12)
+
+	request.MaxTokens = 0
+	estimate, err = EstimateCost(request, model)
+	require.NoError(t, err)
+	require.Equal(t, 128000-134, estimate.MaxCompletionTokens)
+
+	maxCompletion := int64(16384)
+	model.TopProvider.MaxCompletionTokens = &maxCompletion
+	estimate, err = EstimateCost(request, model)
+	require.NoError(t, err)
+	require.Equal(t, 16384, estimate.MaxCompletionTokens)
+
+	model.TopProvider.MaxCompletionTokens = nil
+	model.ContextLength = nil
+	_, err = EstimateCost(request, model)
+	require.ErrorIs(t, err, ErrUnboundedCompletion)
+
+	_, err = EstimateCost(request, Model{ID: "openrouter/auto", Pricing: ModelPricing{Prompt: "-1", Completion: "-1"}})
+	require.EqualError(t, err, "model openrouter/auto has variable pricing")
+}
+
+func TestCostReconciliation(t *testing.T) {
+	t.Parallel()
+
+	reconciled := make(chan string, 2)
+	var generationLookups atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		switch req.URL.Path {
+		case "/api/v1/generation":
+			if generationLookups.Add(1) == 1 {
+				return jsonResponse(http.StatusNotFound, generationNotFound)
+			}
+			id := req.URL.Query().Get("id")
+			return jsonResponse(http.StatusOK, `{"data":{"id":"`+id+`","total_cost":0.25,"cache_discount":0.1,"native_tokens_prompt":12}}`)
+		case "/api/v1/chat/completions":
+			var body ChatCompletionRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			if body.Stream {
+				return jsonResponse(http.StatusOK, sseBody(
+					`data: {"id":"gen-stream","choices":[{"delta":{"content":"hi"}}]}`,
+					`data: [DONE]`,
+				))
+			}
+			return jsonResponse(http.StatusOK, `{"id":"gen-chat","choices":[{"message":{"role":"assistant","content":"hi"}}]}`)
+		}
+		return jsonResponse(http.StatusNotFound, `{"error":{"code":404,"message":"unexpected path"}}`)
+	})
+	client.config.CostReconciliation = func(responseID string, generation Generation, err error) {
+		require.NoError(t, err)
+		require.Equal(t, responseID, generation.ID)
+		require.InDelta(t, 0.25, generation.TotalCost, 1e-9)
+		require.Equal(t, 12, *generation.NativeTokensPrompt)
+		reconciled <- responseID
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{Model: "m"})
+	require.NoError(t, err)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{Model: "m"})
+	require.NoError(t, err)
+	for {
+		if _, err := stream.Recv(); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+	}
+	stream.Close()
+
+	var ids []string
+	for range 2 {
+		select {
+		case id := <-reconciled:
+			ids = append(ids, id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("cost was not reconciled")
+		}
+	}
+	require.ElementsMatch(t, []string{"gen-chat", "gen-stream"}, ids)
+}
+
+func TestCostReconciliationOfResumedStream(t *testing.T) {
+	t.Parallel()
+
+	reconciled := make(chan string, 2)
+	var streams atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		if req.URL.Path == "/api/v1/generation" {
+			id := req.URL.Query().Get("id")
+			return jsonResponse(http.StatusOK, `{"data":{"id":"`+id+`","total_cost":0.25}}`)
+		}
+		if streams.Add(1) == 1 {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body: io.NopCloser(io.MultiReader(
+					strings.NewReader(sseBody(`data: {"id":"gen-1","choices":[{"delta":{"content":"Hel"}}]}`)),
+					failingReader{err: io.ErrUnexpectedEOF},
+				)),
+			}
+		}
+		return jsonResponse(http.StatusOK, sseBody(
+			`data: {"id":"gen-2","choices":[{"delta":{"content":"lo"}}]}`,
+			`data: [DONE]`,
+		))
+	})
+	client.config.StreamResumeAttempts = 1
+	client.config.CostReconciliation = func(responseID string, _ Generation, err error) {
+		require.NoError(t, err)
+		reconciled <- responseID
+	}
+
+	err := client.StreamChatCompletion(context.Background(), ChatCompletionRequest{Model: "m"}, StreamHandlers{})
+	require.NoError(t, err)
+
+	var ids []string
+	for range 2 {
+		select {
+		case id := <-reconciled:
+			ids = append(ids, id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("cost was not reconciled")
+		}
+	}
+	require.ElementsMatch(t, []string{"gen-1", "gen-2"}, ids, "every generation of the stream is reconciled")
+}
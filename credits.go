@@ -0,0 +1,147 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const creditsSuffix = "/credits"
+
+// Credits is the credit balance of the account, in USD.
+type Credits struct {
+	TotalCredits float64 `json:"total_credits"`
+	TotalUsage   float64 `json:"total_usage"`
+}
+
+// Remaining returns the credits left to spend.
+func (c Credits) Remaining() float64 {
+	return c.TotalCredits - c.TotalUsage
+}
+
+// GetCredits returns the credits purchased and used by the account.
+// API reference: https://openrouter.ai/docs/api-reference/get-credits
+func (c *Client) GetCredits(ctx context.Context) (Credits, error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(creditsSuffix),
+	)
+	if err != nil {
+		return Credits{}, err
+	}
+
+	var response struct {
+		Data Credits `json:"data"`
+	}
+
+	err = c.sendRequest(req, &response)
+	return response.Data, err
+}
+
+// Pauser is implemented by client-side rate limiters that can hold requests,
+// such as one wrapping the client's HTTPDoer.
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// CreditsMonitorOptions holds the thresholds and callbacks of MonitorCredits.
+// Every field is optional; callbacks are called from the polling goroutine.
+type CreditsMonitorOptions struct {
+	// Thresholds are remaining balances, in USD. OnThreshold is called when
+	// the balance drops below one, and again only after a top-up lifted the
+	// balance back above it.
+	Thresholds  []float64
+	OnThreshold func(threshold float64, credits Credits)
+	// Pauser is paused when the balance is exhausted and resumed once it is
+	// positive again.
+	Pauser Pauser
+	// OnError is called with every polling error. Polling continues at the
+	// next interval.
+	OnError func(err error)
+}
+
+// CreditsMonitor polls the account balance for MonitorCredits.
+type CreditsMonitor struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	credits *Credits
+}
+
+// MonitorCredits polls GetCredits every interval and calls options as the
+// remaining balance crosses its thresholds. Thresholds already crossed at the
+// first poll fire immediately. A zero or negative interval is replaced by
+// DefaultPollInterval. It stops when ctx is done or Stop is called.
+func (c *Client) MonitorCredits(ctx context.Context, interval time.Duration, options CreditsMonitorOptions) *CreditsMonitor {
+	ctx, cancel := context.WithCancel(ctx)
+	m := &CreditsMonitor{cancel: cancel, done: make(chan struct{})}
+	interval = pollInterval(interval)
+
+	thresholds := append([]float64(nil), options.Thresholds...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(thresholds)))
+
+	go func() {
+		defer close(m.done)
+
+		below := make([]bool, len(thresholds))
+		paused := false
+		poll(ctx, interval, func() {
+			credits, err := c.GetCredits(ctx)
+			if err != nil {
+				if ctx.Err() == nil && options.OnError != nil {
+					options.OnError(err)
+				}
+				return
+			}
+			m.mu.Lock()
+			m.credits = &credits
+			m.mu.Unlock()
+
+			remaining := credits.Remaining()
+			for i, threshold := range thresholds {
+				wasBelow := below[i]
+				below[i] = remaining < threshold
+				if below[i] && !wasBelow && options.OnThreshold != nil {
+					options.OnThreshold(threshold, credits)
+				}
+			}
+
+			if options.Pauser == nil {
+				return
+			}
+			if remaining <= 0 && !paused {
+				options.Pauser.Pause()
+				paused = true
+			} else if remaining > 0 && paused {
+				options.Pauser.Resume()
+				paused = false
+			}
+		})
+	}()
+	return m
+}
+
+// Credits returns the balance of the last successful poll, and false before
+// the first one.
+func (m *CreditsMonitor) Credits() (Credits, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.credits == nil {
+		return Credits{}, false
+	}
+	return *m.credits, true
+}
+
+// Stop stops polling and waits for a callback in progress to return. It is
+// safe to call any number of times, but not from a callback.
+func (m *CreditsMonitor) Stop() {
+	m.stopOnce.Do(m.cancel)
+	<-m.done
+}
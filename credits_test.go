@@ -0,0 +1,82 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPauser struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (p *recordingPauser) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, "pause")
+}
+
+func (p *recordingPauser) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, "resume")
+}
+
+func TestGetCredits(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &fakeHTTPClient{response: jsonResponse(http.StatusOK, `{"data":{"total_credits":25,"total_usage":7.5}}`)}
+	cfg := DefaultConfig("test-token")
+	cfg.HTTPClient = httpClient
+	client := NewClientWithConfig(*cfg)
+
+	credits, err := client.GetCredits(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Credits{TotalCredits: 25, TotalUsage: 7.5}, credits)
+	require.InDelta(t, 17.5, credits.Remaining(), 1e-9)
+	require.Equal(t, "/api/v1/credits", httpClient.lastRequest.URL.Path)
+}
+
+func TestMonitorCreditsFiresThresholdsAndPauses(t *testing.T) {
+	t.Parallel()
+
+	usage := []float64{2, 6, 9.5, 10, 10, 1}
+	var polls atomic.Int32
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		n := min(int(polls.Add(1))-1, len(usage)-1)
+		return jsonResponse(http.StatusOK, fmt.Sprintf(`{"data":{"total_credits":10,"total_usage":%g}}`, usage[n]))
+	})
+
+	var (
+		mu      sync.Mutex
+		crossed []float64
+	)
+	pauser := &recordingPauser{}
+	monitor := client.MonitorCredits(context.Background(), time.Millisecond, CreditsMonitorOptions{
+		Thresholds: []float64{1, 5},
+		OnThreshold: func(threshold float64, credits Credits) {
+			mu.Lock()
+			defer mu.Unlock()
+			crossed = append(crossed, threshold)
+		},
+		Pauser: pauser,
+	})
+	require.Eventually(t, func() bool { return polls.Load() > int32(len(usage)+1) }, time.Second, time.Millisecond)
+	monitor.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []float64{5, 1}, crossed)
+	require.Equal(t, []string{"pause", "resume"}, pauser.events)
+
+	credits, ok := monitor.Credits()
+	require.True(t, ok)
+	require.InDelta(t, 9, credits.Remaining(), 1e-9)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 const embeddingsSuffix = "/embeddings"
@@ -105,6 +106,10 @@ func (c *Client) CreateEmbeddings(
 	ctx context.Context,
 	request EmbeddingsRequest,
 ) (EmbeddingsResponse, error) {
+	if err := c.checkQuota(ctx); err != nil {
+		return EmbeddingsResponse{}, err
+	}
+
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
@@ -115,8 +120,23 @@ func (c *Client) CreateEmbeddings(
 		return EmbeddingsResponse{}, err
 	}
 
+	startedAt := time.Now()
 	var response EmbeddingsResponse
-	if err := c.sendRequest(req, &response); err != nil {
+	err = c.sendRequest(req, &response)
+	event := UsageEvent{Endpoint: "embeddings", RequestID: response.ID, Model: response.Model, Err: err}
+	if event.Model == "" {
+		event.Model = request.Model
+	}
+	var usage *Usage
+	if response.Usage != nil {
+		usage = &Usage{
+			PromptTokens: response.Usage.PromptTokens,
+			TotalTokens:  response.Usage.TotalTokens,
+			Cost:         response.Usage.Cost,
+		}
+	}
+	c.recordUsage(ctx, event, usage, startedAt)
+	if err != nil {
 		return EmbeddingsResponse{}, err
 	}
 
@@ -0,0 +1,251 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Default batch limits of CreateEmbeddingsBatched, within what embedding
+// providers accept in one request.
+const (
+	defaultEmbeddingsBatchSize   = 100
+	defaultEmbeddingsBatchTokens = 8000
+)
+
+// EmbeddingsBatchResult is the outcome of CreateEmbeddingsBatched.
+type EmbeddingsBatchResult struct {
+	// Vectors holds the embedding of every text in input order, nil for the
+	// texts that could not be embedded.
+	Vectors [][]float64
+	// Errors holds the error of every text in input order, nil for the texts
+	// that were embedded.
+	Errors []error
+	// Usage sums the usage of every successful request.
+	Usage EmbeddingsUsage
+}
+
+// Failed returns the indexes of the texts that could not be embedded, in
+// ascending order.
+func (r EmbeddingsBatchResult) Failed() []int {
+	var failed []int
+	for i, err := range r.Errors {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// EmbeddingsBatchError is returned by CreateEmbeddingsBatched when some texts
+// could not be embedded. The embeddings of the other texts are still returned.
+type EmbeddingsBatchError struct {
+	// Failed holds the indexes of the texts that could not be embedded.
+	Failed []int
+	// Total is the number of texts.
+	Total int
+	// Errs holds the distinct errors of the failed requests.
+	Errs []error
+}
+
+func (e *EmbeddingsBatchError) Error() string {
+	return fmt.Sprintf("failed to embed %d of %d texts: %v", len(e.Failed), e.Total, errors.Join(e.Errs...))
+}
+
+func (e *EmbeddingsBatchError) Unwrap() []error {
+	return e.Errs
+}
+
+// CreateEmbeddingsBatched embeds texts with model, splitting them into
+// batches of at most 100 texts and 8000 approximate tokens, see BulkBatchSize.
+// Batches run a bounded number at once, and transient failures are retried.
+//
+// Batches rejected because of their texts, e.g. with 413 or a 400 about the
+// input, are split in halves and retried, down to single texts, so that one
+// bad text does not fail its neighbours. Failures that hold for every batch,
+// the key being rejected, out of credits or rate limited, or the request being
+// invalid, such as an unknown model or bad dimensions, fail the remaining
+// batches at once instead of sending requests bound to fail. Other failures
+// are not split. The texts that
+// could not be embedded are reported by an *EmbeddingsBatchError.
+func (c *Client) CreateEmbeddingsBatched(
+	ctx context.Context,
+	model string,
+	texts []string,
+	opts ...BulkOption,
+) (EmbeddingsBatchResult, error) {
+	options := newBulkOptions(opts)
+
+	result := EmbeddingsBatchResult{
+		Vectors: make([][]float64, len(texts)),
+		Errors:  make([]error, len(texts)),
+	}
+	var mu sync.Mutex
+	embed := func(ctx context.Context, batch embeddingsBatch) error {
+		var resp EmbeddingsResponse
+		err := retry(ctx, options.attempts, options.backoff, isTransient, func(int) error {
+			if err := options.wait(ctx); err != nil {
+				return err
+			}
+			var err error
+			resp, err = c.CreateEmbeddings(ctx, EmbeddingsRequest{
+				Model:          model,
+				Input:          texts[batch.start:batch.end],
+				EncodingFormat: EmbeddingsEncodingFormatFloat,
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		vectors := make([][]float64, batch.end-batch.start)
+		for _, data := range resp.Data {
+			if data.Index >= 0 && data.Index < len(vectors) {
+				vectors[data.Index] = data.Embedding.Vector
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if usage := resp.Usage; usage != nil {
+			result.Usage.PromptTokens += usage.PromptTokens
+			result.Usage.TotalTokens += usage.TotalTokens
+			result.Usage.Cost += usage.Cost
+		}
+		for i, vector := range vectors {
+			j := batch.start + i
+			result.Vectors[j], result.Errors[j] = vector, nil
+			if vector == nil {
+				result.Errors[j] = fmt.Errorf("embed text %d: no embedding in the response", j)
+			}
+		}
+		return nil
+	}
+
+	// Each round retries only the failed batches of the previous one, halved.
+	// fatal is the first error that fails every batch.
+	var fatal error
+	pending := splitEmbeddingsBatches(texts, options)
+	for len(pending) > 0 && ctx.Err() == nil {
+		roundCtx, cancel := context.WithCancel(ctx)
+		var failed []embeddingsBatch
+		runBounded(roundCtx, len(pending), options.concurrency, func(ctx context.Context, i int) {
+			batch := pending[i]
+			err := ctx.Err()
+			if err == nil {
+				err = embed(ctx, batch)
+			}
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case fatal == nil && failsAllEmbeddings(err):
+				fatal = err
+				cancel()
+			case fatal != nil && ctx.Err() != nil:
+				// Aborted by the failure of another batch.
+				err = fatal
+			}
+			for j := batch.start; j < batch.end; j++ {
+				result.Errors[j] = fmt.Errorf("embed texts %d to %d: %w", batch.start, batch.end-1, err)
+			}
+			if fatal == nil && rejectsEmbeddingsInput(err) {
+				failed = append(failed, batch)
+			}
+		})
+		cancel()
+		if fatal != nil {
+			break
+		}
+
+		pending = pending[:0]
+		for _, batch := range failed {
+			if n := batch.end - batch.start; n > 1 {
+				mid := batch.start + n/2
+				pending = append(pending, embeddingsBatch{batch.start, mid}, embeddingsBatch{mid, batch.end})
+			}
+		}
+	}
+
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return result, nil
+	}
+	batchErr := &EmbeddingsBatchError{Failed: failed, Total: len(texts)}
+	var last error
+	for _, i := range failed {
+		if err := result.Errors[i]; err != last {
+			batchErr.Errs = append(batchErr.Errs, err)
+			last = err
+		}
+	}
+	return result, batchErr
+}
+
+// rejectsEmbeddingsInput reports whether err may be caused by one of the texts
+// of a batch, so that embedding its halves separately can succeed.
+func rejectsEmbeddingsInput(err error) bool {
+	return IsErrorCode(err, http.StatusRequestEntityTooLarge) ||
+		IsErrorCode(err, http.StatusUnprocessableEntity) ||
+		IsModerated(err) ||
+		IsContextLengthExceeded(err) ||
+		(IsErrorCode(err, http.StatusBadRequest) && namesEmbeddingsInput(err))
+}
+
+// namesEmbeddingsInput reports whether the message of err is about the texts
+// sent rather than about a parameter of the request, such as the model or the
+// dimensions.
+func namesEmbeddingsInput(err error) bool {
+	return apiErrorMentions(err, "input", "text", "token", "too long", "empty") &&
+		!apiErrorMentions(err, "model", "dimension", "encoding_format")
+}
+
+// failsAllEmbeddings reports whether err fails every batch alike: the key is
+// rejected, out of credits or rate limited, or the request itself is invalid,
+// e.g. names an unknown model.
+func failsAllEmbeddings(err error) bool {
+	return IsErrorCode(err, http.StatusUnauthorized) ||
+		(IsErrorCode(err, http.StatusForbidden) && !IsModerated(err)) ||
+		IsErrorCode(err, http.StatusNotFound) ||
+		(IsErrorCode(err, http.StatusBadRequest) && !rejectsEmbeddingsInput(err)) ||
+		IsInsufficientCredits(err) ||
+		IsRateLimited(err)
+}
+
+// embeddingsBatch is the half-open range of texts sent in one request.
+type embeddingsBatch struct {
+	start, end int
+}
+
+// splitEmbeddingsBatches groups consecutive texts within the batch limits of
+// options. A text larger than the token limit gets a batch of its own.
+func splitEmbeddingsBatches(texts []string, options bulkOptions) []embeddingsBatch {
+	maxItems := options.batchSize
+	if maxItems <= 0 {
+		maxItems = defaultEmbeddingsBatchSize
+	}
+	maxTokens := options.batchTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultEmbeddingsBatchTokens
+	}
+
+	var batches []embeddingsBatch
+	start, tokens := 0, 0
+	for i, text := range texts {
+		n := estimateTextTokens(text)
+		if i > start && (i-start >= maxItems || tokens+n > maxTokens) {
+			batches = append(batches, embeddingsBatch{start, i})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	if start < len(texts) {
+		batches = append(batches, embeddingsBatch{start, len(texts)})
+	}
+	return batches
+}
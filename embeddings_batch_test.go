@@ -0,0 +1,164 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// embeddingsHandler embeds every text as a vector holding its length, and
+// rejects batches containing a text starting with "bad".
+func embeddingsHandler(t *testing.T, requests *atomic.Int32) handlerHTTPClient {
+	return func(req *http.Request) *http.Response {
+		requests.Add(1)
+		var body struct {
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+
+		data := make([]string, len(body.Input))
+		for i, text := range body.Input {
+			if strings.HasPrefix(text, "bad") {
+				return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"invalid input"}}`)
+			}
+			data[i] = fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":[%d]}`, i, len(text))
+		}
+		return jsonResponse(http.StatusOK, fmt.Sprintf(
+			`{"data":[%s],"usage":{"prompt_tokens":%d,"total_tokens":%d,"cost":0.001}}`,
+			strings.Join(data, ","), len(body.Input), len(body.Input)))
+	}
+}
+
+func TestCreateEmbeddingsBatched(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newHandlerClient(embeddingsHandler(t, &requests))
+
+	texts := []string{"a", "bb", "ccc", "bad", "eeeee", "ffffff", "g"}
+	result, err := client.CreateEmbeddingsBatched(context.Background(), "openai/text-embedding-3-small", texts,
+		BulkBatchSize(2, 0), BulkConcurrency(2))
+
+	var batchErr *EmbeddingsBatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, []int{3}, batchErr.Failed)
+	require.Equal(t, len(texts), batchErr.Total)
+	require.True(t, IsErrorCode(err, http.StatusBadRequest))
+	require.ErrorContains(t, err, "failed to embed 1 of 7 texts")
+	// The failed batch {ccc, bad} is retried as two single texts.
+	require.Equal(t, int32(6), requests.Load())
+
+	require.Len(t, result.Vectors, len(texts))
+	require.Equal(t, []int{3}, result.Failed())
+	for i, text := range texts {
+		if i == 3 {
+			require.Nil(t, result.Vectors[i])
+			require.ErrorContains(t, result.Errors[i], "embed texts 3 to 3")
+			continue
+		}
+		require.NoError(t, result.Errors[i])
+		require.Equal(t, []float64{float64(len(text))}, result.Vectors[i])
+	}
+	require.Equal(t, 6, result.Usage.PromptTokens)
+	require.InDelta(t, 0.004, result.Usage.Cost, 1e-9)
+}
+
+func TestCreateEmbeddingsBatchedSucceeds(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newHandlerClient(embeddingsHandler(t, &requests))
+
+	result, err := client.CreateEmbeddingsBatched(context.Background(), "openai/text-embedding-3-small", []string{"a", "bb"})
+	require.NoError(t, err)
+	require.Empty(t, result.Failed())
+	require.Equal(t, [][]float64{{1}, {2}}, result.Vectors)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestCreateEmbeddingsBatchedFailsAllOnAuthError(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		requests.Add(1)
+		return jsonResponse(http.StatusUnauthorized, `{"error":{"code":401,"message":"invalid key"}}`)
+	})
+
+	texts := []string{"a", "b", "c", "d", "e", "f"}
+	result, err := client.CreateEmbeddingsBatched(context.Background(), "openai/text-embedding-3-small", texts,
+		BulkBatchSize(2, 0), BulkConcurrency(1))
+
+	var batchErr *EmbeddingsBatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5}, batchErr.Failed)
+	require.Equal(t, int32(1), requests.Load(), "no request is sent after the key is rejected")
+	for i := range texts {
+		require.True(t, IsErrorCode(result.Errors[i], http.StatusUnauthorized), i)
+	}
+	require.ErrorContains(t, result.Errors[5], "embed texts 4 to 5")
+}
+
+func TestCreateEmbeddingsBatchedFailsAllOnRequestError(t *testing.T) {
+	t.Parallel()
+
+	for _, message := range []string{
+		"dimensions must be between 1 and 3072",
+		"openai/text-embedding-4 is not a valid model ID",
+	} {
+		var requests atomic.Int32
+		client := newHandlerClient(func(*http.Request) *http.Response {
+			requests.Add(1)
+			return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"`+message+`"}}`)
+		})
+
+		_, err := client.CreateEmbeddingsBatched(context.Background(), "openai/text-embedding-4",
+			[]string{"a", "b", "c", "d", "e", "f"}, BulkBatchSize(2, 0), BulkConcurrency(1))
+
+		var batchErr *EmbeddingsBatchError
+		require.ErrorAs(t, err, &batchErr, message)
+		require.Len(t, batchErr.Failed, 6, message)
+		require.Equal(t, int32(1), requests.Load(), "%s: batches are not split or sent after a request error", message)
+	}
+}
+
+func TestCreateEmbeddingsBatchedDoesNotSplitServerErrors(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		requests.Add(1)
+		return jsonResponse(http.StatusInternalServerError, `{"error":{"code":500,"message":"internal error"}}`)
+	})
+
+	_, err := client.CreateEmbeddingsBatched(context.Background(), "openai/text-embedding-3-small",
+		[]string{"a", "b", "c", "d"}, BulkBatchSize(2, 0), BulkRetries(1, nil))
+
+	var batchErr *EmbeddingsBatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Failed, 4)
+	require.Equal(t, int32(2), requests.Load())
+}
+
+func TestSplitEmbeddingsBatches(t *testing.T) {
+	t.Parallel()
+
+	texts := []string{
+		strings.Repeat("a", 40), // 10 tokens
+		strings.Repeat("b", 40),
+		strings.Repeat("c", 200), // 50 tokens, over the limit on its own
+		"d",
+		"e",
+		"f",
+	}
+	batches := splitEmbeddingsBatches(texts, newBulkOptions([]BulkOption{BulkBatchSize(2, 25)}))
+	require.Equal(t, []embeddingsBatch{{0, 2}, {2, 3}, {3, 5}, {5, 6}}, batches)
+
+	require.Empty(t, splitEmbeddingsBatches(nil, newBulkOptions(nil)))
+}
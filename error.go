@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -32,6 +35,65 @@ type RequestError struct {
 	Body           []byte
 }
 
+// GenerationError reports a choice that finished with FinishReasonError because
+// the provider aborted the generation, typically after part of the output was
+// already produced.
+type GenerationError struct {
+	// ChoiceIndex is the index of the aborted choice.
+	ChoiceIndex int
+	// Err holds the error details sent by OpenRouter, if any.
+	Err *APIError
+}
+
+// MediaTooLargeError is returned by the message helpers reading audio, images
+// or documents when the media exceeds the size limit given to them.
+type MediaTooLargeError struct {
+	// Limit is the maximum size in bytes.
+	Limit int64
+}
+
+// InsufficientCreditsError is returned for HTTP 402 responses, when the account
+// or API key cannot pay for the request. It unwraps to the *APIError sent by
+// OpenRouter.
+type InsufficientCreditsError struct {
+	Err *APIError
+	// RequestedTokens and AffordableTokens are parsed from messages such as
+	// "You requested up to 4096 tokens, but can only afford 1234". Zero when
+	// not reported.
+	//
+	// OpenRouter reports no amounts in the metadata of 402 errors, so the
+	// message is the only source of these details. Accounts without credits
+	// at all get a message without them.
+	RequestedTokens  int
+	AffordableTokens int
+}
+
+// TokenShortfall returns how many requested tokens cannot be afforded, which
+// is also how much max_tokens must be lowered for the request to succeed.
+func (e *InsufficientCreditsError) TokenShortfall() int {
+	return max(e.RequestedTokens-e.AffordableTokens, 0)
+}
+
+func (e *InsufficientCreditsError) Error() string {
+	return "insufficient credits: " + e.Err.Error()
+}
+
+func (e *InsufficientCreditsError) Unwrap() error {
+	return e.Err
+}
+
+var affordableTokensPattern = regexp.MustCompile(`requested up to (\d+) tokens, but can only afford (\d+)`)
+
+// newInsufficientCreditsError extracts the credit details of a 402 error.
+func newInsufficientCreditsError(apiErr *APIError) *InsufficientCreditsError {
+	e := &InsufficientCreditsError{Err: apiErr}
+	if match := affordableTokensPattern.FindStringSubmatch(apiErr.Message); match != nil {
+		e.RequestedTokens, _ = strconv.Atoi(match[1])
+		e.AffordableTokens, _ = strconv.Atoi(match[2])
+	}
+	return e
+}
+
 type ErrorResponse struct {
 	Error *APIError `json:"error,omitempty"`
 }
@@ -79,6 +141,78 @@ func IsErrorCode(err error, code int) bool {
 	return IsHTTPStatus(err, code) || IsAPIErrorCode(err, code)
 }
 
+// IsRateLimited reports whether err is OpenRouter or the upstream provider
+// rejecting the request for exceeding a rate limit.
+func IsRateLimited(err error) bool {
+	return IsErrorCode(err, http.StatusTooManyRequests) || apiErrorMentions(err, "rate limit", "rate-limit", "rate_limit")
+}
+
+// IsInsufficientCredits reports whether err is caused by the account or API key
+// running out of credits.
+func IsInsufficientCredits(err error) bool {
+	return IsErrorCode(err, http.StatusPaymentRequired)
+}
+
+// IsModerated reports whether err is caused by the input being flagged by the
+// moderation of the chosen model.
+func IsModerated(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Metadata != nil {
+		if _, ok := (*apiErr.Metadata)["flagged_input"]; ok {
+			return true
+		}
+		if _, ok := (*apiErr.Metadata)["reasons"]; ok && IsErrorCode(err, http.StatusForbidden) {
+			return true
+		}
+	}
+	return IsErrorCode(err, http.StatusForbidden) && apiErrorMentions(err, "moderation", "flagged")
+}
+
+// IsContextLengthExceeded reports whether err is caused by the prompt, plus
+// the requested completion, not fitting in the model's context window.
+func IsContextLengthExceeded(err error) bool {
+	return apiErrorMentions(err,
+		"context length",
+		"context_length_exceeded",
+		"context window",
+		"maximum context",
+		"prompt is too long",
+		"too many tokens",
+	)
+}
+
+// IsProviderUnavailable reports whether err is caused by the upstream provider
+// being down, overloaded or unable to serve the model, i.e. a transient
+// failure worth retrying or falling back from.
+func IsProviderUnavailable(err error) bool {
+	for _, code := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, StatusProviderOverloaded} {
+		if IsErrorCode(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiErrorMentions reports whether the message of the APIError in err,
+// including the provider's message, contains any of substrings, ignoring case.
+func apiErrorMentions(err error, substrings ...string) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	message := strings.ToLower(apiErr.Error())
+	for _, substring := range substrings {
+		if strings.Contains(message, substring) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *ProviderError) Message() any {
 	// {"message": "string"}
 	messageAny, ok := (*e)["message"]
@@ -130,14 +264,16 @@ func (e *APIError) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 
-	err = json.Unmarshal(rawMap["message"], &e.Message)
-	if err != nil {
-		var messages []string
-		err = json.Unmarshal(rawMap["message"], &messages)
+	if message, ok := rawMap["message"]; ok {
+		err = json.Unmarshal(message, &e.Message)
 		if err != nil {
-			return
+			var messages []string
+			err = json.Unmarshal(message, &messages)
+			if err != nil {
+				return
+			}
+			e.Message = strings.Join(messages, ", ")
 		}
-		e.Message = strings.Join(messages, ", ")
 	}
 
 	if meta, ok := rawMap["metadata"]; ok {
@@ -173,6 +309,32 @@ func (e *APIError) UnmarshalJSON(data []byte) (err error) {
 	return json.Unmarshal(rawMap["code"], &e.Code)
 }
 
+func (e *GenerationError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("generation aborted by provider, choice: %d", e.ChoiceIndex)
+	}
+	return fmt.Sprintf("generation aborted by provider, choice: %d, %s", e.ChoiceIndex, e.Err.Error())
+}
+
+func (e *GenerationError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// generationError returns a *GenerationError when reason is FinishReasonError.
+func generationError(index int, reason FinishReason, details *APIError) error {
+	if reason != FinishReasonError {
+		return nil
+	}
+	return &GenerationError{ChoiceIndex: index, Err: details}
+}
+
+func (e *MediaTooLargeError) Error() string {
+	return fmt.Sprintf("media exceeds the size limit of %d bytes", e.Limit)
+}
+
 func (e *RequestError) Error() string {
 	return fmt.Sprintf(
 		"error, status code: %d, status: %s, message: %s, body: %s",
@@ -0,0 +1,163 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decodeAPIError(t *testing.T, status int, body string) error {
+	t.Helper()
+
+	var errRes ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &errRes))
+	errRes.Error.HTTPStatusCode = status
+	return fmt.Errorf("create chat completion: %w", errRes.Error)
+}
+
+func TestErrorClassificationHelpers(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := decodeAPIError(t, http.StatusTooManyRequests,
+		`{"error":{"code":429,"message":"Rate limit exceeded: free-models-per-min"}}`)
+	upstreamRateLimited := decodeAPIError(t, http.StatusBadRequest,
+		`{"error":{"code":400,"message":"Provider returned error","metadata":{"raw":"{\"error\":{\"message\":\"Rate limit reached for requests\"}}","provider_name":"OpenAI"}}}`)
+	credits := decodeAPIError(t, http.StatusPaymentRequired,
+		`{"error":{"code":402,"message":"Insufficient credits. Add more using https://openrouter.ai/credits"}}`)
+	moderated := decodeAPIError(t, http.StatusForbidden,
+		`{"error":{"code":403,"message":"openai/gpt-4o requires moderation on OpenAI. Your input was flagged for \"harassment\".","metadata":{"reasons":["harassment"],"flagged_input":"...","provider_name":"OpenAI","model_slug":"openai/gpt-4o"}}}`)
+	contextLength := decodeAPIError(t, http.StatusBadRequest,
+		`{"error":{"code":400,"message":"This endpoint's maximum context length is 128000 tokens. However, you requested about 130211 tokens."}}`)
+	unavailable := decodeAPIError(t, http.StatusServiceUnavailable,
+		`{"error":{"code":503,"message":"No endpoints found that can handle the request"}}`)
+	overloaded := &APIError{Code: StatusProviderOverloaded, Message: "Provider overloaded"}
+
+	tests := []struct {
+		name     string
+		classify func(error) bool
+		matches  []error
+	}{
+		{"IsRateLimited", IsRateLimited, []error{rateLimited, upstreamRateLimited}},
+		{"IsInsufficientCredits", IsInsufficientCredits, []error{credits}},
+		{"IsModerated", IsModerated, []error{moderated}},
+		{"IsContextLengthExceeded", IsContextLengthExceeded, []error{contextLength}},
+		{"IsProviderUnavailable", IsProviderUnavailable, []error{unavailable, overloaded}},
+	}
+	all := []error{rateLimited, upstreamRateLimited, credits, moderated, contextLength, unavailable, overloaded}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, err := range all {
+				want := false
+				for _, match := range tc.matches {
+					want = want || err == match
+				}
+				require.Equal(t, want, tc.classify(err), "%v", err)
+			}
+			require.False(t, tc.classify(nil))
+			require.False(t, tc.classify(errors.New("rate limit in a plain error")))
+		})
+	}
+}
+
+func TestInsufficientCreditsError(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile("testdata/errors/402_max_tokens.json")
+	require.NoError(t, err)
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusPaymentRequired, string(body)))
+
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+
+	var creditsErr *InsufficientCreditsError
+	require.ErrorAs(t, err, &creditsErr)
+	require.Equal(t, 16384, creditsErr.RequestedTokens)
+	require.Equal(t, 8170, creditsErr.AffordableTokens)
+	require.Equal(t, 8214, creditsErr.TokenShortfall())
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusPaymentRequired, apiErr.HTTPStatusCode)
+	require.True(t, IsInsufficientCredits(err))
+}
+
+func TestInsufficientCreditsErrorWithoutDetails(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile("testdata/errors/402_no_credits.json")
+	require.NoError(t, err)
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusPaymentRequired, string(body)))
+
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+
+	var creditsErr *InsufficientCreditsError
+	require.ErrorAs(t, err, &creditsErr)
+	require.Zero(t, creditsErr.RequestedTokens)
+	require.Zero(t, creditsErr.TokenShortfall())
+	require.True(t, strings.HasPrefix(creditsErr.Error(), "insufficient credits: Insufficient credits. This account never purchased credits."))
+}
+
+func TestHandleErrorRespKeepsNonJSONBody(t *testing.T) {
+	t.Parallel()
+
+	html := "<html><body><h1>502 Bad Gateway</h1></body></html>"
+	client, _ := newSequenceClient(t,
+		jsonResponse(http.StatusBadGateway, html),
+		jsonResponse(http.StatusBadGateway, html),
+	)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr)
+	require.Equal(t, http.StatusBadGateway, reqErr.HTTPStatusCode)
+	require.Equal(t, "Bad Gateway", reqErr.HTTPStatus)
+	require.Equal(t, html, string(reqErr.Body))
+	require.Contains(t, err.Error(), "502 Bad Gateway</h1>")
+
+	_, err = client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.ErrorAs(t, err, &reqErr)
+	require.Equal(t, html, string(reqErr.Body))
+	require.True(t, IsProviderUnavailable(err))
+}
+
+func TestHandleErrorRespCapsBody(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusInternalServerError, strings.Repeat("x", 2*maxErrorBodySize)))
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr)
+	require.Len(t, reqErr.Body, maxErrorBodySize)
+}
+
+func TestHandleErrorRespWithoutErrorObject(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusServiceUnavailable, `{"status":"maintenance"}`))
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr)
+	require.Equal(t, `{"status":"maintenance"}`, string(reqErr.Body))
+	require.Contains(t, err.Error(), "unexpected error response")
+}
@@ -0,0 +1,337 @@
+package evals
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+)
+
+// UpdateBaselinesEnv is the environment variable that, set to any value, makes
+// Baselines record new baselines instead of comparing with them:
+//
+//	OPENROUTER_UPDATE_BASELINES=1 go test ./...
+const UpdateBaselinesEnv = "OPENROUTER_UPDATE_BASELINES"
+
+// ErrBaselineStale is returned by Compare when the request changed since its
+// baseline was recorded and live calls are not allowed.
+var ErrBaselineStale = errors.New("request changed since the baseline was recorded")
+
+// Baselines guards prompts against regressions from go test. The first run
+// records the answer to every prompt, and its embedding, as a cassette of the
+// API calls (see openroutertest.Cassette). Later runs replay the cassette, so
+// an unchanged prompt is checked without live calls, and a prompt or model
+// that changed is reported. With Live set, the changed request is answered by
+// the API instead and flagged when the meaning of the answer drifted from the
+// baseline, measured as the cosine distance of their embeddings:
+//
+//	func TestSupportPrompt(t *testing.T) {
+//		baselines := &evals.Baselines{
+//			Dir:            "testdata/baselines",
+//			Config:         openrouter.DefaultConfig(os.Getenv("OPENROUTER_API_KEY")),
+//			EmbeddingModel: "openai/text-embedding-3-small",
+//			Live:           os.Getenv("OPENROUTER_API_KEY") != "",
+//		}
+//		baselines.Check(t, "refund", request)
+//	}
+type Baselines struct {
+	// Dir holds the baselines, one cassette file per name.
+	Dir string
+	// Config configures the client of the calls. It is required to record
+	// baselines and for Live; its HTTPClient is replaced.
+	Config *openrouter.ClientConfig
+	// Transport sends the live calls. Nil uses http.DefaultTransport.
+	Transport http.RoundTripper
+	// EmbeddingModel embeds the answers.
+	EmbeddingModel string
+	// MaxDistance is the largest cosine distance between the embeddings of an
+	// answer and its baseline that is not drift. Defaults to 0.15.
+	MaxDistance float64
+	// Live answers requests that changed since their baseline was recorded
+	// with live calls, to measure the drift. Without it they fail with
+	// ErrBaselineStale.
+	Live bool
+	// Update records new baselines instead of comparing with them. It is also
+	// enabled by UpdateBaselinesEnv.
+	Update bool
+}
+
+// Baseline is a recorded answer, as read from its cassette.
+type Baseline struct {
+	Name  string
+	Model string
+	// PromptHash identifies the messages the answer was recorded with.
+	PromptHash     string
+	Output         string
+	EmbeddingModel string
+	Embedding      []float64
+}
+
+// Drift compares an answer with its baseline.
+type Drift struct {
+	Baseline Baseline
+	Output   string
+	// Distance is the cosine distance between the embeddings of Output and
+	// the baseline, from 0 for the same meaning to 2. It is 0 when the
+	// request did not change, as the answer is then replayed.
+	Distance float64
+	// Drifted is set when Distance exceeds the maximum.
+	Drifted bool
+	// PromptChanged and ModelChanged report what changed since the baseline
+	// was recorded.
+	PromptChanged bool
+	ModelChanged  bool
+	// Recorded is set when there was no baseline, or Update was set, and the
+	// answer was recorded as the baseline.
+	Recorded bool
+}
+
+// Check compares the answer to request with the baseline called name, and
+// fails t when it drifted, the request changed without Live, or the
+// comparison failed.
+func (b *Baselines) Check(t testing.TB, name string, request openrouter.ChatCompletionRequest) Drift {
+	t.Helper()
+
+	drift, err := b.Compare(context.Background(), name, request)
+	switch {
+	case errors.Is(err, ErrBaselineStale):
+		t.Errorf("baseline %s: %v (prompt changed: %t, model changed: %t); set %s to record it again",
+			name, err, drift.PromptChanged, drift.ModelChanged, UpdateBaselinesEnv)
+	case err != nil:
+		t.Errorf("baseline %s: %v", name, err)
+	case drift.Recorded:
+		t.Logf("baseline %s: recorded", name)
+	case drift.Drifted:
+		t.Errorf("baseline %s: answer drifted by %.3f (prompt changed: %t, model changed: %t)\nbaseline: %s\nanswer:   %s",
+			name, drift.Distance, drift.PromptChanged, drift.ModelChanged, drift.Baseline.Output, drift.Output)
+	}
+	return drift
+}
+
+// Compare answers request and compares the answer with the baseline called
+// name, recording it when there is none.
+func (b *Baselines) Compare(ctx context.Context, name string, request openrouter.ChatCompletionRequest) (Drift, error) {
+	cassette, err := openroutertest.LoadCassette(b.path(name))
+	if errors.Is(err, fs.ErrNotExist) || b.Update || os.Getenv(UpdateBaselinesEnv) != "" {
+		return b.record(ctx, name, request)
+	}
+	if err != nil {
+		return Drift{}, err
+	}
+	baseline, err := baselineOf(name, cassette)
+	if err != nil {
+		return Drift{}, err
+	}
+	prompt, err := promptHash(request.Messages)
+	if err != nil {
+		return Drift{}, err
+	}
+	drift := Drift{
+		Baseline:      baseline,
+		PromptChanged: baseline.PromptHash != prompt,
+		ModelChanged:  baseline.Model != request.Model,
+	}
+
+	// The cassette answers only the request it was recorded with.
+	drift.Output, err = answer(ctx, b.client(cassette), request)
+	if err == nil {
+		return drift, nil
+	}
+	if !errors.Is(err, openroutertest.ErrCassetteMiss) {
+		return Drift{}, err
+	}
+	if !b.Live {
+		return drift, ErrBaselineStale
+	}
+
+	if b.Config == nil {
+		return Drift{}, errors.New("live calls need a Config")
+	}
+	live := b.client(b.transport())
+	if drift.Output, err = answer(ctx, live, request); err != nil {
+		return Drift{}, err
+	}
+	embedding, err := b.embed(ctx, live, drift.Output)
+	if err != nil {
+		return Drift{}, err
+	}
+	if baseline.EmbeddingModel != b.EmbeddingModel {
+		if baseline.Embedding, err = b.embed(ctx, live, baseline.Output); err != nil {
+			return Drift{}, err
+		}
+	}
+
+	maxDistance := b.MaxDistance
+	if maxDistance == 0 {
+		maxDistance = 0.15
+	}
+	drift.Distance = 1 - cosineSimilarity(baseline.Embedding, embedding)
+	drift.Drifted = drift.Distance > maxDistance
+	return drift, nil
+}
+
+// record answers request with live calls and saves them as the baseline
+// called name.
+func (b *Baselines) record(ctx context.Context, name string, request openrouter.ChatCompletionRequest) (Drift, error) {
+	if b.Config == nil {
+		return Drift{}, errors.New("recording a baseline needs a Config")
+	}
+	recorder := openroutertest.NewRecorder(b.transport())
+	client := b.client(recorder)
+	output, err := answer(ctx, client, request)
+	if err != nil {
+		return Drift{}, err
+	}
+	if _, err := b.embed(ctx, client, output); err != nil {
+		return Drift{}, err
+	}
+	if err := recorder.Save(b.path(name)); err != nil {
+		return Drift{}, err
+	}
+	baseline, err := baselineOf(name, recorder)
+	if err != nil {
+		return Drift{}, err
+	}
+	return Drift{Baseline: baseline, Output: output, Recorded: true}, nil
+}
+
+// client returns a client of Config, or of the default configuration, sending
+// its requests with transport.
+func (b *Baselines) client(transport http.RoundTripper) *openrouter.Client {
+	config := openrouter.DefaultConfig("")
+	if b.Config != nil {
+		*config = *b.Config
+	}
+	config.HTTPClient = &http.Client{Transport: transport}
+	return openrouter.NewClientWithConfig(*config)
+}
+
+func (b *Baselines) transport() http.RoundTripper {
+	if b.Transport != nil {
+		return b.Transport
+	}
+	return http.DefaultTransport
+}
+
+func answer(ctx context.Context, client openrouter.ClientInterface, request openrouter.ChatCompletionRequest) (string, error) {
+	response, err := client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", errors.New("no choices in the response")
+	}
+	return response.Choices[0].Message.Content.Text, nil
+}
+
+func (b *Baselines) embed(ctx context.Context, client openrouter.ClientInterface, text string) ([]float64, error) {
+	response, err := client.CreateEmbeddings(ctx, openrouter.EmbeddingsRequest{
+		Model: b.EmbeddingModel,
+		Input: text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("embed answer: %w", err)
+	}
+	if len(response.Data) == 0 || len(response.Data[0].Embedding.Vector) == 0 {
+		return nil, errors.New("embed answer: no embedding in the response")
+	}
+	return response.Data[0].Embedding.Vector, nil
+}
+
+func (b *Baselines) path(name string) string {
+	return filepath.Join(b.Dir, name+".json")
+}
+
+// baselineOf reads the baseline called name from the chat completion and
+// embeddings calls recorded in cassette.
+func baselineOf(name string, cassette *openroutertest.Cassette) (Baseline, error) {
+	baseline := Baseline{Name: name}
+	var answered, embedded bool
+	for _, interaction := range cassette.Interactions() {
+		request, response := interaction.Request, interaction.Response
+		switch {
+		case strings.HasSuffix(request.URL, "/chat/completions"):
+			var sent struct {
+				Model    string          `json:"model"`
+				Messages json.RawMessage `json:"messages"`
+			}
+			var received openrouter.ChatCompletionResponse
+			if err := json.Unmarshal([]byte(request.Body), &sent); err != nil {
+				return Baseline{}, fmt.Errorf("decode baseline %s request: %w", name, err)
+			}
+			if err := json.Unmarshal([]byte(response.Body), &received); err != nil || len(received.Choices) == 0 {
+				return Baseline{}, fmt.Errorf("baseline %s has no answer", name)
+			}
+			baseline.Model = sent.Model
+			baseline.PromptHash = hashJSON(sent.Messages)
+			baseline.Output = received.Choices[0].Message.Content.Text
+			answered = true
+		case strings.HasSuffix(request.URL, "/embeddings"):
+			var sent openrouter.EmbeddingsRequest
+			var received openrouter.EmbeddingsResponse
+			if err := json.Unmarshal([]byte(request.Body), &sent); err != nil {
+				return Baseline{}, fmt.Errorf("decode baseline %s embeddings request: %w", name, err)
+			}
+			if err := json.Unmarshal([]byte(response.Body), &received); err != nil || len(received.Data) == 0 {
+				return Baseline{}, fmt.Errorf("baseline %s has no embedding", name)
+			}
+			baseline.EmbeddingModel = sent.Model
+			baseline.Embedding = received.Data[0].Embedding.Vector
+			embedded = true
+		}
+	}
+	if !answered || !embedded {
+		return Baseline{}, fmt.Errorf("baseline %s: cassette lacks the answer or its embedding", name)
+	}
+	return baseline, nil
+}
+
+// promptHash identifies messages by their encoding in a request.
+func promptHash(messages []openrouter.ChatCompletionMessage) (string, error) {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return "", err
+	}
+	return hashJSON(data), nil
+}
+
+func hashJSON(data []byte) string {
+	var compact bytes.Buffer
+	if json.Compact(&compact, data) != nil {
+		compact.Reset()
+		compact.Write(data)
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, 0 when
+// their lengths differ or either is zero.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
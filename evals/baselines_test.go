@@ -0,0 +1,132 @@
+package evals_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/evals"
+	"github.com/stretchr/testify/require"
+)
+
+// embedding returns the body of an embeddings response with vector.
+func embedding(vector ...float64) json.RawMessage {
+	encoded, _ := json.Marshal(vector)
+	return json.RawMessage(`{"data":[{"object":"embedding","index":0,"embedding":` + string(encoded) + `}]}`)
+}
+
+// fakeAPI is a transport answering chat completions and embeddings with the
+// enqueued responses, standing for the live API.
+type fakeAPI struct {
+	mu         sync.Mutex
+	answers    []openrouter.ChatCompletionResponse
+	embeddings []json.RawMessage
+	calls      int
+}
+
+func (f *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	var response any
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/chat/completions") && len(f.answers) > 0:
+		response, f.answers = f.answers[0], f.answers[1:]
+	case strings.HasSuffix(req.URL.Path, "/embeddings") && len(f.embeddings) > 0:
+		response, f.embeddings = f.embeddings[0], f.embeddings[1:]
+	default:
+		return nil, fmt.Errorf("unexpected call to %s", req.URL.Path)
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func (f *fakeAPI) script(text string, embeddings ...json.RawMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.answers = append(f.answers, answer(text))
+	f.embeddings = append(f.embeddings, embeddings...)
+}
+
+func (f *fakeAPI) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestBaselines(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeAPI{}
+	baselines := &evals.Baselines{
+		Dir:            t.TempDir(),
+		Config:         openrouter.DefaultConfig("sk-or-test"),
+		Transport:      api,
+		EmbeddingModel: "openai/text-embedding-3-small",
+	}
+	request := openrouter.ChatCompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Can I get a refund?")},
+	}
+	ctx := context.Background()
+
+	api.script("Yes, within 30 days.", embedding(1, 0, 0))
+	drift, err := baselines.Compare(ctx, "refund", request)
+	require.NoError(t, err)
+	require.True(t, drift.Recorded)
+	require.Equal(t, 2, api.callCount())
+
+	// The unchanged request is replayed from the cassette.
+	drift = baselines.Check(t, "refund", request)
+	require.False(t, drift.Recorded)
+	require.False(t, drift.Drifted)
+	require.False(t, drift.PromptChanged)
+	require.Equal(t, "Yes, within 30 days.", drift.Output)
+	require.Equal(t, []float64{1, 0, 0}, drift.Baseline.Embedding)
+	require.Equal(t, 2, api.callCount(), "a replay must not call the API")
+
+	request.Model = "openai/gpt-4o"
+	request.Messages = []openrouter.ChatCompletionMessage{openrouter.UserMessage("Refund?")}
+	drift, err = baselines.Compare(ctx, "refund", request)
+	require.ErrorIs(t, err, evals.ErrBaselineStale)
+	require.True(t, drift.PromptChanged)
+	require.True(t, drift.ModelChanged)
+	require.Equal(t, 2, api.callCount(), "a changed request is caught without live calls")
+
+	baselines.Live = true
+	api.script("Refunds are not possible.", embedding(0, 1, 0))
+	drift, err = baselines.Compare(ctx, "refund", request)
+	require.NoError(t, err)
+	require.True(t, drift.Drifted)
+	require.InDelta(t, 1, drift.Distance, 1e-9)
+
+	baselines.Update = true
+	api.script("Refunds are not possible.", embedding(0, 1, 0))
+	drift, err = baselines.Compare(ctx, "refund", request)
+	require.NoError(t, err)
+	require.True(t, drift.Recorded)
+
+	baselines.Update = false
+	baselines.EmbeddingModel = "other/embedder"
+	request.Messages = []openrouter.ChatCompletionMessage{openrouter.UserMessage("Refunds?")}
+	api.script("No refunds.", embedding(0, 0.9, 0.1), embedding(0, 1, 0))
+	drift, err = baselines.Compare(ctx, "refund", request)
+	require.NoError(t, err)
+	require.False(t, drift.Drifted)
+	require.Empty(t, api.embeddings, "the baseline is embedded again with the new model")
+}
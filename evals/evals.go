@@ -0,0 +1,214 @@
+// Package evals runs evaluation cases against models through the openrouter
+// client and scores their answers, so prompt and model changes can be gated on
+// the results.
+//
+// A Case holds the messages to send and the Scorers checking the answer:
+// ExactMatch, Regex, JSONSchema, or an LLM Judge. A Suite runs every case
+// against every model and returns a Report, which is written as JSON for
+// other tools:
+//
+//	suite := &evals.Suite{
+//		Client: client,
+//		Models: []string{"openai/gpt-4o-mini", "anthropic/claude-3.5-haiku"},
+//		Cases: []evals.Case{{
+//			Name:     "capital",
+//			Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Capital of France? One word.")},
+//			Scorers:  []evals.Scorer{evals.ExactMatch("Paris")},
+//		}},
+//	}
+//	report, err := suite.Run(ctx)
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	openrouter "github.com/revrost/go-openrouter"
+)
+
+// Case is an input and the properties expected of the answer to it.
+type Case struct {
+	Name     string
+	Messages []openrouter.ChatCompletionMessage
+	// Scorers check the answer. The case passes when all of them pass.
+	Scorers []Scorer
+}
+
+// Suite runs cases against models.
+type Suite struct {
+	Client openrouter.ClientInterface
+	Models []string
+	Cases  []Case
+	// Request is the template of the requests, e.g. to set the temperature.
+	// Its Model and Messages are replaced.
+	Request openrouter.ChatCompletionRequest
+	// Concurrency is the number of requests in flight. Defaults to 1.
+	Concurrency int
+}
+
+// Report is the outcome of a suite run.
+type Report struct {
+	StartedAt time.Time      `json:"started_at"`
+	Duration  time.Duration  `json:"duration_ns"`
+	Results   []Result       `json:"results"`
+	Models    []ModelSummary `json:"models"`
+}
+
+// Result is the outcome of a case for a model.
+type Result struct {
+	Case    string        `json:"case"`
+	Model   string        `json:"model"`
+	Passed  bool          `json:"passed"`
+	Output  string        `json:"output"`
+	Scores  []Score       `json:"scores"`
+	Latency time.Duration `json:"latency_ns"`
+	Tokens  int           `json:"tokens"`
+	Cost    float64       `json:"cost"`
+	// Error is set when the completion failed, and the case is not scored.
+	Error string `json:"error,omitempty"`
+}
+
+// ModelSummary aggregates the results of a model.
+type ModelSummary struct {
+	Model    string  `json:"model"`
+	Cases    int     `json:"cases"`
+	Passed   int     `json:"passed"`
+	Errors   int     `json:"errors"`
+	PassRate float64 `json:"pass_rate"`
+	Cost     float64 `json:"cost"`
+}
+
+// Run runs every case against every model. Failed completions are reported in
+// the results; Run only fails when ctx is done or the suite is invalid.
+func (s *Suite) Run(ctx context.Context) (*Report, error) {
+	if s.Client == nil {
+		return nil, errors.New("evals: suite without a client")
+	}
+	if len(s.Models) == 0 {
+		return nil, errors.New("evals: suite without models")
+	}
+
+	report := &Report{StartedAt: time.Now(), Results: make([]Result, len(s.Cases)*len(s.Models))}
+	concurrency := max(s.Concurrency, 1)
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, c := range s.Cases {
+		for j, model := range s.Models {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return nil, ctx.Err()
+			}
+			wg.Add(1)
+			go func(result *Result) {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+				*result = s.run(ctx, c, model)
+			}(&report.Results[i*len(s.Models)+j])
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	report.Models = summarize(s.Models, report.Results)
+	return report, nil
+}
+
+func (s *Suite) run(ctx context.Context, c Case, model string) Result {
+	result := Result{Case: c.Name, Model: model}
+
+	request := s.Request
+	request.Model = model
+	request.Messages = c.Messages
+	startedAt := time.Now()
+	response, err := s.Client.CreateChatCompletion(ctx, request)
+	result.Latency = time.Since(startedAt)
+	if err == nil && len(response.Choices) == 0 {
+		err = errors.New("no choices in the response")
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if usage := response.Usage; usage != nil {
+		result.Tokens = usage.TotalTokens
+		result.Cost = usage.Cost
+	}
+
+	result.Output = response.Choices[0].Message.Content.Text
+	result.Passed = true
+	for _, scorer := range c.Scorers {
+		score, err := scorer.Score(ctx, c, result.Output)
+		if err != nil {
+			score = Score{Scorer: fmt.Sprintf("%T", scorer), Reason: err.Error()}
+		}
+		result.Scores = append(result.Scores, score)
+		result.Passed = result.Passed && score.Pass
+	}
+	return result
+}
+
+func summarize(models []string, results []Result) []ModelSummary {
+	summaries := make([]ModelSummary, len(models))
+	index := make(map[string]int, len(models))
+	for i, model := range models {
+		summaries[i].Model = model
+		index[model] = i
+	}
+	for _, result := range results {
+		summary := &summaries[index[result.Model]]
+		summary.Cases++
+		summary.Cost += result.Cost
+		switch {
+		case result.Error != "":
+			summary.Errors++
+		case result.Passed:
+			summary.Passed++
+		}
+	}
+	for i := range summaries {
+		if summaries[i].Cases > 0 {
+			summaries[i].PassRate = float64(summaries[i].Passed) / float64(summaries[i].Cases)
+		}
+	}
+	return summaries
+}
+
+// Passed reports whether every case passed for every model.
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the results that did not pass.
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// WriteJSON writes the report to w as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
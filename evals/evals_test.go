@@ -0,0 +1,120 @@
+package evals_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/evals"
+	"github.com/revrost/go-openrouter/jsonschema"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+func answer(text string) openrouter.ChatCompletionResponse {
+	return openroutertest.NewChatResponse().WithContent(text).WithUsage(10, 5, 0.001).Build()
+}
+
+func TestSuiteRun(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	fake.ChatCompletions.
+		Return(answer("Paris"), answer("paris.")).
+		Return(answer("```json\n{\"city\":\"Paris\",\"population\":2100000}\n```"), answer(`{"city":"Paris"}`)).
+		Fail(errors.New("provider down")).
+		Return(answer("Bonjour !"))
+
+	city := jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"city":       {Type: jsonschema.String},
+			"population": {Type: jsonschema.Integer},
+		},
+		Required: []string{"city", "population"},
+	}
+	suite := &evals.Suite{
+		Client: fake,
+		Models: []string{"model/a", "model/b"},
+		Cases: []evals.Case{
+			{
+				Name:     "capital",
+				Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Capital of France?")},
+				Scorers:  []evals.Scorer{evals.ExactMatch("Paris")},
+			},
+			{
+				Name:     "structured",
+				Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Describe Paris as JSON.")},
+				Scorers:  []evals.Scorer{evals.JSONSchema(city)},
+			},
+			{
+				Name:     "greeting",
+				Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Greet me in French.")},
+				Scorers:  []evals.Scorer{evals.Regex(regexp.MustCompile(`(?i)bonjour`))},
+			},
+		},
+	}
+
+	report, err := suite.Run(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.Passed())
+
+	passed := map[string]bool{}
+	for _, result := range report.Results {
+		passed[result.Case+" "+result.Model] = result.Passed
+	}
+	require.Equal(t, map[string]bool{
+		"capital model/a":    true,
+		"capital model/b":    false,
+		"structured model/a": true,
+		"structured model/b": false,
+		"greeting model/a":   false,
+		"greeting model/b":   true,
+	}, passed)
+	require.Equal(t, "provider down", report.Results[4].Error)
+	require.Len(t, report.Failures(), 3)
+
+	require.Equal(t, []evals.ModelSummary{
+		{Model: "model/a", Cases: 3, Passed: 2, Errors: 1, PassRate: 2.0 / 3, Cost: 0.002},
+		{Model: "model/b", Cases: 3, Passed: 1, PassRate: 1.0 / 3, Cost: 0.003},
+	}, report.Models)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded["results"], 6)
+}
+
+func TestJudge(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	fake.ChatCompletions.Return(
+		answer(`{"score": 0.8, "reason": "Polite and mentions the policy."}`),
+		answer(`{"score": 0.2, "reason": "Rude."}`),
+		answer(`not json`),
+	)
+	judge := &evals.Judge{Client: fake, Model: "openai/gpt-4o", Criteria: "The answer is polite."}
+	c := evals.Case{Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Can I get a refund?")}}
+
+	score, err := judge.Score(context.Background(), c, "Of course, within 30 days.")
+	require.NoError(t, err)
+	require.Equal(t, evals.Score{Scorer: "judge", Pass: true, Value: 0.8, Reason: "Polite and mentions the policy."}, score)
+
+	score, err = judge.Score(context.Background(), c, "No.")
+	require.NoError(t, err)
+	require.False(t, score.Pass)
+
+	_, err = judge.Score(context.Background(), c, "No.")
+	require.Error(t, err)
+
+	request := fake.ChatCompletions.Requests()[0]
+	require.Equal(t, "openai/gpt-4o", request.Model)
+	require.Contains(t, request.Messages[0].Content.Text, "The answer is polite.")
+	require.Contains(t, request.Messages[1].Content.Text, "Of course, within 30 days.")
+}
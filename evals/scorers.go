@@ -0,0 +1,146 @@
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/jsonschema"
+)
+
+// Scorer checks an answer to a case.
+type Scorer interface {
+	// Score scores output, the text of the answer to c. An error means the
+	// answer could not be scored, and fails the case.
+	Score(ctx context.Context, c Case, output string) (Score, error)
+}
+
+// Score is the verdict of a Scorer.
+type Score struct {
+	Scorer string `json:"scorer"`
+	Pass   bool   `json:"pass"`
+	// Value is between 0 and 1, 1 being the best.
+	Value  float64 `json:"value"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// ScorerFunc adapts a function to Scorer.
+type ScorerFunc func(ctx context.Context, c Case, output string) (Score, error)
+
+func (f ScorerFunc) Score(ctx context.Context, c Case, output string) (Score, error) {
+	return f(ctx, c, output)
+}
+
+func verdict(scorer string, pass bool, reason string) Score {
+	score := Score{Scorer: scorer, Pass: pass}
+	if pass {
+		score.Value = 1
+	} else {
+		score.Reason = reason
+	}
+	return score
+}
+
+// ExactMatch passes answers equal to expected, ignoring surrounding
+// whitespace.
+func ExactMatch(expected string) Scorer {
+	return ScorerFunc(func(_ context.Context, _ Case, output string) (Score, error) {
+		pass := strings.TrimSpace(output) == strings.TrimSpace(expected)
+		return verdict("exact_match", pass, fmt.Sprintf("expected %q", expected)), nil
+	})
+}
+
+// Regex passes answers matching pattern.
+func Regex(pattern *regexp.Regexp) Scorer {
+	return ScorerFunc(func(_ context.Context, _ Case, output string) (Score, error) {
+		pass := pattern.MatchString(output)
+		return verdict("regex", pass, fmt.Sprintf("does not match %s", pattern)), nil
+	})
+}
+
+// JSONSchema passes answers that are JSON conforming to schema. A Markdown
+// code fence around the JSON is ignored.
+func JSONSchema(schema jsonschema.Definition) Scorer {
+	return ScorerFunc(func(_ context.Context, _ Case, output string) (Score, error) {
+		var data any
+		if err := json.Unmarshal([]byte(stripCodeFence(output)), &data); err != nil {
+			return verdict("json_schema", false, "invalid JSON: "+err.Error()), nil
+		}
+		pass := jsonschema.Validate(schema, data)
+		return verdict("json_schema", pass, "does not conform to the schema"), nil
+	})
+}
+
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if newline := strings.IndexByte(text, '\n'); newline >= 0 {
+		text = text[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// judgePrompt instructs the model of a Judge.
+const judgePrompt = "You grade the answer of an AI assistant to a conversation against the criteria below. " +
+	"Reply with JSON only: {\"score\": <number between 0 and 1>, \"reason\": \"<one sentence>\"}.\n\nCriteria: "
+
+// Judge scores answers with a model, which grades them against Criteria.
+type Judge struct {
+	Client openrouter.ClientInterface
+	Model  string
+	// Criteria the answer is graded against, e.g. "The answer is polite and
+	// mentions the refund policy."
+	Criteria string
+	// Threshold is the lowest passing score. Defaults to 0.5.
+	Threshold float64
+}
+
+func (j *Judge) Score(ctx context.Context, c Case, output string) (Score, error) {
+	var conversation strings.Builder
+	for _, message := range c.Messages {
+		fmt.Fprintf(&conversation, "%s: %s\n", message.Role, message.Content.Text)
+	}
+	fmt.Fprintf(&conversation, "\nAnswer to grade:\n%s", output)
+
+	response, err := j.Client.CreateChatCompletion(ctx, openrouter.ChatCompletionRequest{
+		Model: j.Model,
+		Messages: []openrouter.ChatCompletionMessage{
+			openrouter.SystemMessage(judgePrompt + j.Criteria),
+			openrouter.UserMessage(conversation.String()),
+		},
+		ResponseFormat: &openrouter.ChatCompletionResponseFormat{
+			Type: openrouter.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	})
+	if err != nil {
+		return Score{}, fmt.Errorf("judge: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return Score{}, errors.New("judge: no choices in the response")
+	}
+
+	var grade struct {
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(response.Choices[0].Message.Content.Text)), &grade); err != nil {
+		return Score{}, fmt.Errorf("judge: decode grade: %w", err)
+	}
+	threshold := j.Threshold
+	if threshold == 0 {
+		threshold = 0.5
+	}
+	return Score{
+		Scorer: "judge",
+		Pass:   grade.Score >= threshold,
+		Value:  grade.Score,
+		Reason: grade.Reason,
+	}, nil
+}
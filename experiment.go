@@ -0,0 +1,174 @@
+package openrouter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"time"
+)
+
+// ExperimentVariant is one arm of an Experiment. Zero fields leave the
+// corresponding request field untouched.
+type ExperimentVariant struct {
+	// Name identifies the variant in metrics and request metadata.
+	Name string
+	// Weight is the relative share of calls routed to the variant, e.g. 90
+	// and 10 for a 90/10 split.
+	Weight float64
+
+	Model        string
+	Temperature  *float32
+	SystemPrompt string
+}
+
+// Apply returns a copy of request configured for the variant. A Temperature
+// also sets SendTemperature, so that a temperature of 0 is sent rather than
+// omitted. A SystemPrompt replaces the leading system message, or is prepended
+// when there is none.
+// The experiment and variant names are added to the request metadata.
+func (v ExperimentVariant) Apply(experiment string, request ChatCompletionRequest) ChatCompletionRequest {
+	if v.Model != "" {
+		request.Model = v.Model
+	}
+	if v.Temperature != nil {
+		request.Temperature = *v.Temperature
+		request.SendTemperature = true
+	}
+	if v.SystemPrompt != "" {
+		messages := append([]ChatCompletionMessage(nil), request.Messages...)
+		if len(messages) > 0 && messages[0].Role == ChatMessageRoleSystem {
+			messages[0] = SystemMessage(v.SystemPrompt)
+		} else {
+			messages = append([]ChatCompletionMessage{SystemMessage(v.SystemPrompt)}, messages...)
+		}
+		request.Messages = messages
+	}
+
+	metadata := maps.Clone(request.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string, 2)
+	}
+	metadata["experiment"] = experiment
+	metadata["variant"] = v.Name
+	request.Metadata = metadata
+	return request
+}
+
+// Experiment routes calls between variants in proportion to their weights,
+// to compare prompts and models on live traffic. Results are reported to the
+// client's MetricsSink tagged with the experiment and variant names.
+type Experiment struct {
+	Name     string
+	Variants []ExperimentVariant
+
+	total float64
+	// random returns a number in [0, 1); it is replaceable for tests.
+	random func() float64
+}
+
+// NewExperiment returns an experiment between variants, which must have
+// unique names and positive weights.
+func NewExperiment(name string, variants ...ExperimentVariant) (*Experiment, error) {
+	if len(variants) == 0 {
+		return nil, errors.New("experiment needs at least one variant")
+	}
+
+	names := make(map[string]bool, len(variants))
+	var total float64
+	for _, variant := range variants {
+		if variant.Weight <= 0 {
+			return nil, fmt.Errorf("variant %q: weight must be positive", variant.Name)
+		}
+		if names[variant.Name] {
+			return nil, fmt.Errorf("variant %q: duplicate name", variant.Name)
+		}
+		names[variant.Name] = true
+		total += variant.Weight
+	}
+
+	return &Experiment{Name: name, Variants: variants, total: total, random: rand.Float64}, nil
+}
+
+// Choose picks a variant at random according to the weights.
+func (e *Experiment) Choose() ExperimentVariant {
+	return e.variantAt(e.random())
+}
+
+// ChooseFor picks a variant for key, e.g. a user ID, so that the same key is
+// always routed to the same variant.
+func (e *Experiment) ChooseFor(key string) ExperimentVariant {
+	sum := sha256.Sum256([]byte(e.Name + "\x00" + key))
+	return e.variantAt(float64(binary.BigEndian.Uint64(sum[:])>>11) / (1 << 53))
+}
+
+// variantAt returns the variant covering point, in [0, 1), of the cumulative weights.
+func (e *Experiment) variantAt(point float64) ExperimentVariant {
+	target := point * e.total
+	for _, variant := range e.Variants {
+		if target < variant.Weight {
+			return variant
+		}
+		target -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// ExperimentResult is the outcome of CreateChatCompletionInExperiment.
+type ExperimentResult struct {
+	Variant  ExperimentVariant
+	Response ChatCompletionResponse
+	Latency  time.Duration
+}
+
+// CreateChatCompletionInExperiment sends request with a variant of experiment
+// applied, chosen by ChooseFor(key) or at random when key is empty, and
+// reports the outcome to the MetricsSink.
+func (c *Client) CreateChatCompletionInExperiment(
+	ctx context.Context,
+	experiment *Experiment,
+	key string,
+	request ChatCompletionRequest,
+) (ExperimentResult, error) {
+	variant := experiment.Choose()
+	if key != "" {
+		variant = experiment.ChooseFor(key)
+	}
+
+	startedAt := time.Now()
+	resp, err := c.CreateChatCompletion(ctx, variant.Apply(experiment.Name, request))
+	result := ExperimentResult{Variant: variant, Response: resp, Latency: time.Since(startedAt)}
+	emitExperimentMetrics(c.metricsSink(), experiment.Name, result, err)
+	return result, err
+}
+
+func emitExperimentMetrics(sink MetricsSink, experiment string, result ExperimentResult, err error) {
+	if sink == nil {
+		return
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	tags := map[string]string{
+		"experiment": experiment,
+		"variant":    result.Variant.Name,
+		"model":      result.Response.Model,
+		"outcome":    outcome,
+	}
+	if tags["model"] == "" {
+		tags["model"] = result.Variant.Model
+	}
+
+	sink.IncCounter(MetricExperimentRequests, 1, tags)
+	sink.Observe(MetricExperimentLatency, result.Latency.Seconds(), tags)
+	if usage := result.Response.Usage; usage != nil {
+		sink.Observe(MetricExperimentPromptTokens, float64(usage.PromptTokens), tags)
+		sink.Observe(MetricExperimentCompletionTokens, float64(usage.CompletionTokens), tags)
+		sink.Observe(MetricExperimentCost, usage.Cost, tags)
+	}
+}
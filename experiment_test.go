@@ -0,0 +1,138 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExperimentValidatesVariants(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewExperiment("empty")
+	require.Error(t, err)
+	_, err = NewExperiment("zero", ExperimentVariant{Name: "a"})
+	require.EqualError(t, err, `variant "a": weight must be positive`)
+	_, err = NewExperiment("dup", ExperimentVariant{Name: "a", Weight: 1}, ExperimentVariant{Name: "a", Weight: 1})
+	require.EqualError(t, err, `variant "a": duplicate name`)
+}
+
+func TestExperimentRoutesByWeight(t *testing.T) {
+	t.Parallel()
+
+	experiment, err := NewExperiment("prompt",
+		ExperimentVariant{Name: "control", Weight: 90},
+		ExperimentVariant{Name: "candidate", Weight: 10},
+	)
+	require.NoError(t, err)
+
+	experiment.random = func() float64 { return 0.89 }
+	require.Equal(t, "control", experiment.Choose().Name)
+	experiment.random = func() float64 { return 0.9 }
+	require.Equal(t, "candidate", experiment.Choose().Name)
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		variant := experiment.ChooseFor(key)
+		require.Equal(t, variant, experiment.ChooseFor(key), "routing must be sticky")
+		counts[variant.Name]++
+	}
+	require.InDelta(t, 1000, counts["candidate"], 150)
+}
+
+func TestExperimentVariantApply(t *testing.T) {
+	t.Parallel()
+
+	temperature := float32(0.2)
+	variant := ExperimentVariant{Name: "terse", Model: "openai/gpt-4o-mini", Temperature: &temperature, SystemPrompt: "Be terse."}
+	request := ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{SystemMessage("Be verbose."), UserMessage("hello")},
+		Metadata: map[string]string{"team": "search"},
+	}
+
+	applied := variant.Apply("tone", request)
+	require.Equal(t, "openai/gpt-4o-mini", applied.Model)
+	require.Equal(t, temperature, applied.Temperature)
+	require.Equal(t, "Be terse.", applied.Messages[0].Content.Text)
+	require.Len(t, applied.Messages, 2)
+	require.Equal(t, map[string]string{"team": "search", "experiment": "tone", "variant": "terse"}, applied.Metadata)
+
+	require.Equal(t, "Be verbose.", request.Messages[0].Content.Text, "request must not be modified")
+	require.Len(t, request.Metadata, 1)
+
+	applied = ExperimentVariant{Name: "v", SystemPrompt: "Be terse."}.Apply("tone", ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.Len(t, applied.Messages, 2)
+	require.Equal(t, ChatMessageRoleSystem, applied.Messages[0].Role)
+
+	zero := float32(0)
+	request.ExtraBody = map[string]any{"top_a": 0.1}
+	applied = ExperimentVariant{Name: "greedy", Temperature: &zero}.Apply("tone", request)
+	require.True(t, applied.SendTemperature)
+	require.Equal(t, request.ExtraBody, applied.ExtraBody, "ExtraBody must be left alone")
+	sent := encodeRequest(t, applied)
+	require.Equal(t, 0.0, sent["temperature"], "an explicit 0 must be sent")
+	require.Equal(t, 0.1, sent["top_a"])
+
+	// A decoded request keeps sending its temperature of 0.
+	data, err := json.Marshal(applied)
+	require.NoError(t, err)
+	var decoded ChatCompletionRequest
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, 0.0, encodeRequest(t, decoded)["temperature"])
+
+	// The temperature stays the request's own to change.
+	applied.Temperature = 0.7
+	require.InDelta(t, 0.7, encodeRequest(t, applied)["temperature"], 1e-6)
+	applied.Temperature, applied.SendTemperature = 0, false
+	require.NotContains(t, encodeRequest(t, applied), "temperature")
+}
+
+// encodeRequest returns the JSON object request is sent as.
+func encodeRequest(t *testing.T, request ChatCompletionRequest) map[string]any {
+	t.Helper()
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	var sent map[string]any
+	require.NoError(t, json.Unmarshal(body, &sent))
+	return sent
+}
+
+func TestCreateChatCompletionInExperimentEmitsMetrics(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, jsonResponse(http.StatusOK,
+		`{"id":"1","model":"openai/gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7,"cost":0.001}}`))
+	sink := &recordingMetricsSink{}
+	client.config.MetricsSink = sink
+
+	experiment, err := NewExperiment("models",
+		ExperimentVariant{Name: "mini", Weight: 1, Model: "openai/gpt-4o-mini"},
+	)
+	require.NoError(t, err)
+
+	result, err := client.CreateChatCompletionInExperiment(context.Background(), experiment, "user-1", ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "mini", result.Variant.Name)
+	require.Equal(t, "openai/gpt-4o-mini", httpClient.requests[0].Model)
+	require.Equal(t, "mini", httpClient.requests[0].Metadata["variant"])
+
+	requests := sink.observed(MetricExperimentRequests)
+	require.Len(t, requests, 1)
+	require.Equal(t, map[string]string{
+		"experiment": "models", "variant": "mini", "model": "openai/gpt-4o-mini", "outcome": "success",
+	}, requests[0].tags)
+	cost := sink.observed(MetricExperimentCost)
+	require.Len(t, cost, 1)
+	require.InDelta(t, 0.001, cost[0].value, 1e-12)
+	require.Len(t, sink.observed(MetricExperimentLatency), 1)
+}
@@ -0,0 +1,128 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrExtraFieldConflict is returned when encoding a request whose
+// ExtraFields set a key it already has.
+var ErrExtraFieldConflict = errors.New("extra field conflicts with a request field")
+
+// MarshalJSON encodes the request with its ExtraBody and ExtraFields merged in,
+// and a temperature of 0 when SendTemperature is set.
+func (r ChatCompletionRequest) MarshalJSON() ([]byte, error) {
+	type request ChatCompletionRequest
+	data, err := json.Marshal(request(r))
+	if err != nil {
+		return nil, err
+	}
+	if r.SendTemperature && r.Temperature == 0 {
+		if data, err = mergeExtraBody(data, map[string]any{"temperature": 0}); err != nil {
+			return nil, err
+		}
+	}
+	if data, err = mergeExtraBody(data, r.ExtraBody); err != nil {
+		return nil, err
+	}
+	return mergeExtraFields(data, r.ExtraFields, chatCompletionRequestFields())
+}
+
+// UnmarshalJSON decodes the request, setting SendTemperature when it has a
+// temperature of 0 so that encoding it again still sends it.
+func (r *ChatCompletionRequest) UnmarshalJSON(data []byte) error {
+	type request ChatCompletionRequest
+	var decoded request
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	var temperature struct {
+		Temperature *float32 `json:"temperature"`
+	}
+	if err := json.Unmarshal(data, &temperature); err != nil {
+		return err
+	}
+	*r = ChatCompletionRequest(decoded)
+	r.SendTemperature = temperature.Temperature != nil && *temperature.Temperature == 0
+	return nil
+}
+
+// MarshalJSON encodes the provider routing with its ExtraBody merged in.
+func (p ChatProvider) MarshalJSON() ([]byte, error) {
+	type provider ChatProvider
+	data, err := json.Marshal(provider(p))
+	if err != nil {
+		return nil, err
+	}
+	return mergeExtraBody(data, p.ExtraBody)
+}
+
+// mergeExtraBody sets the keys of extra in the JSON object data, replacing
+// the fields it already has.
+func mergeExtraBody(data []byte, extra map[string]any) ([]byte, error) {
+	if len(extra) == 0 {
+		return data, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = encoded
+	}
+	return json.Marshal(object)
+}
+
+// mergeExtraFields adds the keys of extra to the JSON object data, failing
+// with ErrExtraFieldConflict for a key data has or that is in fields, even
+// when the field is omitted as empty.
+func mergeExtraFields(data []byte, extra map[string]any, fields map[string]struct{}) ([]byte, error) {
+	if len(extra) == 0 {
+		return data, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		_, present := object[key]
+		if _, known := fields[key]; present || known {
+			return nil, fmt.Errorf("%w: %q", ErrExtraFieldConflict, key)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = encoded
+	}
+	return json.Marshal(object)
+}
+
+var chatCompletionRequestFields = sync.OnceValue(func() map[string]struct{} {
+	return jsonFieldNames(reflect.TypeOf(ChatCompletionRequest{}))
+})
+
+// jsonFieldNames returns the names of the JSON object fields of the struct t.
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{}, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraBody(t *testing.T) {
+	t.Parallel()
+
+	request := ChatCompletionRequest{
+		Model:       "anthropic/claude-sonnet-4",
+		Messages:    []ChatCompletionMessage{UserMessage("Hello")},
+		Temperature: 0.5,
+		Provider: &ChatProvider{
+			Order:     []string{"anthropic"},
+			ExtraBody: map[string]any{"anthropic": map[string]any{"beta": "context-1m"}},
+		},
+		ExtraBody: map[string]any{"verbosity": "low", "temperature": 0.2},
+	}
+
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(data, &body))
+	require.Equal(t, "low", body["verbosity"])
+	require.Equal(t, 0.2, body["temperature"], "extra body overrides fields")
+	require.Equal(t, map[string]any{
+		"order":     []any{"anthropic"},
+		"anthropic": map[string]any{"beta": "context-1m"},
+	}, body["provider"])
+	require.NotContains(t, body, "ExtraBody")
+
+	data, err = json.Marshal(ChatCompletionRequest{Model: "openai/gpt-4o"})
+	require.NoError(t, err)
+	require.Equal(t, `{"model":"openai/gpt-4o","messages":null}`, string(data))
+}
+
+func TestExtraBodyIsSent(t *testing.T) {
+	t.Parallel()
+
+	var body []byte
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		body, _ = io.ReadAll(req.Body)
+		return completionResponse("ok")
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages:  []ChatCompletionMessage{UserMessage("Hello")},
+		ExtraBody: map[string]any{"verbosity": "low"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"verbosity":"low"`)
+}
+
+func TestExtraFields(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(ChatCompletionRequest{
+		Model:       "openai/gpt-5",
+		ExtraFields: map[string]any{"reasoning_effort_v2": "minimal"},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"model":"openai/gpt-5","messages":null,"reasoning_effort_v2":"minimal"}`, string(data))
+
+	for name, request := range map[string]ChatCompletionRequest{
+		"set field":   {Model: "openai/gpt-5", ExtraFields: map[string]any{"model": "openai/gpt-4o"}},
+		"empty field": {ExtraFields: map[string]any{"temperature": 0.2}},
+		"extra body":  {ExtraBody: map[string]any{"beta": true}, ExtraFields: map[string]any{"beta": false}},
+	} {
+		_, err := json.Marshal(request)
+		require.ErrorIs(t, err, ErrExtraFieldConflict, name)
+	}
+}
@@ -0,0 +1,141 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// FewShotExample is a labeled example of the task, shown to the model as a
+// user message with Input answered by an assistant message with Output.
+type FewShotExample struct {
+	Input  string
+	Output string
+	// Vector is the embedding of Input. FewShotPool.Add computes it when nil.
+	Vector []float64
+}
+
+// FewShotPool holds labeled examples and selects those most relevant to an
+// input by the similarity of their embeddings. It is safe for concurrent use.
+type FewShotPool struct {
+	client         *Client
+	embeddingModel string
+
+	mu       sync.RWMutex
+	examples []FewShotExample
+}
+
+// NewFewShotPool returns an empty pool embedding inputs with embeddingModel.
+func (c *Client) NewFewShotPool(embeddingModel string) *FewShotPool {
+	return &FewShotPool{client: c, embeddingModel: embeddingModel}
+}
+
+// Add embeds the inputs of the examples without a Vector and adds them to the
+// pool. No example is added when an input cannot be embedded.
+func (p *FewShotPool) Add(ctx context.Context, examples ...FewShotExample) error {
+	examples = append([]FewShotExample(nil), examples...)
+	var missing []int
+	var texts []string
+	for i, example := range examples {
+		if example.Vector == nil {
+			missing = append(missing, i)
+			texts = append(texts, example.Input)
+		}
+	}
+
+	if len(texts) > 0 {
+		result, err := p.client.CreateEmbeddingsBatched(ctx, p.embeddingModel, texts)
+		if err != nil {
+			return err
+		}
+		for j, i := range missing {
+			examples[i].Vector = result.Vectors[j]
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.examples = append(p.examples, examples...)
+	return nil
+}
+
+// Len returns the number of examples in the pool.
+func (p *FewShotPool) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.examples)
+}
+
+// Select returns the k examples most similar to input, most similar first.
+func (p *FewShotPool) Select(ctx context.Context, input string, k int) ([]FewShotExample, error) {
+	if k <= 0 || p.Len() == 0 {
+		return nil, nil
+	}
+
+	resp, err := p.client.CreateEmbeddings(ctx, EmbeddingsRequest{
+		Model:          p.embeddingModel,
+		Input:          input,
+		EncodingFormat: EmbeddingsEncodingFormatFloat,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 || len(resp.Data[0].Embedding.Vector) == 0 {
+		return nil, errors.New("no embedding in the response")
+	}
+	vector := resp.Data[0].Embedding.Vector
+
+	p.mu.RLock()
+	type scored struct {
+		example    FewShotExample
+		similarity float64
+	}
+	candidates := make([]scored, len(p.examples))
+	for i, example := range p.examples {
+		candidates[i] = scored{example, cosineSimilarity(vector, example.Vector)}
+	}
+	p.mu.RUnlock()
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+	selected := make([]FewShotExample, 0, min(k, len(candidates)))
+	for _, candidate := range candidates[:cap(selected)] {
+		selected = append(selected, candidate.example)
+	}
+	return selected, nil
+}
+
+// Messages returns messages with the k examples most similar to the text of
+// the last message spliced in after the leading system messages, as pairs of
+// user and assistant messages. The most similar example comes last, closest to
+// the input. messages is not modified.
+func (p *FewShotPool) Messages(ctx context.Context, messages []ChatCompletionMessage, k int) ([]ChatCompletionMessage, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	examples, err := p.Select(ctx, messageText(messages[len(messages)-1]), k)
+	if err != nil {
+		return nil, err
+	}
+
+	at := leadingSystemMessages(messages)
+	spliced := make([]ChatCompletionMessage, 0, len(messages)+2*len(examples))
+	spliced = append(spliced, messages[:at]...)
+	for i := len(examples) - 1; i >= 0; i-- {
+		spliced = append(spliced, UserMessage(examples[i].Input), AssistantMessage(examples[i].Output))
+	}
+	return append(spliced, messages[at:]...), nil
+}
+
+// messageText returns the text content of message, joining its text parts.
+func messageText(message ChatCompletionMessage) string {
+	text := message.Content.Text
+	for _, part := range message.Content.Multi {
+		if part.Type == ChatMessagePartTypeText {
+			text += part.Text
+		}
+	}
+	return text
+}
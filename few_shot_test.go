@@ -0,0 +1,94 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// topicEmbeddingsHandler embeds texts on two axes, fruit and weather.
+func topicEmbeddingsHandler(t *testing.T, requests *atomic.Int32) handlerHTTPClient {
+	return func(req *http.Request) *http.Response {
+		requests.Add(1)
+		var body struct {
+			Input json.RawMessage `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		var texts []string
+		if err := json.Unmarshal(body.Input, &texts); err != nil {
+			var text string
+			require.NoError(t, json.Unmarshal(body.Input, &text))
+			texts = []string{text}
+		}
+
+		data := make([]string, len(texts))
+		for i, text := range texts {
+			var fruit, weather float64 = 0.1, 0.1
+			if strings.Contains(text, "apple") || strings.Contains(text, "pear") {
+				fruit = 1
+			}
+			if strings.Contains(text, "rain") || strings.Contains(text, "sun") {
+				weather = 1
+			}
+			data[i] = fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":[%g,%g]}`, i, fruit, weather)
+		}
+		return jsonResponse(http.StatusOK, fmt.Sprintf(`{"data":[%s]}`, strings.Join(data, ",")))
+	}
+}
+
+func TestFewShotPool(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newHandlerClient(topicEmbeddingsHandler(t, &requests))
+	pool := client.NewFewShotPool("openai/text-embedding-3-small")
+
+	ctx := context.Background()
+	require.NoError(t, pool.Add(ctx,
+		FewShotExample{Input: "I love apples", Output: "fruit"},
+		FewShotExample{Input: "It will rain", Output: "weather"},
+		FewShotExample{Input: "Pears are sweet", Output: "fruit"},
+		FewShotExample{Input: "Stocks fell", Output: "finance", Vector: []float64{-1, -1}},
+	))
+	require.Equal(t, 4, pool.Len())
+	require.Equal(t, int32(1), requests.Load(), "inputs are embedded in one batch")
+
+	selected, err := pool.Select(ctx, "Sunny with rain later", 2)
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	require.Equal(t, "weather", selected[0].Output)
+
+	selected, err = pool.Select(ctx, "an apple a day", 10)
+	require.NoError(t, err)
+	require.Len(t, selected, 4)
+	require.Equal(t, "finance", selected[3].Output)
+
+	messages := []ChatCompletionMessage{SystemMessage("Classify the topic."), UserMessage("a pear")}
+	spliced, err := pool.Messages(ctx, messages, 2)
+	require.NoError(t, err)
+	require.Len(t, spliced, 6)
+	require.Equal(t, messages[0], spliced[0])
+	require.Equal(t, ChatMessageRoleUser, spliced[1].Role)
+	require.Equal(t, AssistantMessage("fruit"), spliced[2])
+	require.Equal(t, AssistantMessage("fruit"), spliced[4])
+	require.Equal(t, messages[1], spliced[5])
+	require.Len(t, messages, 2, "messages is not modified")
+}
+
+func TestFewShotPoolEmpty(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	pool := newHandlerClient(topicEmbeddingsHandler(t, &requests)).NewFewShotPool("m")
+	messages := []ChatCompletionMessage{UserMessage("hi")}
+	spliced, err := pool.Messages(context.Background(), messages, 3)
+	require.NoError(t, err)
+	require.Equal(t, messages, spliced)
+	require.Zero(t, requests.Load(), "an empty pool embeds nothing")
+}
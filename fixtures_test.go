@@ -0,0 +1,69 @@
+package openrouter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+type responseDoer func() *http.Response
+
+func (f responseDoer) Do(*http.Request) (*http.Response, error) {
+	return f(), nil
+}
+
+func fixtureClient(name string) *openrouter.Client {
+	config := openrouter.DefaultConfig("test-token")
+	config.HTTPClient = responseDoer(func() *http.Response { return openroutertest.FixtureResponse(name) })
+	return openrouter.NewClientWithConfig(*config)
+}
+
+var fixtureRequest = openrouter.ChatCompletionRequest{
+	Model:    "openai/gpt-4o",
+	Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Hello")},
+}
+
+func TestFixtureResponsesDecode(t *testing.T) {
+	t.Parallel()
+
+	resp, err := fixtureClient(openroutertest.FixtureChatReasoning).CreateChatCompletion(context.Background(), fixtureRequest)
+	require.NoError(t, err)
+	message := resp.Choices[0].Message
+	require.Equal(t, "There are three r's in \"strawberry\".", message.Content.Text)
+	require.NotNil(t, message.Reasoning)
+	require.Equal(t, openrouter.ReasoningDetailsTypeText, message.ReasoningDetails[0].Type)
+	require.Equal(t, 84, resp.Usage.CompletionTokenDetails.ReasoningTokens)
+
+	resp, err = fixtureClient(openroutertest.FixtureChatToolCalls).CreateChatCompletion(context.Background(), fixtureRequest)
+	require.NoError(t, err)
+	require.Equal(t, openrouter.FinishReasonToolCalls, resp.Choices[0].FinishReason)
+	require.Len(t, resp.Choices[0].Message.ToolCalls, 2)
+	require.JSONEq(t, `{"city":"Tokyo","unit":"celsius"}`, resp.Choices[0].Message.ToolCalls[1].Function.Arguments)
+}
+
+func TestFixtureErrorsClassify(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]func(error) bool{
+		openroutertest.FixtureErrorModeration:          openrouter.IsModerated,
+		openroutertest.FixtureErrorInsufficientCredits: openrouter.IsInsufficientCredits,
+		openroutertest.FixtureErrorRateLimit:           openrouter.IsRateLimited,
+		openroutertest.FixtureErrorProvider: func(err error) bool {
+			return openrouter.IsErrorCode(err, http.StatusBadGateway)
+		},
+	}
+	for name, classify := range cases {
+		_, err := fixtureClient(name).CreateChatCompletion(context.Background(), fixtureRequest)
+		require.True(t, classify(err), "%s: %v", name, err)
+	}
+
+	_, err := fixtureClient(openroutertest.FixtureErrorInsufficientCredits).CreateChatCompletion(context.Background(), fixtureRequest)
+	var creditsErr *openrouter.InsufficientCreditsError
+	require.True(t, errors.As(err, &creditsErr))
+	require.Equal(t, 2862, creditsErr.TokenShortfall())
+}
@@ -2,8 +2,11 @@ package openrouter
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"sync"
 )
 
 const (
@@ -63,3 +66,75 @@ func (c *Client) GetGeneration(ctx context.Context, id string) (generation Gener
 	generation = response.Data
 	return
 }
+
+// WaitForGeneration polls GetGeneration until the record of a just finished
+// generation becomes available, which may take a few seconds. It waits backoff
+// between attempts, ExponentialBackoff(500ms, 10s) when nil, and retries
+// not found, rate limit, server and transport errors until ctx is done. Other
+// errors, such as an invalid key or a record that cannot be decoded, are
+// returned at once.
+func (c *Client) WaitForGeneration(ctx context.Context, id string, backoff BackoffFunc) (Generation, error) {
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+	retryable := func(err error) bool {
+		return IsErrorCode(err, http.StatusNotFound) || isTransient(err)
+	}
+
+	var generation Generation
+	err := retry(ctx, math.MaxInt, backoff, retryable, func(int) error {
+		var err error
+		generation, err = c.GetGeneration(ctx, id)
+		return err
+	})
+	return generation, err
+}
+
+// GetGenerations fetches the records of many generations, running a bounded
+// number of requests at once and retrying transient failures; see
+// BulkRateLimit to stay under the API rate limit. It returns the records keyed
+// by ID, and the error of every ID that could not be fetched, nil when all were.
+func (c *Client) GetGenerations(
+	ctx context.Context,
+	ids []string,
+	opts ...BulkOption,
+) (map[string]Generation, map[string]error) {
+	options := newBulkOptions(opts)
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	var mu sync.Mutex
+	generations := make(map[string]Generation, len(unique))
+	var errs map[string]error
+	runBounded(ctx, len(unique), options.concurrency, func(ctx context.Context, i int) {
+		id := unique[i]
+		var generation Generation
+		err := retry(ctx, options.attempts, options.backoff, isTransient, func(int) error {
+			if err := options.wait(ctx); err != nil {
+				return err
+			}
+			var err error
+			generation, err = c.GetGeneration(ctx, id)
+			return err
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[id] = fmt.Errorf("get generation %s: %w", id, err)
+			return
+		}
+		generations[id] = generation
+	})
+	return generations, errs
+}
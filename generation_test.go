@@ -0,0 +1,122 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const generationNotFound = `{"error":{"code":404,"message":"Generation not found"}}`
+
+func TestWaitForGenerationPollsUntilFound(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "gen-1", req.URL.Query().Get("id"))
+		if attempts.Add(1) < 3 {
+			return jsonResponse(http.StatusNotFound, generationNotFound)
+		}
+		return jsonResponse(http.StatusOK, `{"data":{"id":"gen-1","total_cost":0.002}}`)
+	})
+
+	generation, err := client.WaitForGeneration(context.Background(), "gen-1", ExponentialBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, "gen-1", generation.ID)
+	require.Equal(t, int32(3), attempts.Load())
+}
+
+func TestWaitForGenerationStopsAtDeadline(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusNotFound, generationNotFound)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.WaitForGeneration(ctx, "gen-1", ExponentialBackoff(time.Millisecond, 5*time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitForGenerationReturnsPermanentErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		attempts.Add(1)
+		return jsonResponse(http.StatusUnauthorized, `{"error":{"code":401,"message":"No auth credentials found"}}`)
+	})
+
+	_, err := client.WaitForGeneration(context.Background(), "gen-1", nil)
+	require.True(t, IsErrorCode(err, http.StatusUnauthorized))
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+func TestWaitForGenerationReturnsLocalErrorsWithoutDeadline(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		attempts.Add(1)
+		return jsonResponse(http.StatusOK, `{"data":{"id":1}}`)
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.WaitForGeneration(context.Background(), "gen-1", ExponentialBackoff(time.Millisecond, time.Millisecond))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		var typeErr *json.UnmarshalTypeError
+		require.ErrorAs(t, err, &typeErr)
+		require.Equal(t, int32(1), attempts.Load())
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForGeneration kept retrying a decode error")
+	}
+}
+
+func TestGetGenerations(t *testing.T) {
+	t.Parallel()
+
+	var (
+		requests atomic.Int32
+		flaky    atomic.Bool
+	)
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		requests.Add(1)
+		switch id := req.URL.Query().Get("id"); id {
+		case "missing":
+			return jsonResponse(http.StatusNotFound, generationNotFound)
+		case "flaky":
+			if flaky.CompareAndSwap(false, true) {
+				return jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"bad gateway"}}`)
+			}
+			fallthrough
+		default:
+			return jsonResponse(http.StatusOK, `{"data":{"id":"`+id+`","total_cost":0.5}}`)
+		}
+	})
+
+	startedAt := time.Now()
+	generations, errs := client.GetGenerations(context.Background(), []string{"a", "b", "a", "flaky", "missing"},
+		BulkConcurrency(3), BulkRateLimit(200), BulkRetries(2, ExponentialBackoff(time.Millisecond, time.Millisecond)))
+
+	require.Len(t, generations, 3)
+	for _, id := range []string{"a", "b", "flaky"} {
+		require.Equal(t, id, generations[id].ID)
+	}
+	require.Len(t, errs, 1)
+	require.True(t, IsErrorCode(errs["missing"], http.StatusNotFound))
+	require.ErrorContains(t, errs["missing"], "get generation missing")
+
+	// Duplicates are fetched once; the retry of flaky is rate limited too.
+	require.Equal(t, int32(5), requests.Load())
+	require.GreaterOrEqual(t, time.Since(startedAt), 4*5*time.Millisecond)
+}
@@ -0,0 +1,154 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/revrost/go-openrouter/jsonschema"
+)
+
+// Validator checks the text of a response and returns an error describing
+// the violation, which is sent to the model as feedback when retrying.
+type Validator func(text string) error
+
+// DenyPatterns returns a Validator rejecting responses matching any of
+// patterns, e.g. leaked secrets or banned phrases.
+func DenyPatterns(patterns ...*regexp.Regexp) Validator {
+	return func(text string) error {
+		for _, pattern := range patterns {
+			if pattern.MatchString(text) {
+				return fmt.Errorf("the response must not match %s", pattern)
+			}
+		}
+		return nil
+	}
+}
+
+// MaxResponseLength returns a Validator rejecting responses longer than n
+// characters.
+func MaxResponseLength(n int) Validator {
+	return func(text string) error {
+		if length := utf8.RuneCountInString(text); length > n {
+			return fmt.Errorf("the response must be at most %d characters long, it was %d", n, length)
+		}
+		return nil
+	}
+}
+
+// MatchJSONSchema returns a Validator rejecting responses that are not JSON
+// matching schema.
+func MatchJSONSchema(schema jsonschema.Definition) Validator {
+	return func(text string) error {
+		var data any
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			return fmt.Errorf("the response must be valid JSON: %w", err)
+		}
+		if !jsonschema.Validate(schema, data) {
+			return errors.New("the response must match the JSON schema")
+		}
+		return nil
+	}
+}
+
+// Guardrails configures CreateChatCompletionWithGuardrails.
+type Guardrails struct {
+	// Validators are run on the text of the first choice of every response.
+	Validators []Validator
+	// MaxRetries is the number of times a violating response is retried, with
+	// the response and its violations appended to the conversation.
+	MaxRetries int
+	// Feedback returns the user message asking the model to correct the
+	// violations. Defaults to a message listing them.
+	Feedback func(violations []error) ChatCompletionMessage
+}
+
+// GuardrailAttempt is a response and the violations found in it.
+type GuardrailAttempt struct {
+	Response   ChatCompletionResponse
+	Violations []error
+}
+
+// GuardrailResult is the outcome of CreateChatCompletionWithGuardrails.
+type GuardrailResult struct {
+	// Response is the last response, which passed every validator unless the
+	// error is a *GuardrailError.
+	Response ChatCompletionResponse
+	// Attempts holds every response in order, the last one included.
+	Attempts []GuardrailAttempt
+}
+
+// GuardrailError is returned by CreateChatCompletionWithGuardrails when every
+// attempt violated a validator. It unwraps to the violations of the last one.
+type GuardrailError struct {
+	Attempts []GuardrailAttempt
+}
+
+func (e *GuardrailError) Error() string {
+	last := e.Attempts[len(e.Attempts)-1]
+	return fmt.Sprintf("response violated guardrails after %d attempts: %v", len(e.Attempts), errors.Join(last.Violations...))
+}
+
+func (e *GuardrailError) Unwrap() []error {
+	return e.Attempts[len(e.Attempts)-1].Violations
+}
+
+// CreateChatCompletionWithGuardrails creates a chat completion and runs the
+// validators of guardrails on it. While the response violates any, and up to
+// MaxRetries times, the request is sent again with the response and a message
+// describing the violations appended, so the model can correct itself. When
+// every attempt fails validation, the error is a *GuardrailError.
+func (c *Client) CreateChatCompletionWithGuardrails(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	guardrails Guardrails,
+) (GuardrailResult, error) {
+	feedback := guardrails.Feedback
+	if feedback == nil {
+		feedback = defaultGuardrailFeedback
+	}
+	request.Messages = append([]ChatCompletionMessage(nil), request.Messages...)
+
+	var result GuardrailResult
+	for attempt := 0; ; attempt++ {
+		resp, err := c.CreateChatCompletion(ctx, request)
+		if err != nil {
+			return result, err
+		}
+		if len(resp.Choices) == 0 {
+			return result, errors.New("no choices in the response")
+		}
+		result.Response = resp
+
+		message := AssistantMessageFromChoice(resp.Choices[0])
+		var violations []error
+		for _, validate := range guardrails.Validators {
+			if err := validate(messageText(message)); err != nil {
+				violations = append(violations, err)
+			}
+		}
+		result.Attempts = append(result.Attempts, GuardrailAttempt{Response: resp, Violations: violations})
+		if len(violations) == 0 {
+			return result, nil
+		}
+		if attempt >= guardrails.MaxRetries {
+			return result, &GuardrailError{Attempts: result.Attempts}
+		}
+
+		request.Messages = append(request.Messages, message, feedback(violations))
+	}
+}
+
+func defaultGuardrailFeedback(violations []error) ChatCompletionMessage {
+	var b strings.Builder
+	b.WriteString("Your previous response broke these rules:\n")
+	for _, violation := range violations {
+		fmt.Fprintf(&b, "- %v\n", violation)
+	}
+	b.WriteString("Answer again, following every rule.")
+	return UserMessage(b.String())
+}
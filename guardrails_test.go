@@ -0,0 +1,83 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/revrost/go-openrouter/jsonschema"
+	"github.com/stretchr/testify/require"
+)
+
+func completionResponse(content string) *http.Response {
+	body := fmt.Sprintf(`{"id":"gen","choices":[{"message":{"role":"assistant","content":%q}}]}`, content)
+	return jsonResponse(http.StatusOK, body)
+}
+
+func TestCreateChatCompletionWithGuardrails(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		completionResponse("My key is sk-or-v1-abc, and this answer is long."),
+		completionResponse(`{"answer": 42}`),
+	)
+	guardrails := Guardrails{
+		Validators: []Validator{
+			DenyPatterns(regexp.MustCompile(`sk-or-v1-\w+`)),
+			MaxResponseLength(20),
+			MatchJSONSchema(jsonschema.Definition{
+				Type:       jsonschema.Object,
+				Properties: map[string]jsonschema.Definition{"answer": {Type: jsonschema.Integer}},
+				Required:   []string{"answer"},
+			}),
+		},
+		MaxRetries: 2,
+	}
+
+	result, err := client.CreateChatCompletionWithGuardrails(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Answer in JSON.")},
+	}, guardrails)
+	require.NoError(t, err)
+	require.Equal(t, `{"answer": 42}`, result.Response.Choices[0].Message.Content.Text)
+	require.Len(t, result.Attempts, 2)
+	require.Len(t, result.Attempts[0].Violations, 3)
+	require.Empty(t, result.Attempts[1].Violations)
+
+	retry := httpClient.requests[1].Messages
+	require.Len(t, retry, 3)
+	require.Equal(t, ChatMessageRoleAssistant, retry[1].Role)
+	require.Equal(t, ChatMessageRoleUser, retry[2].Role)
+	require.Contains(t, retry[2].Content.Text, "- the response must not match sk-or-v1-\\w+\n")
+	require.Contains(t, retry[2].Content.Text, "- the response must be at most 20 characters long, it was 48\n")
+	require.Contains(t, retry[2].Content.Text, "- the response must be valid JSON")
+}
+
+func TestCreateChatCompletionWithGuardrailsGivesUp(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, completionResponse("too long"), completionResponse("still long"))
+	custom := errors.New("custom violation")
+	result, err := client.CreateChatCompletionWithGuardrails(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Be brief.")},
+	}, Guardrails{
+		Validators: []Validator{MaxResponseLength(3), func(string) error { return custom }},
+		MaxRetries: 1,
+		Feedback: func([]error) ChatCompletionMessage {
+			return UserMessage("Shorter.")
+		},
+	})
+
+	var guardrailErr *GuardrailError
+	require.ErrorAs(t, err, &guardrailErr)
+	require.ErrorIs(t, err, custom)
+	require.Len(t, result.Attempts, 2)
+	require.Equal(t, "still long", result.Response.Choices[0].Message.Content.Text)
+	require.Equal(t, UserMessage("Shorter."), httpClient.requests[1].Messages[2])
+	require.EqualError(t, err, "response violated guardrails after 2 attempts: "+
+		"the response must be at most 3 characters long, it was 10\ncustom violation")
+}
@@ -0,0 +1,161 @@
+package openrouter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // decodes GIF images for downscaling
+	"image/jpeg"
+)
+
+// ImageOption configures how the image helpers prepare an image before it is
+// base64 encoded. Without options images are sent as they are.
+type ImageOption func(*imageOptions)
+
+type imageOptions struct {
+	maxDimension int
+	maxBytes     int
+	quality      int
+}
+
+// ImageMaxDimension downscales images whose width or height exceeds pixels so
+// that their longest side is pixels, keeping the aspect ratio. Vision models
+// bill by the tile, so this bounds the prompt tokens of an image.
+func ImageMaxDimension(pixels int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxDimension = pixels
+	}
+}
+
+// ImageMaxBytes re-encodes images larger than n bytes as JPEG, downscaling
+// them further until they fit. The helpers fail with a *MediaTooLargeError
+// when they cannot.
+func ImageMaxBytes(n int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxBytes = n
+	}
+}
+
+// ImageJPEGQuality sets the JPEG quality from 1 to 100 of re-encoded images,
+// jpeg.DefaultQuality by default.
+func ImageJPEGQuality(quality int) ImageOption {
+	return func(o *imageOptions) {
+		o.quality = quality
+	}
+}
+
+func newImageOptions(opts []ImageOption) imageOptions {
+	options := imageOptions{quality: jpeg.DefaultQuality}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.quality <= 0 {
+		options.quality = jpeg.DefaultQuality
+	}
+	return options
+}
+
+// minDownscaleDimension is the longest side below which ImageMaxBytes gives up
+// shrinking an image.
+const minDownscaleDimension = 16
+
+// DownscaleImage returns data, a PNG, JPEG or GIF, resized and re-encoded as
+// JPEG according to opts when it exceeds their limits, and unchanged
+// otherwise. Transparent areas are flattened onto white, and animated GIFs
+// keep their first frame. Other formats, such as WebP, cannot be decoded and
+// are returned unchanged unless they exceed ImageMaxBytes.
+func DownscaleImage(data []byte, opts ...ImageOption) ([]byte, error) {
+	options := newImageOptions(opts)
+	if options.maxDimension <= 0 && options.maxBytes <= 0 {
+		return data, nil
+	}
+	fits := func(encoded []byte) bool {
+		return options.maxBytes <= 0 || len(encoded) <= options.maxBytes
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if fits(data) {
+			return data, nil
+		}
+		return nil, &MediaTooLargeError{Limit: int64(options.maxBytes)}
+	}
+	longest := max(config.Width, config.Height)
+	if fits(data) && (options.maxDimension <= 0 || longest <= options.maxDimension) {
+		return data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	target := longest
+	if options.maxDimension > 0 {
+		target = min(target, options.maxDimension)
+	}
+	for {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, FitImage(img, target), &jpeg.Options{Quality: options.quality}); err != nil {
+			return nil, fmt.Errorf("encode image: %w", err)
+		}
+		if fits(buf.Bytes()) {
+			return buf.Bytes(), nil
+		}
+		if target <= minDownscaleDimension {
+			return nil, &MediaTooLargeError{Limit: int64(options.maxBytes)}
+		}
+		target = max(target*3/4, minDownscaleDimension)
+	}
+}
+
+// FitImage returns img flattened onto white and, when its width or height
+// exceeds maxDimension, downscaled so that its longest side is maxDimension,
+// keeping the aspect ratio. It is meant for images sent with
+// UserMessageWithGoImage. Pixels are averaged over the area they cover, which
+// keeps text in screenshots legible.
+func FitImage(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	longest := max(width, height)
+	if maxDimension <= 0 || longest <= maxDimension {
+		return src
+	}
+	dstWidth := max(width*maxDimension/longest, 1)
+	dstHeight := max(height*maxDimension/longest, 1)
+	return boxResize(src, dstWidth, dstHeight)
+}
+
+// boxResize shrinks src to width by height, each pixel being the average of
+// the source pixels it covers.
+func boxResize(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,117 @@
+package openrouter_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// noiseImage returns an image that compresses poorly.
+func noiseImage(width, height int) *image.RGBA {
+	rnd := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rnd.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func TestDownscaleImage(t *testing.T) {
+	t.Parallel()
+
+	large := encodePNG(t, noiseImage(400, 200))
+
+	unchanged, err := openrouter.DownscaleImage(large)
+	require.NoError(t, err)
+	require.Equal(t, large, unchanged)
+	unchanged, err = openrouter.DownscaleImage(large, openrouter.ImageMaxDimension(400))
+	require.NoError(t, err)
+	require.Equal(t, large, unchanged)
+
+	resized, err := openrouter.DownscaleImage(large, openrouter.ImageMaxDimension(100))
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(resized))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+
+	low, err := openrouter.DownscaleImage(large, openrouter.ImageMaxDimension(100), openrouter.ImageJPEGQuality(10))
+	require.NoError(t, err)
+	require.Less(t, len(low), len(resized))
+
+	small, err := openrouter.DownscaleImage(large, openrouter.ImageMaxBytes(4096))
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(small), 4096)
+	img, err = jpeg.Decode(bytes.NewReader(small))
+	require.NoError(t, err)
+	require.Less(t, img.Bounds().Dx(), 400)
+	require.Equal(t, img.Bounds().Dx(), 2*img.Bounds().Dy())
+
+	_, err = openrouter.DownscaleImage(large, openrouter.ImageMaxBytes(100))
+	var tooLarge *openrouter.MediaTooLargeError
+	require.True(t, errors.As(err, &tooLarge), err)
+	require.EqualValues(t, 100, tooLarge.Limit)
+
+	webp := []byte("RIFF\x24\x00\x00\x00WEBPVP8 ")
+	unchanged, err = openrouter.DownscaleImage(webp, openrouter.ImageMaxDimension(10))
+	require.NoError(t, err)
+	require.Equal(t, webp, unchanged)
+	_, err = openrouter.DownscaleImage(webp, openrouter.ImageMaxBytes(8))
+	require.True(t, errors.As(err, &tooLarge), err)
+}
+
+func TestFitImage(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		img.Set(x, 10, color.NRGBA{R: 200, A: 255})
+		img.Set(x, 11, color.NRGBA{B: 100, A: 255})
+	}
+	img.Set(13, 11, color.NRGBA{})
+
+	fitted := openrouter.FitImage(img, 2)
+	require.Equal(t, image.Rect(0, 0, 2, 1), fitted.Bounds())
+	require.Equal(t, color.RGBA{R: 100, B: 50, A: 255}, fitted.At(0, 0))
+	// The transparent pixel is flattened onto white.
+	require.Equal(t, color.RGBA{R: 163, G: 63, B: 88, A: 255}, fitted.At(1, 0))
+
+	same := openrouter.FitImage(img, 0)
+	require.Equal(t, image.Rect(0, 0, 4, 2), same.Bounds())
+	require.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, same.At(3, 1))
+}
+
+func TestUserMessageWithImageBytesDownscaled(t *testing.T) {
+	t.Parallel()
+
+	msg, err := openrouter.UserMessageWithImageBytes("Describe this.", encodePNG(t, noiseImage(300, 300)),
+		openrouter.ImageMaxDimension(30))
+	require.NoError(t, err)
+	url := msg.Content.Multi[1].ImageURL.URL
+	require.True(t, strings.HasPrefix(url, "data:image/jpeg;base64,"), url)
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(url, "data:image/jpeg;base64,"))
+	require.NoError(t, err)
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, 30, config.Width)
+
+	_, err = openrouter.UserMessageWithImageBytes("Describe this.", []byte("%PDF-1.7"), openrouter.ImageMaxBytes(1))
+	require.EqualError(t, err, "unsupported image format: application/pdf")
+}
@@ -0,0 +1,131 @@
+package openrouter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+)
+
+// ImageFormat is the encoding of an image.Image sent by UserMessageWithGoImage.
+type ImageFormat string
+
+const (
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatJPEG ImageFormat = "jpeg"
+)
+
+// supportedImageTypes are the MIME types of images accepted by vision models.
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+// UserMessageWithImageFromFile creates a user message with the given prompt text and image file.
+// It reads the image file (PNG, JPEG, WebP or GIF) and creates a message with the embedded image data,
+// downscaled according to opts.
+func UserMessageWithImageFromFile(promptText, filePath string, opts ...ImageOption) (ChatCompletionMessage, error) {
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+
+	return UserMessageWithImageBytes(promptText, fileData, opts...)
+}
+
+// UserMessageWithImageBytes creates a user message with the given prompt text and image content.
+// The image format (PNG, JPEG, WebP or GIF) is detected from the content, and the image is
+// downscaled according to opts; see DownscaleImage.
+func UserMessageWithImageBytes(promptText string, image []byte, opts ...ImageOption) (ChatCompletionMessage, error) {
+	if _, err := imageMIMEType(image); err != nil {
+		return ChatCompletionMessage{}, err
+	}
+	image, err := DownscaleImage(image, opts...)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+	dataURL, err := imageDataURL(image)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+
+	return UserMessageWithImage(promptText, dataURL), nil
+}
+
+// imageDataURL returns the base64 data URL of image, whose format is detected
+// from its content.
+func imageDataURL(image []byte) (string, error) {
+	mimeType, err := imageMIMEType(image)
+	if err != nil {
+		return "", err
+	}
+
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(image), nil
+}
+
+// imageMIMEType returns the MIME type of image, failing for formats vision
+// models do not accept.
+func imageMIMEType(image []byte) (string, error) {
+	mimeType := http.DetectContentType(image)
+	if !supportedImageTypes[mimeType] {
+		return "", fmt.Errorf("unsupported image format: %s", mimeType)
+	}
+	return mimeType, nil
+}
+
+// UserMessageWithGoImage creates a user message with the given prompt text and an in-memory image,
+// e.g. a screenshot or a rendered chart, encoded in the given format. Quality is the JPEG quality
+// from 1 to 100, or jpeg.DefaultQuality when zero, and is ignored for PNG.
+func UserMessageWithGoImage(promptText string, img image.Image, format ImageFormat, quality int) (ChatCompletionMessage, error) {
+	part, err := ChatMessagePartWithGoImage(img, format, quality)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+
+	return ChatCompletionMessage{
+		Role: ChatMessageRoleUser,
+		Content: Content{
+			Multi: []ChatMessagePart{
+				{
+					Type: ChatMessagePartTypeText,
+					Text: promptText,
+				},
+				part,
+			},
+		},
+	}, nil
+}
+
+// ChatMessagePartWithGoImage creates an image ChatMessagePart from an in-memory image, encoded as
+// for UserMessageWithGoImage, for messages with several images.
+func ChatMessagePartWithGoImage(img image.Image, format ImageFormat, quality int) (ChatMessagePart, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case ImageFormatPNG:
+		err = png.Encode(&buf, img)
+	case ImageFormatJPEG:
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	default:
+		return ChatMessagePart{}, fmt.Errorf("unsupported image format: %s", format)
+	}
+	if err != nil {
+		return ChatMessagePart{}, fmt.Errorf("encode image: %w", err)
+	}
+
+	return ChatMessagePart{
+		Type: ChatMessagePartTypeImageURL,
+		ImageURL: &ChatMessageImageURL{
+			URL: "data:image/" + string(format) + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+		},
+	}, nil
+}
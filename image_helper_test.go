@@ -0,0 +1,94 @@
+package openrouter_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserMessageWithImageBytes(t *testing.T) {
+	t.Parallel()
+
+	images := map[string][]byte{
+		"image/png":  []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+		"image/jpeg": {0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10, 'J', 'F', 'I', 'F'},
+		"image/webp": []byte("RIFF\x24\x00\x00\x00WEBPVP8 "),
+		"image/gif":  []byte("GIF89a\x01\x00\x01\x00"),
+	}
+	for mimeType, image := range images {
+		msg, err := openrouter.UserMessageWithImageBytes("Describe this.", image)
+		require.NoError(t, err, mimeType)
+		require.Equal(t, openrouter.ChatMessageRoleUser, msg.Role)
+		require.Len(t, msg.Content.Multi, 2)
+		require.Equal(t, "Describe this.", msg.Content.Multi[0].Text)
+		require.Equal(t, openrouter.ChatMessagePartTypeImageURL, msg.Content.Multi[1].Type)
+		require.Equal(t,
+			"data:"+mimeType+";base64,"+base64.StdEncoding.EncodeToString(image),
+			msg.Content.Multi[1].ImageURL.URL)
+	}
+
+	_, err := openrouter.UserMessageWithImageBytes("Describe this.", []byte("%PDF-1.7"))
+	require.EqualError(t, err, "unsupported image format: application/pdf")
+}
+
+func TestUserMessageWithImageFromFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "image")
+	require.NoError(t, os.WriteFile(path, []byte("GIF87a\x01\x00\x01\x00"), 0o600))
+
+	msg, err := openrouter.UserMessageWithImageFromFile("Describe this.", path)
+	require.NoError(t, err)
+	require.Contains(t, msg.Content.Multi[1].ImageURL.URL, "data:image/gif;base64,")
+
+	_, err = openrouter.UserMessageWithImageFromFile("Describe this.", filepath.Join(t.TempDir(), "missing.png"))
+	require.Error(t, err)
+}
+
+func TestUserMessageWithGoImage(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: 128, A: 255})
+		}
+	}
+	decode := func(msg openrouter.ChatCompletionMessage, prefix string) []byte {
+		t.Helper()
+		url := msg.Content.Multi[1].ImageURL.URL
+		require.True(t, strings.HasPrefix(url, prefix), url)
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(url, prefix))
+		require.NoError(t, err)
+		return data
+	}
+
+	msg, err := openrouter.UserMessageWithGoImage("Describe this.", img, openrouter.ImageFormatPNG, 0)
+	require.NoError(t, err)
+	require.Equal(t, "Describe this.", msg.Content.Multi[0].Text)
+	decoded, err := png.Decode(bytes.NewReader(decode(msg, "data:image/png;base64,")))
+	require.NoError(t, err)
+	require.Equal(t, img.Bounds(), decoded.Bounds())
+
+	low, err := openrouter.UserMessageWithGoImage("Describe this.", img, openrouter.ImageFormatJPEG, 10)
+	require.NoError(t, err)
+	high, err := openrouter.UserMessageWithGoImage("Describe this.", img, openrouter.ImageFormatJPEG, 100)
+	require.NoError(t, err)
+	lowData, highData := decode(low, "data:image/jpeg;base64,"), decode(high, "data:image/jpeg;base64,")
+	require.Less(t, len(lowData), len(highData))
+	_, err = jpeg.Decode(bytes.NewReader(lowData))
+	require.NoError(t, err)
+
+	_, err = openrouter.UserMessageWithGoImage("Describe this.", img, "bmp", 0)
+	require.EqualError(t, err, "unsupported image format: bmp")
+}
@@ -0,0 +1,208 @@
+package openrouter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNoKeyAvailable is returned for requests made while every key of the
+// KeyPool of the client is benched.
+var ErrNoKeyAvailable = errors.New("every API key of the pool is benched")
+
+// KeyPoolStrategy selects the key of a request among the keys of a KeyPool
+// that are not benched.
+type KeyPoolStrategy int
+
+const (
+	// KeyPoolRoundRobin uses the keys in turn.
+	KeyPoolRoundRobin KeyPoolStrategy = iota
+	// KeyPoolLeastUsed uses the key with the fewest requests in flight, and
+	// of those the one used least so far.
+	KeyPoolLeastUsed
+)
+
+// Default bench durations of a KeyPool.
+const (
+	DefaultRateLimitBench = time.Minute
+	DefaultCreditsBench   = 10 * time.Minute
+)
+
+// KeyPool spreads the requests of a client over several API keys, for
+// deployments whose volume exceeds the rate limits of a single key. A key
+// answered with 429 is benched until the reset reported by the Retry-After or
+// X-RateLimit-Reset header, and a key answered with 402 until its credits may
+// have been topped up. Benched keys are restored automatically.
+//
+// Fields must be set before the pool is used. It is safe for concurrent use,
+// and may be shared by several clients.
+type KeyPool struct {
+	// Strategy defaults to KeyPoolRoundRobin.
+	Strategy KeyPoolStrategy
+	// RateLimitBench is how long a rate limited key is benched when the
+	// response does not say when the limit resets. Defaults to
+	// DefaultRateLimitBench.
+	RateLimitBench time.Duration
+	// CreditsBench is how long a key without credits is benched. Defaults to
+	// DefaultCreditsBench.
+	CreditsBench time.Duration
+
+	mu   sync.Mutex
+	keys []*pooledKey
+	next int
+}
+
+type pooledKey struct {
+	key          string
+	inFlight     int
+	uses         int64
+	benchedUntil time.Time
+}
+
+// KeyStatus is the state of a key of a KeyPool.
+type KeyStatus struct {
+	// Suffix is the last four characters of the key, to identify it in logs.
+	Suffix   string
+	InFlight int
+	Uses     int64
+	// BenchedUntil is zero for keys in use.
+	BenchedUntil time.Time
+}
+
+// NewKeyPool returns a pool of keys using round-robin.
+func NewKeyPool(keys ...string) *KeyPool {
+	p := &KeyPool{}
+	for _, key := range keys {
+		p.keys = append(p.keys, &pooledKey{key: key})
+	}
+	return p
+}
+
+// Status returns the state of the keys, in the order given to NewKeyPool.
+func (p *KeyPool) Status() []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]KeyStatus, len(p.keys))
+	for i, k := range p.keys {
+		statuses[i] = KeyStatus{Suffix: k.key[max(len(k.key)-4, 0):], InFlight: k.inFlight, Uses: k.uses}
+		if k.benchedUntil.After(now) {
+			statuses[i].BenchedUntil = k.benchedUntil
+		}
+	}
+	return statuses
+}
+
+// acquire returns the key of the next request, which must be released.
+func (p *KeyPool) acquire(now time.Time) (*pooledKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var chosen *pooledKey
+	for i := range p.keys {
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if k.benchedUntil.After(now) {
+			continue
+		}
+		if p.Strategy != KeyPoolLeastUsed {
+			chosen = k
+			p.next = (p.next + i + 1) % len(p.keys)
+			break
+		}
+		if chosen == nil || k.inFlight < chosen.inFlight || (k.inFlight == chosen.inFlight && k.uses < chosen.uses) {
+			chosen = k
+		}
+	}
+	if chosen == nil {
+		return nil, ErrNoKeyAvailable
+	}
+	chosen.inFlight++
+	chosen.uses++
+	return chosen, nil
+}
+
+// release ends a request with k.
+func (p *KeyPool) release(k *pooledKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k.inFlight--
+}
+
+// bench benches k when resp reports it is rate limited or out of credits.
+func (p *KeyPool) bench(k *pooledKey, resp *http.Response, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var until time.Time
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		until = rateLimitReset(resp.Header, now)
+		if until.IsZero() {
+			until = now.Add(durationOr(p.RateLimitBench, DefaultRateLimitBench))
+		}
+	case http.StatusPaymentRequired:
+		until = now.Add(durationOr(p.CreditsBench, DefaultCreditsBench))
+	}
+	if until.After(k.benchedUntil) {
+		k.benchedUntil = until
+	}
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// rateLimitReset returns when the rate limit of a 429 response resets, from
+// its Retry-After header in seconds or its X-RateLimit-Reset header in Unix
+// milliseconds, or the zero time when neither is set.
+func rateLimitReset(header http.Header, now time.Time) time.Time {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if millis, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && millis > 0 {
+		return time.UnixMilli(millis)
+	}
+	return time.Time{}
+}
+
+// keyPoolDoer sends requests through doer with a key of pool.
+type keyPoolDoer struct {
+	pool *KeyPool
+	doer HTTPDoer
+}
+
+func (d keyPoolDoer) Do(req *http.Request) (*http.Response, error) {
+	k, err := d.pool.acquire(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+k.key)
+
+	resp, err := d.doer.Do(req)
+	if err != nil {
+		d.pool.release(k)
+		return nil, err
+	}
+	d.pool.bench(k, resp, time.Now())
+	// The request is in flight until its body, e.g. a stream, is closed.
+	resp.Body = &keyPoolBody{ReadCloser: resp.Body, release: func() { d.pool.release(k) }}
+	return resp, nil
+}
+
+type keyPoolBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *keyPoolBody) Close() error {
+	b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
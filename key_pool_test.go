@@ -0,0 +1,135 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// keyRecorder answers with the status set for each key and records the keys
+// used.
+type keyRecorder struct {
+	mu       sync.Mutex
+	used     []string
+	statuses map[string]int
+	header   http.Header
+}
+
+func (r *keyRecorder) handle(req *http.Request) *http.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := req.Header.Get("Authorization")[len("Bearer "):]
+	r.used = append(r.used, key)
+	status := r.statuses[key]
+	if status == 0 {
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`)
+	}
+	resp := jsonResponse(status, `{"error":{"code":`+strconv.Itoa(status)+`,"message":"no"}}`)
+	for name, values := range r.header {
+		resp.Header[name] = values
+	}
+	return resp
+}
+
+func keyPoolClient(recorder *keyRecorder, pool *KeyPool) *Client {
+	client := newHandlerClient(recorder.handle)
+	client.config.KeyPool = pool
+	return client
+}
+
+func chatOnce(client *Client) error {
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Hello")},
+	})
+	return err
+}
+
+func TestKeyPoolRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	recorder := &keyRecorder{}
+	client := keyPoolClient(recorder, NewKeyPool("key-a", "key-b", "key-c"))
+	for range 4 {
+		require.NoError(t, chatOnce(client))
+	}
+	require.Equal(t, []string{"key-a", "key-b", "key-c", "key-a"}, recorder.used)
+}
+
+func TestKeyPoolBenchesRateLimitedKeys(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(30 * time.Second).Truncate(time.Millisecond)
+	recorder := &keyRecorder{
+		statuses: map[string]int{"key-a": http.StatusTooManyRequests},
+		header:   http.Header{"X-Ratelimit-Reset": []string{strconv.FormatInt(reset.UnixMilli(), 10)}},
+	}
+	pool := NewKeyPool("key-a", "key-b")
+	client := keyPoolClient(recorder, pool)
+
+	require.True(t, IsRateLimited(chatOnce(client)))
+	for range 3 {
+		require.NoError(t, chatOnce(client))
+	}
+	require.Equal(t, []string{"key-a", "key-b", "key-b", "key-b"}, recorder.used)
+
+	status := pool.Status()
+	require.Equal(t, "ey-a", status[0].Suffix)
+	require.True(t, reset.Equal(status[0].BenchedUntil))
+	require.Zero(t, status[1].BenchedUntil)
+	require.EqualValues(t, 3, status[1].Uses)
+	require.Zero(t, status[1].InFlight)
+
+	// Once the reset has passed, the key is used again.
+	pool.mu.Lock()
+	pool.keys[0].benchedUntil = time.Now().Add(-time.Second)
+	pool.mu.Unlock()
+	recorder.statuses = nil
+	require.NoError(t, chatOnce(client))
+	require.NoError(t, chatOnce(client))
+	require.Contains(t, recorder.used[4:], "key-a")
+}
+
+func TestKeyPoolFailsWhenEveryKeyIsBenched(t *testing.T) {
+	t.Parallel()
+
+	recorder := &keyRecorder{statuses: map[string]int{"key-a": http.StatusPaymentRequired}}
+	pool := NewKeyPool("key-a")
+	client := keyPoolClient(recorder, pool)
+
+	require.True(t, IsInsufficientCredits(chatOnce(client)))
+	require.ErrorIs(t, chatOnce(client), ErrNoKeyAvailable)
+	require.WithinDuration(t, time.Now().Add(DefaultCreditsBench), pool.Status()[0].BenchedUntil, time.Minute)
+}
+
+func TestKeyPoolLeastUsed(t *testing.T) {
+	t.Parallel()
+
+	pool := NewKeyPool("key-a", "key-b")
+	pool.Strategy = KeyPoolLeastUsed
+
+	now := time.Now()
+	first, err := pool.acquire(now)
+	require.NoError(t, err)
+	second, err := pool.acquire(now)
+	require.NoError(t, err)
+	require.NotEqual(t, first.key, second.key)
+
+	pool.release(second)
+	third, err := pool.acquire(now)
+	require.NoError(t, err)
+	require.Equal(t, second.key, third.key, "the key without requests in flight")
+}
+
+func TestRateLimitReset(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	require.Equal(t, now.Add(20*time.Second), rateLimitReset(http.Header{"Retry-After": []string{"20"}}, now))
+	require.True(t, rateLimitReset(http.Header{}, now).IsZero())
+}
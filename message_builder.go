@@ -0,0 +1,123 @@
+package openrouter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// MessageBuilder builds a multipart message part by part, e.g.
+//
+//	msg := NewUserMessage().
+//		Text("Compare these two charts.").
+//		Image(firstURL, ImageURLDetailHigh).
+//		Image(secondURL, ImageURLDetailHigh).
+//		Cached(time.Hour).
+//		Build()
+type MessageBuilder struct {
+	role  string
+	parts []ChatMessagePart
+}
+
+// NewMessage returns a builder of a message with the given role.
+func NewMessage(role string) *MessageBuilder {
+	return &MessageBuilder{role: role}
+}
+
+// NewSystemMessage returns a builder of a system message.
+func NewSystemMessage() *MessageBuilder {
+	return NewMessage(ChatMessageRoleSystem)
+}
+
+// NewUserMessage returns a builder of a user message.
+func NewUserMessage() *MessageBuilder {
+	return NewMessage(ChatMessageRoleUser)
+}
+
+// NewAssistantMessage returns a builder of an assistant message.
+func NewAssistantMessage() *MessageBuilder {
+	return NewMessage(ChatMessageRoleAssistant)
+}
+
+// Text adds a text part.
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+	return b.Part(ChatMessagePart{Type: ChatMessagePartTypeText, Text: text})
+}
+
+// Image adds an image part with an image URL, which may be a base64 data URL.
+// An empty detail leaves the detail to the provider.
+func (b *MessageBuilder) Image(url string, detail ImageURLDetail) *MessageBuilder {
+	return b.Part(ChatMessagePart{
+		Type:     ChatMessagePartTypeImageURL,
+		ImageURL: &ChatMessageImageURL{URL: url, Detail: detail},
+	})
+}
+
+// File adds a file part, such as a PDF. fileData is a URL or a base64 data URL.
+func (b *MessageBuilder) File(filename, fileData string) *MessageBuilder {
+	return b.Part(ChatMessagePart{
+		Type: ChatMessagePartTypeFile,
+		File: &FileContent{Filename: filename, FileData: fileData},
+	})
+}
+
+// Audio adds an audio part with the raw audio, which is base64 encoded.
+func (b *MessageBuilder) Audio(audio []byte, format AudioFormat) *MessageBuilder {
+	return b.Part(ChatMessagePart{
+		Type: ChatMessagePartTypeInputAudio,
+		InputAudio: &ChatMessageInputAudio{
+			Format: format,
+			Data:   base64.StdEncoding.EncodeToString(audio),
+		},
+	})
+}
+
+// Part adds a part built elsewhere, e.g. by ChatMessagePartWithGoImage.
+func (b *MessageBuilder) Part(part ChatMessagePart) *MessageBuilder {
+	b.parts = append(b.parts, part)
+	return b
+}
+
+// Cached marks the message up to the last part added as a prompt caching
+// breakpoint, for providers that require explicit cache control. A zero ttl
+// uses the provider default, usually 5 minutes; other values are sent in whole
+// hours or minutes, e.g. "1h". It has no effect before any part is added.
+//
+// https://openrouter.ai/docs/features/prompt-caching
+func (b *MessageBuilder) Cached(ttl time.Duration) *MessageBuilder {
+	if len(b.parts) == 0 {
+		return b
+	}
+
+	b.parts[len(b.parts)-1].CacheControl = newCacheControl(ttl)
+	return b
+}
+
+// newCacheControl returns an ephemeral cache control with ttl in whole hours
+// or minutes, or without TTL when ttl is zero.
+func newCacheControl(ttl time.Duration) *CacheControl {
+	cacheControl := &CacheControl{Type: "ephemeral"}
+	if ttl > 0 {
+		var value string
+		if ttl%time.Hour == 0 {
+			value = fmt.Sprintf("%dh", ttl/time.Hour)
+		} else {
+			value = fmt.Sprintf("%dm", (ttl+time.Minute-1)/time.Minute)
+		}
+		cacheControl.TTL = &value
+	}
+	return cacheControl
+}
+
+// Build returns the message. A message of a single text part without cache
+// control is sent as plain text content.
+func (b *MessageBuilder) Build() ChatCompletionMessage {
+	if len(b.parts) == 1 && b.parts[0].Type == ChatMessagePartTypeText && b.parts[0].CacheControl == nil {
+		return ChatCompletionMessage{Role: b.role, Content: Content{Text: b.parts[0].Text}}
+	}
+
+	return ChatCompletionMessage{
+		Role:    b.role,
+		Content: Content{Multi: append([]ChatMessagePart(nil), b.parts...)},
+	}
+}
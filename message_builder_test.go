@@ -0,0 +1,60 @@
+package openrouter_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	t.Parallel()
+
+	msg := openrouter.NewUserMessage().
+		Text("Compare these.").
+		Image("https://example.com/a.png", openrouter.ImageURLDetailHigh).
+		File("report.pdf", "https://example.com/report.pdf").
+		Cached(time.Hour).
+		Audio([]byte("RIFF"), openrouter.AudioFormatWav).
+		Build()
+
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"role": "user",
+		"content": [
+			{"type": "text", "text": "Compare these."},
+			{"type": "image_url", "image_url": {"url": "https://example.com/a.png", "detail": "high"}},
+			{
+				"type": "file",
+				"file": {"filename": "report.pdf", "file_data": "https://example.com/report.pdf"},
+				"cache_control": {"type": "ephemeral", "ttl": "1h"}
+			},
+			{"type": "input_audio", "input_audio": {"data": "UklGRg==", "format": "wav"}}
+		]
+	}`, string(data))
+}
+
+func TestMessageBuilderCached(t *testing.T) {
+	t.Parallel()
+
+	ttl := func(d time.Duration) *string {
+		return openrouter.NewSystemMessage().Text("Long instructions.").Cached(d).Build().Content.Multi[0].CacheControl.TTL
+	}
+	require.Nil(t, ttl(0))
+	require.Equal(t, "5m", *ttl(5 * time.Minute))
+	require.Equal(t, "2h", *ttl(2 * time.Hour))
+	require.Equal(t, "90m", *ttl(90 * time.Minute))
+
+	msg := openrouter.NewSystemMessage().Cached(time.Hour).Text("Long instructions.").Build()
+	require.Equal(t, openrouter.SystemMessage("Long instructions."), msg, "Cached has no effect before any part")
+}
+
+func TestMessageBuilderPlainText(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, openrouter.AssistantMessage("Hi."), openrouter.NewAssistantMessage().Text("Hi.").Build())
+	require.Equal(t, openrouter.ChatMessageRoleTool, openrouter.NewMessage(openrouter.ChatMessageRoleTool).Build().Role)
+}
@@ -30,6 +30,48 @@ func AssistantMessage(content string) ChatCompletionMessage {
 	}
 }
 
+// AssistantPrefill creates an assistant message for the end of the messages
+// of a request, which the model then continues instead of starting its own
+// reply. It forces the answer to start with text, e.g. "{" for JSON or the
+// opening of a required format:
+//
+//	request.Messages = append(request.Messages, openrouter.AssistantPrefill(`{"answer":`))
+//
+// The response holds the continuation only, unless the client was created
+// with WithMergedPrefill. Support depends on the model: Anthropic models and
+// most open models continue the prefill, while some providers ignore it.
+func AssistantPrefill(text string) ChatCompletionMessage {
+	return AssistantMessage(text)
+}
+
+// mergePrefill prepends the prefill ending messages, if any, to the text
+// content of every choice of response. The content is never checked for the
+// prefill, since a continuation can legitimately start with the same text.
+func mergePrefill(messages []ChatCompletionMessage, response ChatCompletionResponse) ChatCompletionResponse {
+	if len(messages) == 0 {
+		return response
+	}
+	last := messages[len(messages)-1]
+	if last.Role != ChatMessageRoleAssistant || len(last.ToolCalls) > 0 {
+		return response
+	}
+	prefill := messageText(last)
+	if prefill == "" {
+		return response
+	}
+
+	choices := make([]ChatCompletionChoice, len(response.Choices))
+	for i, choice := range response.Choices {
+		content := &choice.Message.Content
+		if len(content.Multi) == 0 {
+			content.Text = prefill + content.Text
+		}
+		choices[i] = choice
+	}
+	response.Choices = choices
+	return response
+}
+
 // ToolMessage creates a new tool (response) message with a call ID and content.
 func ToolMessage(callID string, content string) ChatCompletionMessage {
 	return ChatCompletionMessage{
@@ -83,3 +125,44 @@ func UserMessageWithImage(text, imageURL string) ChatCompletionMessage {
 		},
 	}
 }
+
+// AssistantMessageFromChoice returns the message of choice shaped to be sent
+// back in the conversation, e.g. after running its tool calls:
+//
+//   - content received as null, "" or [] is sent back in the same form, and
+//     empty content next to tool calls is sent as null;
+//   - reasoning and reasoning details are kept, taken from the choice when the
+//     provider returned them there, so reasoning blocks are preserved;
+//   - tool calls lose their stream index, and calls without arguments get an
+//     empty JSON object, which some providers require;
+//   - the response-only annotations and images are dropped.
+func AssistantMessageFromChoice(choice ChatCompletionChoice) ChatCompletionMessage {
+	message := choice.Message
+	message.Role = ChatMessageRoleAssistant
+	message.Annotations = nil
+	message.Images = nil
+
+	if message.Reasoning == nil {
+		message.Reasoning = choice.Reasoning
+	}
+	if len(message.ReasoningDetails) == 0 {
+		message.ReasoningDetails = choice.ReasoningDetails
+	}
+
+	if len(message.ToolCalls) > 0 {
+		calls := make([]ToolCall, len(message.ToolCalls))
+		for i, call := range message.ToolCalls {
+			call.Index = nil
+			if call.Function.Arguments == "" {
+				call.Function.Arguments = "{}"
+			}
+			calls[i] = call
+		}
+		message.ToolCalls = calls
+
+		if message.Content.Text == "" && len(message.Content.Multi) == 0 && message.Content.Raw == nil {
+			message.Content = Content{empty: emptyContentNull}
+		}
+	}
+	return message
+}
@@ -0,0 +1,46 @@
+package openrouter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssistantMessageFromChoice(t *testing.T) {
+	t.Parallel()
+
+	var response openrouter.ChatCompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"choices":[{
+		"message":{"role":"assistant","content":"","tool_calls":[
+			{"index":0,"id":"call_1","type":"function","function":{"name":"get_time","arguments":""}}
+		]},
+		"reasoning":"The user wants the time.",
+		"reasoning_details":[{"type":"reasoning.encrypted","data":"opaque","index":0}]
+	}]}`), &response))
+
+	message := openrouter.AssistantMessageFromChoice(response.Choices[0])
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"role":"assistant",
+		"content":null,
+		"reasoning":"The user wants the time.",
+		"reasoning_details":[{"type":"reasoning.encrypted","data":"opaque","index":0}],
+		"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_time","arguments":"{}"}}]
+	}`, string(data))
+}
+
+func TestAssistantMessageFromChoiceOfFixture(t *testing.T) {
+	t.Parallel()
+
+	var response openrouter.ChatCompletionResponse
+	require.NoError(t, json.Unmarshal(openroutertest.Fixture(openroutertest.FixtureChatReasoning), &response))
+
+	message := openrouter.AssistantMessageFromChoice(response.Choices[0])
+	require.Equal(t, response.Choices[0].Message.Content.Text, message.Content.Text)
+	require.Equal(t, response.Choices[0].Message.ReasoningDetails, message.ReasoningDetails)
+	require.Empty(t, message.ToolCalls)
+}
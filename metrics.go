@@ -0,0 +1,116 @@
+package openrouter
+
+import "time"
+
+// MetricsSink receives metrics emitted by the client, such as streaming
+// latencies. Implementations must be safe for concurrent use.
+type MetricsSink interface {
+	// IncCounter adds value to the counter called name.
+	IncCounter(name string, value float64, tags map[string]string)
+	// Observe records a single observation, e.g. a latency in seconds, for the
+	// distribution called name.
+	Observe(name string, value float64, tags map[string]string)
+}
+
+// Metric names emitted to the MetricsSink.
+const (
+	MetricStreamTimeToFirstToken = "openrouter_stream_time_to_first_token_seconds"
+	MetricStreamInterChunk       = "openrouter_stream_inter_chunk_seconds"
+	MetricStreamDuration         = "openrouter_stream_duration_seconds"
+	MetricStreamTokensPerSecond  = "openrouter_stream_tokens_per_second"
+	MetricStreamChunks           = "openrouter_stream_chunks_total"
+
+	MetricExperimentRequests         = "openrouter_experiment_requests_total"
+	MetricExperimentLatency          = "openrouter_experiment_latency_seconds"
+	MetricExperimentPromptTokens     = "openrouter_experiment_prompt_tokens"
+	MetricExperimentCompletionTokens = "openrouter_experiment_completion_tokens"
+	MetricExperimentCost             = "openrouter_experiment_cost_usd"
+)
+
+// StreamStats describes the timing of a finished stream.
+type StreamStats struct {
+	// StartedAt is when the request was sent.
+	StartedAt time.Time
+	// TimeToFirstToken is the delay between StartedAt and the first chunk carrying
+	// generated output. Zero if no output was generated.
+	TimeToFirstToken time.Duration
+	// InterChunkLatencies holds the delay between consecutive chunks.
+	InterChunkLatencies []time.Duration
+	// Duration is the time between StartedAt and the end of the stream.
+	Duration time.Duration
+	// Chunks is the number of decoded chunks.
+	Chunks int
+	// CompletionTokens is taken from the reported usage, or approximated by
+	// the number of chunks carrying output when no usage was reported.
+	CompletionTokens int
+	// TokensPerSecond is CompletionTokens divided by the generation time after
+	// the first token.
+	TokensPerSecond float64
+}
+
+// streamStatsRecorder accumulates StreamStats from the reader goroutine.
+type streamStatsRecorder struct {
+	stats       StreamStats
+	lastChunkAt time.Time
+	tokenChunks int
+	usageTokens *int
+}
+
+func newStreamStatsRecorder(startedAt time.Time) *streamStatsRecorder {
+	return &streamStatsRecorder{stats: StreamStats{StartedAt: startedAt}}
+}
+
+// chunk records the arrival of a chunk. hasToken reports whether it carried
+// generated output and usage is its reported usage, if any.
+func (r *streamStatsRecorder) chunk(now time.Time, hasToken bool, usage *Usage) {
+	if !r.lastChunkAt.IsZero() {
+		r.stats.InterChunkLatencies = append(r.stats.InterChunkLatencies, now.Sub(r.lastChunkAt))
+	}
+	r.lastChunkAt = now
+	r.stats.Chunks++
+
+	if hasToken {
+		if r.tokenChunks == 0 {
+			r.stats.TimeToFirstToken = now.Sub(r.stats.StartedAt)
+		}
+		r.tokenChunks++
+	}
+	if usage != nil {
+		tokens := usage.CompletionTokens
+		r.usageTokens = &tokens
+	}
+}
+
+// finish completes the stats at the end of the stream.
+func (r *streamStatsRecorder) finish(now time.Time) StreamStats {
+	r.stats.Duration = now.Sub(r.stats.StartedAt)
+	r.stats.CompletionTokens = r.tokenChunks
+	if r.usageTokens != nil {
+		r.stats.CompletionTokens = *r.usageTokens
+	}
+
+	generation := r.stats.Duration - r.stats.TimeToFirstToken
+	if r.tokenChunks > 0 && generation > 0 {
+		r.stats.TokensPerSecond = float64(r.stats.CompletionTokens) / generation.Seconds()
+	}
+	return r.stats
+}
+
+// emitStreamStats reports stats to sink. A nil sink is ignored.
+func emitStreamStats(sink MetricsSink, stats StreamStats, tags map[string]string) {
+	if sink == nil {
+		return
+	}
+
+	sink.IncCounter(MetricStreamChunks, float64(stats.Chunks), tags)
+	sink.Observe(MetricStreamDuration, stats.Duration.Seconds(), tags)
+	if stats.TimeToFirstToken > 0 {
+		sink.Observe(MetricStreamTimeToFirstToken, stats.TimeToFirstToken.Seconds(), tags)
+	}
+	for _, latency := range stats.InterChunkLatencies {
+		sink.Observe(MetricStreamInterChunk, latency.Seconds(), tags)
+	}
+	if stats.TokensPerSecond > 0 {
+		sink.Observe(MetricStreamTokensPerSecond, stats.TokensPerSecond, tags)
+	}
+}
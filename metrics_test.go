@@ -0,0 +1,116 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordedMetric struct {
+	name  string
+	value float64
+	tags  map[string]string
+}
+
+type recordingMetricsSink struct {
+	mu           sync.Mutex
+	counters     []recordedMetric
+	observations []recordedMetric
+}
+
+func (s *recordingMetricsSink) IncCounter(name string, value float64, tags map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters = append(s.counters, recordedMetric{name: name, value: value, tags: tags})
+}
+
+func (s *recordingMetricsSink) Observe(name string, value float64, tags map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observations = append(s.observations, recordedMetric{name: name, value: value, tags: tags})
+}
+
+func (s *recordingMetricsSink) observed(name string) []recordedMetric {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var metrics []recordedMetric
+	for _, metric := range append(s.counters, s.observations...) {
+		if metric.name == name {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+func TestChatCompletionStreamStats(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"1","choices":[{"delta":{"role":"assistant"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"a"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"b"}}]}`,
+		`data: {"id":"1","choices":[],"usage":{"prompt_tokens":1,"completion_tokens":7,"total_tokens":8}}`,
+		`data: [DONE]`,
+	)))
+	sink := &recordingMetricsSink{}
+	client.config.MetricsSink = sink
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.Zero(t, stream.Stats().Chunks)
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	stats := stream.Stats()
+	require.Equal(t, 4, stats.Chunks)
+	require.Len(t, stats.InterChunkLatencies, 3)
+	require.Equal(t, 7, stats.CompletionTokens)
+	require.Positive(t, stats.TimeToFirstToken)
+	require.GreaterOrEqual(t, stats.Duration, stats.TimeToFirstToken)
+	require.False(t, stats.StartedAt.IsZero())
+
+	require.Eventually(t, func() bool {
+		return len(sink.observed(MetricStreamDuration)) == 1
+	}, time.Second, time.Millisecond)
+	chunks := sink.observed(MetricStreamChunks)
+	require.Len(t, chunks, 1)
+	require.Equal(t, float64(4), chunks[0].value)
+	require.Equal(t, "chat", chunks[0].tags["endpoint"])
+	require.Equal(t, "openai/gpt-4o-mini", chunks[0].tags["model"])
+	require.Len(t, sink.observed(MetricStreamTimeToFirstToken), 1)
+	require.Len(t, sink.observed(MetricStreamInterChunk), 3)
+}
+
+func TestStreamStatsRecorderApproximatesTokensWithoutUsage(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(0, 0)
+	recorder := newStreamStatsRecorder(start)
+	recorder.chunk(start.Add(100*time.Millisecond), false, nil)
+	recorder.chunk(start.Add(200*time.Millisecond), true, nil)
+	recorder.chunk(start.Add(700*time.Millisecond), true, nil)
+	stats := recorder.finish(start.Add(1200 * time.Millisecond))
+
+	require.Equal(t, 200*time.Millisecond, stats.TimeToFirstToken)
+	require.Equal(t, 1200*time.Millisecond, stats.Duration)
+	require.Equal(t, 2, stats.CompletionTokens)
+	require.InDelta(t, 2.0, stats.TokensPerSecond, 1e-9)
+	require.Equal(t, []time.Duration{100 * time.Millisecond, 500 * time.Millisecond}, stats.InterChunkLatencies)
+}
@@ -0,0 +1,169 @@
+package openrouter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ModelChangeKind is the kind of a ModelChange.
+type ModelChangeKind string
+
+const (
+	ModelAdded                ModelChangeKind = "added"
+	ModelRemoved              ModelChangeKind = "removed"
+	ModelPricingChanged       ModelChangeKind = "pricing_changed"
+	ModelContextLengthChanged ModelChangeKind = "context_length_changed"
+)
+
+// ModelChange reports a difference in the model catalog between two polls.
+type ModelChange struct {
+	Kind ModelChangeKind
+	// Model is the model as now listed, or as last listed for ModelRemoved.
+	Model Model
+	// Previous is the model as previously listed, nil for ModelAdded.
+	Previous *Model
+}
+
+// ModelWatcher polls the model catalog and reports changes on Changes.
+type ModelWatcher struct {
+	changes  chan ModelChange
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchModels polls ListModels every interval and sends a ModelChange for
+// every model added, removed, repriced or resized since the previous poll. The
+// first poll only records the catalog. Polling errors are logged and the poll
+// is retried at the next interval. A zero or negative interval is replaced by
+// DefaultPollInterval.
+//
+// Changes must be received promptly, as polling waits for them to be
+// delivered. The watcher stops, closing Changes, when ctx is done or Stop is
+// called.
+func (c *Client) WatchModels(ctx context.Context, interval time.Duration) *ModelWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &ModelWatcher{changes: make(chan ModelChange), cancel: cancel, done: make(chan struct{})}
+	go w.watch(ctx, c, pollInterval(interval))
+	return w
+}
+
+// Changes returns the channel of catalog changes. It is closed when the watcher stops.
+func (w *ModelWatcher) Changes() <-chan ModelChange {
+	return w.changes
+}
+
+// Stop stops polling and waits for the poll in progress to return, so no
+// change is sent and Changes is closed once it returns. It is safe to call
+// any number of times.
+func (w *ModelWatcher) Stop() {
+	w.stopOnce.Do(w.cancel)
+	<-w.done
+}
+
+func (w *ModelWatcher) watch(ctx context.Context, client *Client, interval time.Duration) {
+	defer close(w.done)
+	defer close(w.changes)
+
+	var known map[string]Model
+	poll(ctx, interval, func() {
+		models, err := client.ListModels(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				client.logger().Error("failed to poll model catalog", "error", err)
+			}
+			return
+		}
+
+		current := make(map[string]Model, len(models))
+		for _, model := range models {
+			current[model.ID] = model
+		}
+		if known != nil {
+			for _, change := range diffModels(known, current) {
+				select {
+				case w.changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		known = current
+	})
+}
+
+// DefaultPollInterval is the interval WatchModels, WatchAPIKey and
+// MonitorCredits poll at when given a zero or negative one.
+const DefaultPollInterval = time.Minute
+
+// pollInterval returns interval, or DefaultPollInterval when it is not
+// positive.
+func pollInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return DefaultPollInterval
+	}
+	return interval
+}
+
+// poll calls fn immediately and then every interval until ctx is done.
+func poll(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		fn()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// diffModels returns the changes from previous to current, ordered by model ID.
+func diffModels(previous, current map[string]Model) []ModelChange {
+	var changes []ModelChange
+	for id, model := range current {
+		old, ok := previous[id]
+		if !ok {
+			changes = append(changes, ModelChange{Kind: ModelAdded, Model: model})
+			continue
+		}
+		if !samePricing(old.Pricing, model.Pricing) {
+			changes = append(changes, ModelChange{Kind: ModelPricingChanged, Model: model, Previous: &old})
+		}
+		if !sameInt64(old.ContextLength, model.ContextLength) {
+			changes = append(changes, ModelChange{Kind: ModelContextLengthChanged, Model: model, Previous: &old})
+		}
+	}
+	for id, model := range previous {
+		if _, ok := current[id]; !ok {
+			old := model
+			changes = append(changes, ModelChange{Kind: ModelRemoved, Model: model, Previous: &old})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Model.ID != changes[j].Model.ID {
+			return changes[i].Model.ID < changes[j].Model.ID
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+func samePricing(a, b ModelPricing) bool {
+	return a.Prompt == b.Prompt && a.Completion == b.Completion && a.Image == b.Image &&
+		a.Request == b.Request && a.WebSearch == b.WebSearch && a.InternalReasoning == b.InternalReasoning &&
+		sameString(a.InputCacheRead, b.InputCacheRead) && sameString(a.InputCacheWrite, b.InputCacheWrite)
+}
+
+func sameString(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func sameInt64(a, b *int64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
@@ -0,0 +1,111 @@
+package openrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// catalogHTTPClient serves a model catalog that the test can replace.
+type catalogHTTPClient struct {
+	mu   sync.Mutex
+	body string
+}
+
+func (c *catalogHTTPClient) set(body string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.body = body
+}
+
+func (c *catalogHTTPClient) Do(*http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestWatchModelsReportsChanges(t *testing.T) {
+	t.Parallel()
+
+	catalog := &catalogHTTPClient{body: `{"data":[
+		{"id":"a","context_length":8192,"pricing":{"prompt":"0.000001","completion":"0.000002"}},
+		{"id":"b","context_length":4096,"pricing":{"prompt":"0.000001","completion":"0.000002"}}
+	]}`}
+	cfg := DefaultConfig("test-token")
+	cfg.HTTPClient = catalog
+	client := NewClientWithConfig(*cfg)
+
+	watcher := client.WatchModels(context.Background(), 10*time.Millisecond)
+	defer watcher.Stop()
+
+	time.Sleep(5 * time.Millisecond)
+	catalog.set(`{"data":[
+		{"id":"a","context_length":16384,"pricing":{"prompt":"0.000002","completion":"0.000002"}},
+		{"id":"c","context_length":4096,"pricing":{"prompt":"0","completion":"0"}}
+	]}`)
+
+	var changes []ModelChange
+	for len(changes) < 4 {
+		select {
+		case change := <-watcher.Changes():
+			changes = append(changes, change)
+		case <-time.After(time.Second):
+			t.Fatalf("got %d changes, want 4", len(changes))
+		}
+	}
+
+	require.Equal(t, ModelContextLengthChanged, changes[0].Kind)
+	require.Equal(t, int64(8192), *changes[0].Previous.ContextLength)
+	require.Equal(t, int64(16384), *changes[0].Model.ContextLength)
+	require.Equal(t, ModelPricingChanged, changes[1].Kind)
+	require.Equal(t, "0.000002", changes[1].Model.Pricing.Prompt)
+	require.Equal(t, ModelRemoved, changes[2].Kind)
+	require.Equal(t, "b", changes[2].Model.ID)
+	require.Equal(t, ModelAdded, changes[3].Kind)
+	require.Equal(t, "c", changes[3].Model.ID)
+	require.Nil(t, changes[3].Previous)
+
+	watcher.Stop()
+	watcher.Stop()
+	select {
+	case _, ok := <-watcher.Changes():
+		require.False(t, ok)
+	default:
+		t.Fatal("Changes must be closed once Stop returns")
+	}
+}
+
+func TestPollersDefaultNonPositiveIntervals(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, DefaultPollInterval, pollInterval(0))
+	require.Equal(t, DefaultPollInterval, pollInterval(-time.Second))
+	require.Equal(t, time.Second, pollInterval(time.Second))
+
+	paths := make(chan string, 3)
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		paths <- req.URL.Path
+		return jsonResponse(http.StatusOK, `{"data":[]}`)
+	})
+
+	ctx := context.Background()
+	models := client.WatchModels(ctx, 0)
+	require.Equal(t, "/api/v1/models", <-paths)
+	models.Stop()
+	key := client.WatchAPIKey(ctx, "", -time.Second, APIKeyWatchHandlers{})
+	require.Equal(t, "/api/v1/key", <-paths)
+	key.Stop()
+	credits := client.MonitorCredits(ctx, 0, CreditsMonitorOptions{})
+	require.Equal(t, "/api/v1/credits", <-paths)
+	credits.Stop()
+}
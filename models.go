@@ -2,15 +2,26 @@ package openrouter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 const (
 	listModelsSuffix           = "/models"
 	listUserModelsSuffix       = "/models/user"
 	listEmbeddingsModelsSuffix = "/embeddings/models"
+	modelEndpointsSuffix       = "/models/%s/%s/endpoints"
 )
 
+// ErrModelNotFound is matched by the *ModelNotFoundError returned by GetModel.
+var ErrModelNotFound = errors.New("model not found")
+
 type ModelArchitecture struct {
 	InputModalities  []string `json:"input_modalities"`
 	OutputModalities []string `json:"output_modalities"`
@@ -35,6 +46,101 @@ type ModelPricing struct {
 	InputCacheWrite   *string `json:"input_cache_write,omitempty"`
 }
 
+// PromptPerToken returns the price in USD of one prompt token.
+func (p ModelPricing) PromptPerToken() (float64, error) {
+	return parsePrice("prompt", p.Prompt)
+}
+
+// CompletionPerToken returns the price in USD of one completion token.
+func (p ModelPricing) CompletionPerToken() (float64, error) {
+	return parsePrice("completion", p.Completion)
+}
+
+// ImagePerUnit returns the price in USD of one input image.
+func (p ModelPricing) ImagePerUnit() (float64, error) {
+	return parsePrice("image", p.Image)
+}
+
+// RequestPerUnit returns the fixed price in USD charged per request.
+func (p ModelPricing) RequestPerUnit() (float64, error) {
+	return parsePrice("request", p.Request)
+}
+
+// WebSearchPerUnit returns the price in USD of one web search.
+func (p ModelPricing) WebSearchPerUnit() (float64, error) {
+	return parsePrice("web_search", p.WebSearch)
+}
+
+// InternalReasoningPerToken returns the price in USD of one reasoning token.
+func (p ModelPricing) InternalReasoningPerToken() (float64, error) {
+	return parsePrice("internal_reasoning", p.InternalReasoning)
+}
+
+// InputCacheReadPerToken returns the price in USD of one prompt token read
+// from the cache. It falls back to PromptPerToken when the model has no
+// cache pricing.
+func (p ModelPricing) InputCacheReadPerToken() (float64, error) {
+	if p.InputCacheRead == nil {
+		return p.PromptPerToken()
+	}
+	return parsePrice("input_cache_read", *p.InputCacheRead)
+}
+
+// InputCacheWritePerToken returns the price in USD of one prompt token written
+// to the cache. It falls back to PromptPerToken when the model has no cache
+// pricing.
+func (p ModelPricing) InputCacheWritePerToken() (float64, error) {
+	if p.InputCacheWrite == nil {
+		return p.PromptPerToken()
+	}
+	return parsePrice("input_cache_write", *p.InputCacheWrite)
+}
+
+// CostFor computes the cost in USD of a request that consumed usage, from the
+// token prices and the per-request price. Cached and cache-write prompt
+// tokens are charged at the cache prices, and reasoning tokens at the
+// internal reasoning price when the model has one. Prices that fail to parse
+// or are negative, as for routers with variable pricing, count as zero.
+func (p ModelPricing) CostFor(usage Usage) float64 {
+	price := func(get func() (float64, error)) float64 {
+		value, err := get()
+		if err != nil || value < 0 {
+			return 0
+		}
+		return value
+	}
+
+	cached := usage.PromptTokenDetails.CachedTokens
+	written := usage.PromptTokenDetails.CacheWriteTokens
+	uncached := max(usage.PromptTokens-cached-written, 0)
+
+	completion := price(p.CompletionPerToken)
+	reasoningPrice := price(p.InternalReasoningPerToken)
+	reasoning := usage.CompletionTokenDetails.ReasoningTokens
+	if reasoningPrice == 0 {
+		reasoningPrice = completion
+	}
+
+	return float64(uncached)*price(p.PromptPerToken) +
+		float64(cached)*price(p.InputCacheReadPerToken) +
+		float64(written)*price(p.InputCacheWritePerToken) +
+		float64(max(usage.CompletionTokens-reasoning, 0))*completion +
+		float64(reasoning)*reasoningPrice +
+		price(p.RequestPerUnit)
+}
+
+// parsePrice parses a decimal USD price. An empty price is free.
+func parsePrice(field, price string) (float64, error) {
+	if price == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s price %q: %w", field, price, err)
+	}
+	return value, nil
+}
+
 type Model struct {
 	ID                  string            `json:"id"`
 	Name                string            `json:"name"`
@@ -50,6 +156,75 @@ type Model struct {
 	SupportedParameters []string          `json:"supported_parameters,omitempty"`
 }
 
+// ModelCapability is a feature a model may support, for use with FilterModels.
+type ModelCapability string
+
+const (
+	ModelCapabilityTools             ModelCapability = "tools"
+	ModelCapabilityVision            ModelCapability = "vision"
+	ModelCapabilityStructuredOutputs ModelCapability = "structured_outputs"
+	ModelCapabilityReasoning         ModelCapability = "reasoning"
+)
+
+// SupportsTools reports whether the model accepts tool definitions.
+func (m Model) SupportsTools() bool {
+	return m.supportsParameter("tools")
+}
+
+// SupportsVision reports whether the model accepts images as input.
+func (m Model) SupportsVision() bool {
+	return slices.Contains(m.Architecture.InputModalities, "image")
+}
+
+// SupportsStructuredOutputs reports whether the model can be constrained to a
+// JSON schema through ChatCompletionResponseFormatTypeJSONSchema.
+func (m Model) SupportsStructuredOutputs() bool {
+	return m.supportsParameter("structured_outputs")
+}
+
+// SupportsReasoning reports whether the model can return its reasoning.
+func (m Model) SupportsReasoning() bool {
+	return m.supportsParameter("reasoning") || m.supportsParameter("include_reasoning")
+}
+
+// Supports reports whether the model has capability.
+func (m Model) Supports(capability ModelCapability) bool {
+	switch capability {
+	case ModelCapabilityTools:
+		return m.SupportsTools()
+	case ModelCapabilityVision:
+		return m.SupportsVision()
+	case ModelCapabilityStructuredOutputs:
+		return m.SupportsStructuredOutputs()
+	case ModelCapabilityReasoning:
+		return m.SupportsReasoning()
+	default:
+		return false
+	}
+}
+
+func (m Model) supportsParameter(parameter string) bool {
+	return slices.Contains(m.SupportedParameters, parameter)
+}
+
+// FilterModels returns the models that have all of capabilities.
+func FilterModels(models []Model, capabilities ...ModelCapability) []Model {
+	var filtered []Model
+	for _, model := range models {
+		supported := true
+		for _, capability := range capabilities {
+			if !model.Supports(capability) {
+				supported = false
+				break
+			}
+		}
+		if supported {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
 func (c *Client) ListModels(ctx context.Context) (models []Model, err error) {
 	req, err := c.newRequest(
 		ctx,
@@ -112,3 +287,152 @@ func (c *Client) ListEmbeddingsModels(ctx context.Context) ([]Model, error) {
 
 	return response.Data, nil
 }
+
+// ModelEndpoint describes one provider serving a model.
+type ModelEndpoint struct {
+	Name                string       `json:"name"`
+	ProviderName        string       `json:"provider_name"`
+	Tag                 string       `json:"tag,omitempty"`
+	ContextLength       int64        `json:"context_length"`
+	Pricing             ModelPricing `json:"pricing"`
+	Quantization        *string      `json:"quantization,omitempty"`
+	MaxCompletionTokens *int64       `json:"max_completion_tokens,omitempty"`
+	MaxPromptTokens     *int64       `json:"max_prompt_tokens,omitempty"`
+	SupportedParameters []string     `json:"supported_parameters,omitempty"`
+	// Status is 0 when the endpoint is operational and negative when degraded or down.
+	Status int `json:"status"`
+	// UptimeLast30m is the percentage of successful requests over the last 30 minutes.
+	UptimeLast30m           *float64 `json:"uptime_last_30m,omitempty"`
+	SupportsImplicitCaching bool     `json:"supports_implicit_caching,omitempty"`
+}
+
+// ModelEndpoints is a model together with the provider endpoints serving it.
+type ModelEndpoints struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Created      int64             `json:"created"`
+	Description  string            `json:"description"`
+	Architecture ModelArchitecture `json:"architecture"`
+	Endpoints    []ModelEndpoint   `json:"endpoints"`
+}
+
+// ListModelEndpoints returns the provider endpoints serving the model
+// author/slug, e.g. ("openai", "gpt-4o").
+// API reference: https://openrouter.ai/docs/api-reference/list-endpoints-for-a-model
+func (c *Client) ListModelEndpoints(ctx context.Context, author, slug string) (ModelEndpoints, error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(fmt.Sprintf(modelEndpointsSuffix, url.PathEscape(author), url.PathEscape(slug))),
+	)
+	if err != nil {
+		return ModelEndpoints{}, err
+	}
+
+	var response struct {
+		Data ModelEndpoints `json:"data"`
+	}
+
+	if err := c.sendRequest(req, &response); err != nil {
+		return ModelEndpoints{}, err
+	}
+
+	return response.Data, nil
+}
+
+// ModelNotFoundError is returned by GetModel when no model has the requested ID.
+// It matches ErrModelNotFound with errors.Is.
+type ModelNotFoundError struct {
+	ID string
+	// Suggestions holds the IDs of the closest existing models, best first.
+	Suggestions []string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("model %q not found", e.ID)
+	}
+	return fmt.Sprintf("model %q not found, did you mean %s?", e.ID, strings.Join(e.Suggestions, " or "))
+}
+
+func (e *ModelNotFoundError) Is(target error) bool {
+	return target == ErrModelNotFound
+}
+
+// maxModelSuggestions caps the suggestions of a ModelNotFoundError.
+const maxModelSuggestions = 3
+
+// GetModel returns the model with the given ID or canonical slug, or a
+// *ModelNotFoundError suggesting similar IDs when there is none.
+func (c *Client) GetModel(ctx context.Context, id string) (Model, error) {
+	models, err := c.ListModels(ctx)
+	if err != nil {
+		return Model{}, err
+	}
+
+	for _, model := range models {
+		if model.ID == id || (model.CanonicalSlug != nil && *model.CanonicalSlug == id) {
+			return model, nil
+		}
+	}
+	return Model{}, &ModelNotFoundError{ID: id, Suggestions: suggestModelIDs(id, models)}
+}
+
+// suggestModelIDs returns the IDs of models close to id: those containing it
+// or its name without the author prefix, then those within a small edit distance.
+func suggestModelIDs(id string, models []Model) []string {
+	type candidate struct {
+		id       string
+		distance int
+	}
+
+	needle := strings.ToLower(id)
+	name := needle[strings.LastIndex(needle, "/")+1:]
+	threshold := max(len(needle)/3, 2)
+
+	var candidates []candidate
+	for _, model := range models {
+		modelID := strings.ToLower(model.ID)
+		distance := levenshtein(needle, modelID)
+		if name != "" && strings.Contains(modelID, name) {
+			// Rank substring matches ahead of every fuzzy match.
+			distance -= len(needle) + len(modelID)
+		} else if distance > threshold {
+			continue
+		}
+		candidates = append(candidates, candidate{id: model.ID, distance: distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	suggestions := make([]string, 0, min(len(candidates), maxModelSuggestions))
+	for _, c := range candidates[:min(len(candidates), maxModelSuggestions)] {
+		suggestions = append(suggestions, c.id)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
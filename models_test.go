@@ -0,0 +1,214 @@
+package openrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newModelsTestClient(body string) (*Client, *fakeHTTPClient) {
+	fakeClient := &fakeHTTPClient{response: &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}}
+	cfg := DefaultConfig("test-token")
+	cfg.BaseURL = "https://example.com/api/v1"
+	cfg.HTTPClient = fakeClient
+	return NewClientWithConfig(*cfg), fakeClient
+}
+
+func TestListModelEndpoints(t *testing.T) {
+	t.Parallel()
+
+	client, fakeClient := newModelsTestClient(`{"data":{
+		"id": "openai/gpt-oss-20b",
+		"name": "OpenAI: gpt-oss-20b",
+		"created": 1754414229,
+		"description": "An open-weight model.",
+		"architecture": {"input_modalities": ["text"], "output_modalities": ["text"], "tokenizer": "GPT", "instruct_type": null},
+		"endpoints": [{
+			"name": "DeepInfra | openai/gpt-oss-20b",
+			"provider_name": "DeepInfra",
+			"tag": "deepinfra/bf16",
+			"context_length": 131072,
+			"pricing": {"prompt": "0.00000004", "completion": "0.00000015", "request": "0", "image": "0"},
+			"quantization": "bf16",
+			"max_completion_tokens": 32768,
+			"max_prompt_tokens": null,
+			"supported_parameters": ["tools", "reasoning", "max_tokens"],
+			"status": 0,
+			"uptime_last_30m": 99.82,
+			"supports_implicit_caching": false
+		}]
+	}}`)
+
+	endpoints, err := client.ListModelEndpoints(context.Background(), "openai", "gpt-oss-20b:free")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/api/v1/models/openai/gpt-oss-20b:free/endpoints", fakeClient.lastRequest.URL.String())
+
+	require.Equal(t, "openai/gpt-oss-20b", endpoints.ID)
+	require.Equal(t, "GPT", endpoints.Architecture.Tokenizer)
+	require.Len(t, endpoints.Endpoints, 1)
+
+	endpoint := endpoints.Endpoints[0]
+	require.Equal(t, "DeepInfra", endpoint.ProviderName)
+	require.Equal(t, int64(131072), endpoint.ContextLength)
+	require.Equal(t, "0.00000004", endpoint.Pricing.Prompt)
+	require.Equal(t, "bf16", *endpoint.Quantization)
+	require.Equal(t, int64(32768), *endpoint.MaxCompletionTokens)
+	require.Nil(t, endpoint.MaxPromptTokens)
+	require.Contains(t, endpoint.SupportedParameters, "tools")
+	require.InDelta(t, 99.82, *endpoint.UptimeLast30m, 1e-9)
+}
+
+func TestGetModel(t *testing.T) {
+	t.Parallel()
+
+	const models = `{"data":[
+		{"id":"deepseek/deepseek-chat","canonical_slug":"deepseek/deepseek-chat-v3","name":"DeepSeek V3"},
+		{"id":"deepseek/deepseek-r1","name":"DeepSeek R1"},
+		{"id":"openai/gpt-4o","name":"GPT-4o"},
+		{"id":"openai/gpt-4o-mini","name":"GPT-4o mini"}
+	]}`
+
+	client, _ := newModelsTestClient(models)
+	model, err := client.GetModel(context.Background(), "openai/gpt-4o")
+	require.NoError(t, err)
+	require.Equal(t, "GPT-4o", model.Name)
+
+	client, _ = newModelsTestClient(models)
+	model, err = client.GetModel(context.Background(), "deepseek/deepseek-chat-v3")
+	require.NoError(t, err)
+	require.Equal(t, "deepseek/deepseek-chat", model.ID)
+
+	tests := []struct {
+		id          string
+		suggestions []string
+	}{
+		{"deepseek-chat", []string{"deepseek/deepseek-chat"}},
+		{"deepseek/deepseek-caht", []string{"deepseek/deepseek-chat", "deepseek/deepseek-r1"}},
+		{"openai/gpt4o", []string{"openai/gpt-4o"}},
+		{"mistralai/mistral-large", []string{}},
+	}
+	for _, tc := range tests {
+		client, _ = newModelsTestClient(models)
+		_, err = client.GetModel(context.Background(), tc.id)
+		require.ErrorIs(t, err, ErrModelNotFound)
+
+		var notFound *ModelNotFoundError
+		require.ErrorAs(t, err, &notFound)
+		require.Equal(t, tc.id, notFound.ID)
+		require.Equal(t, tc.suggestions, notFound.Suggestions, tc.id)
+	}
+
+	require.EqualError(t, &ModelNotFoundError{ID: "deepseek-chat", Suggestions: []string{"deepseek/deepseek-chat"}},
+		`model "deepseek-chat" not found, did you mean deepseek/deepseek-chat?`)
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, levenshtein("gpt-4o", "gpt-4o"))
+	require.Equal(t, 1, levenshtein("gpt4o", "gpt-4o"))
+	require.Equal(t, 3, levenshtein("kitten", "sitting"))
+	require.Equal(t, 6, levenshtein("", "gpt-4o"))
+}
+
+func TestModelCapabilities(t *testing.T) {
+	t.Parallel()
+
+	vision := Model{
+		ID:                  "openai/gpt-4o",
+		Architecture:        ModelArchitecture{InputModalities: []string{"text", "image"}},
+		SupportedParameters: []string{"tools", "tool_choice", "structured_outputs", "response_format"},
+	}
+	reasoning := Model{
+		ID:                  "deepseek/deepseek-r1",
+		Architecture:        ModelArchitecture{InputModalities: []string{"text"}},
+		SupportedParameters: []string{"reasoning", "include_reasoning", "tools"},
+	}
+	plain := Model{ID: "gryphe/mythomax-l2-13b", Architecture: ModelArchitecture{InputModalities: []string{"text"}}}
+
+	require.True(t, vision.SupportsTools())
+	require.True(t, vision.SupportsVision())
+	require.True(t, vision.SupportsStructuredOutputs())
+	require.False(t, vision.SupportsReasoning())
+	require.True(t, reasoning.SupportsReasoning())
+	require.False(t, reasoning.SupportsVision())
+	require.False(t, plain.Supports(ModelCapabilityTools))
+	require.False(t, plain.Supports(ModelCapability("unknown")))
+
+	models := []Model{vision, reasoning, plain}
+	require.Len(t, FilterModels(models), 3)
+	require.Equal(t, []Model{vision, reasoning}, FilterModels(models, ModelCapabilityTools))
+	require.Equal(t, []Model{reasoning}, FilterModels(models, ModelCapabilityTools, ModelCapabilityReasoning))
+	require.Empty(t, FilterModels(models, ModelCapabilityVision, ModelCapabilityReasoning))
+}
+
+func TestModelPricingAccessors(t *testing.T) {
+	t.Parallel()
+
+	cacheRead := "0.0000003"
+	pricing := ModelPricing{
+		Prompt:            "0.000003",
+		Completion:        "0.000015",
+		Image:             "0.0048",
+		Request:           "0",
+		WebSearch:         "0.01",
+		InternalReasoning: "",
+		InputCacheRead:    &cacheRead,
+	}
+
+	prompt, err := pricing.PromptPerToken()
+	require.NoError(t, err)
+	require.InDelta(t, 0.000003, prompt, 1e-15)
+	image, err := pricing.ImagePerUnit()
+	require.NoError(t, err)
+	require.InDelta(t, 0.0048, image, 1e-15)
+	reasoning, err := pricing.InternalReasoningPerToken()
+	require.NoError(t, err)
+	require.Zero(t, reasoning)
+	read, err := pricing.InputCacheReadPerToken()
+	require.NoError(t, err)
+	require.InDelta(t, 0.0000003, read, 1e-15)
+	write, err := pricing.InputCacheWritePerToken()
+	require.NoError(t, err)
+	require.InDelta(t, prompt, write, 1e-15)
+
+	_, err = ModelPricing{Completion: "free"}.CompletionPerToken()
+	require.ErrorContains(t, err, `parse completion price "free"`)
+}
+
+func TestModelPricingCostFor(t *testing.T) {
+	t.Parallel()
+
+	cacheRead := "0.0000003"
+	cacheWrite := "0.00000375"
+	pricing := ModelPricing{
+		Prompt:          "0.000003",
+		Completion:      "0.000015",
+		Request:         "0.001",
+		InputCacheRead:  &cacheRead,
+		InputCacheWrite: &cacheWrite,
+	}
+	usage := Usage{
+		PromptTokens:           7325,
+		CompletionTokens:       42,
+		PromptTokenDetails:     PromptTokenDetails{CachedTokens: 4284, CacheWriteTokens: 1000},
+		CompletionTokenDetails: CompletionTokenDetails{ReasoningTokens: 29},
+	}
+
+	want := 2041*0.000003 + 4284*0.0000003 + 1000*0.00000375 + 42*0.000015 + 0.001
+	require.InDelta(t, want, pricing.CostFor(usage), 1e-12)
+
+	pricing.InternalReasoning = "0.00003"
+	want += 29 * (0.00003 - 0.000015)
+	require.InDelta(t, want, pricing.CostFor(usage), 1e-12)
+
+	require.Zero(t, ModelPricing{Prompt: "-1", Completion: "-1"}.CostFor(usage))
+}
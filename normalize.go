@@ -0,0 +1,138 @@
+package openrouter
+
+import (
+	"slices"
+	"strings"
+)
+
+// NormalizeMessages returns messages with empty messages dropped, consecutive
+// messages of the same role merged and single text parts turned into text,
+// the clean-up most providers accept without complaint. It does not enforce
+// alternation; see AlternateRoles.
+func NormalizeMessages(messages []ChatCompletionMessage) []ChatCompletionMessage {
+	return SimplifyContent(MergeConsecutiveMessages(DropEmptyMessages(messages)))
+}
+
+// DropEmptyMessages returns messages without the ones that have neither
+// content nor tool calls, such as placeholders left by a UI. Tool messages are
+// kept, since an empty tool result still answers its call.
+func DropEmptyMessages(messages []ChatCompletionMessage) []ChatCompletionMessage {
+	kept := make([]ChatCompletionMessage, 0, len(messages))
+	for _, message := range messages {
+		if message.Role == ChatMessageRoleTool || !isEmptyMessage(message) {
+			kept = append(kept, message)
+		}
+	}
+	return kept
+}
+
+func isEmptyMessage(message ChatCompletionMessage) bool {
+	if strings.TrimSpace(message.Content.Text) != "" || len(message.ToolCalls) > 0 || message.FunctionCall != nil {
+		return false
+	}
+	for _, part := range message.Content.Multi {
+		if part.Type != ChatMessagePartTypeText || strings.TrimSpace(part.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeConsecutiveMessages returns messages with runs of system, user or
+// assistant messages merged into one, for providers rejecting consecutive
+// messages of the same role. Texts are joined by a blank line, and content
+// with parts keeps all of them in order. Tool messages, and messages carrying
+// reasoning, are never merged.
+func MergeConsecutiveMessages(messages []ChatCompletionMessage) []ChatCompletionMessage {
+	merged := make([]ChatCompletionMessage, 0, len(messages))
+	for _, message := range messages {
+		if n := len(merged); n > 0 && mergeable(merged[n-1], message) {
+			merged[n-1] = mergeMessages(merged[n-1], message)
+			continue
+		}
+		merged = append(merged, message)
+	}
+	return merged
+}
+
+func mergeable(a, b ChatCompletionMessage) bool {
+	if a.Role != b.Role {
+		return false
+	}
+	switch a.Role {
+	case ChatMessageRoleSystem, ChatMessageRoleUser, ChatMessageRoleAssistant:
+	default:
+		return false
+	}
+	for _, message := range []ChatCompletionMessage{a, b} {
+		if message.Reasoning != nil || message.ReasoningContent != nil || len(message.ReasoningDetails) > 0 ||
+			message.FunctionCall != nil || message.Refusal != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeMessages appends the content and tool calls of b to a.
+func mergeMessages(a, b ChatCompletionMessage) ChatCompletionMessage {
+	if len(a.Content.Multi) == 0 && len(b.Content.Multi) == 0 {
+		switch {
+		case a.Content.Text == "":
+			a.Content.Text = b.Content.Text
+		case b.Content.Text != "":
+			a.Content.Text += "\n\n" + b.Content.Text
+		}
+	} else {
+		a.Content = Content{Multi: append(contentParts(a.Content), contentParts(b.Content)...)}
+	}
+	if len(b.ToolCalls) > 0 {
+		a.ToolCalls = append(slices.Clip(a.ToolCalls), b.ToolCalls...)
+	}
+	if len(b.Annotations) > 0 {
+		a.Annotations = append(slices.Clip(a.Annotations), b.Annotations...)
+	}
+	return a
+}
+
+// contentParts returns a copy of the parts of content, text content being a
+// single text part.
+func contentParts(content Content) []ChatMessagePart {
+	if len(content.Multi) > 0 {
+		return slices.Clone(content.Multi)
+	}
+	if content.Text == "" {
+		return nil
+	}
+	return []ChatMessagePart{{Type: ChatMessagePartTypeText, Text: content.Text}}
+}
+
+// SimplifyContent returns messages with content made of a single text part
+// turned into text, which every provider accepts. Parts with cache control
+// are kept, since text cannot carry it.
+func SimplifyContent(messages []ChatCompletionMessage) []ChatCompletionMessage {
+	simplified := make([]ChatCompletionMessage, len(messages))
+	for i, message := range messages {
+		if parts := message.Content.Multi; len(parts) == 1 &&
+			parts[0] == (ChatMessagePart{Type: ChatMessagePartTypeText, Text: parts[0].Text}) {
+			message.Content = Content{Text: parts[0].Text}
+		}
+		simplified[i] = message
+	}
+	return simplified
+}
+
+// AlternateRoles returns messages reshaped for providers requiring the
+// conversation after the system messages to start with a user message and
+// alternate between user and assistant turns, such as Anthropic and some
+// open-weight chat templates. Consecutive messages of the same role are
+// merged, and placeholder is inserted as a user message before a conversation
+// starting with the assistant. Tool results following an assistant message
+// count as the user turn, as OpenRouter sends them that way.
+func AlternateRoles(messages []ChatCompletionMessage, placeholder string) []ChatCompletionMessage {
+	messages = MergeConsecutiveMessages(messages)
+	system := leadingSystemMessages(messages)
+	if len(messages) == system || messages[system].Role != ChatMessageRoleAssistant {
+		return messages
+	}
+	return slices.Insert(messages, system, UserMessage(placeholder))
+}
@@ -0,0 +1,70 @@
+package openrouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMessages(t *testing.T) {
+	t.Parallel()
+
+	image := ChatMessagePart{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{URL: "https://example.com/a.png"}}
+	messages := []ChatCompletionMessage{
+		SystemMessage("Be brief."),
+		SystemMessage("Answer in French."),
+		UserMessage("Hi"),
+		UserMessage("  "),
+		{Role: ChatMessageRoleUser, Content: Content{Multi: []ChatMessagePart{image}}},
+		{Role: ChatMessageRoleAssistant, Content: Content{Multi: []ChatMessagePart{{Type: ChatMessagePartTypeText, Text: "Bonjour"}}}},
+		ToolMessage("call_1", ""),
+		ToolMessage("call_2", "sunny"),
+	}
+
+	require.Equal(t, []ChatCompletionMessage{
+		SystemMessage("Be brief.\n\nAnswer in French."),
+		{Role: ChatMessageRoleUser, Content: Content{Multi: []ChatMessagePart{
+			{Type: ChatMessagePartTypeText, Text: "Hi"},
+			image,
+		}}},
+		AssistantMessage("Bonjour"),
+		ToolMessage("call_1", ""),
+		ToolMessage("call_2", "sunny"),
+	}, NormalizeMessages(messages))
+	require.Equal(t, "Hi", messages[2].Content.Text, "the messages are not modified")
+}
+
+func TestMergeConsecutiveMessagesKeepsReasoningApart(t *testing.T) {
+	t.Parallel()
+
+	reasoning := "thinking"
+	first := AssistantMessage("A")
+	first.Reasoning = &reasoning
+	merged := MergeConsecutiveMessages([]ChatCompletionMessage{first, AssistantMessage("B")})
+	require.Len(t, merged, 2)
+}
+
+func TestSimplifyContentKeepsCacheControl(t *testing.T) {
+	t.Parallel()
+
+	cached := NewUserMessage().Text("Long document").Cached(0).Build()
+	require.Equal(t, []ChatCompletionMessage{cached}, SimplifyContent([]ChatCompletionMessage{cached}))
+}
+
+func TestAlternateRoles(t *testing.T) {
+	t.Parallel()
+
+	alternated := AlternateRoles([]ChatCompletionMessage{
+		SystemMessage("Be brief."),
+		AssistantMessage("How can I help?"),
+		UserMessage("Hi"),
+		UserMessage("Are you there?"),
+	}, "(start)")
+
+	require.Equal(t, []ChatCompletionMessage{
+		SystemMessage("Be brief."),
+		UserMessage("(start)"),
+		AssistantMessage("How can I help?"),
+		UserMessage("Hi\n\nAre you there?"),
+	}, alternated)
+}
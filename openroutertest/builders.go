@@ -0,0 +1,145 @@
+package openroutertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	openrouter "github.com/revrost/go-openrouter"
+)
+
+// ChatResponseBuilder builds a chat completion response. Its methods change the
+// response and return the builder, so it is built by chaining them:
+//
+//	openroutertest.NewChatResponse().
+//		WithToolCall("get_weather", map[string]any{"city": "Paris"}).
+//		WithUsage(82, 51, 0.0007).
+//		Build()
+type ChatResponseBuilder struct {
+	response openrouter.ChatCompletionResponse
+}
+
+// NewChatResponse returns a builder of a response with a single empty
+// assistant message, finished with FinishReasonStop.
+func NewChatResponse() *ChatResponseBuilder {
+	return &ChatResponseBuilder{response: openrouter.ChatCompletionResponse{
+		ID:       StreamID,
+		Object:   "chat.completion",
+		Created:  1735689600,
+		Model:    "openai/gpt-4o",
+		Provider: "OpenAI",
+		Choices: []openrouter.ChatCompletionChoice{{
+			Message:      openrouter.ChatCompletionMessage{Role: openrouter.ChatMessageRoleAssistant},
+			FinishReason: openrouter.FinishReasonStop,
+		}},
+	}}
+}
+
+func (b *ChatResponseBuilder) message() *openrouter.ChatCompletionMessage {
+	return &b.response.Choices[0].Message
+}
+
+// WithID sets the generation ID.
+func (b *ChatResponseBuilder) WithID(id string) *ChatResponseBuilder {
+	b.response.ID = id
+	return b
+}
+
+// WithModel sets the model and the provider that served it.
+func (b *ChatResponseBuilder) WithModel(model, provider string) *ChatResponseBuilder {
+	b.response.Model = model
+	b.response.Provider = provider
+	return b
+}
+
+// WithContent sets the text of the message.
+func (b *ChatResponseBuilder) WithContent(text string) *ChatResponseBuilder {
+	b.message().Content = openrouter.Content{Text: text}
+	return b
+}
+
+// WithReasoning sets the reasoning of the message, in both the reasoning field
+// and a reasoning.text detail, as reasoning models return it.
+func (b *ChatResponseBuilder) WithReasoning(text string) *ChatResponseBuilder {
+	message := b.message()
+	message.Reasoning = &text
+	message.ReasoningDetails = []openrouter.ChatCompletionReasoningDetails{{
+		Type:   openrouter.ReasoningDetailsTypeText,
+		Text:   text,
+		Format: "unknown",
+	}}
+	return b
+}
+
+// WithToolCall adds a call to the function name and finishes the message with
+// FinishReasonToolCalls. Arguments are sent as is when they are a string, and
+// encoded as JSON otherwise.
+func (b *ChatResponseBuilder) WithToolCall(name string, arguments any) *ChatResponseBuilder {
+	encoded, ok := arguments.(string)
+	if !ok {
+		data, err := json.Marshal(arguments)
+		if err != nil {
+			panic(fmt.Sprintf("openroutertest: encode tool call arguments: %v", err))
+		}
+		encoded = string(data)
+	}
+
+	message := b.message()
+	index := len(message.ToolCalls)
+	message.ToolCalls = append(message.ToolCalls, openrouter.ToolCall{
+		Index:    &index,
+		ID:       fmt.Sprintf("call_%d", index+1),
+		Type:     openrouter.ToolTypeFunction,
+		Function: openrouter.FunctionCall{Name: name, Arguments: encoded},
+	})
+	b.response.Choices[0].FinishReason = openrouter.FinishReasonToolCalls
+	return b
+}
+
+// WithFinishReason sets the finish reason of the message.
+func (b *ChatResponseBuilder) WithFinishReason(reason openrouter.FinishReason) *ChatResponseBuilder {
+	b.response.Choices[0].FinishReason = reason
+	return b
+}
+
+// WithUsage sets the reported token counts and cost.
+func (b *ChatResponseBuilder) WithUsage(promptTokens, completionTokens int, cost float64) *ChatResponseBuilder {
+	b.response.Usage = &openrouter.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		Cost:             cost,
+	}
+	return b
+}
+
+// Build returns the response.
+func (b *ChatResponseBuilder) Build() openrouter.ChatCompletionResponse {
+	return b.response
+}
+
+// JSON returns the response as sent by the API.
+func (b *ChatResponseBuilder) JSON() []byte {
+	data, err := json.Marshal(b.response)
+	if err != nil {
+		panic(fmt.Sprintf("openroutertest: encode response: %v", err))
+	}
+	return data
+}
+
+// HTTPResponse returns the response as an *http.Response, for fake HTTP
+// clients.
+func (b *ChatResponseBuilder) HTTPResponse() *http.Response {
+	return jsonResponse(http.StatusOK, b.JSON())
+}
+
+func jsonResponse(status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
@@ -0,0 +1,44 @@
+package openroutertest_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatResponseBuilder(t *testing.T) {
+	t.Parallel()
+
+	builder := openroutertest.NewChatResponse().
+		WithModel("anthropic/claude-3.5-sonnet", "Anthropic").
+		WithReasoning("The user wants the weather.").
+		WithToolCall("get_weather", map[string]any{"city": "Paris"}).
+		WithToolCall("get_time", `{"tz":"Europe/Paris"}`).
+		WithUsage(10, 5, 0.001)
+
+	var decoded openrouter.ChatCompletionResponse
+	require.NoError(t, json.Unmarshal(builder.JSON(), &decoded))
+	resp := builder.Build()
+	require.Equal(t, resp.Choices[0].Message.ToolCalls, decoded.Choices[0].Message.ToolCalls)
+
+	require.Equal(t, "Anthropic", resp.Provider)
+	require.Equal(t, openrouter.FinishReasonToolCalls, resp.Choices[0].FinishReason)
+	calls := resp.Choices[0].Message.ToolCalls
+	require.Len(t, calls, 2)
+	require.Equal(t, "call_1", calls[0].ID)
+	require.JSONEq(t, `{"city":"Paris"}`, calls[0].Function.Arguments)
+	require.Equal(t, `{"tz":"Europe/Paris"}`, calls[1].Function.Arguments)
+	require.Equal(t, 15, resp.Usage.TotalTokens)
+	require.Equal(t, "The user wants the weather.", *resp.Choices[0].Message.Reasoning)
+}
+
+func TestFixtureResponse(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 200, openroutertest.FixtureResponse(openroutertest.FixtureChatToolCalls).StatusCode)
+	require.Equal(t, 403, openroutertest.FixtureResponse(openroutertest.FixtureErrorModeration).StatusCode)
+	require.Panics(t, func() { openroutertest.Fixture("missing") })
+}
@@ -0,0 +1,167 @@
+package openroutertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrCassetteMiss is returned by a replaying Cassette for a request it has no
+// recorded interaction for.
+var ErrCassetteMiss = errors.New("openroutertest: no recorded interaction for the request")
+
+// Interaction is a request and its response as recorded by a Cassette. Only
+// the method, URL and body of the request are kept, so API keys and other
+// headers are never written to disk.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the recorded part of a request.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded response.
+type CassetteResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Cassette is an http.RoundTripper that records the requests it sends and
+// replays them later without network access, for tests of code calling the
+// API:
+//
+//	cassette := openroutertest.NewRecorder(nil)
+//	config := openrouter.DefaultConfig(apiKey)
+//	config.HTTPClient = &http.Client{Transport: cassette}
+//	// ... make the calls ...
+//	err := cassette.Save("testdata/cassette.json")
+//
+// A replaying cassette, from LoadCassette, answers a request with the response
+// recorded for the same method, URL and body, and fails with ErrCassetteMiss
+// for any other. It is safe for concurrent use.
+type Cassette struct {
+	transport http.RoundTripper
+	recording bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a cassette recording the requests it sends with
+// transport, or http.DefaultTransport when nil.
+func NewRecorder(transport http.RoundTripper) *Cassette {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Cassette{transport: transport, recording: true}
+}
+
+// LoadCassette returns a cassette replaying the interactions saved at path.
+// The error wraps fs.ErrNotExist when there is no file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	return &Cassette{interactions: interactions}, nil
+}
+
+// Interactions returns the interactions of the cassette, in the order they
+// were recorded.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save writes the interactions of the cassette to path as JSON, creating its
+// directory.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := CassetteRequest{Method: req.Method, URL: req.URL.String()}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		recorded.Body = string(body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if !c.recording {
+		return c.replay(req, recorded)
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: CassetteResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        string(body),
+		},
+	})
+	c.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded CassetteRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, interaction := range c.interactions {
+		if interaction.Request != recorded {
+			continue
+		}
+		header := make(http.Header)
+		if interaction.Response.ContentType != "" {
+			header.Set("Content-Type", interaction.Response.ContentType)
+		}
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode: interaction.Response.StatusCode,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			Request:    req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, recorded.Method, recorded.URL)
+}
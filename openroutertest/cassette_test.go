@@ -0,0 +1,62 @@
+package openroutertest_test
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func clientWith(transport http.RoundTripper) *openrouter.Client {
+	config := openrouter.DefaultConfig("sk-or-secret")
+	config.HTTPClient = &http.Client{Transport: transport}
+	return openrouter.NewClientWithConfig(*config)
+}
+
+func TestCassette(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	upstream := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return openroutertest.NewChatResponse().WithContent("Short.").HTTPResponse(), nil
+	})
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder := openroutertest.NewRecorder(upstream)
+	summary, err := summarize(ctx, clientWith(recorder), "A long text.")
+	require.NoError(t, err)
+	require.Equal(t, "Short.", summary)
+	require.NoError(t, recorder.Save(path))
+	require.Len(t, recorder.Interactions(), 1)
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(saved), "sk-or-secret")
+
+	cassette, err := openroutertest.LoadCassette(path)
+	require.NoError(t, err)
+	summary, err = summarize(ctx, clientWith(cassette), "A long text.")
+	require.NoError(t, err)
+	require.Equal(t, "Short.", summary)
+	require.Equal(t, 1, calls, "a replay must not reach the network")
+
+	_, err = summarize(ctx, clientWith(cassette), "Another text.")
+	require.ErrorIs(t, err, openroutertest.ErrCassetteMiss)
+
+	_, err = openroutertest.LoadCassette(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
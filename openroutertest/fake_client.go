@@ -0,0 +1,184 @@
+package openroutertest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	openrouter "github.com/revrost/go-openrouter"
+)
+
+// Calls scripts the results of one method of FakeClient and records the
+// requests it received. Results are returned in the order they were enqueued;
+// a call with none left fails. It is safe for concurrent use.
+type Calls[Req, Resp any] struct {
+	method string
+
+	mu       sync.Mutex
+	results  []callResult[Resp]
+	requests []Req
+}
+
+type callResult[Resp any] struct {
+	response Resp
+	err      error
+}
+
+// Return enqueues responses, each answering one call.
+func (c *Calls[Req, Resp]) Return(responses ...Resp) *Calls[Req, Resp] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, response := range responses {
+		c.results = append(c.results, callResult[Resp]{response: response})
+	}
+	return c
+}
+
+// Fail enqueues err, answering one call.
+func (c *Calls[Req, Resp]) Fail(err error) *Calls[Req, Resp] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, callResult[Resp]{err: err})
+	return c
+}
+
+// Requests returns the requests received so far, in order.
+func (c *Calls[Req, Resp]) Requests() []Req {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Req(nil), c.requests...)
+}
+
+// Pending returns the number of results not yet returned.
+func (c *Calls[Req, Resp]) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.results)
+}
+
+func (c *Calls[Req, Resp]) call(request Req) (Resp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, request)
+	if len(c.results) == 0 {
+		var zero Resp
+		return zero, fmt.Errorf("openroutertest: no result enqueued for %s", c.method)
+	}
+	result := c.results[0]
+	c.results = c.results[1:]
+	return result.response, result.err
+}
+
+// FakeClient implements openrouter.ClientInterface without HTTP. Tests enqueue
+// the results of every method on its fields and assert the requests made:
+//
+//	fake := openroutertest.NewFakeClient()
+//	fake.ChatCompletions.Return(openroutertest.NewChatResponse().WithContent("Hi").Build())
+//	// ... run the code under test with fake ...
+//	require.Len(t, fake.ChatCompletions.Requests(), 1)
+//
+// Streams are scripted with a StreamScript, which is decoded by the stream
+// reader of the openrouter package.
+type FakeClient struct {
+	ChatCompletions       *Calls[openrouter.ChatCompletionRequest, openrouter.ChatCompletionResponse]
+	ChatCompletionStreams *Calls[openrouter.ChatCompletionRequest, *StreamScript]
+	Completions           *Calls[openrouter.CompletionRequest, openrouter.CompletionResponse]
+	Embeddings            *Calls[openrouter.EmbeddingsRequest, openrouter.EmbeddingsResponse]
+	Responses             *Calls[openrouter.ResponsesRequest, openrouter.Response]
+	// Generations records the requested IDs, and Models and Credits an empty
+	// struct per call.
+	Models      *Calls[struct{}, []openrouter.Model]
+	Generations *Calls[string, openrouter.Generation]
+	Credits     *Calls[struct{}, openrouter.Credits]
+}
+
+var _ openrouter.ClientInterface = (*FakeClient)(nil)
+
+// NewFakeClient returns a FakeClient without enqueued results.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		ChatCompletions:       &Calls[openrouter.ChatCompletionRequest, openrouter.ChatCompletionResponse]{method: "CreateChatCompletion"},
+		ChatCompletionStreams: &Calls[openrouter.ChatCompletionRequest, *StreamScript]{method: "CreateChatCompletionStream"},
+		Completions:           &Calls[openrouter.CompletionRequest, openrouter.CompletionResponse]{method: "CreateCompletion"},
+		Embeddings:            &Calls[openrouter.EmbeddingsRequest, openrouter.EmbeddingsResponse]{method: "CreateEmbeddings"},
+		Responses:             &Calls[openrouter.ResponsesRequest, openrouter.Response]{method: "CreateResponse"},
+		Models:                &Calls[struct{}, []openrouter.Model]{method: "ListModels"},
+		Generations:           &Calls[string, openrouter.Generation]{method: "GetGeneration"},
+		Credits:               &Calls[struct{}, openrouter.Credits]{method: "GetCredits"},
+	}
+}
+
+func (f *FakeClient) CreateChatCompletion(
+	_ context.Context,
+	request openrouter.ChatCompletionRequest,
+) (openrouter.ChatCompletionResponse, error) {
+	return f.ChatCompletions.call(request)
+}
+
+// CreateChatCompletionStream returns a stream replaying the next enqueued
+// script.
+func (f *FakeClient) CreateChatCompletionStream(
+	ctx context.Context,
+	request openrouter.ChatCompletionRequest,
+) (*openrouter.ChatCompletionStream, error) {
+	script, err := f.ChatCompletionStreams.call(request)
+	if err != nil {
+		return nil, err
+	}
+
+	config := openrouter.DefaultConfig("openroutertest")
+	config.HTTPClient = scriptDoer{script: script}
+	return openrouter.NewClientWithConfig(*config).CreateChatCompletionStream(ctx, request)
+}
+
+func (f *FakeClient) CreateCompletion(
+	_ context.Context,
+	request openrouter.CompletionRequest,
+) (openrouter.CompletionResponse, error) {
+	return f.Completions.call(request)
+}
+
+func (f *FakeClient) CreateEmbeddings(
+	_ context.Context,
+	request openrouter.EmbeddingsRequest,
+) (openrouter.EmbeddingsResponse, error) {
+	return f.Embeddings.call(request)
+}
+
+func (f *FakeClient) CreateResponse(
+	_ context.Context,
+	request openrouter.ResponsesRequest,
+) (openrouter.Response, error) {
+	return f.Responses.call(request)
+}
+
+func (f *FakeClient) ListModels(context.Context) ([]openrouter.Model, error) {
+	return f.Models.call(struct{}{})
+}
+
+func (f *FakeClient) GetGeneration(_ context.Context, id string) (openrouter.Generation, error) {
+	return f.Generations.call(id)
+}
+
+func (f *FakeClient) GetCredits(context.Context) (openrouter.Credits, error) {
+	return f.Credits.call(struct{}{})
+}
+
+// scriptDoer answers every request with script, without a server.
+type scriptDoer struct {
+	script *StreamScript
+}
+
+func (d scriptDoer) Do(req *http.Request) (*http.Response, error) {
+	if script := d.script; script != nil {
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+			Body:       script.body(req.Context()),
+		}, nil
+	}
+	return jsonResponse(http.StatusInternalServerError,
+		[]byte(`{"error":{"code":500,"message":"openroutertest: nil stream script"}}`)), nil
+}
@@ -0,0 +1,77 @@
+package openroutertest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+// summarize stands for application code depending on the client interface.
+func summarize(ctx context.Context, client openrouter.ClientInterface, text string) (string, error) {
+	resp, err := client.CreateChatCompletion(ctx, openrouter.ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Summarize: " + text)},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Choices[0].Message.Content.Text, nil
+}
+
+func TestFakeClient(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	unavailable := errors.New("unavailable")
+	fake.ChatCompletions.
+		Return(openroutertest.NewChatResponse().WithContent("Short.").Build()).
+		Fail(unavailable)
+
+	summary, err := summarize(context.Background(), fake, "A long text.")
+	require.NoError(t, err)
+	require.Equal(t, "Short.", summary)
+	_, err = summarize(context.Background(), fake, "Another text.")
+	require.ErrorIs(t, err, unavailable)
+	_, err = summarize(context.Background(), fake, "One too many.")
+	require.ErrorContains(t, err, "no result enqueued for CreateChatCompletion")
+
+	requests := fake.ChatCompletions.Requests()
+	require.Len(t, requests, 3)
+	require.Equal(t, "Summarize: A long text.", requests[0].Messages[0].Content.Text)
+	require.Zero(t, fake.ChatCompletions.Pending())
+
+	fake.Generations.Return(openrouter.Generation{ID: "gen-1"})
+	generation, err := fake.GetGeneration(context.Background(), "gen-1")
+	require.NoError(t, err)
+	require.Equal(t, "gen-1", generation.ID)
+	require.Equal(t, []string{"gen-1"}, fake.Generations.Requests())
+}
+
+func TestFakeClientStreams(t *testing.T) {
+	t.Parallel()
+
+	fake := openroutertest.NewFakeClient()
+	fake.ChatCompletionStreams.Return(
+		openroutertest.NewStreamScript().Content("Hel", "lo").Done(),
+		openroutertest.NewStreamScript().Content("Hel").Abort(),
+	)
+
+	stream, err := fake.CreateChatCompletionStream(context.Background(), request)
+	require.NoError(t, err)
+	defer stream.Close()
+	text, err := io.ReadAll(stream.TextReader())
+	require.NoError(t, err)
+	require.Equal(t, "Hello", string(text))
+
+	stream, err = fake.CreateChatCompletionStream(context.Background(), request)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = io.ReadAll(stream.TextReader())
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Len(t, fake.ChatCompletionStreams.Requests(), 2)
+}
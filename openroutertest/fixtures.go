@@ -0,0 +1,57 @@
+package openroutertest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Fixtures are response bodies captured from the API, trimmed of identifying
+// details.
+const (
+	// FixtureChatReasoning is a chat completion of a reasoning model, with its
+	// reasoning and reasoning details.
+	FixtureChatReasoning = "chat_reasoning"
+	// FixtureChatToolCalls is a chat completion calling two tools in parallel.
+	FixtureChatToolCalls = "chat_tool_calls"
+	// FixtureErrorModeration is the 403 error of an input flagged by moderation.
+	FixtureErrorModeration = "error_moderation"
+	// FixtureErrorProvider is the 502 error of a provider failure, with the raw
+	// provider error in its metadata.
+	FixtureErrorProvider = "error_provider"
+	// FixtureErrorInsufficientCredits is the 402 error of a request the account
+	// cannot afford.
+	FixtureErrorInsufficientCredits = "error_insufficient_credits"
+	// FixtureErrorRateLimit is the 429 error of a rate-limited free model.
+	FixtureErrorRateLimit = "error_rate_limit"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns the body of the fixture called name. It panics when there
+// is none.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		panic(fmt.Sprintf("openroutertest: unknown fixture %q", name))
+	}
+	return data
+}
+
+// FixtureResponse returns the fixture called name as an *http.Response, whose
+// status is the code of the error for error fixtures and 200 otherwise.
+func FixtureResponse(name string) *http.Response {
+	body := Fixture(name)
+	var payload struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	status := http.StatusOK
+	if json.Unmarshal(body, &payload) == nil && payload.Error != nil && payload.Error.Code != 0 {
+		status = payload.Error.Code
+	}
+	return jsonResponse(status, body)
+}
@@ -0,0 +1,269 @@
+// Package openroutertest provides fakes of the OpenRouter API for testing code
+// built on the openrouter client without reaching the network.
+//
+// FakeClient implements openrouter.ClientInterface with scripted results and
+// records the requests made, for unit tests that should not involve HTTP.
+// A StreamServer replays scripted server-sent event streams, with delays,
+// keep-alive comments, mid-stream errors and dropped connections, so stream
+// handling can be tested deterministically. Responses are built with
+// NewChatResponse or taken from the captured fixtures returned by Fixture.
+package openroutertest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	openrouter "github.com/revrost/go-openrouter"
+)
+
+// StreamID is the ID of the chunks built by StreamScript.
+const StreamID = "gen-openroutertest"
+
+// StreamScript is the sequence of events a StreamServer sends in answer to a
+// request. Its methods append a step and return the script, so it is built by
+// chaining them:
+//
+//	openroutertest.NewStreamScript().
+//		KeepAlive().
+//		Content("Hel", "lo").
+//		Delay(time.Second).
+//		Error(502, "provider disconnected")
+type StreamScript struct {
+	steps []streamStep
+}
+
+type streamStep struct {
+	delay time.Duration
+	line  string
+	abort bool
+}
+
+// NewStreamScript returns an empty script.
+func NewStreamScript() *StreamScript {
+	return &StreamScript{}
+}
+
+// Delay pauses the stream for d before the next step. The pause ends early
+// when the client goes away.
+func (s *StreamScript) Delay(d time.Duration) *StreamScript {
+	s.steps = append(s.steps, streamStep{delay: d})
+	return s
+}
+
+// Comment sends an SSE comment line.
+func (s *StreamScript) Comment(text string) *StreamScript {
+	s.steps = append(s.steps, streamStep{line: ": " + text})
+	return s
+}
+
+// KeepAlive sends the comment OpenRouter uses to keep the connection open
+// while a request is queued.
+func (s *StreamScript) KeepAlive() *StreamScript {
+	return s.Comment("OPENROUTER PROCESSING")
+}
+
+// Chunk sends chunk as a data event. Strings and byte slices are sent as is,
+// other values are encoded as JSON.
+func (s *StreamScript) Chunk(chunk any) *StreamScript {
+	var data string
+	switch chunk := chunk.(type) {
+	case string:
+		data = chunk
+	case []byte:
+		data = string(chunk)
+	default:
+		encoded, err := json.Marshal(chunk)
+		if err != nil {
+			panic(fmt.Sprintf("openroutertest: encode chunk: %v", err))
+		}
+		data = string(encoded)
+	}
+	s.steps = append(s.steps, streamStep{line: "data: " + data})
+	return s
+}
+
+// Content sends a chat completion chunk for every delta, each adding it to the
+// content of the first choice.
+func (s *StreamScript) Content(deltas ...string) *StreamScript {
+	for _, delta := range deltas {
+		s.Chunk(openrouter.ChatCompletionStreamResponse{
+			ID:     StreamID,
+			Object: "chat.completion.chunk",
+			Choices: []openrouter.ChatCompletionStreamChoice{{
+				Delta: openrouter.ChatCompletionStreamChoiceDelta{Role: openrouter.ChatMessageRoleAssistant, Content: delta},
+			}},
+		})
+	}
+	return s
+}
+
+// Finish sends a chat completion chunk ending the first choice with reason.
+func (s *StreamScript) Finish(reason openrouter.FinishReason) *StreamScript {
+	return s.Chunk(openrouter.ChatCompletionStreamResponse{
+		ID:      StreamID,
+		Object:  "chat.completion.chunk",
+		Choices: []openrouter.ChatCompletionStreamChoice{{FinishReason: reason}},
+	})
+}
+
+// Usage sends the final chat completion chunk reporting usage.
+func (s *StreamScript) Usage(usage openrouter.Usage) *StreamScript {
+	return s.Chunk(openrouter.ChatCompletionStreamResponse{
+		ID:      StreamID,
+		Object:  "chat.completion.chunk",
+		Choices: []openrouter.ChatCompletionStreamChoice{},
+		Usage:   &usage,
+	})
+}
+
+// Error sends the chunk OpenRouter uses to report a provider failure after the
+// stream has started: an error, and the first choice finishing with
+// FinishReasonError.
+func (s *StreamScript) Error(code int, message string) *StreamScript {
+	return s.Chunk(openrouter.ChatCompletionStreamResponse{
+		ID:     StreamID,
+		Object: "chat.completion.chunk",
+		Error:  &openrouter.APIError{Code: code, Message: message},
+		Choices: []openrouter.ChatCompletionStreamChoice{{
+			FinishReason: openrouter.FinishReasonError,
+		}},
+	})
+}
+
+// Done sends the [DONE] event ending the stream.
+func (s *StreamScript) Done() *StreamScript {
+	s.steps = append(s.steps, streamStep{line: "data: [DONE]"})
+	return s
+}
+
+// Abort drops the connection, as a network failure would. Later steps are
+// not sent.
+func (s *StreamScript) Abort() *StreamScript {
+	s.steps = append(s.steps, streamStep{abort: true})
+	return s
+}
+
+// RecordedRequest is a request received by a fake server.
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// StreamServer is an HTTP server answering every request with the next of its
+// scripts, and with a 500 error once they are exhausted.
+type StreamServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	scripts  []*StreamScript
+	requests []RecordedRequest
+}
+
+// NewStreamServer starts a StreamServer replaying scripts, which is closed
+// when the test ends.
+func NewStreamServer(t testing.TB, scripts ...*StreamScript) *StreamServer {
+	t.Helper()
+
+	s := &StreamServer{scripts: scripts}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a client sending its requests to the server.
+func (s *StreamServer) Client(opts ...openrouter.Option) *openrouter.Client {
+	config := openrouter.DefaultConfig("openroutertest")
+	config.BaseURL = s.URL
+	for _, opt := range opts {
+		opt(config)
+	}
+	return openrouter.NewClientWithConfig(*config)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *StreamServer) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+func (s *StreamServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+	var script *StreamScript
+	if len(s.scripts) > 0 {
+		script, s.scripts = s.scripts[0], s.scripts[1:]
+	}
+	s.mu.Unlock()
+
+	if script == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"error":{"code":500,"message":"openroutertest: no script left for the request"}}`)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if err := script.write(r.Context(), w, flusher); errors.Is(err, errAborted) {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+var errAborted = errors.New("openroutertest: stream aborted")
+
+// write sends the steps of the script to w, flushing after every event when
+// flusher is not nil. It returns errAborted at an Abort step, and ctx.Err()
+// when ctx is done during a delay.
+func (s *StreamScript) write(ctx context.Context, w io.Writer, flusher http.Flusher) error {
+	for _, step := range s.steps {
+		switch {
+		case step.abort:
+			return errAborted
+		case step.delay > 0:
+			timer := time.NewTimer(step.delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		default:
+			if _, err := fmt.Fprintf(w, "%s\n\n", step.line); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	return nil
+}
+
+// body returns a response body streaming the script as the client reads it.
+// An Abort step fails the read with io.ErrUnexpectedEOF, as a dropped
+// connection would.
+func (s *StreamScript) body(ctx context.Context) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		err := s.write(ctx, writer, nil)
+		if errors.Is(err, errAborted) {
+			err = io.ErrUnexpectedEOF
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader
+}
@@ -0,0 +1,106 @@
+package openroutertest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/openroutertest"
+	"github.com/stretchr/testify/require"
+)
+
+var request = openrouter.ChatCompletionRequest{
+	Model:    "openai/gpt-4o",
+	Messages: []openrouter.ChatCompletionMessage{openrouter.UserMessage("Hello")},
+}
+
+func TestStreamServerReplaysScripts(t *testing.T) {
+	t.Parallel()
+
+	server := openroutertest.NewStreamServer(t,
+		openroutertest.NewStreamScript().
+			KeepAlive().
+			Content("Hel", "lo").
+			Finish(openrouter.FinishReasonStop).
+			Usage(openrouter.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}).
+			Done(),
+		openroutertest.NewStreamScript().
+			Content("Partial").
+			Error(http.StatusBadGateway, "provider disconnected"),
+	)
+	var comments []string
+	client := server.Client(openrouter.WithStreamCommentHook(func(comment string) {
+		comments = append(comments, comment)
+	}))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), request)
+	require.NoError(t, err)
+	defer stream.Close()
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, chunk.Err())
+		if len(chunk.Choices) > 0 {
+			content += chunk.Choices[0].Delta.Content
+		}
+	}
+	require.Equal(t, "Hello", content)
+	require.Equal(t, 5, stream.Usage().TotalTokens)
+	require.Equal(t, []string{"OPENROUTER PROCESSING"}, comments)
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), request)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	var genErr *openrouter.GenerationError
+	require.ErrorAs(t, chunk.Err(), &genErr)
+
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	require.True(t, openrouter.IsErrorCode(err, http.StatusInternalServerError))
+
+	requests := server.Requests()
+	require.Len(t, requests, 3)
+	require.Equal(t, "/chat/completions", requests[0].Path)
+	var sent openrouter.ChatCompletionRequest
+	require.NoError(t, json.Unmarshal(requests[0].Body, &sent))
+	require.True(t, sent.Stream)
+}
+
+func TestStreamServerDelaysAndAborts(t *testing.T) {
+	t.Parallel()
+
+	server := openroutertest.NewStreamServer(t,
+		openroutertest.NewStreamScript().Content("Hi").Delay(time.Second).Done(),
+		openroutertest.NewStreamScript().Content("Hi").Abort().Done(),
+	)
+	client := server.Client(openrouter.WithStreamIdleTimeout(20 * time.Millisecond))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), request)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, openrouter.ErrStreamStalled)
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), request)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Error(t, err)
+	require.NotErrorIs(t, err, io.EOF)
+}
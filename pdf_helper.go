@@ -1,8 +1,17 @@
 package openrouter
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"strings"
+	"time"
 )
 
 // CreatePDFPlugin creates a completion plugin to process PDFs using the specified engine.
@@ -17,13 +26,119 @@ func CreatePDFPlugin(engine PDFEngine) ChatCompletionPlugin {
 	}
 }
 
+// PDFPageExtractor extracts pages from a PDF, for sending only the part of a
+// long document a prompt is about. The standard library has no PDF parser, so
+// implementations typically wrap a PDF library or a tool such as qpdf.
+type PDFPageExtractor interface {
+	// ExtractPages returns a PDF made of the pages first to last of pdf,
+	// numbered from 1 and inclusive.
+	ExtractPages(pdf []byte, first, last int) ([]byte, error)
+}
+
+// PDFPageExtractorFunc adapts a function to a PDFPageExtractor.
+type PDFPageExtractorFunc func(pdf []byte, first, last int) ([]byte, error)
+
+func (f PDFPageExtractorFunc) ExtractPages(pdf []byte, first, last int) ([]byte, error) {
+	return f(pdf, first, last)
+}
+
+// PDFOption configures how the PDF helpers prepare a PDF before it is base64
+// encoded.
+type PDFOption func(*pdfOptions)
+
+type pdfOptions struct {
+	extractor   PDFPageExtractor
+	first, last int
+	maxBytes    int64
+	httpClient  HTTPDoer
+}
+
+// PDFPages sends only the pages first to last, numbered from 1 and inclusive,
+// as extracted by extractor.
+func PDFPages(extractor PDFPageExtractor, first, last int) PDFOption {
+	return func(o *pdfOptions) {
+		o.extractor = extractor
+		o.first = first
+		o.last = last
+	}
+}
+
+// PDFMaxBytes makes the helpers fail with a *MediaTooLargeError instead of
+// sending a PDF larger than n bytes, which would make a request so large that
+// it times out. Without PDFPages reading stops as soon as the PDF exceeds the
+// limit; with PDFPages the limit applies to the extracted pages, and the whole
+// document is read.
+func PDFMaxBytes(n int64) PDFOption {
+	return func(o *pdfOptions) {
+		o.maxBytes = n
+	}
+}
+
+// PDFHTTPClient makes UserMessageWithPDFFromURL download the PDF with doer,
+// e.g. the HTTP client of the application with its proxy and timeouts. By
+// default it uses a client that gives up after DefaultPDFDownloadTimeout.
+func PDFHTTPClient(doer HTTPDoer) PDFOption {
+	return func(o *pdfOptions) {
+		o.httpClient = doer
+	}
+}
+
+// DefaultPDFDownloadTimeout bounds the download of UserMessageWithPDFFromURL
+// when no PDFHTTPClient is given.
+const DefaultPDFDownloadTimeout = time.Minute
+
+var defaultPDFHTTPClient = &http.Client{Timeout: DefaultPDFDownloadTimeout}
+
+func newPDFOptions(opts []PDFOption) pdfOptions {
+	options := pdfOptions{httpClient: defaultPDFHTTPClient}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.httpClient == nil {
+		options.httpClient = defaultPDFHTTPClient
+	}
+	return options
+}
+
+// readLimit returns the number of bytes of a PDF to read at most before
+// preparing it, zero for no limit.
+func (o pdfOptions) readLimit() int64 {
+	if o.extractor != nil {
+		return 0
+	}
+	return o.maxBytes
+}
+
+// preparePDF returns the part of pdf to send according to options.
+func (o pdfOptions) preparePDF(filename string, pdf []byte) ([]byte, error) {
+	if o.extractor != nil {
+		if o.first < 1 || o.last < o.first {
+			return nil, fmt.Errorf("invalid page range %d-%d", o.first, o.last)
+		}
+		pages, err := o.extractor.ExtractPages(pdf, o.first, o.last)
+		if err != nil {
+			return nil, fmt.Errorf("extract pages %d-%d of %s: %w", o.first, o.last, filename, err)
+		}
+		if !bytes.HasPrefix(pages, []byte("%PDF")) {
+			return nil, fmt.Errorf("pages %d-%d of %s are not a PDF", o.first, o.last, filename)
+		}
+		pdf = pages
+	}
+	if o.maxBytes > 0 && int64(len(pdf)) > o.maxBytes {
+		return nil, &MediaTooLargeError{Limit: o.maxBytes}
+	}
+	return pdf, nil
+}
+
 // UserMessageWithPDFFromFile creates a user message with text and PDF content from a file.
-// It reads the PDF file and creates a message with the embedded PDF data.
-func UserMessageWithPDFFromFile(text, filePath string) (ChatCompletionMessage, error) {
-	fileData, err := os.ReadFile(filePath)
+// It reads the PDF file and creates a message with the PDF embedded as a base64 data URL,
+// prepared according to opts.
+func UserMessageWithPDFFromFile(text, filePath string, opts ...PDFOption) (ChatCompletionMessage, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
 		return ChatCompletionMessage{}, err
 	}
+	defer file.Close()
 
 	filename := filePath
 	if idx := strings.LastIndex(filePath, "\\"); idx != -1 {
@@ -33,22 +148,66 @@ func UserMessageWithPDFFromFile(text, filePath string) (ChatCompletionMessage, e
 		filename = filename[idx+1:]
 	}
 
-	return ChatCompletionMessage{
-		Role: ChatMessageRoleUser,
-		Content: Content{
-			Multi: []ChatMessagePart{
-				{
-					Type: ChatMessagePartTypeText,
-					Text: text,
-				},
-				{
-					Type: ChatMessagePartTypeFile,
-					File: &FileContent{
-						Filename: filename,
-						FileData: string(fileData),
-					},
-				},
-			},
-		},
-	}, nil
+	return UserMessageWithPDFFromReader(text, filename, file, opts...)
+}
+
+// UserMessageWithPDFFromReader creates a user message with text and PDF content read from r,
+// embedded as a base64 data URL and prepared according to opts. It fails when the content does
+// not start with the %PDF signature, and with a *MediaTooLargeError when it exceeds PDFMaxBytes.
+func UserMessageWithPDFFromReader(
+	text, filename string,
+	r io.Reader,
+	opts ...PDFOption,
+) (ChatCompletionMessage, error) {
+	options := newPDFOptions(opts)
+	pdf, err := readMedia(r, options.readLimit())
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		return ChatCompletionMessage{}, fmt.Errorf("%s is not a PDF", filename)
+	}
+	if pdf, err = options.preparePDF(filename, pdf); err != nil {
+		return ChatCompletionMessage{}, err
+	}
+
+	return UserMessageWithPDF(text, filename, "data:application/pdf;base64,"+base64.StdEncoding.EncodeToString(pdf)), nil
+}
+
+// UserMessageWithPDFFromURL downloads the PDF at pdfURL and creates a user message with text and
+// the PDF embedded as for UserMessageWithPDFFromReader, so the PDF is validated and size limited
+// by PDFMaxBytes before it is sent, and prepared according to opts. It downloads with the
+// PDFHTTPClient option. The filename is the last element of the URL path.
+func UserMessageWithPDFFromURL(
+	ctx context.Context,
+	text, pdfURL string,
+	opts ...PDFOption,
+) (ChatCompletionMessage, error) {
+	parsed, err := url.Parse(pdfURL)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pdfURL, nil)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+
+	options := newPDFOptions(opts)
+	res, err := options.httpClient.Do(req)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ChatCompletionMessage{}, fmt.Errorf("download %s: %s", pdfURL, res.Status)
+	}
+	if limit := options.readLimit(); limit > 0 && res.ContentLength > limit {
+		return ChatCompletionMessage{}, &MediaTooLargeError{Limit: limit}
+	}
+
+	filename := path.Base(parsed.Path)
+	if filename == "/" || filename == "." {
+		filename = "document.pdf"
+	}
+	return UserMessageWithPDFFromReader(text, filename, res.Body, opts...)
 }
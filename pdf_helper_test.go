@@ -0,0 +1,164 @@
+package openrouter_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/stretchr/testify/require"
+)
+
+const testPDF = "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<<>>\nendobj\n%%EOF\n"
+
+func TestUserMessageWithPDFFromReader(t *testing.T) {
+	t.Parallel()
+
+	msg, err := openrouter.UserMessageWithPDFFromReader("Summarize this.", "report.pdf", strings.NewReader(testPDF))
+	require.NoError(t, err)
+	require.Equal(t, "Summarize this.", msg.Content.Multi[0].Text)
+	file := msg.Content.Multi[1].File
+	require.Equal(t, "report.pdf", file.Filename)
+	require.Equal(t, "data:application/pdf;base64,"+base64.StdEncoding.EncodeToString([]byte(testPDF)), file.FileData)
+
+	_, err = openrouter.UserMessageWithPDFFromReader("Summarize this.", "notes.txt", strings.NewReader("plain text"))
+	require.EqualError(t, err, "notes.txt is not a PDF")
+
+	_, err = openrouter.UserMessageWithPDFFromReader("Summarize this.", "report.pdf", strings.NewReader(testPDF),
+		openrouter.PDFMaxBytes(10))
+	var tooLarge *openrouter.MediaTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+}
+
+func TestUserMessageWithPDFFromFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte(testPDF), 0o600))
+
+	msg, err := openrouter.UserMessageWithPDFFromFile("Summarize this.", path)
+	require.NoError(t, err)
+	require.Equal(t, "report.pdf", msg.Content.Multi[1].File.Filename)
+	require.True(t, strings.HasPrefix(msg.Content.Multi[1].File.FileData, "data:application/pdf;base64,"))
+}
+
+func TestUserMessageWithPDFFromURL(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/papers/report.pdf" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testPDF))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	msg, err := openrouter.UserMessageWithPDFFromURL(ctx, "Summarize this.", server.URL+"/papers/report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "report.pdf", msg.Content.Multi[1].File.Filename)
+	require.Equal(t,
+		"data:application/pdf;base64,"+base64.StdEncoding.EncodeToString([]byte(testPDF)),
+		msg.Content.Multi[1].File.FileData)
+
+	_, err = openrouter.UserMessageWithPDFFromURL(ctx, "Summarize this.", server.URL+"/papers/report.pdf",
+		openrouter.PDFMaxBytes(10))
+	var tooLarge *openrouter.MediaTooLargeError
+	require.True(t, errors.As(err, &tooLarge), "the declared length is checked before reading")
+
+	_, err = openrouter.UserMessageWithPDFFromURL(ctx, "Summarize this.", server.URL+"/missing.pdf")
+	require.ErrorContains(t, err, "404 Not Found")
+}
+
+// doerFunc adapts a function to an openrouter.HTTPDoer.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestUserMessageWithPDFFromURLHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	var requested string
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		requested = req.URL.String()
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader(testPDF)),
+			ContentLength: int64(len(testPDF)),
+		}, nil
+	})
+
+	msg, err := openrouter.UserMessageWithPDFFromURL(context.Background(), "Summarize this.",
+		"https://example.com/report.pdf", openrouter.PDFHTTPClient(doer))
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/report.pdf", requested)
+	require.Equal(t, "report.pdf", msg.Content.Multi[1].File.Filename)
+}
+
+func TestUserMessageWithPDFPages(t *testing.T) {
+	t.Parallel()
+
+	var calls [][2]int
+	extractor := openrouter.PDFPageExtractorFunc(func(pdf []byte, first, last int) ([]byte, error) {
+		require.Equal(t, testPDF, string(pdf))
+		calls = append(calls, [2]int{first, last})
+		return []byte("%PDF-1.7\npages\n"), nil
+	})
+
+	msg, err := openrouter.UserMessageWithPDFFromReader("Summarize this.", "report.pdf", strings.NewReader(testPDF),
+		openrouter.PDFPages(extractor, 2, 3), openrouter.PDFMaxBytes(20))
+	require.NoError(t, err)
+	require.Equal(t, [][2]int{{2, 3}}, calls)
+	require.Equal(t,
+		"data:application/pdf;base64,"+base64.StdEncoding.EncodeToString([]byte("%PDF-1.7\npages\n")),
+		msg.Content.Multi[1].File.FileData)
+
+	_, err = openrouter.UserMessageWithPDFFromReader("Summarize this.", "report.pdf", strings.NewReader(testPDF),
+		openrouter.PDFPages(extractor, 3, 2))
+	require.EqualError(t, err, "invalid page range 3-2")
+
+	failing := openrouter.PDFPageExtractorFunc(func([]byte, int, int) ([]byte, error) {
+		return nil, errors.New("no page 9")
+	})
+	_, err = openrouter.UserMessageWithPDFFromReader("Summarize this.", "report.pdf", strings.NewReader(testPDF),
+		openrouter.PDFPages(failing, 9, 9))
+	require.EqualError(t, err, "extract pages 9-9 of report.pdf: no page 9")
+
+	_, err = openrouter.UserMessageWithPDFFromReader("Summarize this.", "report.pdf", strings.NewReader(testPDF),
+		openrouter.PDFPages(extractor, 1, 1), openrouter.PDFMaxBytes(10))
+	var tooLarge *openrouter.MediaTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	require.EqualValues(t, 10, tooLarge.Limit)
+}
+
+func TestUserMessageWithPDFFromFileMaxBytes(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte(testPDF), 0o600))
+
+	_, err := openrouter.UserMessageWithPDFFromFile("Summarize this.", path, openrouter.PDFMaxBytes(int64(len(testPDF))))
+	require.NoError(t, err)
+
+	_, err = openrouter.UserMessageWithPDFFromFile("Summarize this.", path, openrouter.PDFMaxBytes(10))
+	var tooLarge *openrouter.MediaTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+
+	// The limit applies to the extracted pages, not to the file.
+	extractor := openrouter.PDFPageExtractorFunc(func([]byte, int, int) ([]byte, error) {
+		return []byte("%PDF-1.7\n"), nil
+	})
+	_, err = openrouter.UserMessageWithPDFFromFile("Summarize this.", path,
+		openrouter.PDFPages(extractor, 1, 1), openrouter.PDFMaxBytes(10))
+	require.NoError(t, err)
+}
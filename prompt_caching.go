@@ -0,0 +1,146 @@
+package openrouter
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxCacheBreakpoints is the number of cache_control breakpoints a request may
+// carry, as limited by Anthropic.
+const MaxCacheBreakpoints = 4
+
+// PromptCachingStrategy selects where EnablePromptCaching places breakpoints.
+type PromptCachingStrategy struct {
+	// System places a breakpoint at the end of the leading system messages,
+	// caching the instructions shared by every request.
+	System bool
+	// RecentUserMessages places breakpoints on up to this many of the last
+	// user messages, so the conversation so far is read from the cache on the
+	// next turn.
+	RecentUserMessages int
+	// MinTokens is the smallest prefix worth caching; breakpoints on shorter
+	// prefixes are skipped, since providers ignore them. Defaults to the
+	// minimum of the model, e.g. 1024 tokens for Claude Sonnet.
+	MinTokens int
+	// TTL of the cached prefixes. Zero uses the provider default, 5 minutes.
+	TTL time.Duration
+	// Counter counts the tokens of prefixes. Defaults to
+	// HeuristicTokenCounter.
+	Counter TokenCounter
+}
+
+// DefaultPromptCaching caches the system messages and the last two user
+// messages, which keeps the whole conversation cached as it grows.
+var DefaultPromptCaching = PromptCachingStrategy{System: true, RecentUserMessages: 2}
+
+// EnablePromptCaching adds cache_control breakpoints to the messages of req
+// according to strategy, for the models whose providers only cache explicitly
+// marked prefixes: Anthropic Claude and Google Gemini. Other models cache
+// automatically and are left unchanged.
+//
+// Text content of the marked messages is converted to a single text part,
+// since cache control is set on parts. Breakpoints already in req count
+// towards MaxCacheBreakpoints; when there is not room for all, the system
+// breakpoint and then the most recent messages win. It returns the number of
+// breakpoints added.
+//
+// https://openrouter.ai/docs/features/prompt-caching
+func EnablePromptCaching(req *ChatCompletionRequest, strategy PromptCachingStrategy) int {
+	minTokens, ok := promptCachingMinTokens(req.Model)
+	if !ok {
+		return 0
+	}
+	if strategy.MinTokens > 0 {
+		minTokens = strategy.MinTokens
+	}
+	counter := strategy.Counter
+	if counter == nil {
+		counter = HeuristicTokenCounter
+	}
+
+	var candidates []int
+	if strategy.System {
+		if n := leadingSystemMessages(req.Messages); n > 0 {
+			candidates = append(candidates, n-1)
+		}
+	}
+	users := 0
+	for i := len(req.Messages) - 1; i >= 0 && users < strategy.RecentUserMessages; i-- {
+		if req.Messages[i].Role == ChatMessageRoleUser {
+			candidates = append(candidates, i)
+			users++
+		}
+	}
+
+	room := MaxCacheBreakpoints - cacheBreakpoints(req.Messages)
+	messages := append([]ChatCompletionMessage(nil), req.Messages...)
+	added := 0
+	for _, i := range candidates {
+		if added >= room {
+			break
+		}
+		if counter.CountTokens(messages[:i+1]) < minTokens {
+			continue
+		}
+		if message, ok := withCacheBreakpoint(messages[i], strategy.TTL); ok {
+			messages[i] = message
+			added++
+		}
+	}
+	req.Messages = messages
+	return added
+}
+
+// promptCachingMinTokens returns the smallest prefix model caches, and false
+// for models that do not need explicit breakpoints.
+func promptCachingMinTokens(model string) (int, bool) {
+	switch {
+	case strings.HasPrefix(model, "anthropic/"):
+		if strings.Contains(model, "haiku") {
+			return 2048, true
+		}
+		return 1024, true
+	case strings.HasPrefix(model, "google/gemini"):
+		if strings.Contains(model, "pro") {
+			return 2048, true
+		}
+		return 1028, true
+	}
+	return 0, false
+}
+
+// cacheBreakpoints counts the parts of messages with cache control.
+func cacheBreakpoints(messages []ChatCompletionMessage) int {
+	var n int
+	for _, message := range messages {
+		for _, part := range message.Content.Multi {
+			if part.CacheControl != nil {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// withCacheBreakpoint returns message with cache control on its last text
+// part, converting text content to a part. It reports false when message has
+// no text or already ends a cached prefix.
+func withCacheBreakpoint(message ChatCompletionMessage, ttl time.Duration) (ChatCompletionMessage, bool) {
+	if message.Content.Text != "" {
+		message.Content = Content{Multi: []ChatMessagePart{{Type: ChatMessagePartTypeText, Text: message.Content.Text}}}
+	}
+	parts := message.Content.Multi
+	for i := len(parts) - 1; i >= 0; i-- {
+		if (parts[i].Type != ChatMessagePartTypeText && parts[i].Type != "") || parts[i].Text == "" {
+			continue
+		}
+		if parts[i].CacheControl != nil {
+			return message, false
+		}
+		parts = append([]ChatMessagePart(nil), parts...)
+		parts[i].CacheControl = newCacheControl(ttl)
+		message.Content.Multi = parts
+		return message, true
+	}
+	return message, false
+}
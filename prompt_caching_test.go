@@ -0,0 +1,74 @@
+package openrouter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnablePromptCaching(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("All work and no play makes Jack a dull boy. ", 200)
+	request := ChatCompletionRequest{
+		Model: "anthropic/claude-3.5-sonnet",
+		Messages: []ChatCompletionMessage{
+			SystemMessage(long),
+			UserMessage("First question"),
+			AssistantMessage("First answer"),
+			UserMessage("Second question"),
+			AssistantMessage("Second answer"),
+			UserMessage("Third question"),
+		},
+	}
+	original := append([]ChatCompletionMessage(nil), request.Messages...)
+
+	added := EnablePromptCaching(&request, PromptCachingStrategy{System: true, RecentUserMessages: 2, TTL: time.Hour})
+	require.Equal(t, 3, added)
+	for _, i := range []int{0, 3, 5} {
+		parts := request.Messages[i].Content.Multi
+		require.Len(t, parts, 1, i)
+		require.Equal(t, original[i].Content.Text, parts[0].Text)
+		require.Equal(t, "1h", *parts[0].CacheControl.TTL)
+	}
+	require.Equal(t, "First question", request.Messages[1].Content.Text)
+	require.Equal(t, "First question", original[1].Content.Text)
+	require.Equal(t, long, original[0].Content.Text, "the messages of the caller are not modified")
+
+	require.Zero(t, EnablePromptCaching(&request, DefaultPromptCaching), "breakpoints are not repeated")
+}
+
+func TestEnablePromptCachingLimits(t *testing.T) {
+	t.Parallel()
+
+	short := ChatCompletionRequest{
+		Model:    "anthropic/claude-3-haiku",
+		Messages: []ChatCompletionMessage{SystemMessage("Be brief."), UserMessage("Hi")},
+	}
+	require.Zero(t, EnablePromptCaching(&short, DefaultPromptCaching), "prefix under the minimum")
+	require.Equal(t, "Hi", short.Messages[1].Content.Text)
+
+	automatic := ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{SystemMessage(strings.Repeat("x", 10000))},
+	}
+	require.Zero(t, EnablePromptCaching(&automatic, DefaultPromptCaching), "OpenAI caches automatically")
+
+	long := strings.Repeat("y", 10000)
+	var messages []ChatCompletionMessage
+	for range 6 {
+		messages = append(messages, NewUserMessage().Text(long).Cached(0).Build())
+	}
+	messages[5] = UserMessage(long)
+	messages[4] = UserMessage(long)
+	full := ChatCompletionRequest{Model: "google/gemini-2.5-flash", Messages: messages}
+	require.Equal(t, 4, cacheBreakpoints(full.Messages))
+	require.Zero(t, EnablePromptCaching(&full, PromptCachingStrategy{RecentUserMessages: 2}))
+
+	full.Messages = full.Messages[1:]
+	require.Equal(t, 1, EnablePromptCaching(&full, PromptCachingStrategy{RecentUserMessages: 2}))
+	require.NotNil(t, full.Messages[4].Content.Multi[0].CacheControl, "the most recent message wins")
+	require.Equal(t, long, full.Messages[3].Content.Text)
+}
@@ -0,0 +1,123 @@
+package openrouter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProviderBuilder builds the provider routing of a request, e.g.
+//
+//	routing, err := NewProvider().
+//		Order("anthropic", "openai").
+//		RequireParameters().
+//		DenyDataCollection().
+//		MaxPromptPrice(1.0).
+//		Sort(ProviderSortingThroughput).
+//		Build()
+type ProviderBuilder struct {
+	provider ChatProvider
+	catalog  []Provider
+	errs     []error
+}
+
+// NewProvider returns a builder of provider routing with the defaults of
+// OpenRouter.
+func NewProvider() *ProviderBuilder {
+	return &ProviderBuilder{}
+}
+
+// Order adds providers to try, in order.
+func (b *ProviderBuilder) Order(slugs ...ProviderSlug) *ProviderBuilder {
+	b.provider.Order = append(b.provider.Order, slugs...)
+	return b
+}
+
+// Only restricts the request to providers.
+func (b *ProviderBuilder) Only(slugs ...ProviderSlug) *ProviderBuilder {
+	b.provider.Only = append(b.provider.Only, slugs...)
+	return b
+}
+
+// Ignore skips providers.
+func (b *ProviderBuilder) Ignore(slugs ...ProviderSlug) *ProviderBuilder {
+	b.provider.Ignore = append(b.provider.Ignore, slugs...)
+	return b
+}
+
+// NoFallbacks fails the request when the providers of Order fail, instead of
+// falling back to other providers.
+func (b *ProviderBuilder) NoFallbacks() *ProviderBuilder {
+	allow := false
+	b.provider.AllowFallbacks = &allow
+	return b
+}
+
+// RequireParameters only uses providers supporting all the parameters of the
+// request.
+func (b *ProviderBuilder) RequireParameters() *ProviderBuilder {
+	b.provider.RequireParameters = true
+	return b
+}
+
+// DenyDataCollection only uses providers that do not store data.
+func (b *ProviderBuilder) DenyDataCollection() *ProviderBuilder {
+	b.provider.DataCollection = DataCollectionDeny
+	return b
+}
+
+// Quantizations only uses providers serving the model at one of levels, e.g.
+// "fp8".
+func (b *ProviderBuilder) Quantizations(levels ...string) *ProviderBuilder {
+	b.provider.Quantizations = append(b.provider.Quantizations, levels...)
+	return b
+}
+
+// Sort orders providers by sorting instead of load balancing them.
+func (b *ProviderBuilder) Sort(sorting ProviderSorting) *ProviderBuilder {
+	b.provider.Sort = sorting
+	return b
+}
+
+// MaxPromptPrice only uses providers charging at most usd per million prompt
+// tokens.
+func (b *ProviderBuilder) MaxPromptPrice(usd float64) *ProviderBuilder {
+	b.maxPrice("prompt", usd).Prompt = usd
+	return b
+}
+
+// MaxCompletionPrice only uses providers charging at most usd per million
+// completion tokens.
+func (b *ProviderBuilder) MaxCompletionPrice(usd float64) *ProviderBuilder {
+	b.maxPrice("completion", usd).Completion = usd
+	return b
+}
+
+func (b *ProviderBuilder) maxPrice(name string, usd float64) *ProviderMaxPrice {
+	if usd <= 0 {
+		b.errs = append(b.errs, fmt.Errorf("max %s price must be positive, got %v", name, usd))
+	}
+	if b.provider.MaxPrice == nil {
+		b.provider.MaxPrice = &ProviderMaxPrice{}
+	}
+	return b.provider.MaxPrice
+}
+
+// Catalog makes Build check the slugs of Order, Only and Ignore against
+// providers, as returned by ListProviders.
+func (b *ProviderBuilder) Catalog(providers []Provider) *ProviderBuilder {
+	b.catalog = providers
+	return b
+}
+
+// Build returns the provider routing, or an error when a price is not
+// positive or, when a catalog is set, a slug is not in it.
+func (b *ProviderBuilder) Build() (ChatProvider, error) {
+	errs := b.errs
+	if b.catalog != nil {
+		errs = append(errs, ValidateProviderSlugs(b.provider, b.catalog))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return ChatProvider{}, err
+	}
+	return b.provider, nil
+}
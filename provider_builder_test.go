@@ -0,0 +1,49 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderBuilder(t *testing.T) {
+	t.Parallel()
+
+	routing, err := NewProvider().
+		Order("anthropic", "openai").
+		RequireParameters().
+		DenyDataCollection().
+		MaxPromptPrice(1.0).
+		Sort(ProviderSortingThroughput).
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(routing)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"order": ["anthropic", "openai"],
+		"require_parameters": true,
+		"data_collection": "deny",
+		"max_price": {"prompt": 1},
+		"sort": "throughput"
+	}`, string(data))
+}
+
+func TestProviderBuilderValidation(t *testing.T) {
+	t.Parallel()
+
+	catalog := []Provider{{Name: "OpenAI", Slug: "openai"}, {Name: "Anthropic", Slug: "anthropic"}}
+
+	_, err := NewProvider().Order("anthropic").Ignore("openai").Catalog(catalog).Build()
+	require.NoError(t, err)
+
+	_, err = NewProvider().Order("anthropc").Catalog(catalog).Build()
+	require.ErrorContains(t, err, "anthropc")
+
+	_, err = NewProvider().Order("anthropc").Build()
+	require.NoError(t, err, "slugs are only checked against a catalog")
+
+	_, err = NewProvider().MaxCompletionPrice(-1).Build()
+	require.ErrorContains(t, err, "max completion price")
+}
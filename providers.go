@@ -0,0 +1,76 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const listProvidersSuffix = "/providers"
+
+// ProviderSlug identifies a provider in ChatProvider.Order, Only and Ignore.
+// It is an alias of string, so the ProviderSlug constants generated into
+// providers_gen.go can be used in those []string lists directly. Only the
+// constants are checked at compile time: a slug spelled out as a string
+// literal, such as "togther", still compiles. Build the lists from the
+// constants, or check them with ValidateProviderSlugs, to catch typos.
+//
+//go:generate go run ./cmd/gencatalog -kind providers -save cmd/gencatalog/catalog/providers.json -o providers_gen.go
+type ProviderSlug = string
+
+// Provider describes an upstream provider that OpenRouter can route requests to.
+type Provider struct {
+	Name string `json:"name"`
+	// Slug identifies the provider in ChatProvider.Order, Only and Ignore.
+	Slug              string  `json:"slug"`
+	PrivacyPolicyURL  *string `json:"privacy_policy_url,omitempty"`
+	TermsOfServiceURL *string `json:"terms_of_service_url,omitempty"`
+	StatusPageURL     *string `json:"status_page_url,omitempty"`
+}
+
+// ListProviders returns the providers OpenRouter routes to.
+// API reference: https://openrouter.ai/docs/api-reference/list-providers
+func (c *Client) ListProviders(ctx context.Context) ([]Provider, error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodGet,
+		c.fullURL(listProvidersSuffix),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data []Provider `json:"data"`
+	}
+
+	if err := c.sendRequest(req, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+// ValidateProviderSlugs checks that every slug in the Order, Only and Ignore
+// lists of routing is one of providers, so typos are caught before the request
+// is sent. Slugs are matched case-insensitively.
+func ValidateProviderSlugs(routing ChatProvider, providers []Provider) error {
+	known := make(map[string]struct{}, len(providers))
+	for _, provider := range providers {
+		known[strings.ToLower(provider.Slug)] = struct{}{}
+	}
+
+	var unknown []string
+	for _, slugs := range [][]string{routing.Order, routing.Only, routing.Ignore} {
+		for _, slug := range slugs {
+			if _, ok := known[strings.ToLower(slug)]; !ok {
+				unknown = append(unknown, slug)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown provider slugs: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
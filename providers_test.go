@@ -0,0 +1,50 @@
+package openrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProviders(t *testing.T) {
+	t.Parallel()
+
+	body := `{"data":[
+		{"name":"OpenAI","slug":"openai","privacy_policy_url":"https://openai.com/policies/privacy-policy/","terms_of_service_url":"https://openai.com/policies/row-terms-of-use/","status_page_url":"https://status.openai.com/"},
+		{"name":"Together","slug":"together","privacy_policy_url":"https://www.together.ai/privacy","terms_of_service_url":null,"status_page_url":null}
+	]}`
+	fakeClient := &fakeHTTPClient{response: &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}}
+	cfg := DefaultConfig("test-token")
+	cfg.BaseURL = "https://example.com/api/v1"
+	cfg.HTTPClient = fakeClient
+
+	providers, err := NewClientWithConfig(*cfg).ListProviders(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, http.MethodGet, fakeClient.lastRequest.Method)
+	require.Equal(t, "https://example.com/api/v1/providers", fakeClient.lastRequest.URL.String())
+
+	require.Len(t, providers, 2)
+	require.Equal(t, "OpenAI", providers[0].Name)
+	require.Equal(t, "openai", providers[0].Slug)
+	require.Equal(t, "https://status.openai.com/", *providers[0].StatusPageURL)
+	require.Nil(t, providers[1].TermsOfServiceURL)
+
+	require.NoError(t, ValidateProviderSlugs(ChatProvider{Order: []string{"OpenAI"}, Ignore: []string{"together"}}, providers))
+	err = ValidateProviderSlugs(ChatProvider{Only: []string{"openai", "anthropc"}}, providers)
+	require.EqualError(t, err, "unknown provider slugs: anthropc")
+}
+
+func TestProviderSlugConstants(t *testing.T) {
+	t.Parallel()
+
+	routing := ChatProvider{Order: []string{ProviderSlugTogether, ProviderSlugDeepinfra}}
+	require.NoError(t, ValidateProviderSlugs(routing, []Provider{{Slug: "together"}, {Slug: "deepinfra"}}))
+}
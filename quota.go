@@ -0,0 +1,288 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned, wrapped in a *QuotaExceededError, for requests
+// of a caller who has used up a quota limit.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaPeriod is the period a quota limit applies to. Periods follow calendar
+// days and months in UTC.
+type QuotaPeriod string
+
+const (
+	QuotaDaily   QuotaPeriod = "daily"
+	QuotaMonthly QuotaPeriod = "monthly"
+)
+
+// window returns the identifier of the period containing t, e.g. "2025-01-02"
+// for a day, and how long the usage of that period must be kept.
+func (p QuotaPeriod) window(t time.Time) (string, time.Duration) {
+	t = t.UTC()
+	if p == QuotaMonthly {
+		return t.Format("2006-01"), 32 * 24 * time.Hour
+	}
+	return t.Format("2006-01-02"), 25 * time.Hour
+}
+
+// QuotaUsage is the cost and tokens used by a caller in a period.
+type QuotaUsage struct {
+	Cost   float64 `json:"cost"`
+	Tokens int     `json:"tokens"`
+}
+
+// QuotaLimits caps the usage of a caller. Zero limits are unlimited.
+type QuotaLimits struct {
+	DailyCost     float64
+	DailyTokens   int
+	MonthlyCost   float64
+	MonthlyTokens int
+}
+
+// QuotaStore persists the usage of callers, so quotas survive restarts and can
+// be shared between processes. Implementations must be safe for concurrent use.
+type QuotaStore interface {
+	// Usage returns the usage of caller in window, zero when none was added.
+	Usage(ctx context.Context, caller string, period QuotaPeriod, window string) (QuotaUsage, error)
+	// Add adds usage to that of caller in window. The usage must be kept for at
+	// least ttl.
+	Add(ctx context.Context, caller string, period QuotaPeriod, window string, usage QuotaUsage, ttl time.Duration) error
+}
+
+// QuotaExceededError reports the limit a caller has reached. It unwraps to
+// ErrQuotaExceeded.
+type QuotaExceededError struct {
+	Caller string
+	Period QuotaPeriod
+	// Limit is "cost" or "tokens".
+	Limit string
+	Used  QuotaUsage
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%v: caller %q reached the %s %s limit", ErrQuotaExceeded, e.Caller, e.Period, e.Limit)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// QuotaManager enforces cost and token limits per caller. The caller of a
+// request is taken from its context, see ContextWithCaller; requests without
+// one are not limited. Usage is recorded from the UsageEvent of every call, so
+// a request is only rejected once an earlier one has reached a limit.
+type QuotaManager struct {
+	// Limits applies to every caller without an entry in LimitsFor.
+	Limits QuotaLimits
+	// LimitsFor, if set, returns the limits of a caller and whether it has
+	// specific ones, e.g. from a plan stored alongside the user.
+	LimitsFor func(caller string) (QuotaLimits, bool)
+	// Store persists usage. Defaults to a MemoryQuotaStore.
+	Store QuotaStore
+
+	now func() time.Time
+}
+
+// NewQuotaManager returns a QuotaManager applying limits to every caller and
+// persisting usage to store, or in memory when store is nil.
+func NewQuotaManager(limits QuotaLimits, store QuotaStore) *QuotaManager {
+	if store == nil {
+		store = NewMemoryQuotaStore()
+	}
+	return &QuotaManager{Limits: limits, Store: store, now: time.Now}
+}
+
+type callerContextKey struct{}
+
+// ContextWithCaller returns a copy of ctx attributing the requests made with it
+// to caller, such as a user ID, for the QuotaManager and UsageSink.
+func ContextWithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// CallerFromContext returns the caller set by ContextWithCaller.
+func CallerFromContext(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(callerContextKey{}).(string)
+	return caller, ok && caller != ""
+}
+
+func (m *QuotaManager) limits(caller string) QuotaLimits {
+	if m.LimitsFor != nil {
+		if limits, ok := m.LimitsFor(caller); ok {
+			return limits
+		}
+	}
+	return m.Limits
+}
+
+func (m *QuotaManager) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
+}
+
+// Usage returns the usage of caller in the current period.
+func (m *QuotaManager) Usage(ctx context.Context, caller string, period QuotaPeriod) (QuotaUsage, error) {
+	window, _ := period.window(m.clock())
+	return m.Store.Usage(ctx, caller, period, window)
+}
+
+// Check returns a *QuotaExceededError when caller has reached any of its limits.
+func (m *QuotaManager) Check(ctx context.Context, caller string) error {
+	limits := m.limits(caller)
+	for _, check := range []struct {
+		period QuotaPeriod
+		cost   float64
+		tokens int
+	}{
+		{QuotaDaily, limits.DailyCost, limits.DailyTokens},
+		{QuotaMonthly, limits.MonthlyCost, limits.MonthlyTokens},
+	} {
+		if check.cost <= 0 && check.tokens <= 0 {
+			continue
+		}
+		used, err := m.Usage(ctx, caller, check.period)
+		if err != nil {
+			return err
+		}
+		if check.cost > 0 && used.Cost >= check.cost {
+			return &QuotaExceededError{Caller: caller, Period: check.period, Limit: "cost", Used: used}
+		}
+		if check.tokens > 0 && used.Tokens >= check.tokens {
+			return &QuotaExceededError{Caller: caller, Period: check.period, Limit: "tokens", Used: used}
+		}
+	}
+	return nil
+}
+
+// Record adds usage to that of caller in the current day and month.
+func (m *QuotaManager) Record(ctx context.Context, caller string, usage QuotaUsage) error {
+	now := m.clock()
+	var errs []error
+	for _, period := range []QuotaPeriod{QuotaDaily, QuotaMonthly} {
+		window, ttl := period.window(now)
+		errs = append(errs, m.Store.Add(ctx, caller, period, window, usage, ttl))
+	}
+	return errors.Join(errs...)
+}
+
+// checkQuota rejects the request of the caller of ctx when it has reached a
+// limit of the QuotaManager of the client.
+func (c *Client) checkQuota(ctx context.Context) error {
+	manager := c.config.QuotaManager
+	if manager == nil {
+		return nil
+	}
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return manager.Check(ctx, caller)
+}
+
+// recordQuota adds the usage of event to the caller of ctx. Responses served
+// from the client-side caches are not counted.
+func (c *Client) recordQuota(ctx context.Context, event UsageEvent) {
+	manager := c.config.QuotaManager
+	caller, ok := CallerFromContext(ctx)
+	if manager == nil || !ok || event.CacheHit || (event.Cost == 0 && event.TotalTokens == 0) {
+		return
+	}
+	if err := manager.Record(ctx, caller, QuotaUsage{Cost: event.Cost, Tokens: event.TotalTokens}); err != nil {
+		c.logger().Error("failed to record quota usage", "caller", caller, "error", err)
+	}
+}
+
+// MemoryQuotaStore is a QuotaStore keeping usage in memory.
+type MemoryQuotaStore struct {
+	mu    sync.Mutex
+	usage map[string]memoryQuotaEntry
+}
+
+type memoryQuotaEntry struct {
+	usage     QuotaUsage
+	expiresAt time.Time
+}
+
+// NewMemoryQuotaStore returns an empty MemoryQuotaStore.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{usage: make(map[string]memoryQuotaEntry)}
+}
+
+func (m *MemoryQuotaStore) Usage(_ context.Context, caller string, period QuotaPeriod, window string) (QuotaUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage[quotaKey(caller, period, window)].usage, nil
+}
+
+func (m *MemoryQuotaStore) Add(_ context.Context, caller string, period QuotaPeriod, window string, usage QuotaUsage, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range m.usage {
+		if !now.Before(entry.expiresAt) {
+			delete(m.usage, key)
+		}
+	}
+	key := quotaKey(caller, period, window)
+	entry := m.usage[key]
+	entry.usage.Cost += usage.Cost
+	entry.usage.Tokens += usage.Tokens
+	entry.expiresAt = now.Add(ttl)
+	m.usage[key] = entry
+	return nil
+}
+
+// CacheQuotaStore is a QuotaStore keeping usage in a CacheStore, such as a
+// FileCacheStore, so it survives restarts. Additions are serialized within the
+// process only; stores shared between processes need an atomic increment and
+// a QuotaStore of their own.
+type CacheQuotaStore struct {
+	store CacheStore
+	mu    sync.Mutex
+}
+
+// NewCacheQuotaStore returns a CacheQuotaStore keeping usage in store.
+func NewCacheQuotaStore(store CacheStore) *CacheQuotaStore {
+	return &CacheQuotaStore{store: store}
+}
+
+func (s *CacheQuotaStore) Usage(ctx context.Context, caller string, period QuotaPeriod, window string) (QuotaUsage, error) {
+	var usage QuotaUsage
+	data, ok, err := s.store.Get(ctx, quotaKey(caller, period, window))
+	if err != nil || !ok {
+		return usage, err
+	}
+	err = json.Unmarshal(data, &usage)
+	return usage, err
+}
+
+func (s *CacheQuotaStore) Add(ctx context.Context, caller string, period QuotaPeriod, window string, usage QuotaUsage, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total, err := s.Usage(ctx, caller, period, window)
+	if err != nil {
+		return err
+	}
+	total.Cost += usage.Cost
+	total.Tokens += usage.Tokens
+	data, err := json.Marshal(total)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(ctx, quotaKey(caller, period, window), data, ttl)
+}
+
+func quotaKey(caller string, period QuotaPeriod, window string) string {
+	return "openrouter:quota:" + string(period) + ":" + window + ":" + caller
+}
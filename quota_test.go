@@ -0,0 +1,72 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaManagerRejectsCallersOverTheLimit(t *testing.T) {
+	t.Parallel()
+
+	usage := `"usage":{"prompt_tokens":60,"completion_tokens":40,"total_tokens":100,"cost":0.5}`
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusOK, `{"id":"gen-1","choices":[{"message":{"role":"assistant","content":"Hi"}}],`+usage+`}`),
+		jsonResponse(http.StatusOK, `{"id":"gen-2","choices":[{"message":{"role":"assistant","content":"Hi"}}],`+usage+`}`),
+	)
+	manager := NewQuotaManager(QuotaLimits{DailyTokens: 150}, nil)
+	manager.LimitsFor = func(caller string) (QuotaLimits, bool) {
+		return QuotaLimits{MonthlyCost: 10}, caller == "premium"
+	}
+	client.config.QuotaManager = manager
+
+	request := ChatCompletionRequest{Model: "openai/gpt-4o", Messages: []ChatCompletionMessage{UserMessage("Hello")}}
+	alice := ContextWithCaller(context.Background(), "alice")
+	for range 2 {
+		_, err := client.CreateChatCompletion(alice, request)
+		require.NoError(t, err)
+	}
+
+	_, err := client.CreateChatCompletion(alice, request)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	var quotaErr *QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	require.Equal(t, QuotaExceededError{Caller: "alice", Period: QuotaDaily, Limit: "tokens",
+		Used: QuotaUsage{Cost: 1, Tokens: 200}}, *quotaErr)
+	_, err = client.CreateEmbeddings(alice, EmbeddingsRequest{Model: "openai/text-embedding-3-small", Input: "Hello"})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	require.Len(t, httpClient.requests, 2)
+
+	require.NoError(t, manager.Check(context.Background(), "bob"))
+	require.NoError(t, manager.Record(context.Background(), "premium", QuotaUsage{Tokens: 1000}))
+	require.NoError(t, manager.Check(context.Background(), "premium"))
+}
+
+func TestQuotaPeriodsRollOver(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewFileCacheStore(t.TempDir())
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)
+	manager := NewQuotaManager(QuotaLimits{DailyCost: 1, MonthlyCost: 2}, NewCacheQuotaStore(store))
+	manager.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, manager.Record(ctx, "alice", QuotaUsage{Cost: 1.5}))
+	require.ErrorIs(t, manager.Check(ctx, "alice"), ErrQuotaExceeded)
+
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, manager.Check(ctx, "alice"))
+	require.NoError(t, manager.Record(ctx, "alice", QuotaUsage{Cost: 0.25}))
+	used, err := manager.Usage(ctx, "alice", QuotaMonthly)
+	require.NoError(t, err)
+	require.Equal(t, QuotaUsage{Cost: 0.25}, used)
+
+	now = now.Add(-2 * time.Hour)
+	used, err = manager.Usage(ctx, "alice", QuotaMonthly)
+	require.NoError(t, err)
+	require.Equal(t, QuotaUsage{Cost: 1.5}, used)
+}
@@ -0,0 +1,188 @@
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+)
+
+// Redactor removes secrets and personal data from the text the client logs
+// and exports: log messages and attributes, and metrics tags.
+type Redactor interface {
+	Redact(text string) string
+}
+
+// RedactorFunc adapts a function to the Redactor interface.
+type RedactorFunc func(text string) string
+
+func (f RedactorFunc) Redact(text string) string {
+	return f(text)
+}
+
+// PatternRedactor replaces every match of Pattern with Replacement, which may
+// refer to submatches as in regexp.Regexp.ReplaceAllString.
+type PatternRedactor struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+func (p PatternRedactor) Redact(text string) string {
+	return p.Pattern.ReplaceAllString(text, p.Replacement)
+}
+
+// Redactors applies every redactor in turn, so the default can be extended:
+//
+//	openrouter.Redactors{openrouter.DefaultRedactor, openrouter.PatternRedactor{...}}
+type Redactors []Redactor
+
+func (r Redactors) Redact(text string) string {
+	for _, redactor := range r {
+		text = redactor.Redact(text)
+	}
+	return text
+}
+
+// DefaultRedactor is used by clients without a Redactor. It replaces API keys
+// and bearer tokens, base64 payloads such as embedded images, email addresses,
+// phone numbers and payment card numbers with placeholders.
+var DefaultRedactor Redactor = Redactors{
+	PatternRedactor{regexp.MustCompile(`(?i)\bbearer\s+[\w.~+/-]+=*`), "Bearer [REDACTED]"},
+	PatternRedactor{regexp.MustCompile(`\bsk-[\w-]{16,}`), "[REDACTED_KEY]"},
+	PatternRedactor{regexp.MustCompile(`(data:[\w.+/-]+;base64,)[A-Za-z0-9+/]+=*`), "${1}[REDACTED]"},
+	PatternRedactor{regexp.MustCompile(`[A-Za-z0-9+/]{200,}={0,2}`), "[REDACTED_BASE64]"},
+	PatternRedactor{regexp.MustCompile(`[\w.%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+	RedactorFunc(redactCardNumbers),
+	PatternRedactor{regexp.MustCompile(`\+\d{1,3}[ .-]?(?:\(\d{1,4}\)[ .-]?)?\d{2,4}(?:[ .-]?\d{2,4}){1,3}\b`), "[REDACTED_PHONE]"},
+	PatternRedactor{regexp.MustCompile(`(?:\(\d{3}\) ?|\b\d{3}[ .-])\d{3}[ .-]\d{4}\b`), "[REDACTED_PHONE]"},
+}
+
+// NoRedaction leaves text unchanged, for clients whose logs may hold secrets.
+var NoRedaction Redactor = RedactorFunc(func(text string) string { return text })
+
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// redactCardNumbers replaces the sequences of 13 to 19 digits that pass the
+// Luhn check, so other long numbers such as IDs are mostly kept.
+func redactCardNumbers(text string) string {
+	return cardNumberPattern.ReplaceAllStringFunc(text, func(match string) string {
+		sum, double := 0, false
+		for i := len(match) - 1; i >= 0; i-- {
+			if match[i] < '0' || match[i] > '9' {
+				continue
+			}
+			digit := int(match[i] - '0')
+			if double {
+				if digit *= 2; digit > 9 {
+					digit -= 9
+				}
+			}
+			sum += digit
+			double = !double
+		}
+		if sum%10 != 0 {
+			return match
+		}
+		return "[REDACTED_CARD]"
+	})
+}
+
+func (c *Client) redactor() Redactor {
+	if c.config.Redactor == nil {
+		return DefaultRedactor
+	}
+	return c.config.Redactor
+}
+
+// logger returns the logger of the client, which redacts the records it passes
+// to the default slog handler.
+func (c *Client) logger() *slog.Logger {
+	return slog.New(&redactingHandler{handler: slog.Default().Handler(), redactor: c.redactor()})
+}
+
+// metricsSink returns the MetricsSink of the client redacting tags, or nil.
+func (c *Client) metricsSink() MetricsSink {
+	if c.config.MetricsSink == nil {
+		return nil
+	}
+	return &redactingMetricsSink{sink: c.config.MetricsSink, redactor: c.redactor()}
+}
+
+// redactingHandler redacts the message and attributes of records before
+// passing them to handler.
+type redactingHandler struct {
+	handler  slog.Handler
+	redactor Redactor
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(attr)
+	}
+	return &redactingHandler{handler: h.handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{handler: h.handler.WithGroup(name), redactor: h.redactor}
+}
+
+// redactAttr redacts string values, and values of other kinds that format as
+// arbitrary text, such as errors and byte slices.
+func (h *redactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.redactor.Redact(value.String()))
+	case slog.KindAny:
+		if data, ok := value.Any().([]byte); ok {
+			return slog.String(attr.Key, h.redactor.Redact(string(data)))
+		}
+		return slog.String(attr.Key, h.redactor.Redact(fmt.Sprint(value.Any())))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, attr := range group {
+			redacted[i] = h.redactAttr(attr)
+		}
+		return slog.Group(attr.Key, redacted...)
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
+
+// redactingMetricsSink redacts the tag values of metrics before passing them
+// to sink.
+type redactingMetricsSink struct {
+	sink     MetricsSink
+	redactor Redactor
+}
+
+func (s *redactingMetricsSink) IncCounter(name string, value float64, tags map[string]string) {
+	s.sink.IncCounter(name, value, s.redact(tags))
+}
+
+func (s *redactingMetricsSink) Observe(name string, value float64, tags map[string]string) {
+	s.sink.Observe(name, value, s.redact(tags))
+}
+
+func (s *redactingMetricsSink) redact(tags map[string]string) map[string]string {
+	redacted := make(map[string]string, len(tags))
+	for key, value := range tags {
+		redacted[key] = s.redactor.Redact(value)
+	}
+	return redacted
+}
@@ -0,0 +1,101 @@
+package openrouter
+
+import (
+	"context"
+	"time"
+)
+
+// ReproRecord holds what is needed to replay a chat completion, so a flagged
+// output can be investigated later. It is encoded as JSON for storage.
+//
+// Only requests with a Seed are reproducible, and only on providers honoring
+// it; set one on requests whose outputs may need investigating.
+type ReproRecord struct {
+	CapturedAt time.Time             `json:"captured_at"`
+	Request    ChatCompletionRequest `json:"request"`
+	// RequestHash identifies the request, ignoring the fields that do not
+	// affect generation such as User and Metadata.
+	RequestHash string `json:"request_hash"`
+	// Model and Provider served the generation, after aliases, fallbacks and
+	// routing were resolved.
+	Model    string `json:"model"`
+	Provider string `json:"provider,omitempty"`
+	Seed     *int   `json:"seed,omitempty"`
+	// SystemFingerprint identifies the backend configuration of the provider.
+	// Outputs may differ once it changes, even with the same seed.
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+	GenerationID      string `json:"generation_id"`
+	// Output is the content of the first choice.
+	Output string `json:"output"`
+}
+
+// CaptureRepro returns the ReproRecord of response, the answer to request.
+func CaptureRepro(request ChatCompletionRequest, response ChatCompletionResponse) (ReproRecord, error) {
+	hash, err := HashRequest(request)
+	if err != nil {
+		return ReproRecord{}, err
+	}
+	record := ReproRecord{
+		CapturedAt:        time.Now(),
+		Request:           request,
+		RequestHash:       hash,
+		Model:             response.Model,
+		Provider:          response.Provider,
+		Seed:              request.Seed,
+		SystemFingerprint: response.SystemFingerprint,
+		GenerationID:      response.ID,
+	}
+	if len(response.Choices) > 0 {
+		record.Output = messageText(response.Choices[0].Message)
+	}
+	return record, nil
+}
+
+// ReplayResult is the outcome of Replay.
+type ReplayResult struct {
+	Response ChatCompletionResponse
+	// Reproduced reports whether the content of the first choice equals the
+	// recorded output.
+	Reproduced bool
+	// FingerprintChanged reports whether the provider returned a different
+	// system fingerprint than recorded, which explains a differing output.
+	FingerprintChanged bool
+}
+
+// Replay sends the request of record again, pinned to the recorded model,
+// provider and seed, and compares the output with the recorded one. The
+// client-side caches are bypassed, so the generation is always fresh.
+func (c *Client) Replay(ctx context.Context, record ReproRecord) (ReplayResult, error) {
+	request := record.Request
+	request.Stream = false
+	request.StreamOptions = nil
+	request.Seed = record.Seed
+	if record.Model != "" {
+		request.Model = record.Model
+		request.Models = nil
+	}
+	if record.Provider != "" {
+		provider := ChatProvider{}
+		if request.Provider != nil {
+			provider = *request.Provider
+		}
+		allowFallbacks := false
+		provider.Order = []string{record.Provider}
+		provider.AllowFallbacks = &allowFallbacks
+		request.Provider = &provider
+	}
+
+	response, err := c.sendFreshChatCompletion(ctx, request)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	result := ReplayResult{
+		Response:           response,
+		FingerprintChanged: response.SystemFingerprint != record.SystemFingerprint,
+	}
+	if len(response.Choices) > 0 {
+		result.Reproduced = messageText(response.Choices[0].Message) == record.Output
+	}
+	return result, nil
+}
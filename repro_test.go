@@ -0,0 +1,73 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureReproAndReplay(t *testing.T) {
+	t.Parallel()
+
+	answer := func(fingerprint, content string) *http.Response {
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","model":"openai/gpt-4o-2024-08-06","provider":"Azure",`+
+			`"system_fingerprint":"`+fingerprint+`","choices":[{"message":{"role":"assistant","content":"`+content+`"}}]}`)
+	}
+	client, httpClient := newSequenceClient(t,
+		answer("fp_1", "42"),
+		answer("fp_1", "42"),
+		answer("fp_2", "41"),
+	)
+	client.config.CompletionCache = NewCompletionCache(0)
+
+	seed := 7
+	request := ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Models:   []string{"anthropic/claude-3.5-sonnet"},
+		Messages: []ChatCompletionMessage{UserMessage("The answer?")},
+		Seed:     &seed,
+		User:     "user-1",
+	}
+	response, err := client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+
+	record, err := CaptureRepro(request, response)
+	require.NoError(t, err)
+	require.Equal(t, "openai/gpt-4o-2024-08-06", record.Model)
+	require.Equal(t, "Azure", record.Provider)
+	require.Equal(t, "fp_1", record.SystemFingerprint)
+	require.Equal(t, "42", record.Output)
+	require.Equal(t, &seed, record.Seed)
+
+	// The hash ignores fields that do not affect generation.
+	request.User = "user-2"
+	other, err := CaptureRepro(request, response)
+	require.NoError(t, err)
+	require.Equal(t, record.RequestHash, other.RequestHash)
+
+	// Records survive storage.
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+	var stored ReproRecord
+	require.NoError(t, json.Unmarshal(data, &stored))
+
+	result, err := client.Replay(context.Background(), stored)
+	require.NoError(t, err)
+	require.True(t, result.Reproduced)
+	require.False(t, result.FingerprintChanged)
+
+	replayed := httpClient.requests[1]
+	require.Equal(t, "openai/gpt-4o-2024-08-06", replayed.Model)
+	require.Empty(t, replayed.Models)
+	require.Equal(t, []string{"Azure"}, replayed.Provider.Order)
+	require.False(t, *replayed.Provider.AllowFallbacks)
+	require.Equal(t, 7, *replayed.Seed)
+
+	result, err = client.Replay(context.Background(), stored)
+	require.NoError(t, err)
+	require.False(t, result.Reproduced)
+	require.True(t, result.FingerprintChanged)
+}
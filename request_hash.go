@@ -0,0 +1,73 @@
+package openrouter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CanonicalRequestJSON returns request encoded as canonical JSON, equal for
+// requests that ask for the same generation, for dedupe and idempotency keys:
+//
+//   - attribution and transport fields that do not affect the output, User,
+//     SessionId, Metadata, Trace, Stream and StreamOptions, are dropped;
+//   - cache control is dropped from message parts, and content made of a
+//     single text part is encoded as a plain string;
+//   - object keys are sorted, including those of ExtraBody and ExtraFields.
+func CanonicalRequestJSON(request ChatCompletionRequest) ([]byte, error) {
+	request.User = ""
+	request.SessionId = ""
+	request.Metadata = nil
+	request.Trace = nil
+	request.Stream = false
+	request.StreamOptions = nil
+
+	messages := make([]ChatCompletionMessage, len(request.Messages))
+	for i, message := range request.Messages {
+		message.Content = canonicalContent(message.Content)
+		messages[i] = message
+	}
+	request.Messages = messages
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	// Decoding into maps and encoding again sorts the keys of every object.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// canonicalContent returns content without cache control, as text when it is
+// a single text part.
+func canonicalContent(content Content) Content {
+	if len(content.Multi) == 0 {
+		return content
+	}
+	parts := make([]ChatMessagePart, len(content.Multi))
+	for i, part := range content.Multi {
+		part.CacheControl = nil
+		parts[i] = part
+	}
+	if len(parts) == 1 && parts[0] == (ChatMessagePart{Type: ChatMessagePartTypeText, Text: parts[0].Text}) {
+		return Content{Text: parts[0].Text}
+	}
+	return Content{Multi: parts}
+}
+
+// HashRequest returns the hex encoded SHA-256 of the CanonicalRequestJSON of
+// request. The caches of the client key their entries with it.
+func HashRequest(request ChatCompletionRequest) (string, error) {
+	data, err := CanonicalRequestJSON(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
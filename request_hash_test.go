@@ -0,0 +1,49 @@
+package openrouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalRequestJSON(t *testing.T) {
+	t.Parallel()
+
+	request := ChatCompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: Content{Multi: []ChatMessagePart{{
+				Type:         ChatMessagePartTypeText,
+				Text:         "Be brief.",
+				CacheControl: &CacheControl{Type: "ephemeral"},
+			}}}},
+			UserMessage("Hi"),
+		},
+		ExtraBody: map[string]any{"zeta": 1, "alpha": 2},
+		User:      "user-1",
+		Stream:    true,
+	}
+
+	data, err := CanonicalRequestJSON(request)
+	require.NoError(t, err)
+	require.Equal(t, `{"alpha":2,"messages":[{"content":"Be brief.","role":"system"},{"content":"Hi","role":"user"}],`+
+		`"model":"openai/gpt-4o","zeta":1}`, string(data))
+	require.NotNil(t, request.Messages[0].Content.Multi[0].CacheControl, "the request is not modified")
+
+	plain := ChatCompletionRequest{
+		Model:     "openai/gpt-4o",
+		Messages:  []ChatCompletionMessage{SystemMessage("Be brief."), UserMessage("Hi")},
+		ExtraBody: map[string]any{"alpha": 2, "zeta": 1},
+	}
+	hash, err := HashRequest(request)
+	require.NoError(t, err)
+	plainHash, err := HashRequest(plain)
+	require.NoError(t, err)
+	require.Equal(t, hash, plainHash)
+	require.Len(t, hash, 64)
+
+	plain.Messages = append(plain.Messages, AssistantMessage("Hello"))
+	otherHash, err := HashRequest(plain)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherHash)
+}
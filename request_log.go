@@ -0,0 +1,138 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// RequestLogMessage is the message of the records logged by WithRequestLogging.
+const RequestLogMessage = "openrouter request"
+
+// The fields of the records logged by WithRequestLogging. Fields without a
+// value, such as the provider of an embeddings call, are left out.
+const (
+	LogFieldEndpoint         = "endpoint"
+	LogFieldModel            = "model"
+	LogFieldProvider         = "provider"
+	LogFieldRequestID        = "request_id"
+	LogFieldCaller           = "caller"
+	LogFieldStreamed         = "streamed"
+	LogFieldCacheHit         = "cache_hit"
+	LogFieldLatencyMS        = "latency_ms"
+	LogFieldPromptTokens     = "prompt_tokens"
+	LogFieldCompletionTokens = "completion_tokens"
+	LogFieldTotalTokens      = "total_tokens"
+	LogFieldCost             = "cost"
+	LogFieldFinishReason     = "finish_reason"
+	// LogFieldErrorClass is the ErrorClass of the error of a failed call.
+	LogFieldErrorClass = "error_class"
+	LogFieldError      = "error"
+)
+
+// Error classes returned by ErrorClass.
+const (
+	ErrorClassCanceled            = "canceled"
+	ErrorClassTimeout             = "timeout"
+	ErrorClassStreamStalled       = "stream_stalled"
+	ErrorClassQuota               = "quota"
+	ErrorClassRateLimited         = "rate_limited"
+	ErrorClassInsufficientCredits = "insufficient_credits"
+	ErrorClassModerated           = "moderated"
+	ErrorClassContextLength       = "context_length"
+	ErrorClassProviderUnavailable = "provider_unavailable"
+	ErrorClassGeneration          = "generation"
+	ErrorClassAuth                = "auth"
+	ErrorClassInvalidRequest      = "invalid_request"
+	ErrorClassServer              = "server"
+	ErrorClassNetwork             = "network"
+)
+
+// ErrorClass returns the category of err as one of the ErrorClass constants,
+// for logs and dashboards, or "" when err is nil.
+func ErrorClass(err error) string {
+	var generationErr *GenerationError
+	var authErr *AuthError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, ErrStreamStalled):
+		return ErrorClassStreamStalled
+	case errors.Is(err, ErrQuotaExceeded):
+		return ErrorClassQuota
+	case IsRateLimited(err):
+		return ErrorClassRateLimited
+	case IsInsufficientCredits(err):
+		return ErrorClassInsufficientCredits
+	case IsModerated(err):
+		return ErrorClassModerated
+	case IsContextLengthExceeded(err):
+		return ErrorClassContextLength
+	case IsProviderUnavailable(err):
+		return ErrorClassProviderUnavailable
+	case errors.As(err, &generationErr):
+		return ErrorClassGeneration
+	case errors.As(err, &authErr):
+		return ErrorClassAuth
+	}
+
+	status, ok := HTTPStatusCode(err)
+	switch {
+	case !ok:
+		return ErrorClassNetwork
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorClassAuth
+	case status >= http.StatusInternalServerError:
+		return ErrorClassServer
+	default:
+		return ErrorClassInvalidRequest
+	}
+}
+
+// logRequest logs event to the RequestLogger of the client, if any.
+func (c *Client) logRequest(ctx context.Context, event UsageEvent) {
+	logger := c.config.RequestLogger
+	if logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String(LogFieldEndpoint, event.Endpoint),
+		slog.String(LogFieldModel, event.Model),
+	}
+	for _, field := range []struct{ key, value string }{
+		{LogFieldProvider, event.Provider},
+		{LogFieldRequestID, event.RequestID},
+		{LogFieldCaller, event.Caller},
+		{LogFieldFinishReason, string(event.FinishReason)},
+	} {
+		if field.value != "" {
+			attrs = append(attrs, slog.String(field.key, field.value))
+		}
+	}
+	attrs = append(attrs,
+		slog.Bool(LogFieldStreamed, event.Streamed),
+		slog.Bool(LogFieldCacheHit, event.CacheHit),
+		slog.Int64(LogFieldLatencyMS, event.Latency.Milliseconds()),
+		slog.Int(LogFieldPromptTokens, event.PromptTokens),
+		slog.Int(LogFieldCompletionTokens, event.CompletionTokens),
+		slog.Int(LogFieldTotalTokens, event.TotalTokens),
+		slog.Float64(LogFieldCost, event.Cost),
+	)
+	level := slog.LevelInfo
+	if event.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs,
+			slog.String(LogFieldErrorClass, ErrorClass(event.Err)),
+			slog.String(LogFieldError, event.Err.Error()),
+		)
+	}
+
+	handler := &redactingHandler{handler: logger.Handler(), redactor: c.redactor()}
+	slog.New(handler).LogAttrs(ctx, level, RequestLogMessage, attrs...)
+}
@@ -0,0 +1,90 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLoggingLogsOneRecordPerRequest(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t,
+		jsonResponse(http.StatusOK, `{"id":"gen-1","model":"openai/gpt-4o","provider":"OpenAI",`+
+			`"choices":[{"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7,"cost":0.001}}`),
+		jsonResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"rate limited for a@b.io"}}`),
+	)
+	var buf bytes.Buffer
+	client.config.RequestLogger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime}))
+
+	ctx := ContextWithCaller(context.Background(), "search")
+	request := ChatCompletionRequest{Model: "openai/gpt-4o", Messages: []ChatCompletionMessage{UserMessage("Hello")}}
+	_, err := client.CreateChatCompletion(ctx, request)
+	require.NoError(t, err)
+	_, err = client.CreateChatCompletion(ctx, request)
+	require.Error(t, err)
+
+	var records []map[string]any
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record map[string]any
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+
+	first := records[0]
+	require.GreaterOrEqual(t, first[LogFieldLatencyMS], 0.0)
+	delete(first, LogFieldLatencyMS)
+	require.Equal(t, map[string]any{
+		"level":                  "INFO",
+		"msg":                    RequestLogMessage,
+		LogFieldEndpoint:         "chat",
+		LogFieldModel:            "openai/gpt-4o",
+		LogFieldProvider:         "OpenAI",
+		LogFieldRequestID:        "gen-1",
+		LogFieldCaller:           "search",
+		LogFieldFinishReason:     "stop",
+		LogFieldStreamed:         false,
+		LogFieldCacheHit:         false,
+		LogFieldPromptTokens:     5.0,
+		LogFieldCompletionTokens: 2.0,
+		LogFieldTotalTokens:      7.0,
+		LogFieldCost:             0.001,
+	}, first)
+
+	second := records[1]
+	require.Equal(t, "ERROR", second["level"])
+	require.Equal(t, ErrorClassRateLimited, second[LogFieldErrorClass])
+	require.Contains(t, second[LogFieldError], "[REDACTED_EMAIL]")
+	require.NotContains(t, second, LogFieldRequestID)
+}
+
+func TestErrorClass(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]error{
+		"":                            nil,
+		ErrorClassCanceled:            fmt.Errorf("send: %w", context.Canceled),
+		ErrorClassTimeout:             context.DeadlineExceeded,
+		ErrorClassStreamStalled:       ErrStreamStalled,
+		ErrorClassQuota:               ErrQuotaExceeded,
+		ErrorClassRateLimited:         &APIError{Code: http.StatusTooManyRequests, HTTPStatusCode: http.StatusTooManyRequests},
+		ErrorClassInsufficientCredits: &APIError{Code: http.StatusPaymentRequired, HTTPStatusCode: http.StatusPaymentRequired},
+		ErrorClassAuth:                &APIError{Code: http.StatusUnauthorized, HTTPStatusCode: http.StatusUnauthorized},
+		ErrorClassInvalidRequest:      &APIError{Code: http.StatusBadRequest, HTTPStatusCode: http.StatusBadRequest, Message: "bad request"},
+		ErrorClassServer:              &RequestError{HTTPStatusCode: http.StatusInternalServerError, Err: errors.New("boom")},
+		ErrorClassNetwork:             errors.New("connection reset"),
+	}
+	for want, err := range cases {
+		require.Equal(t, want, ErrorClass(err), "%v", err)
+	}
+}
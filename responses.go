@@ -0,0 +1,366 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const responsesSuffix = "/responses"
+
+var ErrResponseStreamNotSupported = errors.New("streaming is not supported with this method, please use CreateResponseStream") //nolint:lll
+
+// ResponsesRequest is a request to the OpenAI-compatible Responses API, in alpha.
+// API reference: https://openrouter.ai/docs/api-reference/responses-api/overview
+type ResponsesRequest struct {
+	Model string `json:"model,omitempty"`
+	// Input is a plain text prompt or a list of items; see ResponseInput.
+	Input ResponseInput `json:"input"`
+	// Instructions is a system message inserted before the input.
+	Instructions string `json:"instructions,omitempty"`
+	// Optional model fallbacks: https://openrouter.ai/docs/features/model-routing#the-models-parameter
+	Models     []string            `json:"models,omitempty"`
+	Provider   *ChatProvider       `json:"provider,omitempty"`
+	Tools      []ResponseTool      `json:"tools,omitempty"`
+	ToolChoice any                 `json:"tool_choice,omitempty"`
+	Reasoning  *ResponsesReasoning `json:"reasoning,omitempty"`
+	// MaxOutputTokens caps the generated tokens, including reasoning tokens.
+	MaxOutputTokens int     `json:"max_output_tokens,omitempty"`
+	Temperature     float32 `json:"temperature,omitempty"`
+	TopP            float32 `json:"top_p,omitempty"`
+	// PreviousResponseID continues the conversation of an earlier response.
+	PreviousResponseID string            `json:"previous_response_id,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	User               string            `json:"user,omitempty"`
+	Stream             bool              `json:"stream,omitempty"`
+}
+
+// ResponsesReasoning configures the reasoning of models that support it.
+type ResponsesReasoning struct {
+	// Effort is "low", "medium" or "high".
+	Effort string `json:"effort,omitempty"`
+	// Summary requests a summary of the reasoning: "auto", "concise" or "detailed".
+	Summary string `json:"summary,omitempty"`
+}
+
+// ResponseTool is a tool the model may call. Unlike Tool in chat completions,
+// the function is described inline.
+type ResponseTool struct {
+	Type        ToolType `json:"type"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Strict      bool     `json:"strict,omitempty"`
+	// Parameters is the JSON schema of the arguments, see FunctionDefinition.Parameters.
+	Parameters any `json:"parameters,omitempty"`
+}
+
+// ResponseInput is the input of a ResponsesRequest: either Text or Items. It
+// serializes like Content.
+type ResponseInput struct {
+	Text  string
+	Items []ResponseItem
+}
+
+func (i ResponseInput) MarshalJSON() ([]byte, error) {
+	if len(i.Items) > 0 {
+		return json.Marshal(i.Items)
+	}
+	return json.Marshal(i.Text)
+}
+
+// UnmarshalJSON deserializes ResponseInput from a string or array.
+func (i *ResponseInput) UnmarshalJSON(data []byte) error {
+	*i = ResponseInput{}
+	if err := json.Unmarshal(data, &i.Text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &i.Items)
+}
+
+// ResponseItemType is the type of a ResponseItem.
+type ResponseItemType string
+
+const (
+	ResponseItemMessage            ResponseItemType = "message"
+	ResponseItemFunctionCall       ResponseItemType = "function_call"
+	ResponseItemFunctionCallOutput ResponseItemType = "function_call_output"
+	ResponseItemReasoning          ResponseItemType = "reasoning"
+)
+
+// ResponseItem is an element of the input or output of the Responses API.
+// Which fields are set depends on Type.
+type ResponseItem struct {
+	Type   ResponseItemType `json:"type"`
+	ID     string           `json:"id,omitempty"`
+	Status string           `json:"status,omitempty"`
+
+	// Role and Content are set for messages.
+	Role    string                `json:"role,omitempty"`
+	Content []ResponseContentPart `json:"content,omitempty"`
+
+	// CallID identifies a function call and its output.
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
+
+	// Summary is set for reasoning items.
+	Summary []ResponseContentPart `json:"summary,omitempty"`
+}
+
+// ResponseContentPartType is the type of a ResponseContentPart.
+type ResponseContentPartType string
+
+const (
+	ResponseContentInputText   ResponseContentPartType = "input_text"
+	ResponseContentInputImage  ResponseContentPartType = "input_image"
+	ResponseContentOutputText  ResponseContentPartType = "output_text"
+	ResponseContentSummaryText ResponseContentPartType = "summary_text"
+)
+
+// ResponseContentPart is a piece of the content of a ResponseItem.
+type ResponseContentPart struct {
+	Type ResponseContentPartType `json:"type"`
+	Text string                  `json:"text,omitempty"`
+	// ImageURL is a URL or base64 data URL, for input_image parts.
+	ImageURL    string         `json:"image_url,omitempty"`
+	Detail      ImageURLDetail `json:"detail,omitempty"`
+	Annotations []Annotation   `json:"annotations,omitempty"`
+}
+
+// ResponseMessage returns an input message item with text content.
+func ResponseMessage(role, text string) ResponseItem {
+	return ResponseItem{
+		Type:    ResponseItemMessage,
+		Role:    role,
+		Content: []ResponseContentPart{{Type: ResponseContentInputText, Text: text}},
+	}
+}
+
+// ResponseFunctionCallOutput returns the input item carrying the result of the
+// function call identified by callID.
+func ResponseFunctionCallOutput(callID, output string) ResponseItem {
+	return ResponseItem{Type: ResponseItemFunctionCallOutput, CallID: callID, Output: output}
+}
+
+// ResponseUsage is the token usage of a Response.
+type ResponseUsage struct {
+	InputTokens        int `json:"input_tokens"`
+	InputTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"input_tokens_details"`
+	OutputTokens        int `json:"output_tokens"`
+	OutputTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"output_tokens_details"`
+	TotalTokens int     `json:"total_tokens"`
+	Cost        float64 `json:"cost,omitempty"`
+}
+
+// usage converts u to the chat completion Usage, for stream statistics.
+func (u *ResponseUsage) usage() *Usage {
+	if u == nil {
+		return nil
+	}
+	usage := &Usage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.TotalTokens,
+		Cost:             u.Cost,
+	}
+	usage.PromptTokenDetails.CachedTokens = u.InputTokensDetails.CachedTokens
+	usage.CompletionTokenDetails.ReasoningTokens = u.OutputTokensDetails.ReasoningTokens
+	return usage
+}
+
+// Response is the result of the Responses API.
+type Response struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	CreatedAt int64  `json:"created_at"`
+	Model     string `json:"model"`
+	// Status is "completed", "incomplete", "in_progress" or "failed".
+	Status string         `json:"status"`
+	Output []ResponseItem `json:"output"`
+	Usage  *ResponseUsage `json:"usage,omitempty"`
+	// Error describes why the response failed.
+	Error *APIError `json:"error,omitempty"`
+}
+
+// OutputText returns the concatenated text of the output messages.
+func (r Response) OutputText() string {
+	var b strings.Builder
+	for _, item := range r.Output {
+		if item.Type != ResponseItemMessage {
+			continue
+		}
+		for _, part := range item.Content {
+			if part.Type == ResponseContentOutputText {
+				b.WriteString(part.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// FunctionCalls returns the function call items of the output.
+func (r Response) FunctionCalls() []ResponseItem {
+	var calls []ResponseItem
+	for _, item := range r.Output {
+		if item.Type == ResponseItemFunctionCall {
+			calls = append(calls, item)
+		}
+	}
+	return calls
+}
+
+// CreateResponse — API call to create a response with the Responses API.
+func (c *Client) CreateResponse(
+	ctx context.Context,
+	request ResponsesRequest,
+) (response Response, err error) {
+	if request.Stream {
+		err = ErrResponseStreamNotSupported
+		return
+	}
+	if err = c.checkQuota(ctx); err != nil {
+		return
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(responsesSuffix),
+		withBody(request),
+	)
+	if err != nil {
+		return
+	}
+
+	startedAt := time.Now()
+	err = c.sendRequest(req, &response)
+	event := UsageEvent{Endpoint: "responses", RequestID: response.ID, Model: response.Model,
+		Metadata: request.Metadata, Err: err}
+	if event.Model == "" {
+		event.Model = request.Model
+	}
+	c.recordUsage(ctx, event, response.Usage.usage(), startedAt)
+	return
+}
+
+// Response stream event types.
+const (
+	ResponseEventCreated                    = "response.created"
+	ResponseEventInProgress                 = "response.in_progress"
+	ResponseEventOutputItemAdded            = "response.output_item.added"
+	ResponseEventOutputItemDone             = "response.output_item.done"
+	ResponseEventOutputTextDelta            = "response.output_text.delta"
+	ResponseEventOutputTextDone             = "response.output_text.done"
+	ResponseEventFunctionCallArgumentsDelta = "response.function_call_arguments.delta"
+	ResponseEventFunctionCallArgumentsDone  = "response.function_call_arguments.done"
+	ResponseEventCompleted                  = "response.completed"
+	ResponseEventIncomplete                 = "response.incomplete"
+	ResponseEventFailed                     = "response.failed"
+)
+
+// ResponseStreamEvent is an event of a response stream. Which fields are set
+// depends on Type.
+type ResponseStreamEvent struct {
+	Type           string `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	ItemID         string `json:"item_id,omitempty"`
+	OutputIndex    int    `json:"output_index"`
+	ContentIndex   int    `json:"content_index"`
+	// Delta is the text or arguments added by a delta event.
+	Delta string `json:"delta,omitempty"`
+	// Item is set by output item events.
+	Item *ResponseItem `json:"item,omitempty"`
+	// Response is the response so far, set by the lifecycle events such as
+	// response.created and response.completed.
+	Response *Response `json:"response,omitempty"`
+}
+
+type ResponseStream struct {
+	reader *sseStream[ResponseStreamEvent]
+}
+
+// CreateResponseStream — API call to create a response with the Responses API with streaming.
+// The final event, response.completed, carries the complete Response.
+func (c *Client) CreateResponseStream(
+	ctx context.Context,
+	request ResponsesRequest,
+) (*ResponseStream, error) {
+	request.Stream = true
+	if err := c.checkQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	startedAt := time.Now()
+	resp, err := c.openStream(ctx, responsesSuffix, request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	usage := newStreamUsage("responses", request.Model)
+	usage.event.Metadata = request.Metadata
+	return &ResponseStream{
+		reader: newSSEStream(ctx, cancel, resp, sseStreamOptions[ResponseStreamEvent]{
+			name:        "response",
+			idleTimeout: c.config.StreamIdleTimeout,
+			raw:         c.config.RawStreamEvents,
+			onComment:   c.config.StreamCommentHook,
+			onChunk: func(event ResponseStreamEvent) {
+				if event.Response != nil {
+					usage.chunk(event.Response.ID, event.Response.Model, "", event.Response.Usage.usage())
+				}
+			},
+			onEnd: func(err error, _ StreamStats) {
+				usage.event.Err = err
+				c.recordUsage(ctx, usage.event, usage.usage, startedAt)
+			},
+			startedAt: startedAt,
+			hasToken: func(event ResponseStreamEvent) bool {
+				return event.Type == ResponseEventOutputTextDelta && event.Delta != ""
+			},
+			usage: func(event ResponseStreamEvent) *Usage {
+				if event.Response == nil {
+					return nil
+				}
+				return event.Response.Usage.usage()
+			},
+			metrics: c.metricsSink(),
+			logger:  c.logger(),
+			tags:    map[string]string{"endpoint": "responses", "model": request.Model},
+		}),
+	}, nil
+}
+
+// Recv reads the next event from the stream.
+// It returns io.EOF once the stream has ended, or the error that terminated it.
+func (s *ResponseStream) Recv() (ResponseStreamEvent, error) {
+	return s.reader.Recv()
+}
+
+// RecvEvent reads the next server-sent event from the stream. With
+// WithRawStreamEvents every line of the body is returned, including the
+// "event:" lines; otherwise only data events are returned.
+func (s *ResponseStream) RecvEvent() (StreamEvent[ResponseStreamEvent], error) {
+	return s.reader.RecvEvent()
+}
+
+// Stats returns the timing of the stream, such as time to first token and
+// tokens per second. It is complete once Recv has returned an error, including io.EOF.
+func (s *ResponseStream) Stats() StreamStats {
+	return s.reader.Stats()
+}
+
+// Close terminates the stream and cleans up resources.
+// It is safe to call from any goroutine, any number of times.
+func (s *ResponseStream) Close() {
+	s.reader.Close()
+}
@@ -0,0 +1,134 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateResponse(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &fakeHTTPClient{response: jsonResponse(http.StatusOK, `{
+		"id": "resp_1",
+		"object": "response",
+		"model": "openai/gpt-4o-mini",
+		"status": "completed",
+		"output": [
+			{"type": "reasoning", "id": "rs_1", "summary": [{"type": "summary_text", "text": "thinking"}]},
+			{"type": "message", "id": "msg_1", "role": "assistant", "status": "completed",
+			 "content": [{"type": "output_text", "text": "Hello", "annotations": []}, {"type": "output_text", "text": "!"}]},
+			{"type": "function_call", "id": "fc_1", "call_id": "call_1", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}
+		],
+		"usage": {"input_tokens": 10, "output_tokens": 5, "total_tokens": 15, "output_tokens_details": {"reasoning_tokens": 2}}
+	}`)}
+	cfg := DefaultConfig("test-token")
+	cfg.HTTPClient = httpClient
+	client := NewClientWithConfig(*cfg)
+
+	resp, err := client.CreateResponse(context.Background(), ResponsesRequest{
+		Model: "openai/gpt-4o-mini",
+		Input: ResponseInput{Items: []ResponseItem{
+			ResponseMessage(ChatMessageRoleUser, "Weather in Paris?"),
+			ResponseFunctionCallOutput("call_0", "sunny"),
+		}},
+		Tools: []ResponseTool{{Type: ToolTypeFunction, Name: "get_weather"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Hello!", resp.OutputText())
+	require.Len(t, resp.FunctionCalls(), 1)
+	require.Equal(t, "call_1", resp.FunctionCalls()[0].CallID)
+	require.Equal(t, 2, resp.Usage.OutputTokensDetails.ReasoningTokens)
+
+	require.Equal(t, "/api/v1/responses", httpClient.lastRequest.URL.Path)
+	body, err := io.ReadAll(httpClient.lastRequest.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"model": "openai/gpt-4o-mini",
+		"input": [
+			{"type": "message", "role": "user", "content": [{"type": "input_text", "text": "Weather in Paris?"}]},
+			{"type": "function_call_output", "call_id": "call_0", "output": "sunny"}
+		],
+		"tools": [{"type": "function", "name": "get_weather"}]
+	}`, string(body))
+}
+
+func TestCreateResponseRejectsStream(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient("test-token")
+	_, err := client.CreateResponse(context.Background(), ResponsesRequest{Stream: true})
+	require.ErrorIs(t, err, ErrResponseStreamNotSupported)
+}
+
+func TestResponseInputJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(ResponseInput{Text: "hi"})
+	require.NoError(t, err)
+	require.JSONEq(t, `"hi"`, string(data))
+
+	var input ResponseInput
+	require.NoError(t, json.Unmarshal([]byte(`"hi"`), &input))
+	require.Equal(t, ResponseInput{Text: "hi"}, input)
+
+	require.NoError(t, json.Unmarshal([]byte(`[{"type":"message","role":"user","content":[{"type":"input_text","text":"hi"}]}]`), &input))
+	require.Equal(t, ResponseInput{Items: []ResponseItem{ResponseMessage(ChatMessageRoleUser, "hi")}}, input)
+}
+
+func TestCreateResponseStream(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`event: response.created`,
+		`data: {"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress","output":[]}}`,
+		``,
+		`event: response.output_text.delta`,
+		`data: {"type":"response.output_text.delta","sequence_number":1,"item_id":"msg_1","delta":"Hel"}`,
+		``,
+		`event: response.output_text.delta`,
+		`data: {"type":"response.output_text.delta","sequence_number":2,"item_id":"msg_1","delta":"lo"}`,
+		``,
+		`event: response.completed`,
+		`data: {"type":"response.completed","sequence_number":3,"response":{"id":"resp_1","status":"completed",`+
+			`"output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Hello"}]}],`+
+			`"usage":{"input_tokens":3,"output_tokens":2,"total_tokens":5}}}`,
+		``,
+		`data: [DONE]`,
+	)))
+
+	stream, err := client.CreateResponseStream(context.Background(), ResponsesRequest{
+		Model: "openai/gpt-4o-mini",
+		Input: ResponseInput{Text: "Say hello"},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+	require.True(t, httpClient.requests[0].Stream)
+
+	var deltas string
+	var completed *Response
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		switch event.Type {
+		case ResponseEventOutputTextDelta:
+			deltas += event.Delta
+		case ResponseEventCompleted:
+			completed = event.Response
+		}
+	}
+
+	require.Equal(t, "Hello", deltas)
+	require.NotNil(t, completed)
+	require.Equal(t, "Hello", completed.OutputText())
+	stats := stream.Stats()
+	require.Equal(t, 2, stats.CompletionTokens)
+}
@@ -0,0 +1,125 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// BackoffFunc returns the delay before retry attempt n, counting from 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff returns a BackoffFunc that starts at base and doubles
+// every attempt, up to maxDelay.
+func ExponentialBackoff(base, maxDelay time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		return min(delay, maxDelay)
+	}
+}
+
+// defaultBackoff is used by helpers that retry when no BackoffFunc is given.
+var defaultBackoff = ExponentialBackoff(500*time.Millisecond, 10*time.Second)
+
+// transientStatusCodes are the statuses of failures that may succeed when
+// retried unchanged.
+var transientStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+	StatusEdgeNetworkTimeout,
+	StatusProviderOverloaded,
+}
+
+// isTransient reports whether err may go away on retry: a transient status, or
+// a transport failure that got no response at all. A successful response
+// whose body broke off is only retried for idempotent methods. Every other
+// error, such as a request that cannot be encoded, a QuotaExceededError or a
+// response that cannot be decoded, fails the same way when retried.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	for _, code := range transientStatusCodes {
+		if IsErrorCode(err, code) {
+			return true
+		}
+	}
+	var bodyErr *responseBodyError
+	if errors.As(err, &bodyErr) {
+		return isIdempotent(bodyErr.method) && isTransportFailure(bodyErr.err)
+	}
+	return isTransportFailure(err)
+}
+
+// isIdempotent reports whether sending a request with method again has the
+// same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTransportFailure reports whether err is the failure of a connection, as
+// returned by an HTTPDoer when the request got no response.
+func isTransportFailure(err error) bool {
+	// A *url.Error is also returned for URLs that cannot be parsed, so only
+	// the error it wraps tells a network failure apart.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// retry calls fn up to attempts times, waiting backoff between attempts, for as
+// long as it fails with an error retryable reports true for and ctx is not
+// done. It returns the last error, wrapped with ctx.Err() when ctx ended the
+// retries, so the failure of the last attempt is not lost.
+func retry(
+	ctx context.Context,
+	attempts int,
+	backoff BackoffFunc,
+	retryable func(error) bool,
+	fn func(attempt int) error,
+) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(attempt)
+		if err == nil || attempt >= attempts || !retryable(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+
+		timer := time.NewTimer(backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+	}
+}
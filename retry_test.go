@@ -0,0 +1,63 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	_, marshalErr := json.Marshal(ChatCompletionRequest{ExtraFields: map[string]any{"model": "x"}})
+	require.ErrorIs(t, marshalErr, ErrExtraFieldConflict)
+	_, parseErr := url.Parse("http://[::1")
+	decodeErr := json.Unmarshal([]byte(`{"id":1}`), &struct{ ID string }{})
+
+	for name, err := range map[string]error{
+		"quota":         &QuotaExceededError{Caller: "alice", Period: QuotaDaily, Limit: "cost"},
+		"marshal":       marshalErr,
+		"url parse":     parseErr,
+		"decode":        decodeErr,
+		"bad request":   &APIError{Code: http.StatusBadRequest, HTTPStatusCode: http.StatusBadRequest},
+		"cancelled":     &url.Error{Op: "Post", URL: "https://openrouter.ai", Err: context.Canceled},
+		"unknown error": errors.New("boom"),
+	} {
+		require.False(t, isTransient(err), name)
+	}
+
+	for name, err := range map[string]error{
+		"reset":          &url.Error{Op: "Post", URL: "https://openrouter.ai", Err: syscall.ECONNRESET},
+		"unexpected EOF": io.ErrUnexpectedEOF,
+		"bad gateway":    &APIError{Code: http.StatusBadGateway, HTTPStatusCode: http.StatusBadGateway},
+	} {
+		require.True(t, isTransient(err), name)
+	}
+}
+
+func TestRetryTriesPermanentErrorsOnce(t *testing.T) {
+	t.Parallel()
+
+	_, marshalErr := json.Marshal(ChatCompletionRequest{ExtraFields: map[string]any{"model": "x"}})
+	for _, err := range []error{
+		&QuotaExceededError{Caller: "alice", Period: QuotaDaily, Limit: "cost"},
+		marshalErr,
+	} {
+		attempts := 0
+		got := retry(context.Background(), 5, ExponentialBackoff(time.Millisecond, time.Millisecond), isTransient,
+			func(int) error {
+				attempts++
+				return err
+			})
+		require.ErrorIs(t, got, err)
+		require.Equal(t, 1, attempts, "%v must not be retried", err)
+	}
+}
@@ -0,0 +1,307 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// SemanticCacheEntry is a cached chat completion.
+type SemanticCacheEntry struct {
+	// Partition groups the entries that may answer each other: those with the
+	// same model, parameters and messages before the last one.
+	Partition string
+	// Vector is the embedding of the last user message.
+	Vector    []float64
+	Response  ChatCompletionResponse
+	CreatedAt time.Time
+}
+
+// SemanticCacheStore stores the entries of a SemanticCache. Implementations
+// must be safe for concurrent use. NewCacheSemanticStore adapts any CacheStore.
+type SemanticCacheStore interface {
+	// Nearest returns the entry of partition whose vector is most similar to
+	// vector, with their cosine similarity, or false when the partition has
+	// none. Entries created before notBefore have expired and are skipped; a
+	// zero notBefore skips none.
+	Nearest(ctx context.Context, partition string, vector []float64, notBefore time.Time) (SemanticCacheEntry, float64, bool, error)
+	// Add stores entry. Entries created before notBefore have expired and may
+	// be dropped; a zero notBefore drops none.
+	Add(ctx context.Context, entry SemanticCacheEntry, notBefore time.Time) error
+}
+
+// SemanticCache answers chat completions from previous responses to similar
+// prompts, saving the cost and latency of generating them again. A request is
+// answered from cache when the model, parameters and messages other than the
+// last user message are identical to those of a cached request, and the last
+// user messages are at least Threshold similar.
+//
+// Every lookup embeds the last user message, so the cache pays off when the
+// embedding model is much cheaper than the chat model.
+type SemanticCache struct {
+	// EmbeddingModel embeds the last user message of requests.
+	EmbeddingModel string
+	// Threshold is the cosine similarity above which a cached response is
+	// returned, e.g. 0.95. Lower values answer more requests from cache at the
+	// risk of wrong answers.
+	Threshold float64
+	// TTL is how long entries answer requests. Zero keeps them forever.
+	TTL   time.Duration
+	Store SemanticCacheStore
+}
+
+// NewSemanticCache returns a SemanticCache keeping up to maxEntries entries in
+// memory.
+func NewSemanticCache(embeddingModel string, threshold float64, maxEntries int) *SemanticCache {
+	return &SemanticCache{
+		EmbeddingModel: embeddingModel,
+		Threshold:      threshold,
+		Store:          NewMemorySemanticCacheStore(maxEntries),
+	}
+}
+
+// semanticCacheLookup is the state of a cache lookup, reused to store the
+// response of a miss without embedding the prompt again.
+type semanticCacheLookup struct {
+	cache     *SemanticCache
+	partition string
+	vector    []float64
+	logger    *slog.Logger
+	// hit is the cached response, nil on a miss.
+	hit *ChatCompletionResponse
+}
+
+// lookup searches the cache for request. Requests the cache cannot answer, and
+// failures of the embedding or the store, are treated as misses.
+func (s *SemanticCache) lookup(ctx context.Context, client *Client, request ChatCompletionRequest) *semanticCacheLookup {
+	n := len(request.Messages)
+	if n == 0 || request.Messages[n-1].Role != ChatMessageRoleUser || request.Messages[n-1].Content.Text == "" {
+		return nil
+	}
+	prompt := request.Messages[n-1].Content.Text
+
+	request.Messages = request.Messages[:n-1]
+	partition, err := HashRequest(request)
+	if err != nil {
+		return nil
+	}
+	l := &semanticCacheLookup{cache: s, partition: partition, logger: client.logger()}
+
+	resp, err := client.CreateEmbeddings(ctx, EmbeddingsRequest{
+		Model:          s.EmbeddingModel,
+		Input:          prompt,
+		EncodingFormat: EmbeddingsEncodingFormatFloat,
+	})
+	if err == nil && (len(resp.Data) == 0 || len(resp.Data[0].Embedding.Vector) == 0) {
+		err = errors.New("no embedding in the response")
+	}
+	if err != nil {
+		l.logger.Error("failed to embed prompt for the semantic cache", "error", err)
+		return nil
+	}
+	l.vector = resp.Data[0].Embedding.Vector
+
+	entry, similarity, ok, err := s.Store.Nearest(ctx, l.partition, l.vector, s.notBefore())
+	if err != nil {
+		l.logger.Error("failed to search the semantic cache", "error", err)
+		return l
+	}
+	if ok && similarity >= s.Threshold {
+		hit := entry.Response
+		hit.Cached = true
+		l.hit = &hit
+	}
+	return l
+}
+
+// notBefore returns the creation time before which entries have expired, or
+// the zero time when they never do.
+func (s *SemanticCache) notBefore() time.Time {
+	if s.TTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-s.TTL)
+}
+
+// store adds response to the cache for the request of the lookup.
+func (l *semanticCacheLookup) store(ctx context.Context, response ChatCompletionResponse) {
+	err := l.cache.Store.Add(ctx, SemanticCacheEntry{
+		Partition: l.partition,
+		Vector:    l.vector,
+		Response:  response,
+		CreatedAt: time.Now(),
+	}, l.cache.notBefore())
+	if err != nil {
+		l.logger.Error("failed to add to the semantic cache", "error", err)
+	}
+}
+
+// MemorySemanticCacheStore is a SemanticCacheStore searching its entries
+// linearly, suitable for up to tens of thousands of entries. Expired entries
+// are dropped on every addition, then the oldest entries are evicted first.
+type MemorySemanticCacheStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries []SemanticCacheEntry
+}
+
+// NewMemorySemanticCacheStore returns a store keeping up to maxEntries
+// entries, or any number when maxEntries is not positive.
+func NewMemorySemanticCacheStore(maxEntries int) *MemorySemanticCacheStore {
+	return &MemorySemanticCacheStore{maxEntries: maxEntries}
+}
+
+func (m *MemorySemanticCacheStore) Nearest(
+	_ context.Context,
+	partition string,
+	vector []float64,
+	notBefore time.Time,
+) (SemanticCacheEntry, float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, similarity, ok := nearestEntry(m.entries, partition, vector, notBefore)
+	return entry, similarity, ok, nil
+}
+
+func (m *MemorySemanticCacheStore) Add(_ context.Context, entry SemanticCacheEntry, notBefore time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = dropExpired(m.entries, notBefore)
+	if m.maxEntries > 0 && len(m.entries) >= m.maxEntries {
+		m.entries = append(m.entries[:0], m.entries[len(m.entries)-m.maxEntries+1:]...)
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// CacheSemanticStore is a SemanticCacheStore keeping the entries of every
+// partition as one value of a CacheStore, so a semantic cache can be shared
+// through Redis, SQLite or files. Adding an entry rewrites its partition, so
+// concurrent additions from several processes may drop one another.
+type CacheSemanticStore struct {
+	store           CacheStore
+	ttl             time.Duration
+	maxPerPartition int
+}
+
+// NewCacheSemanticStore returns a SemanticCacheStore over store, keeping up to
+// maxPerPartition entries per partition, or any number when not positive, for
+// ttl after the last addition, or forever when ttl is zero.
+func NewCacheSemanticStore(store CacheStore, ttl time.Duration, maxPerPartition int) *CacheSemanticStore {
+	return &CacheSemanticStore{store: store, ttl: ttl, maxPerPartition: maxPerPartition}
+}
+
+// semanticCachePrefix namespaces the partitions of CacheSemanticStore in its store.
+const semanticCachePrefix = "openrouter:semantic:"
+
+func (c *CacheSemanticStore) entries(ctx context.Context, partition string) ([]SemanticCacheEntry, error) {
+	data, ok, err := c.store.Get(ctx, semanticCachePrefix+partition)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var entries []SemanticCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (c *CacheSemanticStore) Nearest(
+	ctx context.Context,
+	partition string,
+	vector []float64,
+	notBefore time.Time,
+) (SemanticCacheEntry, float64, bool, error) {
+	entries, err := c.entries(ctx, partition)
+	if err != nil {
+		return SemanticCacheEntry{}, 0, false, err
+	}
+	entry, similarity, ok := nearestEntry(entries, partition, vector, notBefore)
+	return entry, similarity, ok, nil
+}
+
+func (c *CacheSemanticStore) Add(ctx context.Context, entry SemanticCacheEntry, notBefore time.Time) error {
+	entries, err := c.entries(ctx, entry.Partition)
+	if err != nil {
+		return err
+	}
+	entries = append(dropExpired(entries, notBefore), entry)
+	if c.maxPerPartition > 0 && len(entries) > c.maxPerPartition {
+		entries = entries[len(entries)-c.maxPerPartition:]
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, semanticCachePrefix+entry.Partition, data, c.ttl)
+}
+
+// nearestEntry returns the entry of partition most similar to vector among
+// those not expired at notBefore.
+func nearestEntry(
+	entries []SemanticCacheEntry,
+	partition string,
+	vector []float64,
+	notBefore time.Time,
+) (SemanticCacheEntry, float64, bool) {
+	var best SemanticCacheEntry
+	bestSimilarity, found := math.Inf(-1), false
+	for _, entry := range entries {
+		if entry.Partition != partition || expired(entry, notBefore) {
+			continue
+		}
+		if similarity := cosineSimilarity(vector, entry.Vector); similarity > bestSimilarity {
+			best, bestSimilarity, found = entry, similarity, true
+		}
+	}
+	if !found {
+		return SemanticCacheEntry{}, 0, false
+	}
+	return best, bestSimilarity, true
+}
+
+// expired reports whether entry was created before notBefore, when it is set.
+func expired(entry SemanticCacheEntry, notBefore time.Time) bool {
+	return !notBefore.IsZero() && entry.CreatedAt.Before(notBefore)
+}
+
+// dropExpired removes the entries expired at notBefore from entries, in place.
+func dropExpired(entries []SemanticCacheEntry, notBefore time.Time) []SemanticCacheEntry {
+	if notBefore.IsZero() {
+		return entries
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if !expired(entry, notBefore) {
+			kept = append(kept, entry)
+		}
+	}
+	clear(entries[len(kept):])
+	return kept
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, 0 when
+// their lengths differ or either is zero.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,128 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemanticCache(t *testing.T) {
+	t.Parallel()
+
+	// Prompts about the weather embed close together, anything else far away.
+	vectors := map[string]string{
+		"What's the weather in Paris?":   "[1, 0.1, 0]",
+		"what is the weather in paris":   "[1, 0.12, 0]",
+		"Write a poem about the weather": "[0, 0.1, 1]",
+	}
+	var completions atomic.Int32
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		if strings.HasSuffix(req.URL.Path, "/embeddings") {
+			var body struct {
+				Input string `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			return jsonResponse(http.StatusOK, `{"data":[{"index":0,"embedding":`+vectors[body.Input]+`}]}`)
+		}
+		completions.Add(1)
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","choices":[{"message":{"role":"assistant","content":"Sunny"}}]}`)
+	})
+	client.config.SemanticCache = NewSemanticCache("openai/text-embedding-3-small", 0.95, 10)
+
+	ask := func(system, prompt string) ChatCompletionResponse {
+		t.Helper()
+		resp, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+			Model:    "openai/gpt-4o-mini",
+			Messages: []ChatCompletionMessage{SystemMessage(system), UserMessage(prompt)},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := ask("Be brief.", "What's the weather in Paris?")
+	require.False(t, first.Cached)
+
+	second := ask("Be brief.", "what is the weather in paris")
+	require.True(t, second.Cached)
+	require.Equal(t, "Sunny", second.Choices[0].Message.Content.Text)
+	require.Equal(t, int32(1), completions.Load())
+
+	require.False(t, ask("Be verbose.", "what is the weather in paris").Cached, "other messages must match exactly")
+	require.False(t, ask("Be brief.", "Write a poem about the weather").Cached)
+	require.Equal(t, int32(3), completions.Load())
+
+	// Requests not ending with a user message bypass the cache.
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []ChatCompletionMessage{UserMessage("What's the weather in Paris?"), AssistantMessage("It is")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(4), completions.Load())
+}
+
+func TestMemorySemanticCacheStoreEvictsOldest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemorySemanticCacheStore(2)
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, store.Add(ctx, SemanticCacheEntry{
+			Partition: "p",
+			Vector:    []float64{1, 0},
+			Response:  ChatCompletionResponse{ID: id},
+		}, time.Time{}))
+	}
+	require.Len(t, store.entries, 2)
+	require.Equal(t, "b", store.entries[0].Response.ID)
+
+	_, _, ok, err := store.Nearest(ctx, "other", []float64{1, 0}, time.Time{})
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestMemorySemanticCacheStoreSkipsExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemorySemanticCacheStore(0)
+	require.NoError(t, store.Add(ctx, SemanticCacheEntry{
+		Partition: "p",
+		Vector:    []float64{1, 0},
+		Response:  ChatCompletionResponse{ID: "expired"},
+		CreatedAt: now.Add(-2 * time.Hour),
+	}, time.Time{}))
+	require.NoError(t, store.Add(ctx, SemanticCacheEntry{
+		Partition: "p",
+		Vector:    []float64{1, 0.1},
+		Response:  ChatCompletionResponse{ID: "fresh"},
+		CreatedAt: now,
+	}, time.Time{}))
+
+	// The expired entry is the nearest but must not hide the fresh one.
+	entry, similarity, ok, err := store.Nearest(ctx, "p", []float64{1, 0}, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "fresh", entry.Response.ID)
+	require.Greater(t, similarity, 0.99)
+
+	require.NoError(t, store.Add(ctx, SemanticCacheEntry{Partition: "p", Vector: []float64{0, 1}, CreatedAt: now}, now.Add(-time.Hour)))
+	require.Len(t, store.entries, 2, "expired entries are dropped on Add")
+	require.Equal(t, "fresh", store.entries[0].Response.ID)
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 1, cosineSimilarity([]float64{1, 2}, []float64{2, 4}), 1e-9)
+	require.InDelta(t, 0, cosineSimilarity([]float64{1, 0}, []float64{0, 1}), 1e-9)
+	require.InDelta(t, -1, cosineSimilarity([]float64{1, 0}, []float64{-1, 0}), 1e-9)
+	require.Zero(t, cosineSimilarity([]float64{1}, []float64{1, 0}))
+	require.Zero(t, cosineSimilarity([]float64{0, 0}, []float64{1, 0}))
+}
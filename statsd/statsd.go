@@ -0,0 +1,142 @@
+// Package statsd sends the metrics of the openrouter client to a StatsD
+// server over UDP, for shops on Datadog or another StatsD-compatible agent.
+//
+// A Sink implements openrouter.MetricsSink. Counters are sent as StatsD
+// counters and observations as histograms, with tags in the DogStatsD format
+// understood by Datadog, Telegraf and StatsD exporters:
+//
+//	sink, err := statsd.Dial("127.0.0.1:8125", statsd.Options{
+//		Tags: map[string]string{"service": "search"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer sink.Close()
+//	client := openrouter.NewClient(key, openrouter.WithMetricsSink(sink))
+package statsd
+
+import (
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	openrouter "github.com/revrost/go-openrouter"
+)
+
+// Options configures a Sink.
+type Options struct {
+	// Prefix is prepended to the name of every metric, e.g. "myapp.".
+	Prefix string
+	// Tags are added to the tags of every metric.
+	Tags map[string]string
+	// NoTags drops all tags, for StatsD servers that do not support the
+	// DogStatsD tag extension.
+	NoTags bool
+}
+
+// Sink is an openrouter.MetricsSink writing one StatsD packet per metric.
+// Write errors are dropped, as StatsD is fire-and-forget. It is safe for
+// concurrent use.
+type Sink struct {
+	options Options
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ openrouter.MetricsSink = (*Sink)(nil)
+
+// Dial returns a Sink sending to the StatsD server at addr over UDP.
+func Dial(addr string, options Options) (*Sink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewSink(conn, options), nil
+}
+
+// NewSink returns a Sink writing every metric to w in a single Write call,
+// e.g. to a Unix datagram socket.
+func NewSink(w io.Writer, options Options) *Sink {
+	return &Sink{options: options, w: w}
+}
+
+// IncCounter sends value to the counter called name.
+func (s *Sink) IncCounter(name string, value float64, tags map[string]string) {
+	s.send(name, value, "c", tags)
+}
+
+// Observe sends value to the histogram called name.
+func (s *Sink) Observe(name string, value float64, tags map[string]string) {
+	s.send(name, value, "h", tags)
+}
+
+// Close closes the underlying writer, if it is an io.Closer.
+func (s *Sink) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *Sink) send(name string, value float64, kind string, tags map[string]string) {
+	var b strings.Builder
+	b.WriteString(sanitize(s.options.Prefix+name, ":|@"))
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if !s.options.NoTags {
+		writeTags(&b, s.options.Tags, tags)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = io.WriteString(s.w, b.String())
+}
+
+// writeTags writes the DogStatsD tags of base and tags, sorted by key. Tags
+// override base tags with the same key.
+func writeTags(b *strings.Builder, base, tags map[string]string) {
+	merged := make(map[string]string, len(base)+len(tags))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	if len(merged) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	b.WriteString("|#")
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(sanitize(key, ":|,#"))
+		if value := merged[key]; value != "" {
+			b.WriteByte(':')
+			b.WriteString(sanitize(value, "|,#"))
+		}
+	}
+}
+
+// sanitize replaces the characters of reserved and newlines in s with
+// underscores.
+func sanitize(s, reserved string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || strings.ContainsRune(reserved, r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
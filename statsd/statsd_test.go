@@ -0,0 +1,65 @@
+package statsd_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/revrost/go-openrouter/statsd"
+	"github.com/stretchr/testify/require"
+)
+
+type packets []string
+
+func (p *packets) Write(b []byte) (int, error) {
+	*p = append(*p, string(b))
+	return len(b), nil
+}
+
+func TestSinkFormatsMetrics(t *testing.T) {
+	t.Parallel()
+
+	var sent packets
+	sink := statsd.NewSink(&sent, statsd.Options{
+		Prefix: "app.",
+		Tags:   map[string]string{"service": "search", "env": "prod"},
+	})
+	sink.IncCounter("openrouter_stream_chunks_total", 12, map[string]string{"model": "openai/gpt-4o", "env": "dev"})
+	sink.Observe("openrouter_stream_duration_seconds", 0.25, nil)
+	sink.Observe("a:b|c", 1, map[string]string{"k,#": "v|1", "flag": ""})
+
+	require.Equal(t, packets{
+		"app.openrouter_stream_chunks_total:12|c|#env:dev,model:openai/gpt-4o,service:search",
+		"app.openrouter_stream_duration_seconds:0.25|h|#env:prod,service:search",
+		"app.a_b_c:1|h|#env:prod,flag,k__:v_1,service:search",
+	}, sent)
+}
+
+func TestSinkWithoutTags(t *testing.T) {
+	t.Parallel()
+
+	var sent packets
+	sink := statsd.NewSink(&sent, statsd.Options{NoTags: true, Tags: map[string]string{"service": "search"}})
+	sink.IncCounter("requests", 1, map[string]string{"model": "openai/gpt-4o"})
+
+	require.Equal(t, packets{"requests:1|c"}, sent)
+}
+
+func TestDialSendsOverUDP(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := statsd.Dial(conn.LocalAddr().String(), statsd.Options{})
+	require.NoError(t, err)
+	defer sink.Close()
+	sink.IncCounter("requests", 2, map[string]string{"model": "openai/gpt-4o"})
+
+	buf := make([]byte, 512)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, "requests:2|c|#model:openai/gpt-4o", string(buf[:n]))
+}
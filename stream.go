@@ -0,0 +1,394 @@
+package openrouter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStreamStalled is returned by Recv when no SSE event, not even a keep-alive
+// comment, arrived within the configured stream idle timeout.
+var ErrStreamStalled = errors.New("stream stalled: no event received within the idle timeout")
+
+var (
+	sseDataPrefix    = []byte("data:")
+	sseCommentPrefix = []byte(":")
+	sseDone          = []byte("[DONE]")
+)
+
+// StreamEvent is a single line of a server-sent events stream as returned by
+// RecvEvent. Unless raw stream events are enabled (see WithRawStreamEvents),
+// only data lines carrying a chunk are surfaced and Raw is nil.
+type StreamEvent[T any] struct {
+	// Raw is the line exactly as received, including its line terminator, so
+	// proxies can forward the stream byte for byte.
+	Raw []byte
+	// Comment is true for comment lines such as ": OPENROUTER PROCESSING".
+	Comment bool
+	// Done is true for the terminating "data: [DONE]" line.
+	Done bool
+	// Chunk is the decoded payload of a data line, nil for every other line.
+	Chunk *T
+}
+
+// sseStreamOptions configures how an sseStream reads its body.
+type sseStreamOptions[T any] struct {
+	// name identifies the stream in log records, e.g. "chat completion".
+	name string
+	// idleTimeout terminates the stream with ErrStreamStalled when positive.
+	idleTimeout time.Duration
+	// raw surfaces every line of the body as a StreamEvent, not only chunks.
+	raw bool
+	// onComment, if set, is called with the text of every comment line.
+	onComment func(comment string)
+	// onChunk, if set, observes every decoded chunk before it is delivered.
+	onChunk func(T)
+	// onEnd, if set, is called once the stream has ended, with the error that
+	// terminated it or nil, and its final StreamStats.
+	onEnd func(err error, stats StreamStats)
+	// resume, if set, is asked for a replacement response when the body fails
+	// with a transport error. Returning an error ends the stream.
+	resume func(ctx context.Context) (*http.Response, error)
+
+	// startedAt is when the request was sent, the origin for StreamStats.
+	startedAt time.Time
+	// hasToken reports whether a chunk carries generated output.
+	hasToken func(T) bool
+	// usage extracts the reported usage from a chunk, if any.
+	usage func(T) *Usage
+	// metrics receives the StreamStats when the stream ends.
+	metrics MetricsSink
+	tags    map[string]string
+	// logger receives the log records of the stream. Defaults to slog.Default.
+	logger *slog.Logger
+}
+
+// sseStream decodes the data events of a server-sent events body into values of type T.
+// It backs both ChatCompletionStream and CompletionStream.
+type sseStream[T any] struct {
+	stream chan StreamEvent[T]
+	done   chan struct{}
+	cancel context.CancelFunc
+	// mu guards response, which the reader replaces when it resumes the
+	// stream, so that Close releases the connection currently being read.
+	mu       sync.Mutex
+	response *http.Response
+	// err and stats are set by the reader goroutine before stream is closed.
+	err   error
+	stats StreamStats
+	// finished is closed once stats is final.
+	finished chan struct{}
+
+	closeOnce sync.Once
+}
+
+// newSSEStream starts reading resp in the background. cancel must cancel ctx;
+// it is called when the stream ends or is closed.
+func newSSEStream[T any](
+	ctx context.Context,
+	cancel context.CancelFunc,
+	resp *http.Response,
+	opts sseStreamOptions[T],
+) *sseStream[T] {
+	s := &sseStream[T]{
+		stream:   make(chan StreamEvent[T]),
+		done:     make(chan struct{}),
+		response: resp,
+		cancel:   cancel,
+		finished: make(chan struct{}),
+	}
+	go s.read(ctx, opts)
+	return s
+}
+
+func (s *sseStream[T]) read(ctx context.Context, opts sseStreamOptions[T]) {
+	resp := s.response
+	logger := opts.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	watchdog := newIdleWatchdog(opts.idleTimeout, resp.Body)
+	recorder := newStreamStatsRecorder(opts.startedAt)
+	defer close(s.stream)
+	if opts.onEnd != nil {
+		defer func() { opts.onEnd(s.err, s.stats) }()
+	}
+	defer func() {
+		s.stats = recorder.finish(time.Now())
+		close(s.finished)
+		emitStreamStats(opts.metrics, s.stats, opts.tags)
+	}()
+	defer s.cancel()
+	defer func() {
+		watchdog.stop()
+		resp.Body.Close()
+	}()
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		// Only time spent waiting on the network counts as idle, not time the
+		// consumer takes to receive the previous event.
+		watchdog.reset()
+		line, err := reader.ReadBytes('\n')
+		watchdog.stop()
+		if watchdog.fired() {
+			s.err = ErrStreamStalled
+			return
+		}
+		if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+			if errors.Is(err, io.EOF) || s.closed() {
+				return
+			}
+			if ctx.Err() != nil {
+				s.err = ctx.Err()
+				return
+			}
+			if opts.resume != nil {
+				next, resumeErr := opts.resume(ctx)
+				if resumeErr == nil {
+					watchdog.stop()
+					resp.Body.Close()
+					if !s.replaceResponse(next) {
+						return
+					}
+					resp = next
+					watchdog = newIdleWatchdog(opts.idleTimeout, resp.Body)
+					reader = bufio.NewReader(resp.Body)
+					continue
+				}
+				logger.Error("failed to resume "+opts.name+" stream", "error", resumeErr)
+			}
+			logger.Error("failed to read "+opts.name+" stream", "error", err)
+			s.err = err
+			return
+		}
+
+		var raw []byte
+		if opts.raw {
+			raw = append([]byte(nil), line...)
+		}
+
+		// Comments (such as ": OPENROUTER PROCESSING"), blank lines and
+		// non-data fields like "event:" carry no payload.
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, sseDataPrefix) {
+			comment := bytes.HasPrefix(line, sseCommentPrefix)
+			if comment && opts.onComment != nil {
+				opts.onComment(string(bytes.TrimSpace(bytes.TrimPrefix(line, sseCommentPrefix))))
+			}
+			if opts.raw && !s.deliver(ctx, StreamEvent[T]{Raw: raw, Comment: comment}) {
+				return
+			}
+			continue
+		}
+		data := bytes.TrimSpace(bytes.TrimPrefix(line, sseDataPrefix))
+		if bytes.Equal(data, sseDone) {
+			if opts.raw {
+				s.deliver(ctx, StreamEvent[T]{Raw: raw, Done: true})
+			}
+			return
+		}
+
+		var chunk T
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			logger.Error("failed to decode "+opts.name+" stream", "error", err, "line", string(data))
+			s.err = fmt.Errorf("decode %s stream chunk: %w", opts.name, err)
+			return
+		}
+		recorder.chunk(time.Now(), opts.hasToken != nil && opts.hasToken(chunk), usageOf(opts.usage, chunk))
+		if opts.onChunk != nil {
+			opts.onChunk(chunk)
+		}
+
+		if !s.deliver(ctx, StreamEvent[T]{Raw: raw, Chunk: &chunk}) {
+			return
+		}
+	}
+}
+
+// replaceResponse makes next the response released by Close. It returns false,
+// closing next, when the stream was closed while resuming.
+func (s *sseStream[T]) replaceResponse(next *http.Response) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed() {
+		next.Body.Close()
+		return false
+	}
+	s.response = next
+	return true
+}
+
+// deliver hands event to the consumer. It returns false when the stream was
+// closed or its context cancelled while waiting.
+func (s *sseStream[T]) deliver(ctx context.Context, event StreamEvent[T]) bool {
+	select {
+	case s.stream <- event:
+		return true
+	case <-s.done:
+		return false
+	case <-ctx.Done():
+		if !s.closed() {
+			s.err = ctx.Err()
+		}
+		return false
+	}
+}
+
+func usageOf[T any](usage func(T) *Usage, chunk T) *Usage {
+	if usage == nil {
+		return nil
+	}
+	return usage(chunk)
+}
+
+// closed reports whether Close has been called.
+func (s *sseStream[T]) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Recv returns the next chunk, io.EOF once the stream has ended, or the error
+// that terminated it. Lines without a chunk are skipped.
+func (s *sseStream[T]) Recv() (T, error) {
+	for {
+		event, err := s.RecvEvent()
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		if event.Chunk != nil {
+			return *event.Chunk, nil
+		}
+	}
+}
+
+// RecvEvent returns the next event, io.EOF once the stream has ended, or the
+// error that terminated it.
+func (s *sseStream[T]) RecvEvent() (StreamEvent[T], error) {
+	select {
+	case event, ok := <-s.stream:
+		if !ok {
+			if s.err != nil {
+				return StreamEvent[T]{}, s.err
+			}
+			return StreamEvent[T]{}, io.EOF
+		}
+		return event, nil
+	case <-s.done:
+		return StreamEvent[T]{}, io.EOF
+	}
+}
+
+// Stats returns the timing of the stream. It is complete once Recv has
+// returned an error, including io.EOF.
+func (s *sseStream[T]) Stats() StreamStats {
+	select {
+	case <-s.finished:
+		return s.stats
+	default:
+		return StreamStats{}
+	}
+}
+
+// Close terminates the stream and releases the underlying connection.
+// It is safe to call from any goroutine, any number of times.
+func (s *sseStream[T]) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.response != nil {
+			s.response.Body.Close()
+		}
+	})
+}
+
+// openStream sends a streaming request and returns the response once the
+// server has accepted it.
+func (c *Client) openStream(ctx context.Context, urlSuffix string, body any) (*http.Response, error) {
+	req, err := c.newStreamRequest(ctx, http.MethodPost, urlSuffix, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpDoer().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if isFailureStatusCode(resp) {
+		defer resp.Body.Close()
+		return nil, c.handleErrorResp(resp)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, &RequestError{
+			HTTPStatus:     resp.Status,
+			HTTPStatusCode: resp.StatusCode,
+			Err:            errors.New("unexpected status code: " + resp.Status),
+			Body:           body,
+		}
+	}
+	return resp, nil
+}
+
+// idleWatchdog closes the stream body when a read did not return a line within
+// timeout, which unblocks the pending read so the reader can report
+// ErrStreamStalled. It runs only between reset and stop.
+type idleWatchdog struct {
+	timer   *time.Timer
+	timeout time.Duration
+	stalled atomic.Bool
+}
+
+// newIdleWatchdog returns nil when timeout is not positive; all methods are nil-safe.
+func newIdleWatchdog(timeout time.Duration, body io.Closer) *idleWatchdog {
+	if timeout <= 0 {
+		return nil
+	}
+
+	w := &idleWatchdog{timeout: timeout}
+	w.timer = time.AfterFunc(timeout, func() {
+		w.stalled.Store(true)
+		body.Close()
+	})
+	return w
+}
+
+// reset restarts the idle countdown before a read.
+func (w *idleWatchdog) reset() {
+	if w == nil {
+		return
+	}
+	w.timer.Reset(w.timeout)
+}
+
+func (w *idleWatchdog) stop() {
+	if w == nil {
+		return
+	}
+	w.timer.Stop()
+}
+
+// fired reports whether the stream was terminated for being idle.
+func (w *idleWatchdog) fired() bool {
+	return w != nil && w.stalled.Load()
+}
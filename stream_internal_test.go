@@ -0,0 +1,424 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// streamCase opens either a chat or a text completion stream against body and
+// exposes its text deltas, so both readers run through the same assertions.
+type streamCase struct {
+	name  string
+	chunk func(text string) string
+	open  func(ctx context.Context, client *Client) (recv func() (string, error), closeFn func(), err error)
+}
+
+var streamCases = []streamCase{
+	{
+		name: "chat",
+		chunk: func(text string) string {
+			return `data: {"id":"1","choices":[{"delta":{"content":"` + text + `"}}]}`
+		},
+		open: func(ctx context.Context, client *Client) (func() (string, error), func(), error) {
+			stream, err := client.CreateChatCompletionStream(ctx, ChatCompletionRequest{
+				Messages: []ChatCompletionMessage{UserMessage("hello")},
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return func() (string, error) {
+				chunk, err := stream.Recv()
+				if err != nil {
+					return "", err
+				}
+				return chunk.Choices[0].Delta.Content, nil
+			}, stream.Close, nil
+		},
+	},
+	{
+		name: "completion",
+		chunk: func(text string) string {
+			return `data: {"id":"1","choices":[{"text":"` + text + `"}]}`
+		},
+		open: func(ctx context.Context, client *Client) (func() (string, error), func(), error) {
+			stream, err := client.CreateCompletionStream(ctx, CompletionRequest{Prompt: TextPrompt("hello")})
+			if err != nil {
+				return nil, nil, err
+			}
+			return func() (string, error) {
+				chunk, err := stream.Recv()
+				if err != nil {
+					return "", err
+				}
+				return chunk.Choices[0].Text, nil
+			}, stream.Close, nil
+		},
+	},
+}
+
+func newStreamTestClient(body io.Reader) *Client {
+	cfg := DefaultConfig("test-token")
+	cfg.BaseURL = "https://example.com/api/v1"
+	cfg.HTTPClient = &fakeHTTPClient{response: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(body),
+	}}
+	return NewClientWithConfig(*cfg)
+}
+
+func readAllDeltas(t *testing.T, recv func() (string, error)) (string, error) {
+	t.Helper()
+
+	var text strings.Builder
+	for {
+		delta, err := recv()
+		if err != nil {
+			return text.String(), err
+		}
+		text.WriteString(delta)
+	}
+}
+
+func TestSSEStreamSkipsNonDataLines(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newStreamTestClient(strings.NewReader(strings.Join([]string{
+				`: OPENROUTER PROCESSING`,
+				``,
+				`event: message`,
+				tc.chunk("a"),
+				`id: 2`,
+				"\r",
+				tc.chunk("b"),
+				`data: [DONE]`,
+				tc.chunk("ignored"),
+			}, "\n")))
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+			defer closeFn()
+
+			text, err := readAllDeltas(t, recv)
+			require.ErrorIs(t, err, io.EOF)
+			require.Equal(t, "ab", text)
+		})
+	}
+}
+
+func TestSSEStreamHandlesMissingTrailingNewline(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newStreamTestClient(strings.NewReader(tc.chunk("a") + "\n" + tc.chunk("b")))
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+			defer closeFn()
+
+			text, err := readAllDeltas(t, recv)
+			require.ErrorIs(t, err, io.EOF)
+			require.Equal(t, "ab", text)
+		})
+	}
+}
+
+func TestSSEStreamReportsDecodeErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newStreamTestClient(strings.NewReader(sseBody(tc.chunk("a"), `data: {not json`)))
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+			defer closeFn()
+
+			text, err := readAllDeltas(t, recv)
+			require.Equal(t, "a", text)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, io.EOF)
+			require.Contains(t, err.Error(), "decode")
+		})
+	}
+}
+
+func TestSSEStreamReportsReadErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newStreamTestClient(io.MultiReader(
+				strings.NewReader(sseBody(tc.chunk("a"))),
+				failingReader{err: io.ErrUnexpectedEOF},
+			))
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+			defer closeFn()
+
+			text, err := readAllDeltas(t, recv)
+			require.Equal(t, "a", text)
+			require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		})
+	}
+}
+
+func TestSSEStreamReportsContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, writer := io.Pipe()
+			defer writer.Close()
+			go func() {
+				_, _ = writer.Write([]byte(sseBody(tc.chunk("a"))))
+			}()
+			client := newStreamTestClient(body)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			recv, closeFn, err := tc.open(ctx, client)
+			require.NoError(t, err)
+			defer closeFn()
+
+			delta, err := recv()
+			require.NoError(t, err)
+			require.Equal(t, "a", delta)
+
+			cancel()
+			// The pending read is not interrupted by the fake transport, so
+			// unblock it the way a real one would.
+			writer.CloseWithError(context.Canceled)
+
+			_, err = recv()
+			require.True(t, errors.Is(err, context.Canceled), "got %v", err)
+		})
+	}
+}
+
+func TestSSEStreamCloseStopsReading(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newStreamTestClient(strings.NewReader(sseBody(tc.chunk("a"), tc.chunk("b"))))
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+
+			closeFn()
+			_, err = recv()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestSSEStreamCloseIsIdempotentAndConcurrent(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, writer := io.Pipe()
+			defer writer.Close()
+			go func() {
+				for {
+					if _, err := writer.Write([]byte(sseBody(tc.chunk("a")))); err != nil {
+						return
+					}
+				}
+			}()
+			client := newStreamTestClient(body)
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if _, err := recv(); err != nil {
+						return
+					}
+				}
+			}()
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					closeFn()
+				}()
+			}
+			wg.Wait()
+
+			closeFn()
+			_, err = recv()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestSSEStreamCloseUnblocksProducerWithoutReader(t *testing.T) {
+	t.Parallel()
+
+	client := newStreamTestClient(strings.NewReader(sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"a"}}]}`,
+		`data: {"id":"1","choices":[{"delta":{"content":"b"}}]}`,
+	)))
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+
+	// Nobody calls Recv, so the producer is blocked delivering the first chunk.
+	stream.Close()
+	stream.Close()
+
+	select {
+	case <-stream.reader.finished:
+	case <-time.After(time.Second):
+		t.Fatal("producer goroutine did not exit after Close")
+	}
+}
+
+func TestTeeBranchCloseIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	client := newStreamTestClient(strings.NewReader(sseBody(
+		`data: {"id":"1","choices":[{"delta":{"content":"a"}}]}`,
+	)))
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+
+	branches := stream.Tee(2)
+	branches[0].Close()
+	branches[0].Close()
+
+	text, err := io.ReadAll(branches[1].TextReader())
+	require.NoError(t, err)
+	require.Equal(t, "a", string(text))
+	branches[1].Close()
+	stream.Close()
+}
+
+func TestSSEStreamRawEventsReproduceBody(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Join([]string{
+		": OPENROUTER PROCESSING\n",
+		"\n",
+		"data: {\"id\":\"1\",\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\r\n",
+		"\r\n",
+		"data: [DONE]\n",
+	}, "")
+	client := newStreamTestClient(strings.NewReader(body))
+	client.config.RawStreamEvents = true
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var (
+		forwarded strings.Builder
+		events    []StreamEvent[ChatCompletionStreamResponse]
+	)
+	for {
+		event, err := stream.RecvEvent()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		forwarded.Write(event.Raw)
+		events = append(events, event)
+	}
+
+	require.Equal(t, body, forwarded.String())
+	require.Len(t, events, 5)
+	require.True(t, events[0].Comment)
+	require.Nil(t, events[1].Chunk)
+	require.NotNil(t, events[2].Chunk)
+	require.Equal(t, "a", events[2].Chunk.Choices[0].Delta.Content)
+	require.True(t, events[4].Done)
+}
+
+func TestSSEStreamRecvSkipsRawOnlyEvents(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []bool{false, true} {
+		client := newStreamTestClient(strings.NewReader(sseBody(
+			`: OPENROUTER PROCESSING`,
+			`data: {"id":"1","choices":[{"text":"a"}]}`,
+			`data: [DONE]`,
+		)))
+		client.config.RawStreamEvents = raw
+
+		stream, err := client.CreateCompletionStream(context.Background(), CompletionRequest{Prompt: TextPrompt("hello")})
+		require.NoError(t, err)
+
+		chunk, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "a", chunk.Choices[0].Text)
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+		stream.Close()
+	}
+}
+
+func TestSSEStreamCommentHook(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range streamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var comments []string
+			client := newStreamTestClient(strings.NewReader(sseBody(
+				`: OPENROUTER PROCESSING`,
+				tc.chunk("a"),
+				`:OPENROUTER PROCESSING`,
+				`data: [DONE]`,
+			)))
+			client.config.StreamCommentHook = func(comment string) {
+				comments = append(comments, comment)
+			}
+
+			recv, closeFn, err := tc.open(context.Background(), client)
+			require.NoError(t, err)
+			defer closeFn()
+
+			text, err := readAllDeltas(t, recv)
+			require.ErrorIs(t, err, io.EOF)
+			require.Equal(t, "a", text)
+			require.Equal(t, []string{"OPENROUTER PROCESSING", "OPENROUTER PROCESSING"}, comments)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Thread is a conversation with a model that keeps its history between turns.
+// It is safe for concurrent use; turns are sent one at a time.
+type Thread struct {
+	client   *Client
+	request  ChatCompletionRequest
+	memory   Memory
+	mu       sync.Mutex
+	messages []ChatCompletionMessage
+}
+
+// Memory keeps the history of a Thread within bounds. Compact is called with
+// the whole history before every turn and returns the history to send and
+// keep, e.g. with older turns summarized.
+type Memory interface {
+	Compact(ctx context.Context, messages []ChatCompletionMessage) ([]ChatCompletionMessage, error)
+}
+
+// NewThread returns a thread sending request with the history as its messages.
+// The messages of request, e.g. a system prompt, start the history. memory may
+// be nil to keep the whole history.
+func (c *Client) NewThread(request ChatCompletionRequest, memory Memory) *Thread {
+	messages := append([]ChatCompletionMessage(nil), request.Messages...)
+	request.Messages = nil
+	return &Thread{client: c, request: request, memory: memory, messages: messages}
+}
+
+// Messages returns a copy of the history.
+func (t *Thread) Messages() []ChatCompletionMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ChatCompletionMessage(nil), t.messages...)
+}
+
+// Send sends a user message with text and adds it and the reply to the history.
+func (t *Thread) Send(ctx context.Context, text string) (ChatCompletionResponse, error) {
+	return t.SendMessage(ctx, UserMessage(text))
+}
+
+// SendMessage sends message and adds it and the reply to the history. The
+// history is left unchanged when the completion fails.
+func (t *Thread) SendMessage(ctx context.Context, message ChatCompletionMessage) (ChatCompletionResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	messages := append(append([]ChatCompletionMessage(nil), t.messages...), message)
+	if t.memory != nil {
+		var err error
+		messages, err = t.memory.Compact(ctx, messages)
+		if err != nil {
+			return ChatCompletionResponse{}, fmt.Errorf("thread memory: %w", err)
+		}
+	}
+
+	request := t.request
+	request.Messages = messages
+	response, err := t.client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	if len(response.Choices) == 0 {
+		return response, errors.New("thread: no choices in the response")
+	}
+
+	t.messages = append(messages, AssistantMessageFromChoice(response.Choices[0]))
+	return response, nil
+}
+
+// defaultSummaryPrompt instructs the model of SummaryMemory.
+const defaultSummaryPrompt = "Summarize the conversation below for the assistant taking part in it, " +
+	"so it can continue without the original messages. Keep facts, decisions, names, numbers " +
+	"and open questions; drop pleasantries. Reply with the summary only."
+
+// summaryPrefix starts the content of the messages holding summaries.
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// SummaryMemory is a Memory that, when the history exceeds MaxTokens,
+// summarizes all but the most recent messages with a cheap model and replaces
+// them with a system message holding the summary. Leading system messages are
+// kept, and an earlier summary is summarized again with the messages after it.
+type SummaryMemory struct {
+	// Client sends the summarization requests.
+	Client *Client
+	// Model writes the summaries, usually a cheaper model than the thread's.
+	Model string
+	// MaxTokens is the history size above which it is summarized.
+	MaxTokens int
+	// KeepRecent is the number of most recent messages kept verbatim,
+	// extended so tool results stay with the call they answer. Defaults to 4.
+	KeepRecent int
+	// Counter counts tokens. Defaults to HeuristicTokenCounter.
+	Counter TokenCounter
+	// Prompt instructs the summarizing model. Defaults to a generic prompt.
+	Prompt string
+}
+
+func (m *SummaryMemory) Compact(ctx context.Context, messages []ChatCompletionMessage) ([]ChatCompletionMessage, error) {
+	counter := m.Counter
+	if counter == nil {
+		counter = HeuristicTokenCounter
+	}
+	if counter.CountTokens(messages) <= m.MaxTokens {
+		return messages, nil
+	}
+
+	keepRecent := m.KeepRecent
+	if keepRecent <= 0 {
+		keepRecent = 4
+	}
+	start := 0
+	for start < len(messages) && messages[start].Role == ChatMessageRoleSystem && !isSummary(messages[start]) {
+		start++
+	}
+	end := max(start, len(messages)-keepRecent)
+	// Do not separate tool results from the assistant message calling them.
+	for end > start && messages[end].Role == ChatMessageRoleTool {
+		end--
+	}
+	if end <= start {
+		return messages, nil
+	}
+
+	summary, err := m.summarize(ctx, messages[start:end])
+	if err != nil {
+		return nil, err
+	}
+
+	compacted := append([]ChatCompletionMessage(nil), messages[:start]...)
+	compacted = append(compacted, SystemMessage(summaryPrefix+summary))
+	return append(compacted, messages[end:]...), nil
+}
+
+func (m *SummaryMemory) summarize(ctx context.Context, messages []ChatCompletionMessage) (string, error) {
+	prompt := m.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+
+	response, err := m.Client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model:    m.Model,
+		Messages: []ChatCompletionMessage{SystemMessage(prompt), UserMessage(transcript(messages))},
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarize: %w", err)
+	}
+	if len(response.Choices) == 0 || response.Choices[0].Message.Content.Text == "" {
+		return "", errors.New("summarize: empty summary")
+	}
+	return response.Choices[0].Message.Content.Text, nil
+}
+
+func isSummary(message ChatCompletionMessage) bool {
+	return message.Role == ChatMessageRoleSystem && strings.HasPrefix(message.Content.Text, summaryPrefix)
+}
+
+// transcript renders messages as text for a summarizing model. Earlier
+// summaries are rendered as such, and non-text parts are omitted.
+func transcript(messages []ChatCompletionMessage) string {
+	var b strings.Builder
+	for _, message := range messages {
+		text := messageText(message)
+		switch {
+		case isSummary(message):
+			fmt.Fprintf(&b, "%s\n", text)
+		case message.Role == ChatMessageRoleTool:
+			fmt.Fprintf(&b, "tool result: %s\n", text)
+		default:
+			if text != "" {
+				fmt.Fprintf(&b, "%s: %s\n", message.Role, text)
+			}
+			for _, call := range message.ToolCalls {
+				fmt.Fprintf(&b, "%s called %s(%s)\n", message.Role, call.Function.Name, call.Function.Arguments)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThread(t *testing.T) {
+	t.Parallel()
+
+	var turns int
+	client := newHandlerClient(func(r *http.Request) *http.Response {
+		var request ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if strings.Contains(request.Messages[len(request.Messages)-1].Content.Text, "fail") {
+			return jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"bad"}}`)
+		}
+		turns++
+		return jsonResponse(http.StatusOK, fmt.Sprintf(
+			`{"choices":[{"message":{"role":"assistant","content":"reply %d to %d messages"}}]}`,
+			turns, len(request.Messages)))
+	})
+
+	thread := client.NewThread(ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{SystemMessage("Be brief.")},
+	}, nil)
+
+	resp, err := thread.Send(context.Background(), "hi")
+	require.NoError(t, err)
+	require.Equal(t, "reply 1 to 2 messages", resp.Choices[0].Message.Content.Text)
+	resp, err = thread.Send(context.Background(), "again")
+	require.NoError(t, err)
+	require.Equal(t, "reply 2 to 4 messages", resp.Choices[0].Message.Content.Text)
+
+	_, err = thread.Send(context.Background(), "fail")
+	require.Error(t, err)
+	require.Len(t, thread.Messages(), 5, "failed turns are not kept")
+}
+
+func TestSummaryMemory(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var summarized []string
+	client := newHandlerClient(func(r *http.Request) *http.Response {
+		var request ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.Model == "cheap" {
+			mu.Lock()
+			summarized = append(summarized, request.Messages[1].Content.Text)
+			n := len(summarized)
+			mu.Unlock()
+			return jsonResponse(http.StatusOK, fmt.Sprintf(
+				`{"choices":[{"message":{"role":"assistant","content":"summary %d"}}]}`, n))
+		}
+		return jsonResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	})
+
+	// Every message counts as one token.
+	memory := &SummaryMemory{
+		Client:     client,
+		Model:      "cheap",
+		MaxTokens:  6,
+		KeepRecent: 2,
+		Counter: TokenCounterFunc(func(messages []ChatCompletionMessage) int {
+			return len(messages)
+		}),
+	}
+	thread := client.NewThread(ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{SystemMessage("Be brief.")},
+	}, memory)
+
+	for i := 1; i <= 3; i++ {
+		_, err := thread.Send(context.Background(), fmt.Sprintf("question %d", i))
+		require.NoError(t, err)
+	}
+	require.Empty(t, summarized, "the history fits until the fourth turn")
+
+	_, err := thread.Send(context.Background(), "question 4")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"user: question 1\nassistant: ok\nuser: question 2\nassistant: ok\nuser: question 3\n",
+	}, summarized)
+	require.Equal(t, []ChatCompletionMessage{
+		SystemMessage("Be brief."),
+		SystemMessage(summaryPrefix + "summary 1"),
+		AssistantMessage("ok"),
+		UserMessage("question 4"),
+		AssistantMessage("ok"),
+	}, thread.Messages())
+
+	for i := 5; i <= 6; i++ {
+		_, err := thread.Send(context.Background(), fmt.Sprintf("question %d", i))
+		require.NoError(t, err)
+	}
+	require.Len(t, summarized, 2)
+	require.True(t, strings.HasPrefix(summarized[1], summaryPrefix+"summary 1\n"), "earlier summaries are summarized again")
+}
+
+func TestSummaryMemoryKeepsToolResultsWithTheirCall(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"summary"}}]}`)
+	})
+	memory := &SummaryMemory{Client: client, Model: "cheap", KeepRecent: 2}
+
+	call := ChatCompletionMessage{
+		Role:      ChatMessageRoleAssistant,
+		ToolCalls: []ToolCall{{ID: "1", Type: ToolTypeFunction, Function: FunctionCall{Name: "a"}}},
+	}
+	messages := []ChatCompletionMessage{
+		UserMessage("hi"), call, ToolMessage("1", "x"), ToolMessage("2", "y"), UserMessage("thanks"),
+	}
+	compacted, err := memory.Compact(context.Background(), messages)
+	require.NoError(t, err)
+	require.Equal(t, []ChatCompletionMessage{
+		SystemMessage(summaryPrefix + "summary"), call, ToolMessage("1", "x"), ToolMessage("2", "y"), UserMessage("thanks"),
+	}, compacted)
+}
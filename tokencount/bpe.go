@@ -0,0 +1,171 @@
+package tokencount
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GPTPattern splits text into the pieces that BPE merges are applied to. It
+// follows the pre-tokenizer of cl100k_base except for its \s+(?!\S)
+// alternative, as Go regular expressions have no lookahead; BPE emulates it
+// when splitting, see NewBPE.
+var GPTPattern = regexp.MustCompile(`'(?i:[sdmt]|ll|ve|re)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// O200KPattern is the pre-tokenizer of o200k_base, which also splits words
+// at case changes and keeps contractions with their word. Like GPTPattern it
+// lacks the lookahead on trailing whitespace, which BPE emulates.
+var O200KPattern = regexp.MustCompile(strings.Join([]string{
+	`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+	`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+	`\p{N}{1,3}`,
+	` ?[^\s\p{L}\p{N}]+[\r\n/]*`,
+	`\s*[\r\n]+`,
+	`\s+`,
+}, "|"))
+
+// BPE is a byte-level byte pair encoding tokenizer, the scheme used by the
+// OpenAI, Llama 3 and most other current vocabularies.
+type BPE struct {
+	ranks   map[string]int
+	pattern *regexp.Regexp
+}
+
+// NewBPE returns a tokenizer for the vocabulary ranks, which maps every token
+// to its rank, and thus ID. Lower ranks are merged first. Every single byte
+// must be in the vocabulary. A nil pattern defaults to GPTPattern.
+//
+// Like the tiktoken pre-tokenizers, a run of two or more spaces or tabs
+// followed by other text gives its last character back to the next piece, so
+// "a   b" is split into "a", "  " and " b".
+func NewBPE(ranks map[string]int, pattern *regexp.Regexp) (*BPE, error) {
+	for b := 0; b < 256; b++ {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("vocabulary has no token for byte %#x", b)
+		}
+	}
+	if pattern == nil {
+		pattern = GPTPattern
+	}
+	return &BPE{ranks: ranks, pattern: pattern}, nil
+}
+
+// LoadTiktoken reads a vocabulary in the tiktoken format, one base64 encoded
+// token and its rank per line, as published for cl100k_base and o200k_base.
+// The pattern must be the pre-tokenizer of the vocabulary: GPTPattern for
+// cl100k_base and O200KPattern for o200k_base.
+func LoadTiktoken(r io.Reader, pattern *regexp.Regexp) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("tiktoken line %d: want token and rank", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("tiktoken line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("tiktoken line %d: %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewBPE(ranks, pattern)
+}
+
+func (b *BPE) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range b.split(text) {
+		if rank, ok := b.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		tokens = append(tokens, b.merge(piece)...)
+	}
+	return tokens
+}
+
+func (b *BPE) Count(text string) int {
+	return len(b.Encode(text))
+}
+
+// split returns the pieces of text matched by the pattern, emulating the
+// \s+(?!\S) lookahead: a whitespace piece of several characters directly
+// followed by non-whitespace leaves its last character to the next match.
+func (b *BPE) split(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		loc := b.pattern.FindStringIndex(text)
+		if loc == nil {
+			break
+		}
+		start, end := loc[0], loc[1]
+		if end == start {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			text = text[end+size:]
+			continue
+		}
+		if end < len(text) && end-start > 1 && isBlank(text[start:end]) {
+			end--
+		}
+		pieces = append(pieces, text[start:end])
+		text = text[end:]
+	}
+	return pieces
+}
+
+// isBlank reports whether s consists only of the whitespace that \s matches
+// other than line breaks, which the pre-tokenizers keep in their own pieces.
+func isBlank(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\f':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// merge applies the BPE merges to piece, repeatedly joining the adjacent pair
+// of parts with the lowest rank, and returns the ranks of the final parts.
+func (b *BPE) merge(piece string) []int {
+	// bounds[i] is the offset where part i starts; the last bound is len(piece).
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := b.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+	}
+
+	tokens := make([]int, 0, len(bounds)-1)
+	for i := 0; i+1 < len(bounds); i++ {
+		tokens = append(tokens, b.ranks[piece[bounds[i]:bounds[i+1]]])
+	}
+	return tokens
+}
@@ -0,0 +1,202 @@
+// Package tokencount counts the tokens of chat messages offline, so context
+// windows can be managed and costs estimated before a request is sent.
+//
+// Counts are heuristic by default. The package bundles the BPE algorithm but
+// no vocabulary: cl100k_base and o200k_base are several megabytes each, more
+// than a client library should embed, so until a vocabulary is loaded every
+// model, including the OpenAI ones, is counted with the Heuristic tokenizer.
+// Its counts are estimates, often off by 10 to 20 percent, so callers trimming
+// to a context window or budgeting cost from them should keep a margin; Exact
+// reports which case applies.
+//
+// Exact counts require loading the vocabulary of the model's tokenizer, either
+// from the files of a directory with LoadDir or from any tiktoken rank file
+// with LoadTiktoken and Register. The OpenAI vocabularies are published at
+//
+//	https://openaipublic.blob.core.windows.net/encodings/cl100k_base.tiktoken
+//	https://openaipublic.blob.core.windows.net/encodings/o200k_base.tiktoken
+//
+// and need to be downloaded once.
+package tokencount
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	openrouter "github.com/revrost/go-openrouter"
+)
+
+// Tokenizer splits text into tokens.
+type Tokenizer interface {
+	// Encode returns the token IDs of text.
+	Encode(text string) []int
+	// Count returns the number of tokens of text, len(Encode(text)).
+	Count(text string) int
+}
+
+// Tokenizer families, as used by Register and Family.
+const (
+	FamilyCL100K = "cl100k_base"
+	FamilyO200K  = "o200k_base"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Tokenizer{}
+)
+
+// Register makes tokenizer the one used for models of family. It is safe to
+// call concurrently with counting.
+func Register(family string, tokenizer Tokenizer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[family] = tokenizer
+}
+
+// familyPatterns are the pre-tokenizers of the families LoadDir loads.
+var familyPatterns = []struct {
+	family  string
+	pattern *regexp.Regexp
+}{
+	{FamilyCL100K, GPTPattern},
+	{FamilyO200K, O200KPattern},
+}
+
+// LoadDir registers the tokenizer of every family whose vocabulary is found in
+// dir, as a tiktoken file named after the family, e.g. cl100k_base.tiktoken,
+// with the pre-tokenizer of the family. It returns the families loaded;
+// missing files are skipped, and models of those families keep being counted
+// by Heuristic.
+func LoadDir(dir string) ([]string, error) {
+	var loaded []string
+	for _, p := range familyPatterns {
+		family := p.family
+		f, err := os.Open(filepath.Join(dir, family+".tiktoken"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return loaded, err
+		}
+		bpe, err := LoadTiktoken(f, p.pattern)
+		f.Close()
+		if err != nil {
+			return loaded, fmt.Errorf("load %s: %w", family, err)
+		}
+		Register(family, bpe)
+		loaded = append(loaded, family)
+	}
+	return loaded, nil
+}
+
+// Exact reports whether the tokens of model are counted with the vocabulary
+// of its tokenizer rather than estimated by Heuristic.
+func Exact(model string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[Family(model)]
+	return ok
+}
+
+// Family returns the tokenizer family of an OpenRouter model ID, or "" when
+// it is not known.
+func Family(model string) string {
+	model = strings.ToLower(model)
+	name := model[strings.LastIndex(model, "/")+1:]
+
+	switch {
+	case strings.HasPrefix(name, "gpt-4o"), strings.HasPrefix(name, "gpt-4.1"), strings.HasPrefix(name, "gpt-5"),
+		strings.HasPrefix(name, "gpt-oss"), strings.HasPrefix(name, "o1"), strings.HasPrefix(name, "o3"),
+		strings.HasPrefix(name, "o4"):
+		return FamilyO200K
+	case strings.HasPrefix(name, "gpt-4"), strings.HasPrefix(name, "gpt-3.5"):
+		return FamilyCL100K
+	default:
+		return ""
+	}
+}
+
+// ForModel returns the tokenizer registered for the family of model, or a
+// Heuristic tokenizer when there is none. Nothing is registered by default,
+// so without a call to LoadDir or Register it always returns Heuristic.
+func ForModel(model string) Tokenizer {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if tokenizer, ok := registry[Family(model)]; ok {
+		return tokenizer
+	}
+	return Heuristic{}
+}
+
+// Overhead is the number of tokens a chat template adds around the content.
+type Overhead struct {
+	// PerMessage is added for every message, for its role and delimiters.
+	PerMessage int
+	// PerName is added for every message with a tool call ID.
+	PerName int
+	// PerReply primes the assistant reply and is added once.
+	PerReply int
+}
+
+// DefaultOverhead is the overhead of the ChatML template used by OpenAI models,
+// a reasonable approximation for other chat models.
+var DefaultOverhead = Overhead{PerMessage: 3, PerName: 1, PerReply: 3}
+
+// CountMessages returns the number of prompt tokens of messages for model,
+// including the per-message overhead of the chat template.
+func CountMessages(messages []openrouter.ChatCompletionMessage, model string) int {
+	return CountMessagesWith(ForModel(model), DefaultOverhead, messages)
+}
+
+// CountMessagesWith counts messages with tokenizer and overhead.
+func CountMessagesWith(tokenizer Tokenizer, overhead Overhead, messages []openrouter.ChatCompletionMessage) int {
+	tokens := overhead.PerReply
+	for _, message := range messages {
+		tokens += overhead.PerMessage + tokenizer.Count(message.Role)
+		tokens += tokenizer.Count(message.Content.Text)
+		for _, part := range message.Content.Multi {
+			tokens += tokenizer.Count(part.Text)
+		}
+		for _, call := range message.ToolCalls {
+			tokens += tokenizer.Count(call.Function.Name) + tokenizer.Count(call.Function.Arguments)
+		}
+		if message.ToolCallID != "" {
+			tokens += overhead.PerName + tokenizer.Count(message.ToolCallID)
+		}
+	}
+	return tokens
+}
+
+// Heuristic approximates tokens as CharsPerToken bytes each, 4 when zero,
+// which is close for English text with most BPE vocabularies. Encode returns
+// placeholder IDs.
+type Heuristic struct {
+	CharsPerToken int
+}
+
+func (h Heuristic) Encode(text string) []int {
+	return make([]int, h.Count(text))
+}
+
+func (h Heuristic) Count(text string) int {
+	chars := h.CharsPerToken
+	if chars <= 0 {
+		chars = 4
+	}
+	return (len(text) + chars - 1) / chars
+}
+
+// Counter returns an openrouter.TokenCounter counting messages for model, for
+// use with openrouter.Trimmer.
+func Counter(model string) openrouter.TokenCounter {
+	return openrouter.TokenCounterFunc(func(messages []openrouter.ChatCompletionMessage) int {
+		return CountMessages(messages, model)
+	})
+}
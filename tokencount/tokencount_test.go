@@ -0,0 +1,130 @@
+package tokencount_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	openrouter "github.com/revrost/go-openrouter"
+	"github.com/revrost/go-openrouter/tokencount"
+	"github.com/stretchr/testify/require"
+)
+
+// testVocabulary returns a tiktoken file with every byte plus a few merges.
+func testVocabulary() string {
+	var vocabulary strings.Builder
+	for b := 0; b < 256; b++ {
+		fmt.Fprintf(&vocabulary, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), b)
+	}
+	for rank, token := range []string{"he", "ll", "hell", " w", "or", " wor"} {
+		fmt.Fprintf(&vocabulary, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 256+rank)
+	}
+	return vocabulary.String()
+}
+
+func TestBPE(t *testing.T) {
+	t.Parallel()
+
+	bpe, err := tokencount.LoadTiktoken(strings.NewReader(testVocabulary()), nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []int{258, 'o'}, bpe.Encode("hello"))
+	require.Equal(t, []int{258, 'o', 261, 'l', 'd'}, bpe.Encode("hello world"))
+	// Bytes without merges, like the two of "é", are tokens of their own.
+	require.Equal(t, 3, bpe.Count("hé"))
+	require.Empty(t, bpe.Encode(""))
+}
+
+func TestNewBPERequiresEveryByte(t *testing.T) {
+	t.Parallel()
+
+	_, err := tokencount.NewBPE(map[string]int{"a": 0}, nil)
+	require.EqualError(t, err, "vocabulary has no token for byte 0x0")
+
+	_, err = tokencount.LoadTiktoken(strings.NewReader("YQ== zero\n"), nil)
+	require.ErrorContains(t, err, "tiktoken line 1")
+}
+
+func TestFamily(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, tokencount.FamilyO200K, tokencount.Family("openai/gpt-4o-mini"))
+	require.Equal(t, tokencount.FamilyO200K, tokencount.Family("openai/o3-mini"))
+	require.Equal(t, tokencount.FamilyCL100K, tokencount.Family("openai/gpt-4-turbo"))
+	require.Empty(t, tokencount.Family("anthropic/claude-sonnet-4"))
+}
+
+func TestCountMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := []openrouter.ChatCompletionMessage{
+		openrouter.SystemMessage("You are terse."), // 14 bytes
+		openrouter.UserMessage("hello"),
+		openrouter.ToolMessage("call_1", "{}"),
+	}
+
+	// Heuristic: 3 reply + 3*3 per message + roles (2+1+1) + content (4+2+1) + tool call ID (1+2).
+	require.Equal(t, 26, tokencount.CountMessages(messages, "mistralai/mistral-large"))
+
+	bpe, err := tokencount.LoadTiktoken(strings.NewReader(testVocabulary()), nil)
+	require.NoError(t, err)
+	counted := tokencount.CountMessagesWith(bpe, tokencount.Overhead{PerReply: 1}, messages[1:2])
+	require.Equal(t, 1+bpe.Count("user")+2, counted)
+}
+
+func TestCounter(t *testing.T) {
+	t.Parallel()
+
+	messages := []openrouter.ChatCompletionMessage{openrouter.UserMessage("hello")}
+	counter := tokencount.Counter("mistralai/mistral-large")
+	require.Equal(t, tokencount.CountMessages(messages, "mistralai/mistral-large"), counter.CountTokens(messages))
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cl100k_base.tiktoken"), []byte(testVocabulary()), 0o600))
+
+	require.False(t, tokencount.Exact("openai/gpt-3.5-turbo"))
+	loaded, err := tokencount.LoadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{tokencount.FamilyCL100K}, loaded)
+	require.True(t, tokencount.Exact("openai/gpt-3.5-turbo"))
+	require.False(t, tokencount.Exact("mistralai/mistral-large"))
+	require.Equal(t, 2, tokencount.ForModel("openai/gpt-3.5-turbo").Count("hello"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "o200k_base.tiktoken"), []byte("not a vocabulary\n"), 0o600))
+	_, err = tokencount.LoadDir(dir)
+	require.ErrorContains(t, err, "load o200k_base")
+}
+
+func TestO200KPattern(t *testing.T) {
+	t.Parallel()
+
+	text := "HelloWorld they'll pay 12345"
+	require.Equal(t, []string{"HelloWorld", " they", "'ll", " pay", " ", "123", "45"},
+		tokencount.GPTPattern.FindAllString(text, -1))
+	require.Equal(t, []string{"Hello", "World", " they'll", " pay", " ", "123", "45"},
+		tokencount.O200KPattern.FindAllString(text, -1))
+}
+
+func TestBPETrailingWhitespace(t *testing.T) {
+	t.Parallel()
+
+	vocabulary := testVocabulary()
+	for rank, token := range []string{"  ", " b"} {
+		vocabulary += fmt.Sprintf("%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 300+rank)
+	}
+	bpe, err := tokencount.LoadTiktoken(strings.NewReader(vocabulary), nil)
+	require.NoError(t, err)
+
+	// tiktoken splits "a   b" into "a", "  " and " b".
+	require.Equal(t, []int{'a', 300, 301}, bpe.Encode("a   b"))
+	// A single space and whitespace ending the text are kept whole.
+	require.Equal(t, []int{'1', ' ', '2'}, bpe.Encode("1 2"))
+	require.Equal(t, []int{'a', 300, ' '}, bpe.Encode("a   "))
+}
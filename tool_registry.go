@@ -0,0 +1,75 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownTool is returned by ToolRegistry.Call for a function that is not
+// registered.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// ToolHandler runs a call of a tool with the JSON arguments chosen by the
+// model, and returns the result sent back to it.
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// ToolRegistry holds the function tools offered to a model and the handlers
+// running their calls. It is safe for concurrent use.
+type ToolRegistry struct {
+	mu       sync.RWMutex
+	tools    []Tool
+	handlers map[string]ToolHandler
+}
+
+// NewToolRegistry returns an empty registry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{handlers: make(map[string]ToolHandler)}
+}
+
+// Register adds the function of definition, whose calls run handler. It fails
+// when a function of the same name is registered.
+func (r *ToolRegistry) Register(definition FunctionDefinition, handler ToolHandler) error {
+	if definition.Name == "" {
+		return errors.New("tool registry: function without a name")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool registry: nil handler for %q", definition.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[definition.Name]; ok {
+		return fmt.Errorf("tool registry: %q is already registered", definition.Name)
+	}
+	r.tools = append(r.tools, Tool{Type: ToolTypeFunction, Function: &definition})
+	r.handlers[definition.Name] = handler
+	return nil
+}
+
+// Tools returns the tools to set on ChatCompletionRequest.Tools, in the order
+// they were registered.
+func (r *ToolRegistry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Tool(nil), r.tools...)
+}
+
+// Call runs the handler of the function called by call. Arguments that are
+// empty are passed as an empty object.
+func (r *ToolRegistry) Call(ctx context.Context, call ToolCall) (string, error) {
+	r.mu.RLock()
+	handler, ok := r.handlers[call.Function.Name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownTool, call.Function.Name)
+	}
+
+	arguments := json.RawMessage(call.Function.Arguments)
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	return handler(ctx, arguments)
+}
@@ -0,0 +1,37 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewToolRegistry()
+	require.NoError(t, registry.Register(FunctionDefinition{Name: "echo"}, func(_ context.Context, arguments json.RawMessage) (string, error) {
+		return string(arguments), nil
+	}))
+	require.Error(t, registry.Register(FunctionDefinition{Name: "echo"}, func(context.Context, json.RawMessage) (string, error) {
+		return "", nil
+	}))
+	require.Error(t, registry.Register(FunctionDefinition{Name: "nil"}, nil))
+
+	tools := registry.Tools()
+	require.Len(t, tools, 1)
+	require.Equal(t, ToolTypeFunction, tools[0].Type)
+	require.Equal(t, "echo", tools[0].Function.Name)
+
+	result, err := registry.Call(context.Background(), ToolCall{Function: FunctionCall{Name: "echo", Arguments: `{"a":1}`}})
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, result)
+	result, err = registry.Call(context.Background(), ToolCall{Function: FunctionCall{Name: "echo"}})
+	require.NoError(t, err)
+	require.Equal(t, `{}`, result)
+
+	_, err = registry.Call(context.Background(), ToolCall{Function: FunctionCall{Name: "missing"}})
+	require.ErrorIs(t, err, ErrUnknownTool)
+}
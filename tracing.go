@@ -0,0 +1,312 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Tracer starts a span around every chat and text completion call of the
+// client, for bridging to OpenTelemetry or another tracing system. Spans carry
+// the attributes of the OpenTelemetry GenAI semantic conventions; see the Attr
+// constants. The span of a stream stays open until the stream ends or is
+// closed. Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span called name, as a child of the span in ctx if any,
+	// and returns a context carrying it. Requests of the call are sent with
+	// that context.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	AddEvent(name string, attributes ...Attribute)
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+	End()
+}
+
+// Attribute is an attribute of a span or span event. Value is a string, int,
+// float64, bool or []string.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// GenAISystem is the value of AttrGenAISystem.
+const GenAISystem = "openrouter"
+
+// Attributes set on the spans of the client. The gen_ai ones follow the
+// OpenTelemetry GenAI semantic conventions.
+//
+// https://opentelemetry.io/docs/specs/semconv/gen-ai/gen-ai-spans/
+const (
+	AttrGenAISystem                = "gen_ai.system"
+	AttrGenAIOperationName         = "gen_ai.operation.name"
+	AttrGenAIRequestModel          = "gen_ai.request.model"
+	AttrGenAIRequestMaxTokens      = "gen_ai.request.max_tokens"
+	AttrGenAIRequestTemperature    = "gen_ai.request.temperature"
+	AttrGenAIRequestTopP           = "gen_ai.request.top_p"
+	AttrGenAIResponseID            = "gen_ai.response.id"
+	AttrGenAIResponseModel         = "gen_ai.response.model"
+	AttrGenAIResponseFinishReasons = "gen_ai.response.finish_reasons"
+	AttrGenAIUsageInputTokens      = "gen_ai.usage.input_tokens"
+	AttrGenAIUsageOutputTokens     = "gen_ai.usage.output_tokens"
+	// AttrGenAIPrompt and AttrGenAICompletion hold the JSON encoded messages
+	// of EventGenAIPrompt and EventGenAICompletion.
+	AttrGenAIPrompt     = "gen_ai.prompt"
+	AttrGenAICompletion = "gen_ai.completion"
+	// AttrErrorType is the ErrorClass of the error of a failed call.
+	AttrErrorType = "error.type"
+
+	AttrOpenRouterProvider = "openrouter.provider"
+	AttrOpenRouterCost     = "openrouter.usage.cost"
+	AttrOpenRouterCacheHit = "openrouter.cache_hit"
+
+	// Attributes of streamed calls, whose spans end with the stream. Times
+	// are in seconds.
+	AttrOpenRouterStreamed     = "openrouter.streamed"
+	AttrStreamTimeToFirstToken = "openrouter.stream.time_to_first_token"
+	AttrStreamDuration         = "openrouter.stream.duration"
+	AttrStreamChunks           = "openrouter.stream.chunks"
+	AttrStreamChunkIndex       = "openrouter.stream.chunk.index"
+	AttrStreamChunkDelta       = "openrouter.stream.chunk.delta"
+	AttrStreamErrorMessage     = "openrouter.stream.error.message"
+	AttrStreamTokensPerSecond  = "openrouter.stream.tokens_per_second"
+)
+
+// Span events recording content, added when the client is created with
+// WithTracedContent.
+const (
+	EventGenAIPrompt     = "gen_ai.content.prompt"
+	EventGenAICompletion = "gen_ai.content.completion"
+)
+
+// Span events of streamed calls. EventStreamChunk is added for every chunk,
+// with its index and, when content is traced, the text it added.
+// EventStreamError is added when a chunk reports that the generation failed
+// mid-stream, with the ErrorClass and message of the error.
+const (
+	EventStreamFirstToken = "openrouter.stream.first_token"
+	EventStreamChunk      = "openrouter.stream.chunk"
+	EventStreamError      = "openrouter.stream.error"
+)
+
+// Values of AttrGenAIOperationName.
+const (
+	GenAIOperationChat           = "chat"
+	GenAIOperationTextCompletion = "text_completion"
+)
+
+// genAISpan is a span of a call, following the GenAI semantic conventions.
+// Its methods do nothing on a nil span, so calls are traced unconditionally.
+type genAISpan struct {
+	span     Span
+	content  bool
+	redactor Redactor
+
+	// chunks counts the chunks of a stream and firstToken reports whether
+	// one carried output. streamErr is the first error reported by a chunk.
+	chunks     int
+	firstToken bool
+	streamErr  error
+}
+
+// startSpan starts the span of an operation on model, if the client has a
+// Tracer, and returns the context to send the requests of the call with.
+func (c *Client) startSpan(ctx context.Context, operation, model string) (context.Context, *genAISpan) {
+	tracer := c.config.Tracer
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	ctx, span := tracer.Start(ctx, operation+" "+model)
+	span.SetAttributes(
+		Attribute{AttrGenAISystem, GenAISystem},
+		Attribute{AttrGenAIOperationName, operation},
+		Attribute{AttrGenAIRequestModel, model},
+	)
+	return ctx, &genAISpan{span: span, content: c.config.TraceContent, redactor: c.redactor()}
+}
+
+// startChatSpan starts the span of a chat completion with request.
+func (c *Client) startChatSpan(ctx context.Context, request ChatCompletionRequest) (context.Context, *genAISpan) {
+	ctx, span := c.startSpan(ctx, GenAIOperationChat, request.Model)
+	maxTokens := request.MaxTokens
+	if request.MaxCompletionTokens > 0 {
+		maxTokens = request.MaxCompletionTokens
+	}
+	span.request(maxTokens, request.Temperature, request.TopP, request.Messages)
+	return ctx, span
+}
+
+// startCompletionSpan starts the span of a text completion with request.
+func (c *Client) startCompletionSpan(ctx context.Context, request CompletionRequest) (context.Context, *genAISpan) {
+	ctx, span := c.startSpan(ctx, GenAIOperationTextCompletion, request.Model)
+	span.request(request.MaxTokens, request.Temperature, request.TopP, request.Prompt)
+	return ctx, span
+}
+
+// request sets the sampling parameters of a request, and records its prompt
+// when content is traced.
+func (s *genAISpan) request(maxTokens int, temperature, topP float32, prompt any) {
+	if s == nil {
+		return
+	}
+
+	var attributes []Attribute
+	if maxTokens > 0 {
+		attributes = append(attributes, Attribute{AttrGenAIRequestMaxTokens, maxTokens})
+	}
+	if temperature != 0 {
+		attributes = append(attributes, Attribute{AttrGenAIRequestTemperature, float64(temperature)})
+	}
+	if topP != 0 {
+		attributes = append(attributes, Attribute{AttrGenAIRequestTopP, float64(topP)})
+	}
+	if len(attributes) > 0 {
+		s.span.SetAttributes(attributes...)
+	}
+	s.event(EventGenAIPrompt, AttrGenAIPrompt, prompt)
+}
+
+// event adds an event recording content as the JSON encoded attribute key,
+// when content is traced.
+func (s *genAISpan) event(name, key string, content any) {
+	if !s.content {
+		return
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return
+	}
+	s.span.AddEvent(name, Attribute{key, s.redactor.Redact(string(data))})
+}
+
+// end sets the attributes of the response and ends the span. completion is
+// recorded when content is traced.
+func (s *genAISpan) end(event UsageEvent, usage *Usage, finishReasons []string, completion any, err error) {
+	if s == nil {
+		return
+	}
+	defer s.span.End()
+
+	var attributes []Attribute
+	if event.RequestID != "" {
+		attributes = append(attributes, Attribute{AttrGenAIResponseID, event.RequestID})
+	}
+	if event.Model != "" {
+		attributes = append(attributes, Attribute{AttrGenAIResponseModel, event.Model})
+	}
+	if event.Provider != "" {
+		attributes = append(attributes, Attribute{AttrOpenRouterProvider, event.Provider})
+	}
+	if len(finishReasons) > 0 {
+		attributes = append(attributes, Attribute{AttrGenAIResponseFinishReasons, finishReasons})
+	}
+	if usage != nil {
+		attributes = append(attributes,
+			Attribute{AttrGenAIUsageInputTokens, usage.PromptTokens},
+			Attribute{AttrGenAIUsageOutputTokens, usage.CompletionTokens},
+			Attribute{AttrOpenRouterCost, usage.Cost},
+		)
+	}
+	if event.CacheHit {
+		attributes = append(attributes, Attribute{AttrOpenRouterCacheHit, true})
+	}
+	if err == nil {
+		err = s.streamErr
+	}
+	if err != nil {
+		attributes = append(attributes, Attribute{AttrErrorType, ErrorClass(err)})
+		s.span.RecordError(err)
+	} else if completion != nil {
+		s.event(EventGenAICompletion, AttrGenAICompletion, completion)
+	}
+	if len(attributes) > 0 {
+		s.span.SetAttributes(attributes...)
+	}
+}
+
+// chunk adds the event of a chunk of a stream, carrying output when hasToken
+// is set, and delta, the text it added, when content is traced. err is the
+// error the chunk reports, if any.
+func (s *genAISpan) chunk(hasToken bool, delta string, err error) {
+	if s == nil {
+		return
+	}
+
+	if hasToken && !s.firstToken {
+		s.firstToken = true
+		s.span.AddEvent(EventStreamFirstToken)
+	}
+	attributes := []Attribute{{AttrStreamChunkIndex, s.chunks}}
+	if s.content && delta != "" {
+		attributes = append(attributes, Attribute{AttrStreamChunkDelta, s.redactor.Redact(delta)})
+	}
+	s.span.AddEvent(EventStreamChunk, attributes...)
+	s.chunks++
+
+	if err != nil {
+		s.span.AddEvent(EventStreamError,
+			Attribute{AttrErrorType, ErrorClass(err)},
+			Attribute{AttrStreamErrorMessage, s.redactor.Redact(err.Error())},
+		)
+		if s.streamErr == nil {
+			s.streamErr = err
+		}
+	}
+}
+
+// endStream sets the timing of a stream from stats and ends the span as end
+// does. An error reported by a chunk fails the span even when the stream
+// ended cleanly.
+func (s *genAISpan) endStream(stats StreamStats, event UsageEvent, usage *Usage, finishReasons []string, completion any, err error) {
+	if s == nil {
+		return
+	}
+
+	attributes := []Attribute{
+		{AttrOpenRouterStreamed, true},
+		{AttrStreamChunks, stats.Chunks},
+		{AttrStreamDuration, stats.Duration.Seconds()},
+	}
+	if stats.TimeToFirstToken > 0 {
+		attributes = append(attributes, Attribute{AttrStreamTimeToFirstToken, stats.TimeToFirstToken.Seconds()})
+	}
+	if stats.TokensPerSecond > 0 {
+		attributes = append(attributes, Attribute{AttrStreamTokensPerSecond, stats.TokensPerSecond})
+	}
+	s.span.SetAttributes(attributes...)
+	s.end(event, usage, finishReasons, completion, err)
+}
+
+// chatSpanOutput returns the finish reasons and messages of choices, or nil
+// messages when there are no choices.
+func chatSpanOutput(choices []ChatCompletionChoice) ([]string, any) {
+	if len(choices) == 0 {
+		return nil, nil
+	}
+	finishReasons := make([]string, len(choices))
+	messages := make([]ChatCompletionMessage, len(choices))
+	for i, choice := range choices {
+		finishReasons[i] = string(choice.FinishReason)
+		messages[i] = choice.Message
+	}
+	return finishReasons, messages
+}
+
+// completionSpanOutput returns the finish reasons and texts of choices, or nil
+// texts when there are no choices.
+func completionSpanOutput(choices []CompletionChoice) ([]string, any) {
+	if len(choices) == 0 {
+		return nil, nil
+	}
+	finishReasons := make([]string, len(choices))
+	texts := make([]string, len(choices))
+	for i, choice := range choices {
+		finishReasons[i] = string(choice.FinishReason)
+		texts[i] = choice.Text
+	}
+	return finishReasons, texts
+}
@@ -0,0 +1,269 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordedSpan{name: name, attributes: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type recordedEvent struct {
+	name       string
+	attributes map[string]any
+}
+
+type recordedSpan struct {
+	mu         sync.Mutex
+	name       string
+	attributes map[string]any
+	events     []recordedEvent
+	err        error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttributes(attributes ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attribute := range attributes {
+		s.attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *recordedSpan) AddEvent(name string, attributes ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := recordedEvent{name: name, attributes: map[string]any{}}
+	for _, attribute := range attributes {
+		event.attributes[attribute.Key] = attribute.Value
+	}
+	s.events = append(s.events, event)
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+}
+
+func TestTracerRecordsGenAIAttributes(t *testing.T) {
+	t.Parallel()
+
+	var spanned bool
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		spanned = req.Context().Value(spanKey{}) != nil
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","model":"openai/gpt-4o-2024-08-06","provider":"OpenAI",`+
+			`"choices":[{"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7,"cost":0.001}}`)
+	})
+	tracer := &recordingTracer{}
+	client.config.Tracer = tracer
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:       "openai/gpt-4o",
+		Messages:    []ChatCompletionMessage{UserMessage("Hello")},
+		MaxTokens:   100,
+		Temperature: 0.5,
+	})
+	require.NoError(t, err)
+	require.True(t, spanned)
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	require.Equal(t, "chat openai/gpt-4o", span.name)
+	require.True(t, span.ended)
+	require.Empty(t, span.events)
+	require.Equal(t, map[string]any{
+		AttrGenAISystem:                GenAISystem,
+		AttrGenAIOperationName:         GenAIOperationChat,
+		AttrGenAIRequestModel:          "openai/gpt-4o",
+		AttrGenAIRequestMaxTokens:      100,
+		AttrGenAIRequestTemperature:    0.5,
+		AttrGenAIResponseID:            "gen-1",
+		AttrGenAIResponseModel:         "openai/gpt-4o-2024-08-06",
+		AttrGenAIResponseFinishReasons: []string{"stop"},
+		AttrGenAIUsageInputTokens:      5,
+		AttrGenAIUsageOutputTokens:     2,
+		AttrOpenRouterProvider:         "OpenAI",
+		AttrOpenRouterCost:             0.001,
+	}, span.attributes)
+}
+
+func TestTracedContentIsRedacted(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusOK, `{"id":"gen-1","model":"openai/gpt-3.5-turbo-instruct",`+
+			`"choices":[{"text":"Write to a@b.io","finish_reason":"stop"}]}`)
+	})
+	tracer := &recordingTracer{}
+	client.config.Tracer = tracer
+	client.config.TraceContent = true
+
+	_, err := client.CreateCompletion(context.Background(), CompletionRequest{
+		Model:  "openai/gpt-3.5-turbo-instruct",
+		Prompt: TextPrompt("Email c@d.io"),
+	})
+	require.NoError(t, err)
+
+	span := tracer.spans[0]
+	require.Equal(t, GenAIOperationTextCompletion, span.attributes[AttrGenAIOperationName])
+	require.Equal(t, []recordedEvent{
+		{name: EventGenAIPrompt, attributes: map[string]any{AttrGenAIPrompt: `"Email [REDACTED_EMAIL]"`}},
+		{name: EventGenAICompletion, attributes: map[string]any{AttrGenAICompletion: `["Write to [REDACTED_EMAIL]"]`}},
+	}, span.events)
+}
+
+func TestTracerRecordsErrors(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"rate limited"}}`)
+	})
+	tracer := &recordingTracer{}
+	client.config.Tracer = tracer
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Hello")},
+	})
+	require.Error(t, err)
+
+	span := tracer.spans[0]
+	require.True(t, span.ended)
+	require.True(t, errors.Is(span.err, err))
+	require.Equal(t, ErrorClassRateLimited, span.attributes[AttrErrorType])
+}
+
+func TestTracerSpansStreams(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`: OPENROUTER PROCESSING`,
+		`data: {"id":"gen-2","model":"openai/gpt-4o","provider":"Azure","choices":[{"delta":{"content":"Hel"}}]}`,
+		`data: {"id":"gen-2","choices":[{"delta":{"content":"lo"}}]}`,
+		`data: {"id":"gen-2","error":{"code":502,"message":"provider disconnected"},"choices":[{"delta":{},"finish_reason":"error"}]}`,
+		`data: [DONE]`,
+	)))
+	tracer := &recordingTracer{}
+	client.config.Tracer = tracer
+	client.config.TraceContent = true
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Hi")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	span.mu.Lock()
+	require.False(t, span.ended)
+	span.mu.Unlock()
+
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	require.ErrorIs(t, err, io.EOF)
+	require.Eventually(t, func() bool {
+		span.mu.Lock()
+		defer span.mu.Unlock()
+		return span.ended
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t, true, span.attributes[AttrOpenRouterStreamed])
+	require.Equal(t, 3, span.attributes[AttrStreamChunks])
+	require.Positive(t, span.attributes[AttrStreamTimeToFirstToken])
+	require.Equal(t, []string{"error"}, span.attributes[AttrGenAIResponseFinishReasons])
+	require.Equal(t, ErrorClassProviderUnavailable, span.attributes[AttrErrorType])
+	var generationErr *GenerationError
+	require.ErrorAs(t, span.err, &generationErr)
+
+	var names []string
+	for _, event := range span.events {
+		names = append(names, event.name)
+	}
+	require.Equal(t, []string{
+		EventGenAIPrompt,
+		EventStreamFirstToken,
+		EventStreamChunk,
+		EventStreamChunk,
+		EventStreamChunk,
+		EventStreamError,
+	}, names)
+	require.Equal(t, map[string]any{AttrStreamChunkIndex: 1, AttrStreamChunkDelta: "lo"}, span.events[3].attributes)
+	require.Contains(t, span.events[5].attributes[AttrStreamErrorMessage], "provider disconnected")
+}
+
+func TestTracerRecordsCompletionStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"gen-3","model":"openai/gpt-3.5-turbo-instruct","choices":[{"text":"Hel"}]}`,
+		`data: {"id":"gen-3","choices":[{"text":"","finish_reason":"error","error":{"code":502,"message":"provider disconnected"}}]}`,
+		`data: [DONE]`,
+	)))
+	tracer := &recordingTracer{}
+	client.config.Tracer = tracer
+
+	stream, err := client.CreateCompletionStream(context.Background(), CompletionRequest{
+		Model:  "openai/gpt-3.5-turbo-instruct",
+		Prompt: TextPrompt("Hi"),
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	require.ErrorIs(t, err, io.EOF)
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	require.Eventually(t, func() bool {
+		span.mu.Lock()
+		defer span.mu.Unlock()
+		return span.ended
+	}, time.Second, time.Millisecond)
+
+	var names []string
+	for _, event := range span.events {
+		names = append(names, event.name)
+	}
+	require.Equal(t, []string{
+		EventStreamFirstToken,
+		EventStreamChunk,
+		EventStreamChunk,
+		EventStreamError,
+	}, names)
+	require.Equal(t, ErrorClassProviderUnavailable, span.events[3].attributes[AttrErrorType])
+	require.Contains(t, span.events[3].attributes[AttrStreamErrorMessage], "provider disconnected")
+}
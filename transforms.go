@@ -0,0 +1,71 @@
+package openrouter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Transform is the name of a message transform OpenRouter applies to the
+// prompt before sending it to the model. The Transforms fields of requests are
+// plain strings; WithTransforms accepts the typed constants.
+// https://openrouter.ai/docs/features/message-transforms
+type Transform string
+
+// TransformMiddleOut compresses prompts that exceed the model's context window
+// by removing or truncating messages from the middle of the prompt.
+const TransformMiddleOut Transform = "middle-out"
+
+// knownTransforms are the transforms accepted in strict mode.
+var knownTransforms = []Transform{TransformMiddleOut}
+
+// ErrUnknownTransform is returned in strict mode for requests naming a
+// transform OpenRouter does not document.
+var ErrUnknownTransform = errors.New("unknown transform")
+
+// WithTransforms adds transforms to the request, skipping those it already
+// applies.
+func (r *ChatCompletionRequest) WithTransforms(transforms ...Transform) *ChatCompletionRequest {
+	r.Transforms = addTransforms(r.Transforms, transforms)
+	return r
+}
+
+// WithTransforms adds transforms to the request, skipping those it already
+// applies.
+func (r *CompletionRequest) WithTransforms(transforms ...Transform) *CompletionRequest {
+	r.Transforms = addTransforms(r.Transforms, transforms)
+	return r
+}
+
+func addTransforms(transforms []string, added []Transform) []string {
+	for _, transform := range added {
+		if !slices.Contains(transforms, string(transform)) {
+			transforms = append(transforms, string(transform))
+		}
+	}
+	return transforms
+}
+
+// checkTransforms returns an error wrapping ErrUnknownTransform in strict mode
+// when transforms names one that is not known.
+func (c *Client) checkTransforms(transforms []string) error {
+	if !c.config.StrictMode {
+		return nil
+	}
+	for _, transform := range transforms {
+		if !slices.Contains(knownTransforms, Transform(transform)) {
+			return fmt.Errorf("%w: %q", ErrUnknownTransform, transform)
+		}
+	}
+	return nil
+}
+
+// middleOutRetry reports whether a request that failed with err should be
+// retried with the middle-out transform, and returns the transforms to use.
+func (c *Client) middleOutRetry(err error, transforms []string) ([]string, bool) {
+	if err == nil || !c.config.MiddleOutOnContextOverflow || !IsContextLengthExceeded(err) ||
+		slices.Contains(transforms, string(TransformMiddleOut)) {
+		return nil, false
+	}
+	return append(slices.Clone(transforms), string(TransformMiddleOut)), true
+}
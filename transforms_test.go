@@ -0,0 +1,115 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const contextLengthErrorBody = `{"error":{"code":400,"message":"This endpoint's maximum context length is 8192 tokens. However, you requested about 9000 tokens."}}`
+
+func TestMiddleOutFallbackRetriesOnce(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusBadRequest, contextLengthErrorBody),
+		jsonResponse(http.StatusOK, `{"id":"1","choices":[{"message":{"role":"assistant","content":"ok"}}]}`),
+	)
+	client.config.MiddleOutOnContextOverflow = true
+
+	resp, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages:   []ChatCompletionMessage{UserMessage("hello")},
+		Transforms: []string{"custom"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp.Choices[0].Message.Content.Text)
+	require.Len(t, httpClient.requests, 2)
+	require.Equal(t, []string{"custom"}, httpClient.requests[0].Transforms)
+	require.Equal(t, []string{"custom", "middle-out"}, httpClient.requests[1].Transforms)
+}
+
+func TestMiddleOutFallbackGivesUpAfterRetry(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusBadRequest, contextLengthErrorBody),
+		jsonResponse(http.StatusBadRequest, contextLengthErrorBody),
+	)
+	client.config.MiddleOutOnContextOverflow = true
+
+	_, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.True(t, IsContextLengthExceeded(err))
+	require.Len(t, httpClient.requests, 2)
+	require.Equal(t, []string{"middle-out"}, httpClient.requests[1].Transforms)
+}
+
+func TestMiddleOutFallbackRetriesCompletionStream(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusBadRequest, contextLengthErrorBody),
+		jsonResponse(http.StatusOK, sseBody(
+			`data: {"id":"1","choices":[{"text":"ok","finish_reason":"stop"}]}`,
+			`data: [DONE]`,
+		)),
+	)
+	client.config.MiddleOutOnContextOverflow = true
+
+	stream, err := client.CreateCompletionStream(context.Background(), CompletionRequest{Prompt: TextPrompt("hello")})
+	require.NoError(t, err)
+	defer stream.Close()
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "ok", chunk.Choices[0].Text)
+	require.Len(t, httpClient.requests, 2)
+	require.Equal(t, []string{"middle-out"}, httpClient.requests[1].Transforms)
+}
+
+func TestMiddleOutFallbackIsOptIn(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t, jsonResponse(http.StatusBadRequest, contextLengthErrorBody))
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages: []ChatCompletionMessage{UserMessage("hello")},
+	})
+	require.True(t, IsContextLengthExceeded(err))
+	require.Len(t, httpClient.requests, 1)
+}
+
+func TestWithTransforms(t *testing.T) {
+	t.Parallel()
+
+	request := ChatCompletionRequest{Transforms: []string{"middle-out"}}
+	request.WithTransforms(TransformMiddleOut, "custom")
+	require.Equal(t, []string{"middle-out", "custom"}, request.Transforms)
+}
+
+func TestStrictModeRejectsUnknownTransforms(t *testing.T) {
+	t.Parallel()
+
+	client, httpClient := newSequenceClient(t,
+		jsonResponse(http.StatusOK, `{"id":"1","choices":[{"message":{"role":"assistant","content":"ok"}}]}`),
+	)
+	client.config.StrictMode = true
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Messages:   []ChatCompletionMessage{UserMessage("hello")},
+		Transforms: []string{"midle-out"},
+	})
+	require.ErrorIs(t, err, ErrUnknownTransform)
+	_, err = client.CreateCompletion(context.Background(), CompletionRequest{
+		Prompt:     TextPrompt("hello"),
+		Transforms: []string{"midle-out"},
+	})
+	require.ErrorIs(t, err, ErrUnknownTransform)
+	require.Empty(t, httpClient.requests)
+
+	request := ChatCompletionRequest{Messages: []ChatCompletionMessage{UserMessage("hello")}}
+	_, err = client.CreateChatCompletion(context.Background(), *request.WithTransforms(TransformMiddleOut))
+	require.NoError(t, err)
+}
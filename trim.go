@@ -0,0 +1,192 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrContextWindowTooSmall is returned by Trimmer.Trim when the messages that
+// cannot be trimmed, the system messages and the last message, do not fit.
+var ErrContextWindowTooSmall = errors.New("context window too small for the untrimmable messages")
+
+// TokenCounter counts the prompt tokens of messages. The tokencount package
+// provides exact counters for known tokenizers.
+type TokenCounter interface {
+	CountTokens(messages []ChatCompletionMessage) int
+}
+
+// TokenCounterFunc adapts a function to the TokenCounter interface.
+type TokenCounterFunc func(messages []ChatCompletionMessage) int
+
+func (f TokenCounterFunc) CountTokens(messages []ChatCompletionMessage) int {
+	return f(messages)
+}
+
+// HeuristicTokenCounter approximates token counts from the length of the
+// messages, about four characters per token.
+var HeuristicTokenCounter TokenCounter = TokenCounterFunc(estimateMessageTokens)
+
+// TrimStrategy selects how a Trimmer makes room.
+type TrimStrategy int
+
+const (
+	// TrimDrop drops the oldest messages.
+	TrimDrop TrimStrategy = iota
+	// TrimTruncateMiddle cuts the middle out of the oldest messages' text,
+	// dropping those that cannot be shortened enough.
+	TrimTruncateMiddle
+	// TrimSummarize drops the oldest messages and replaces them with the
+	// message returned by Trimmer.Summarize.
+	TrimSummarize
+)
+
+// truncationMarker replaces the text cut out by TrimTruncateMiddle.
+const truncationMarker = "\n[…]\n"
+
+// minTruncatedLength is the shortest text TrimTruncateMiddle keeps; shorter
+// messages are dropped instead.
+const minTruncatedLength = 64
+
+// Trimmer fits conversations into a model's context window by trimming their
+// oldest messages. System messages and the last message are never trimmed, and
+// tool results are dropped together with the assistant message that called
+// the tools.
+type Trimmer struct {
+	// ContextLength is the model's context window in tokens, see
+	// Model.ContextLength.
+	ContextLength int
+	// ReserveTokens are kept free for the completion, usually MaxTokens.
+	ReserveTokens int
+	// Counter counts tokens. Defaults to HeuristicTokenCounter.
+	Counter  TokenCounter
+	Strategy TrimStrategy
+	// Summarize is called by TrimSummarize with the dropped messages and
+	// returns the message that replaces them, e.g. a system message holding a
+	// summary written by a cheaper model.
+	Summarize func(ctx context.Context, dropped []ChatCompletionMessage) (ChatCompletionMessage, error)
+}
+
+// Trim returns messages trimmed to fit the context window. messages is not
+// modified.
+func (t Trimmer) Trim(ctx context.Context, messages []ChatCompletionMessage) ([]ChatCompletionMessage, error) {
+	counter := t.Counter
+	if counter == nil {
+		counter = HeuristicTokenCounter
+	}
+	budget := t.ContextLength - t.ReserveTokens
+	fits := func(messages []ChatCompletionMessage) bool {
+		return counter.CountTokens(messages) <= budget
+	}
+
+	trimmed := append([]ChatCompletionMessage(nil), messages...)
+	if fits(trimmed) {
+		return trimmed, nil
+	}
+
+	var dropped []ChatCompletionMessage
+	for !fits(trimmed) {
+		i := oldestTrimmable(trimmed, 0)
+		if i < 0 {
+			return nil, ErrContextWindowTooSmall
+		}
+		if t.Strategy == TrimTruncateMiddle && t.truncate(&trimmed[i], trimmed, counter, budget) {
+			break
+		}
+		var removed []ChatCompletionMessage
+		trimmed, removed = dropTurn(trimmed, i)
+		dropped = append(dropped, removed...)
+	}
+
+	if t.Strategy != TrimSummarize || len(dropped) == 0 {
+		return trimmed, nil
+	}
+	if t.Summarize == nil {
+		return nil, errors.New("trim: TrimSummarize requires a Summarize function")
+	}
+
+	summary, err := t.Summarize(ctx, dropped)
+	if err != nil {
+		return nil, fmt.Errorf("trim: summarize: %w", err)
+	}
+	at := leadingSystemMessages(trimmed)
+	trimmed = append(trimmed[:at], append([]ChatCompletionMessage{summary}, trimmed[at:]...)...)
+	// Make room for the summary itself, keeping it.
+	for !fits(trimmed) {
+		i := oldestTrimmable(trimmed, at+1)
+		if i < 0 {
+			return nil, ErrContextWindowTooSmall
+		}
+		trimmed, _ = dropTurn(trimmed, i)
+	}
+	return trimmed, nil
+}
+
+// dropTurn removes messages[i] together with the tool results that follow it,
+// and returns the remaining and the removed messages.
+func dropTurn(messages []ChatCompletionMessage, i int) ([]ChatCompletionMessage, []ChatCompletionMessage) {
+	n := 1
+	for i+n < len(messages)-1 && messages[i+n].Role == ChatMessageRoleTool {
+		n++
+	}
+	removed := append([]ChatCompletionMessage(nil), messages[i:i+n]...)
+	return append(messages[:i], messages[i+n:]...), removed
+}
+
+// truncate shortens the text of message, an element of messages, by cutting
+// out its middle until messages fit in budget. It reports false, leaving the
+// message untouched, when that would leave less than minTruncatedLength.
+func (t Trimmer) truncate(message *ChatCompletionMessage, messages []ChatCompletionMessage, counter TokenCounter, budget int) bool {
+	original := []rune(message.Content.Text)
+	if len(message.Content.Multi) > 0 || len(original) <= minTruncatedLength {
+		return false
+	}
+
+	// Binary search the longest kept length that fits.
+	lo, hi := minTruncatedLength, len(original)-1
+	best := -1
+	for lo <= hi {
+		keep := (lo + hi) / 2
+		message.Content.Text = truncateMiddle(original, keep)
+		if counter.CountTokens(messages) <= budget {
+			best, lo = keep, keep+1
+		} else {
+			hi = keep - 1
+		}
+	}
+
+	if best < 0 {
+		message.Content.Text = string(original)
+		return false
+	}
+	message.Content.Text = truncateMiddle(original, best)
+	return true
+}
+
+// truncateMiddle keeps the first and last keep/2 runes of text.
+func truncateMiddle(text []rune, keep int) string {
+	head := keep / 2
+	tail := keep - head
+	return string(text[:head]) + truncationMarker + string(text[len(text)-tail:])
+}
+
+// oldestTrimmable returns the index of the oldest message from index from on
+// that may be trimmed, or -1 when only system messages and the last message
+// are left.
+func oldestTrimmable(messages []ChatCompletionMessage, from int) int {
+	for i := from; i < len(messages)-1; i++ {
+		if messages[i].Role != ChatMessageRoleSystem {
+			return i
+		}
+	}
+	return -1
+}
+
+func leadingSystemMessages(messages []ChatCompletionMessage) int {
+	for i, message := range messages {
+		if message.Role != ChatMessageRoleSystem {
+			return i
+		}
+	}
+	return len(messages)
+}
@@ -0,0 +1,108 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runeCounter counts one token per rune of text, so budgets are easy to reason about.
+var runeCounter = TokenCounterFunc(func(messages []ChatCompletionMessage) int {
+	var tokens int
+	for _, message := range messages {
+		tokens += utf8.RuneCountInString(message.Content.Text)
+	}
+	return tokens
+})
+
+func trimTestConversation() []ChatCompletionMessage {
+	return []ChatCompletionMessage{
+		SystemMessage(strings.Repeat("s", 10)),
+		UserMessage(strings.Repeat("a", 100)),
+		{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Type: ToolTypeFunction}}},
+		ToolMessage("call_1", strings.Repeat("t", 50)),
+		AssistantMessage(strings.Repeat("b", 100)),
+		UserMessage(strings.Repeat("c", 20)),
+	}
+}
+
+func TestTrimmerKeepsConversationsThatFit(t *testing.T) {
+	t.Parallel()
+
+	messages := trimTestConversation()
+	trimmed, err := Trimmer{ContextLength: 1000, Counter: runeCounter}.Trim(context.Background(), messages)
+	require.NoError(t, err)
+	require.Equal(t, messages, trimmed)
+}
+
+func TestTrimmerDrop(t *testing.T) {
+	t.Parallel()
+
+	messages := trimTestConversation()
+	trimmed, err := Trimmer{ContextLength: 150, ReserveTokens: 10, Counter: runeCounter}.Trim(context.Background(), messages)
+	require.NoError(t, err)
+
+	// The user message goes first, then the tool call goes with its result.
+	require.Equal(t, []ChatCompletionMessage{messages[0], messages[4], messages[5]}, trimmed)
+	require.Len(t, messages, 6, "input must not be modified")
+}
+
+func TestTrimmerTruncateMiddle(t *testing.T) {
+	t.Parallel()
+
+	messages := trimTestConversation()
+	trimmed, err := Trimmer{ContextLength: 250, Counter: runeCounter, Strategy: TrimTruncateMiddle}.Trim(context.Background(), messages)
+	require.NoError(t, err)
+
+	require.Len(t, trimmed, 6)
+	require.LessOrEqual(t, runeCounter(trimmed), 250)
+	require.True(t, strings.HasPrefix(trimmed[1].Content.Text, "aaa"))
+	require.Contains(t, trimmed[1].Content.Text, truncationMarker)
+	require.True(t, strings.HasSuffix(trimmed[1].Content.Text, "aaa"))
+	require.Equal(t, messages[1].Content.Text, strings.Repeat("a", 100))
+}
+
+func TestTrimmerSummarize(t *testing.T) {
+	t.Parallel()
+
+	var summarized []ChatCompletionMessage
+	trimmer := Trimmer{
+		ContextLength: 150,
+		Counter:       runeCounter,
+		Strategy:      TrimSummarize,
+		Summarize: func(_ context.Context, dropped []ChatCompletionMessage) (ChatCompletionMessage, error) {
+			summarized = dropped
+			return SystemMessage("summary"), nil
+		},
+	}
+
+	messages := trimTestConversation()
+	trimmed, err := trimmer.Trim(context.Background(), messages)
+	require.NoError(t, err)
+	require.Equal(t, messages[1:4], summarized)
+	require.Equal(t, []ChatCompletionMessage{messages[0], SystemMessage("summary"), messages[4], messages[5]}, trimmed)
+
+	trimmer.Summarize = func(context.Context, []ChatCompletionMessage) (ChatCompletionMessage, error) {
+		return ChatCompletionMessage{}, errors.New("boom")
+	}
+	_, err = trimmer.Trim(context.Background(), messages)
+	require.EqualError(t, err, "trim: summarize: boom")
+}
+
+func TestTrimmerContextWindowTooSmall(t *testing.T) {
+	t.Parallel()
+
+	_, err := Trimmer{ContextLength: 25, Counter: runeCounter}.Trim(context.Background(), trimTestConversation())
+	require.ErrorIs(t, err, ErrContextWindowTooSmall)
+}
+
+func TestHeuristicTokenCounter(t *testing.T) {
+	t.Parallel()
+
+	// 3 conversation + 4 framing + 1 role + 2 content.
+	require.Equal(t, 10, HeuristicTokenCounter.CountTokens([]ChatCompletionMessage{UserMessage("hello")}))
+}
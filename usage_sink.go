@@ -0,0 +1,111 @@
+package openrouter
+
+import (
+	"context"
+	"time"
+)
+
+// UsageEvent describes a single API call for billing and accounting. Unlike
+// the aggregated metrics of a MetricsSink, it carries the identity and cost of
+// the request.
+type UsageEvent struct {
+	// Endpoint is "chat", "completion", "embeddings" or "responses".
+	Endpoint string
+	// Caller is the caller set on the context with ContextWithCaller.
+	Caller string
+	// RequestID is the generation ID returned by OpenRouter, empty when the
+	// call failed before one was received.
+	RequestID string
+	// Model is the model that served the request, or the requested model when
+	// the response did not say.
+	Model    string
+	Provider string
+	// Metadata is the metadata of the chat or responses request, for
+	// attributing usage to features or teams.
+	Metadata map[string]string
+	Streamed bool
+	// FinishReason is the finish reason of the first choice of chat and text
+	// completions.
+	FinishReason FinishReason
+	// CacheHit reports whether the response was served by the client-side
+	// CompletionCache or SemanticCache. The token counts are then those of the
+	// cached response and Cost is zero, since nothing was billed.
+	CacheHit         bool
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	// Cost is the cost in credits reported by OpenRouter.
+	Cost float64
+	// Latency is the time from the start of the call until the response was
+	// received, or the end of the stream for streamed calls.
+	Latency time.Duration
+	// Err is the error the call or stream failed with.
+	Err error
+}
+
+// UsageSink receives a UsageEvent after every chat, completion, embeddings
+// and responses call, including streamed ones, whose event is sent when the
+// stream ends. RecordUsage is called synchronously, from the reader goroutine
+// for streams, so implementations must not block and must be safe for
+// concurrent use. ctx is the context of the call, without its cancellation.
+type UsageSink interface {
+	RecordUsage(ctx context.Context, event UsageEvent)
+}
+
+// UsageSinkFunc adapts a function to the UsageSink interface.
+type UsageSinkFunc func(ctx context.Context, event UsageEvent)
+
+func (f UsageSinkFunc) RecordUsage(ctx context.Context, event UsageEvent) {
+	f(ctx, event)
+}
+
+// recordUsage completes event with usage and the latency since startedAt and
+// sends it to the QuotaManager and UsageSink of the client, if any.
+func (c *Client) recordUsage(ctx context.Context, event UsageEvent, usage *Usage, startedAt time.Time) {
+	sink := c.config.UsageSink
+	if sink == nil && c.config.QuotaManager == nil && c.config.RequestLogger == nil {
+		return
+	}
+	if usage != nil {
+		event.PromptTokens = usage.PromptTokens
+		event.CompletionTokens = usage.CompletionTokens
+		event.TotalTokens = usage.TotalTokens
+		if !event.CacheHit {
+			event.Cost = usage.Cost
+		}
+	}
+	event.Latency = time.Since(startedAt)
+	event.Caller, _ = CallerFromContext(ctx)
+	ctx = context.WithoutCancel(ctx)
+	c.recordQuota(ctx, event)
+	c.logRequest(ctx, event)
+	if sink != nil {
+		sink.RecordUsage(ctx, event)
+	}
+}
+
+// streamUsage accumulates the UsageEvent of a stream from its chunks.
+type streamUsage struct {
+	event UsageEvent
+	usage *Usage
+}
+
+func newStreamUsage(endpoint, model string) *streamUsage {
+	return &streamUsage{event: UsageEvent{Endpoint: endpoint, Model: model, Streamed: true}}
+}
+
+// chunk records the fields of a chunk, ignoring those it left empty.
+func (u *streamUsage) chunk(id, model, provider string, usage *Usage) {
+	if id != "" {
+		u.event.RequestID = id
+	}
+	if model != "" {
+		u.event.Model = model
+	}
+	if provider != "" {
+		u.event.Provider = provider
+	}
+	if usage != nil {
+		u.usage = usage
+	}
+}
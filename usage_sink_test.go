@@ -0,0 +1,104 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingUsageSink struct {
+	mu     sync.Mutex
+	events []UsageEvent
+}
+
+func (s *recordingUsageSink) RecordUsage(_ context.Context, event UsageEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func TestUsageSinkReceivesChatCompletions(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t,
+		jsonResponse(http.StatusOK, `{"id":"gen-1","model":"openai/gpt-4o","provider":"OpenAI",`+
+			`"choices":[{"message":{"role":"assistant","content":"Hi"}}],`+
+			`"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7,"cost":0.001}}`),
+		jsonResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"bad request"}}`),
+	)
+	sink := &recordingUsageSink{}
+	client.config.UsageSink = sink
+	client.config.CompletionCache = NewCompletionCache(time.Minute)
+
+	request := ChatCompletionRequest{Model: "openai/gpt-4o", Messages: []ChatCompletionMessage{UserMessage("Hello")}}
+	for range 2 {
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		require.NoError(t, err)
+	}
+	request.Model = "anthropic/claude-3.5-sonnet"
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	require.Error(t, err)
+
+	require.Len(t, sink.events, 3)
+	first := sink.events[0]
+	require.Positive(t, first.Latency)
+	first.Latency = 0
+	require.Equal(t, UsageEvent{
+		Endpoint:         "chat",
+		RequestID:        "gen-1",
+		Model:            "openai/gpt-4o",
+		Provider:         "OpenAI",
+		PromptTokens:     5,
+		CompletionTokens: 2,
+		TotalTokens:      7,
+		Cost:             0.001,
+	}, first)
+
+	require.True(t, sink.events[1].CacheHit)
+	require.Equal(t, 7, sink.events[1].TotalTokens)
+	require.Zero(t, sink.events[1].Cost)
+
+	require.Equal(t, "anthropic/claude-3.5-sonnet", sink.events[2].Model)
+	require.Equal(t, err, sink.events[2].Err)
+}
+
+func TestUsageSinkReceivesStreamsWhenTheyEnd(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newSequenceClient(t, jsonResponse(http.StatusOK, sseBody(
+		`data: {"id":"gen-2","model":"openai/gpt-4o","provider":"Azure","choices":[{"delta":{"content":"Hi"}}]}`,
+		`data: {"id":"gen-2","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4,"cost":0.0005}}`,
+		`data: [DONE]`,
+	)))
+	sink := &recordingUsageSink{}
+	client.config.UsageSink = sink
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []ChatCompletionMessage{UserMessage("Hello")},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	require.True(t, event.Streamed)
+	require.Equal(t, "gen-2", event.RequestID)
+	require.Equal(t, "Azure", event.Provider)
+	require.Equal(t, 4, event.TotalTokens)
+	require.Equal(t, 0.0005, event.Cost)
+	require.NoError(t, event.Err)
+}
@@ -0,0 +1,90 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrMissingAPIKey is wrapped by the AuthError of a client without an API
+	// key.
+	ErrMissingAPIKey = errors.New("no API key configured")
+	// ErrAPIKeyExpired is wrapped by the AuthError of an API key past its
+	// expiry.
+	ErrAPIKeyExpired = errors.New("API key expired")
+)
+
+// AuthError is returned by Verify when the credential of the client is
+// rejected. It unwraps to the *APIError sent by OpenRouter, ErrMissingAPIKey
+// or ErrAPIKeyExpired.
+type AuthError struct {
+	// HTTPStatusCode is the status of the rejected request, 401 or 403, or
+	// zero when the credential was rejected without one.
+	HTTPStatusCode int
+	Err            error
+}
+
+func (e *AuthError) Error() string {
+	return "invalid credential: " + e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Credential describes the API key of a client, as verified by Verify.
+type Credential struct {
+	Label string
+	// FreeTier reports whether the account has never purchased credits, which
+	// limits it to free models with lower rate limits.
+	FreeTier bool
+	// ProvisioningKey reports whether the key manages other keys rather than
+	// calling models.
+	ProvisioningKey bool
+	// RateLimit is the rate limit of the key, if reported.
+	RateLimit *APIRateLimit
+	// Limit and LimitRemaining are the credit limit of the key in USD and
+	// what remains of it, nil for keys without a limit.
+	Limit          *float64
+	LimitRemaining *float64
+	ExpiresAt      *time.Time
+}
+
+// Verify checks the API key of the client with OpenRouter, for service
+// startup and connection settings. It returns the details of the key, or an
+// *AuthError when the key is missing, unknown, disabled or expired. Other
+// errors, e.g. network failures, are returned as is, since they say nothing
+// about the key.
+func (c *Client) Verify(ctx context.Context) (Credential, error) {
+	if c.config.authToken == "" && c.config.KeyPool == nil {
+		return Credential{}, &AuthError{Err: ErrMissingAPIKey}
+	}
+
+	res, err := c.GetCurrentAPIKey(ctx)
+	if err != nil {
+		status, _ := HTTPStatusCode(err)
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return Credential{}, &AuthError{HTTPStatusCode: status, Err: err}
+		}
+		return Credential{}, err
+	}
+
+	key := res.Data
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return Credential{}, &AuthError{Err: ErrAPIKeyExpired}
+	}
+	credential := Credential{
+		Label:           key.Label,
+		FreeTier:        key.IsFreeTier,
+		ProvisioningKey: key.IsProvisioningKey,
+		RateLimit:       key.RateLimit,
+		ExpiresAt:       key.ExpiresAt,
+	}
+	if key.Limit > 0 {
+		limit, remaining := key.Limit, key.LimitRemaining
+		credential.Limit, credential.LimitRemaining = &limit, &remaining
+	}
+	return credential, nil
+}
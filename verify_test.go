@@ -0,0 +1,77 @@
+package openrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	client := newHandlerClient(func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "/api/v1/key", req.URL.Path)
+		return jsonResponse(http.StatusOK, `{"data":{"label":"sk-or-v1-abc...xyz","limit":10,"limit_remaining":7.5,`+
+			`"is_free_tier":true,"rate_limit":{"requests":20,"interval":"10s"}}}`)
+	})
+
+	credential, err := client.Verify(context.Background())
+	require.NoError(t, err)
+	limit, remaining := 10.0, 7.5
+	require.Equal(t, Credential{
+		Label:          "sk-or-v1-abc...xyz",
+		FreeTier:       true,
+		RateLimit:      &APIRateLimit{Requests: 20, Interval: "10s"},
+		Limit:          &limit,
+		LimitRemaining: &remaining,
+	}, credential)
+}
+
+func TestVerifyRejectsCredentials(t *testing.T) {
+	t.Parallel()
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	cases := map[string]struct {
+		response *http.Response
+		status   int
+		err      error
+	}{
+		"unknown": {
+			response: jsonResponse(http.StatusUnauthorized, `{"error":{"code":401,"message":"No auth credentials found"}}`),
+			status:   http.StatusUnauthorized,
+		},
+		"expired": {
+			response: jsonResponse(http.StatusOK, `{"data":{"label":"old","expires_at":"`+expired+`"}}`),
+			err:      ErrAPIKeyExpired,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := newHandlerClient(func(*http.Request) *http.Response { return tc.response })
+
+			_, err := client.Verify(context.Background())
+			var authErr *AuthError
+			require.ErrorAs(t, err, &authErr)
+			require.Equal(t, tc.status, authErr.HTTPStatusCode)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+
+	_, err := NewClient("").Verify(context.Background())
+	require.ErrorIs(t, err, ErrMissingAPIKey)
+	require.Equal(t, ErrorClassAuth, ErrorClass(err))
+
+	client := newHandlerClient(func(*http.Request) *http.Response {
+		return jsonResponse(http.StatusBadGateway, `{"error":{"code":502,"message":"upstream"}}`)
+	})
+	_, err = client.Verify(context.Background())
+	var authErr *AuthError
+	require.False(t, errors.As(err, &authErr))
+}